@@ -2,7 +2,12 @@ package main
 
 import (
 	"log"
+	"strings"
 
+	"soa-socialnetwork/services/accounts/internal/blobstore"
+	"soa-socialnetwork/services/accounts/internal/notify"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"soa-socialnetwork/services/accounts/internal/passhash"
 	"soa-socialnetwork/services/accounts/internal/server"
 	"soa-socialnetwork/services/accounts/internal/service"
 	"soa-socialnetwork/services/common/envvar"
@@ -10,14 +15,113 @@ import (
 
 func extractServiceConfig() service.Config {
 	return service.Config{
-		DbHost:        envvar.MustStringFromEnv("DB_HOST"),
-		DbUser:        envvar.MustStringFromEnv("DB_USER"),
-		DbPassword:    envvar.MustStringFromEnv("DB_PASSWORD"),
-		DbPoolSize:    envvar.MustIntFromEnv("DB_POOL_SIZE"),
-		JwtPrivateKey: envvar.MustEd25519PrivKeyFromEnv("JWT_ED25519_PRIVATE_KEY"),
+		DbHost:                  envvar.MustStringFromEnv("DB_HOST"),
+		DbUser:                  envvar.MustStringFromEnv("DB_USER"),
+		DbPassword:              envvar.MustStringFromEnv("DB_PASSWORD"),
+		DbPoolSize:              envvar.MustIntFromEnv("DB_POOL_SIZE"),
+		JwtPrivateKey:           envvar.MustEd25519PrivKeyFromEnv("JWT_ED25519_PRIVATE_KEY"),
+		ApiTokenHmacKey:         envvar.MustHexBytesFromEnv("API_TOKEN_HMAC_KEY", service.API_TOKEN_HMAC_KEY_MIN_LENGTH),
+		JwtPreviousPublicKeys:   envvar.MustEd25519PubKeyListFromEnv("JWT_ED25519_PREVIOUS_PUBLIC_KEYS"),
+		PasswordHashParams:      passhash.DefaultParams(),
+		Notifier:                createNotifier(),
+		BlobStore:               createBlobStore(),
+		ImagesBaseUrl:           envvar.MustStringFromEnv("IMAGES_BASE_URL"),
+		ExportStore:             createExportStore(),
+		PostsServiceHost:        envvar.MustStringFromEnv("POSTS_SERVICE_HOST"),
+		PostsServicePort:        envvar.MustIntFromEnv("POSTS_SERVICE_PORT"),
+		StatsServiceHost:        envvar.MustStringFromEnv("STATS_SERVICE_HOST"),
+		StatsServicePort:        envvar.MustIntFromEnv("STATS_SERVICE_PORT"),
+		RequireVerifiedContacts: envvar.MustBoolFromEnv("REQUIRE_VERIFIED_CONTACTS"),
+		AdminLogins:             extractAdminLogins(),
+		OidcProviders:           extractOidcProviders(),
 	}
 }
 
+// ADMIN_LOGINS is an optional comma separated list
+func extractAdminLogins() []string {
+	val, err := envvar.TryStringFromEnv("ADMIN_LOGINS")
+	if err != nil {
+		return nil
+	}
+
+	logins := make([]string, 0)
+	for _, login := range strings.Split(val, ",") {
+		login = strings.TrimSpace(login)
+		if login != "" {
+			logins = append(logins, login)
+		}
+	}
+
+	return logins
+}
+
+// OIDC_PROVIDERS is an optional comma separated list of provider names, each
+// provider is configured by OIDC_<NAME>_* variables
+func extractOidcProviders() []oidc.ProviderConfig {
+	val, err := envvar.TryStringFromEnv("OIDC_PROVIDERS")
+	if err != nil {
+		return nil
+	}
+
+	providers := make([]oidc.ProviderConfig, 0)
+	for _, name := range strings.Split(val, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		autoProvision, err := envvar.TryBoolFromEnv(prefix + "AUTO_PROVISION")
+		if err != nil {
+			autoProvision = false
+		}
+
+		providers = append(providers, oidc.ProviderConfig{
+			Name:          name,
+			Issuer:        envvar.MustStringFromEnv(prefix + "ISSUER"),
+			ClientId:      envvar.MustStringFromEnv(prefix + "CLIENT_ID"),
+			ClientSecret:  envvar.MustStringFromEnv(prefix + "CLIENT_SECRET"),
+			RedirectUri:   envvar.MustStringFromEnv(prefix + "REDIRECT_URI"),
+			AutoProvision: autoProvision,
+		})
+	}
+
+	return providers
+}
+
+// Messages are written to files in NOTIFICATIONS_DIR if it is set, otherwise to log
+func createNotifier() notify.Notifier {
+	dir, err := envvar.TryStringFromEnv("NOTIFICATIONS_DIR")
+	if err != nil || dir == "" {
+		return notify.LogNotifier{}
+	}
+
+	notifier, err := notify.NewFileNotifier(dir)
+	if err != nil {
+		log.Fatalf("cannot create file notifier: %v", err)
+	}
+
+	return notifier
+}
+
+func createBlobStore() blobstore.Store {
+	store, err := blobstore.NewLocalStore(envvar.MustStringFromEnv("BLOB_STORE_DIR"))
+	if err != nil {
+		log.Fatalf("cannot create blob store: %v", err)
+	}
+
+	return store
+}
+
+func createExportStore() blobstore.Store {
+	store, err := blobstore.NewLocalStore(envvar.MustStringFromEnv("EXPORTS_DIR"))
+	if err != nil {
+		log.Fatalf("cannot create export store: %v", err)
+	}
+
+	return store
+}
+
 func main() {
 	log.Println("Accounts Service")
 
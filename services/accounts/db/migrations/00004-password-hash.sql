@@ -0,0 +1,6 @@
+ALTER TABLE accounts
+    RENAME COLUMN password TO password_hash;
+
+-- Existing rows still contain plaintext passwords, they are rehashed on next successful login
+ALTER TABLE accounts
+    ALTER COLUMN password_hash TYPE VARCHAR(256);
@@ -0,0 +1,6 @@
+ALTER TABLE api_tokens
+    ADD COLUMN IF NOT EXISTS name VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS api_tokens_account_id_idx ON api_tokens (account_id);
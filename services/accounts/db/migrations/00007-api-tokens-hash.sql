@@ -0,0 +1,10 @@
+-- Raw tokens are replaced by HMAC-SHA256 of them. HMAC key is known only to
+-- the service, so existing rows are converted by the service on startup and
+-- their raw token is erased afterwards.
+ALTER TABLE api_tokens
+    ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64);
+
+ALTER TABLE api_tokens
+    ALTER COLUMN token DROP NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_tokens_token_hash_idx ON api_tokens (token_hash);
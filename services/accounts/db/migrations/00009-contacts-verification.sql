@@ -0,0 +1,23 @@
+-- contacts of existing accounts are trusted, so that requiring verified
+-- contacts does not lock their owners out
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS phone_number_verified BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE accounts
+    ALTER COLUMN email_verified SET DEFAULT FALSE,
+    ALTER COLUMN phone_number_verified SET DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS verification_codes (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id INTEGER NOT NULL,
+    contact_kind VARCHAR(16) NOT NULL,
+    address VARCHAR(320) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    is_used BOOLEAN NOT NULL DEFAULT FALSE,
+    valid_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS verification_codes_account_idx ON verification_codes (account_id, contact_kind);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS auth_failures (
+    key VARCHAR(400) PRIMARY KEY,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS totp_secrets (
+    account_id INTEGER PRIMARY KEY,
+    secret BYTEA NOT NULL,
+    is_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    -- codes of this and earlier time steps are not accepted anymore
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS backup_codes (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id INTEGER NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    is_used BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS backup_codes_account_idx ON backup_codes (account_id);
+
+CREATE TABLE IF NOT EXISTS second_factor_challenges (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    challenge_hash VARCHAR(64) NOT NULL UNIQUE,
+    account_id INTEGER NOT NULL,
+    purpose VARCHAR(16) NOT NULL,
+    -- params of api token created after challenge is passed
+    token_name VARCHAR(64),
+    token_read_access BOOLEAN,
+    token_write_access BOOLEAN,
+    token_ttl INTERVAL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    is_used BOOLEAN NOT NULL DEFAULT FALSE,
+    valid_until TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,14 @@
+ALTER TABLE api_tokens
+    ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+
+-- tokens created before scopes keep their read/write rights
+UPDATE api_tokens
+SET scopes =
+    (CASE WHEN read_access THEN ARRAY['account:read', 'tokens:read', 'posts:read', 'comments:read'] ELSE '{}'::TEXT[] END) ||
+    (CASE WHEN write_access THEN ARRAY['account:manage', 'profile:write', 'tokens:manage', 'posts:write', 'comments:write', 'reactions:write'] ELSE '{}'::TEXT[] END)
+WHERE scopes = '{}';
+
+ALTER TABLE second_factor_challenges
+    DROP COLUMN IF EXISTS token_read_access,
+    DROP COLUMN IF EXISTS token_write_access,
+    ADD COLUMN IF NOT EXISTS token_scopes TEXT[];
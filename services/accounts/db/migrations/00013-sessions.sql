@@ -0,0 +1,13 @@
+-- id is shared with the family of refresh tokens obtained by the login
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY,
+    account_id INTEGER NOT NULL,
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    is_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    valid_until TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_account_id_idx ON sessions (account_id);
@@ -0,0 +1,8 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- trigram indexes serve both prefix (LIKE 'abc%') and fuzzy (%) matching
+CREATE INDEX IF NOT EXISTS profiles_name_trgm_idx ON profiles USING GIN (lower(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS profiles_surname_trgm_idx ON profiles USING GIN (lower(surname) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS profiles_full_name_trgm_idx ON profiles USING GIN (lower(name || ' ' || surname) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS accounts_login_trgm_idx ON accounts USING GIN (lower(login) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS profiles_account_id_idx ON profiles (account_id);
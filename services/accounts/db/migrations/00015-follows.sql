@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS follows (
+    follower_account_id INTEGER NOT NULL,
+    followee_account_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_account_id, followee_account_id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_followee_idx ON follows (followee_account_id, created_at);
+CREATE INDEX IF NOT EXISTS follows_follower_created_at_idx ON follows (follower_account_id, created_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS blocks (
+    blocker_account_id INTEGER NOT NULL,
+    blocked_account_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_account_id, blocked_account_id)
+);
+
+CREATE INDEX IF NOT EXISTS blocks_blocked_idx ON blocks (blocked_account_id);
+CREATE INDEX IF NOT EXISTS blocks_blocker_created_at_idx ON blocks (blocker_account_id, created_at);
@@ -0,0 +1,3 @@
+-- names of image blobs (id with format extension), NULL if not uploaded
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS avatar_image VARCHAR(64);
+ALTER TABLE profiles ADD COLUMN IF NOT EXISTS cover_image VARCHAR(64);
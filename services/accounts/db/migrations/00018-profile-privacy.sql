@@ -0,0 +1,7 @@
+-- visibility of profile fields: 'everyone', 'authenticated' or 'only_me',
+-- birthday and bio stay public by default, contacts are private
+ALTER TABLE profiles
+    ADD COLUMN IF NOT EXISTS birthday_visibility VARCHAR(16) NOT NULL DEFAULT 'everyone',
+    ADD COLUMN IF NOT EXISTS bio_visibility VARCHAR(16) NOT NULL DEFAULT 'everyone',
+    ADD COLUMN IF NOT EXISTS email_visibility VARCHAR(16) NOT NULL DEFAULT 'only_me',
+    ADD COLUMN IF NOT EXISTS phone_number_visibility VARCHAR(16) NOT NULL DEFAULT 'only_me';
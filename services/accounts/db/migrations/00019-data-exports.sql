@@ -0,0 +1,13 @@
+-- archive of a ready export is stored under its id
+CREATE TABLE IF NOT EXISTS data_exports (
+    id UUID PRIMARY KEY,
+    account_id INTEGER NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_account_id_idx ON data_exports (account_id);
+
+-- at most one export of an account is in progress
+CREATE UNIQUE INDEX IF NOT EXISTS data_exports_pending_idx ON data_exports (account_id) WHERE status = 'pending';
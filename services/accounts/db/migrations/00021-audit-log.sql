@@ -0,0 +1,25 @@
+-- append-only log of security relevant events of accounts, entries outlive
+-- deleted accounts
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id INTEGER NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    identifier_type VARCHAR(16) NOT NULL,
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_account_id_idx ON audit_events (account_id, id);
+
+CREATE OR REPLACE FUNCTION forbid_audit_events_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER on_audit_events_change
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW
+EXECUTE FUNCTION forbid_audit_events_change();
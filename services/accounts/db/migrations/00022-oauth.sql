@@ -0,0 +1,28 @@
+-- third-party applications authorized by users with authorization code flow
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    client_id VARCHAR(64) PRIMARY KEY,
+    owner_account_id INTEGER NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    redirect_uris TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS oauth_clients_owner_account_id_idx ON oauth_clients (owner_account_id);
+
+-- only SHA-256 of authorization code is stored
+CREATE TABLE IF NOT EXISTS oauth_codes (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    client_id VARCHAR(64) NOT NULL,
+    account_id INTEGER NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    scopes TEXT[] NOT NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    is_used BOOLEAN NOT NULL DEFAULT FALSE,
+    valid_until TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- tokens issued to applications, NULL for tokens created by the user
+ALTER TABLE api_tokens ADD COLUMN IF NOT EXISTS client_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS api_tokens_client_id_idx ON api_tokens (client_id) WHERE client_id IS NOT NULL;
@@ -0,0 +1,30 @@
+-- external identities of accounts at OpenID Connect providers
+CREATE TABLE IF NOT EXISTS oidc_identities (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    account_id INTEGER NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(320),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT oidc_identities_subject_unique UNIQUE (provider, subject),
+    CONSTRAINT oidc_identities_account_unique UNIQUE (account_id, provider)
+);
+
+-- pending logins at providers, only SHA-256 of state is stored
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    state_hash VARCHAR(64) NOT NULL UNIQUE,
+    provider VARCHAR(32) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    link_account_id INTEGER,
+    valid_until TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS oidc_login_states_valid_until_idx ON oidc_login_states (valid_until);
+
+-- accounts provisioned by providers may have no email or phone number,
+-- empty values would violate uniqueness
+UPDATE accounts SET email = NULL WHERE email = '';
+UPDATE accounts SET phone_number = NULL WHERE phone_number = '';
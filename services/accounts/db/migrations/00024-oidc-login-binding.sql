@@ -0,0 +1,4 @@
+-- logins are bound to the user agent that began them, pending ones without
+-- binding cannot be completed
+ALTER TABLE oidc_login_states
+    ADD COLUMN IF NOT EXISTS binding_hash VARCHAR(64) NOT NULL DEFAULT '';
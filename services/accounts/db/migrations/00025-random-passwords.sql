@@ -0,0 +1,4 @@
+-- accounts provisioned by identity providers get a password unknown to the
+-- user, they may sign in with it only after password reset
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS password_is_random BOOLEAN NOT NULL DEFAULT FALSE;
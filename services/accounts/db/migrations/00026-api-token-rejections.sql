@@ -0,0 +1,3 @@
+-- replays of rejected tokens are recorded in the audit log once per period
+ALTER TABLE api_tokens
+    ADD COLUMN IF NOT EXISTS last_rejected_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+-- stats used to be readable by any token, keep it for tokens that can read posts
+UPDATE api_tokens
+SET scopes = array_append(scopes, 'stats:read')
+WHERE 'posts:read' = ANY(scopes) AND NOT 'stats:read' = ANY(scopes);
@@ -0,0 +1,25 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+)
+
+type BlobNotFound struct{}
+
+func (BlobNotFound) Error() string {
+	return "blob not found"
+}
+
+// Stores immutable binary objects by slash separated keys (e.g., "avatars/1.png").
+// Local implementation is for development and single instance setups,
+// S3-compatible storages are expected to be plugged in here.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Returns BlobNotFound if there is no blob with the key
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Same as Get, but for blobs too large to be read at once
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,56 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "avatars/1.png")
+	require.ErrorAs(t, err, &BlobNotFound{})
+
+	require.NoError(t, store.Put(ctx, "avatars/1.png", []byte("first")))
+	require.NoError(t, store.Put(ctx, "avatars/1.png", []byte("second")))
+
+	data, err := store.Get(ctx, "avatars/1.png")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	reader, err := store.Open(ctx, "avatars/1.png")
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, []byte("second"), data)
+
+	require.NoError(t, store.Delete(ctx, "avatars/1.png"))
+	require.NoError(t, store.Delete(ctx, "avatars/1.png"), "deleting missing blob")
+
+	_, err = store.Get(ctx, "avatars/1.png")
+	require.ErrorAs(t, err, &BlobNotFound{})
+
+	_, err = store.Open(ctx, "avatars/1.png")
+	require.ErrorAs(t, err, &BlobNotFound{})
+}
+
+func TestLocalStoreBadKeys(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "avatars/../../outside", "avatars//1.png", `avatars\1.png`, "avatars/"} {
+		assert.Error(t, store.Put(ctx, key, []byte("data")), "key %q", key)
+		_, err := store.Get(ctx, key)
+		assert.Error(t, err, "key %q", key)
+		_, err = store.Open(ctx, key)
+		assert.Error(t, err, "key %q", key)
+	}
+}
@@ -0,0 +1,48 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Json file of the archive
+type Document struct {
+	Name    string
+	Content any
+}
+
+// Writes documents as indented json files of zip archive in the given order
+func Pack(docs []Document, modified time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	for _, doc := range docs {
+		content, err := json.MarshalIndent(doc.Content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := w.CreateHeader(&zip.FileHeader{
+			Name:     doc.Name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = f.Write(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPack(t *testing.T) {
+	modified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	data, err := Pack([]Document{
+		{Name: "account.json", Content: Account{AccountId: 1, Login: "alice"}},
+		{Name: "likes.json", Content: []Like{{PostId: 2, CreatedAt: modified}}},
+	}, modified)
+	require.NoError(t, err)
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, r.File, 2)
+	assert.Equal(t, "account.json", r.File[0].Name)
+	assert.Equal(t, "likes.json", r.File[1].Name)
+	assert.True(t, modified.Equal(r.File[0].Modified))
+
+	readJson := func(f *zip.File, v any) {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		defer rc.Close()
+
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(content, v))
+	}
+
+	var account Account
+	readJson(r.File[0], &account)
+	assert.Equal(t, int32(1), account.AccountId)
+	assert.Equal(t, "alice", account.Login)
+
+	var likes []Like
+	readJson(r.File[1], &likes)
+	require.Len(t, likes, 1)
+	assert.Equal(t, int32(2), likes[0].PostId)
+	assert.True(t, modified.Equal(likes[0].CreatedAt))
+}
+
+func TestPackEmpty(t *testing.T) {
+	data, err := Pack(nil, time.Now())
+	require.NoError(t, err)
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	assert.Empty(t, r.File)
+}
@@ -0,0 +1,78 @@
+package dataexport
+
+import "time"
+
+type Account struct {
+	AccountId   int32     `json:"account_id"`
+	ProfileId   string    `json:"profile_id"`
+	Login       string    `json:"login"`
+	Email       string    `json:"email"`
+	PhoneNumber string    `json:"phone_number"`
+	Name        string    `json:"name"`
+	Surname     string    `json:"surname"`
+	Birthday    string    `json:"birthday,omitempty"`
+	Bio         string    `json:"bio"`
+	Avatar      string    `json:"avatar,omitempty"`
+	Cover       string    `json:"cover,omitempty"`
+	Privacy     Privacy   `json:"privacy"`
+	Following   []Profile `json:"following"`
+	Followers   []Profile `json:"followers"`
+	Blocked     []Profile `json:"blocked"`
+	Sessions    []Session `json:"sessions"`
+	ExportedAt  time.Time `json:"exported_at"`
+}
+
+type Privacy struct {
+	Birthday    string `json:"birthday"`
+	Bio         string `json:"bio"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+type Profile struct {
+	ProfileId string `json:"profile_id"`
+	Name      string `json:"name"`
+	Surname   string `json:"surname"`
+}
+
+type Session struct {
+	UserAgent  string    `json:"user_agent"`
+	IpAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type Post struct {
+	PostId       int32     `json:"post_id"`
+	Text         string    `json:"text"`
+	SourcePostId *int32    `json:"source_post_id,omitempty"`
+	Pinned       bool      `json:"pinned"`
+	ViewsCount   int32     `json:"views_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Comment struct {
+	CommentId      int32     `json:"comment_id"`
+	PostId         int32     `json:"post_id"`
+	Content        string    `json:"content"`
+	ReplyCommentId *int32    `json:"reply_comment_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type Like struct {
+	PostId    int32     `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DayCount struct {
+	Date  string `json:"date"`
+	Count uint64 `json:"count"`
+}
+
+// Daily metric history of a post of the account
+type PostStats struct {
+	PostId   int32      `json:"post_id"`
+	Views    []DayCount `json:"views"`
+	Likes    []DayCount `json:"likes"`
+	Comments []DayCount `json:"comments"`
+}
@@ -0,0 +1,152 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+const JPEG_QUALITY = 85
+
+type InvalidImage struct {
+	Reason string
+}
+
+func (e InvalidImage) Error() string {
+	return "invalid image: " + e.Reason
+}
+
+// Restrictions and thumbnail size for images of a kind
+type Spec struct {
+	MaxBytes  int
+	MinWidth  int
+	MinHeight int
+	MaxWidth  int
+	MaxHeight int
+	// Thumbnail is scaled down to fit the box keeping aspect ratio
+	ThumbnailWidth  int
+	ThumbnailHeight int
+}
+
+type Processed struct {
+	// "png" or "jpeg", same as of the uploaded image
+	Format    string
+	Original  []byte
+	Thumbnail []byte
+}
+
+// Validates uploaded image against spec and makes its thumbnail. Original is
+// re-encoded, so that metadata (e.g., EXIF with location) is not published.
+func Process(data []byte, spec Spec) (Processed, error) {
+	if len(data) > spec.MaxBytes {
+		return Processed{}, InvalidImage{Reason: fmt.Sprintf("size exceeds %d bytes", spec.MaxBytes)}
+	}
+
+	// dimensions are checked before decoding to not allocate memory for huge images
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, InvalidImage{Reason: "unsupported format, png or jpeg expected"}
+	}
+
+	if cfg.Width < spec.MinWidth || cfg.Height < spec.MinHeight {
+		return Processed{}, InvalidImage{Reason: fmt.Sprintf("dimensions are less than %dx%d", spec.MinWidth, spec.MinHeight)}
+	}
+
+	if cfg.Width > spec.MaxWidth || cfg.Height > spec.MaxHeight {
+		return Processed{}, InvalidImage{Reason: fmt.Sprintf("dimensions exceed %dx%d", spec.MaxWidth, spec.MaxHeight)}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, InvalidImage{Reason: "malformed image data"}
+	}
+
+	original, err := encode(img, format)
+	if err != nil {
+		return Processed{}, err
+	}
+	if len(original) > spec.MaxBytes {
+		return Processed{}, InvalidImage{Reason: fmt.Sprintf("size exceeds %d bytes after re-encoding", spec.MaxBytes)}
+	}
+
+	thumbnail, err := encode(fit(img, spec.ThumbnailWidth, spec.ThumbnailHeight), format)
+	if err != nil {
+		return Processed{}, err
+	}
+
+	return Processed{
+		Format:    format,
+		Original:  original,
+		Thumbnail: thumbnail,
+	}, nil
+}
+
+func encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEG_QUALITY})
+
+	default:
+		return nil, InvalidImage{Reason: "unsupported format, png or jpeg expected"}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Scales image down to fit into maxWidth x maxHeight box keeping aspect ratio.
+// Every destination pixel is the average of source pixels it covers.
+func fit(img image.Image, maxWidth int, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxWidth && srcH <= maxHeight {
+		return img
+	}
+
+	dstW, dstH := maxWidth, srcH*maxWidth/srcW
+	if dstH > maxHeight {
+		dstW, dstH = srcW*maxHeight/srcH, maxHeight
+	}
+	dstW, dstH = max(dstW, 1), max(dstH, 1)
+
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := range dstH {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := range dstW {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			offset := y*dst.Stride + x*4
+			for c := range 4 {
+				dst.Pix[offset+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,106 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var test_spec = Spec{
+	MaxBytes:        1 << 20,
+	MinWidth:        16,
+	MinHeight:       16,
+	MaxWidth:        1000,
+	MaxHeight:       1000,
+	ThumbnailWidth:  32,
+	ThumbnailHeight: 32,
+}
+
+func newTestImage(width int, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePng(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestProcessPng(t *testing.T) {
+	processed, err := Process(encodePng(t, newTestImage(200, 100)), test_spec)
+	require.NoError(t, err)
+	assert.Equal(t, "png", processed.Format)
+
+	original, err := png.Decode(bytes.NewReader(processed.Original))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 100), original.Bounds())
+
+	thumbnail, err := png.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 32, 16), thumbnail.Bounds())
+}
+
+func TestProcessJpeg(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, newTestImage(50, 300), nil))
+
+	processed, err := Process(buf.Bytes(), test_spec)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", processed.Format)
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 5, 32), thumbnail.Bounds())
+}
+
+func TestProcessSmallImageThumbnail(t *testing.T) {
+	processed, err := Process(encodePng(t, newTestImage(20, 20)), test_spec)
+	require.NoError(t, err)
+
+	thumbnail, err := png.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 20, 20), thumbnail.Bounds(), "small image is not upscaled")
+}
+
+func TestProcessInvalid(t *testing.T) {
+	cases := map[string][]byte{
+		"not an image": []byte("definitely not an image"),
+		"too small":    encodePng(t, newTestImage(8, 100)),
+		"too large":    encodePng(t, newTestImage(1001, 20)),
+		"truncated":    encodePng(t, newTestImage(100, 100))[:100],
+	}
+
+	for name, data := range cases {
+		_, err := Process(data, test_spec)
+		assert.ErrorAs(t, err, &InvalidImage{}, name)
+	}
+
+	spec := test_spec
+	spec.MaxBytes = 10
+	_, err := Process(encodePng(t, newTestImage(20, 20)), spec)
+	assert.ErrorAs(t, err, &InvalidImage{})
+}
+
+func TestFitAveragesPixels(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 0, color.RGBA{R: 255, A: 255})
+	img.Set(0, 1, color.RGBA{B: 255, A: 255})
+	img.Set(1, 1, color.RGBA{B: 255, A: 255})
+
+	fitted := fit(img, 1, 1)
+	require.Equal(t, image.Rect(0, 0, 1, 1), fitted.Bounds())
+	assert.Equal(t, color.RGBA{R: 127, B: 127, A: 255}, fitted.At(0, 0))
+}
@@ -1,7 +1,41 @@
 package models
 
+import (
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
 type AccountId int32
 
+type PasswordHash string
+
 type AccountParams struct {
 	Id AccountId
 }
+
+type AccountCredentials struct {
+	Id           AccountId
+	PasswordHash PasswordHash
+}
+
+type AccountContacts struct {
+	Email               string
+	EmailVerified       bool
+	PhoneNumber         string
+	PhoneNumberVerified bool
+}
+
+type Suspension struct {
+	Reason      string
+	SuspendedAt time.Time
+	// Suspension is permanent if not set
+	Until opt.Option[time.Time]
+}
+
+// Role and current suspension of account
+type AccountStatus struct {
+	Role soajwt.Role
+	// Not set if account is not suspended or its suspension has ended
+	Suspension opt.Option[Suspension]
+}
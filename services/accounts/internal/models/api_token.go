@@ -1,14 +1,30 @@
 package models
 
-import "time"
+import (
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
 
 type ApiToken string
 
+// HMAC of api token, only it is stored in database
+type ApiTokenHash string
+
+// Public token identifier, the token itself is never shown after creation
+type ApiTokenId int32
+
 type ApiTokenData struct {
-	Token       ApiToken
-	AccountId   int
+	Id        ApiTokenId
+	TokenHash ApiTokenHash
+	Name      string
+	AccountId int
+	Scopes    []soatoken.Scope
+	// Derived from scopes for clients which do not know about them
 	ReadAccess  bool
 	WriteAccess bool
+	IsRevoked   bool
 	CreatedAt   time.Time
 	ValidUntil  time.Time
+	LastUsedAt  opt.Option[time.Time]
 }
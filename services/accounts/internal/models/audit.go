@@ -0,0 +1,23 @@
+package models
+
+import (
+	"soa-socialnetwork/services/accounts/pkg/audit"
+	"time"
+)
+
+type AuditEventId int64
+
+type AuditEventParams struct {
+	AccountId      AccountId
+	Type           audit.EventType
+	IdentifierType audit.IdentifierType
+	ClientIp       string
+	UserAgent      string
+}
+
+type AuditEvent struct {
+	AuditEventParams
+
+	Id        AuditEventId
+	CreatedAt time.Time
+}
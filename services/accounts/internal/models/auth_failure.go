@@ -0,0 +1,17 @@
+package models
+
+import (
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+// Identifies what failed authentication attempts are counted for,
+// e.g. "login:<login>" or "client:<ip>"
+type AuthFailureKey string
+
+type AuthFailureData struct {
+	Key          AuthFailureKey
+	FailedCount  int
+	LastFailedAt time.Time
+	LockedUntil  opt.Option[time.Time]
+}
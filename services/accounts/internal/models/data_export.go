@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type DataExportId string
+
+type DataExportStatus string
+
+const (
+	DATA_EXPORT_PENDING DataExportStatus = "pending"
+	DATA_EXPORT_READY   DataExportStatus = "ready"
+	DATA_EXPORT_FAILED  DataExportStatus = "failed"
+)
+
+type DataExport struct {
+	Id        DataExportId
+	AccountId AccountId
+	Status    DataExportStatus
+	CreatedAt time.Time
+	// Zero until the export is finished
+	FinishedAt time.Time
+}
@@ -0,0 +1,6 @@
+package models
+
+type FollowCounts struct {
+	Followers int
+	Following int
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+// Public identifier of third-party application
+type OAuthClientId string
+
+type OAuthClientParams struct {
+	OwnerAccountId AccountId
+	Name           string
+	// Authorization codes are delivered only to these exact uris
+	RedirectUris []string
+}
+
+type OAuthClient struct {
+	OAuthClientParams
+
+	ClientId  OAuthClientId
+	CreatedAt time.Time
+}
+
+type OAuthCodeId int
+
+// Only SHA-256 of authorization code is stored, plain code is sent to client
+type OAuthCodeHash string
+
+type OAuthCodeParams struct {
+	ClientId    OAuthClientId
+	AccountId   AccountId
+	RedirectUri string
+	Scopes      []soatoken.Scope
+	// S256 PKCE challenge, code is exchanged only with its verifier
+	CodeChallenge string
+}
+
+type OAuthCodeData struct {
+	OAuthCodeParams
+
+	Id         OAuthCodeId
+	IsUsed     bool
+	ValidUntil time.Time
+}
+
+// Application holding unrevoked tokens of the account
+type AuthorizedApp struct {
+	ClientId OAuthClientId
+	Name     string
+	// Union of scopes of all tokens of the application
+	Scopes       []soatoken.Scope
+	AuthorizedAt time.Time
+	LastUsedAt   opt.Option[time.Time]
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+type OidcIdentityParams struct {
+	AccountId AccountId
+	// Name of configured provider
+	Provider string
+	// Identifier of the user at provider, unique per provider
+	Subject string
+	// Email reported by provider at linking, informational only
+	Email string
+}
+
+type OidcIdentity struct {
+	OidcIdentityParams
+
+	CreatedAt   time.Time
+	LastLoginAt opt.Option[time.Time]
+}
+
+// Only SHA-256 of state is stored, plain state is passed through provider
+type OidcLoginStateHash string
+
+type OidcLoginStateParams struct {
+	Provider string
+	// Expected in ID token
+	Nonce string
+	// PKCE verifier sent to provider with the code
+	CodeVerifier string
+	// Set when the identity is linked to the account instead of login
+	LinkAccountId opt.Option[AccountId]
+	// SHA-256 of the secret returned to user agent that began the login
+	BindingHash OidcLoginStateHash
+}
+
+type OidcLoginState struct {
+	OidcLoginStateParams
+
+	ValidUntil time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type PasswordResetCodeId int
+
+// Only SHA-256 of reset code is stored, plain code is delivered to user once
+type PasswordResetCodeHash string
+
+type PasswordResetCodeData struct {
+	Id         PasswordResetCodeId
+	AccountId  AccountId
+	CodeHash   PasswordResetCodeHash
+	Attempts   int
+	ValidUntil time.Time
+}
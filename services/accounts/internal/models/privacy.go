@@ -0,0 +1,17 @@
+package models
+
+// Who can see a profile field besides its owner
+type Visibility string
+
+const (
+	VISIBILITY_EVERYONE      Visibility = "everyone"
+	VISIBILITY_AUTHENTICATED Visibility = "authenticated"
+	VISIBILITY_ONLY_ME       Visibility = "only_me"
+)
+
+type PrivacySettings struct {
+	Birthday    Visibility
+	Bio         Visibility
+	Email       Visibility
+	PhoneNumber Visibility
+}
@@ -11,4 +11,22 @@ type ProfileData struct {
 	Surname   string
 	Birthday  time.Time
 	Bio       string
+	// Image names, empty if not uploaded
+	AvatarImage string
+	CoverImage  string
+	Privacy     PrivacySettings
+}
+
+type ProfileImageKind int
+
+const (
+	PROFILE_IMAGE_AVATAR ProfileImageKind = iota
+	PROFILE_IMAGE_COVER
+)
+
+// Public part of profile, login is not exposed as it is used to sign in
+type ProfileCard struct {
+	ProfileId ProfileId
+	Name      string
+	Surname   string
 }
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Only SHA-256 of refresh token is stored, plain token is returned to client once
+type RefreshTokenHash string
+
+// All tokens obtained from one authentication by rotation share the same family
+type RefreshTokenFamilyId string
+
+type RefreshTokenData struct {
+	AccountId  AccountId
+	FamilyId   RefreshTokenFamilyId
+	IsUsed     bool
+	IsRevoked  bool
+	CreatedAt  time.Time
+	ValidUntil time.Time
+}
@@ -1,10 +1,12 @@
 package models
 
 type RegistrationData struct {
-	Login       string
-	Password    string
-	Email       string
-	PhoneNumber string
-	Name        string
-	Surname     string
+	Login        string
+	PasswordHash PasswordHash
+	Email        string
+	PhoneNumber  string
+	Name         string
+	Surname      string
+	// Password is not known to the user, e.g. for provisioned accounts
+	PasswordIsRandom bool
 }
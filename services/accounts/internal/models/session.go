@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Session is started by successful authentication and continued by refresh
+// token rotation, its id is the id of refresh token family
+type SessionId string
+
+type SessionParams struct {
+	Id        SessionId
+	AccountId AccountId
+	UserAgent string
+	IpAddress string
+}
+
+type SessionData struct {
+	SessionParams
+
+	IsRevoked  bool
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ValidUntil time.Time
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+type TotpSecretData struct {
+	AccountId AccountId
+	Secret    []byte
+	// Secret is enabled only after user confirms it with a code
+	IsEnabled bool
+	// Last time step whose code was accepted, protects from code replay
+	LastUsedStep int64
+}
+
+// Only SHA-256 of backup code is stored, plain codes are shown to user once
+type BackupCodeHash string
+
+type SecondFactorChallengeId int
+
+// Only SHA-256 of challenge is stored, plain challenge is returned to client
+type SecondFactorChallengeHash string
+
+// What is issued after second factor is passed
+type SecondFactorPurpose string
+
+const (
+	SECOND_FACTOR_AUTHENTICATE SecondFactorPurpose = "authenticate"
+	SECOND_FACTOR_API_TOKEN    SecondFactorPurpose = "api_token"
+)
+
+// Params of api token requested before second factor was passed
+type PendingApiToken struct {
+	Name   string
+	Scopes []soatoken.Scope
+	Ttl    time.Duration
+}
+
+type SecondFactorChallengeParams struct {
+	AccountId AccountId
+	Purpose   SecondFactorPurpose
+	// Has value only for SECOND_FACTOR_API_TOKEN purpose
+	ApiToken opt.Option[PendingApiToken]
+}
+
+type SecondFactorChallengeData struct {
+	SecondFactorChallengeParams
+
+	Id       SecondFactorChallengeId
+	Attempts int
+}
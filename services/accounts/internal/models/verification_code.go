@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type ContactKind string
+
+const (
+	CONTACT_EMAIL        ContactKind = "email"
+	CONTACT_PHONE_NUMBER ContactKind = "phone_number"
+)
+
+type VerificationCodeId int
+
+// Only SHA-256 of verification code is stored, plain code is delivered to user once
+type VerificationCodeHash string
+
+type VerificationCodeData struct {
+	Id        VerificationCodeId
+	AccountId AccountId
+	Kind      ContactKind
+	// Contact value the code was sent to
+	Address    string
+	CodeHash   VerificationCodeHash
+	Attempts   int
+	ValidUntil time.Time
+}
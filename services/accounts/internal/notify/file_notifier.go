@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Appends messages as json lines to file named after recipient address in the
+// given directory, so that they can be read by e2e tests
+type FileNotifier struct {
+	dir string
+	mu  sync.Mutex
+}
+
+type FileNotification struct {
+	Channel string    `json:"channel"`
+	Subject string    `json:"subject"`
+	Text    string    `json:"text"`
+	Code    string    `json:"code"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+func NewFileNotifier(dir string) (*FileNotifier, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileNotifier{
+		dir: dir,
+	}, nil
+}
+
+func (n *FileNotifier) Send(to Recipient, msg Message) error {
+	line, err := json.Marshal(FileNotification{
+		Channel: to.Channel.String(),
+		Subject: msg.Subject,
+		Text:    msg.Text,
+		Code:    msg.Code,
+		SentAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	path, err := n.recipientFile(to.Address)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func (n *FileNotifier) recipientFile(address string) (string, error) {
+	if address == "" || strings.ContainsAny(address, `/\`) || address == "." || address == ".." {
+		return "", fmt.Errorf("bad recipient address %q", address)
+	}
+
+	return filepath.Join(n.dir, address), nil
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifierSend(t *testing.T) {
+	dir := t.TempDir()
+	notifier, err := NewFileNotifier(dir)
+	require.NoError(t, err)
+
+	to := Recipient{
+		Channel: CHANNEL_EMAIL,
+		Address: "some_user@yahoo.com",
+	}
+	for _, code := range []string{"111111", "222222"} {
+		err = notifier.Send(to, Message{
+			Subject: "Password reset",
+			Text:    "code " + code,
+			Code:    code,
+		})
+		require.NoError(t, err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, to.Address))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var notifications []FileNotification
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var n FileNotification
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &n))
+		notifications = append(notifications, n)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, notifications, 2)
+	assert.Equal(t, "email", notifications[0].Channel)
+	assert.Equal(t, "111111", notifications[0].Code)
+	assert.Equal(t, "222222", notifications[1].Code)
+}
+
+func TestFileNotifierBadAddress(t *testing.T) {
+	notifier, err := NewFileNotifier(t.TempDir())
+	require.NoError(t, err)
+
+	for _, address := range []string{"", "..", "../escape", "a/b"} {
+		err = notifier.Send(Recipient{Address: address}, Message{})
+		assert.Error(t, err, "address %q", address)
+	}
+}
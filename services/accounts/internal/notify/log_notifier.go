@@ -0,0 +1,11 @@
+package notify
+
+import "log"
+
+// Writes messages to service log
+type LogNotifier struct{}
+
+func (LogNotifier) Send(to Recipient, msg Message) error {
+	log.Printf("notification via %s to %s: %s: %s", to.Channel, to.Address, msg.Subject, msg.Text)
+	return nil
+}
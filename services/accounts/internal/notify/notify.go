@@ -0,0 +1,40 @@
+package notify
+
+type Channel int
+
+const (
+	CHANNEL_EMAIL Channel = iota
+	CHANNEL_SMS
+)
+
+func (c Channel) String() string {
+	switch c {
+	case CHANNEL_EMAIL:
+		return "email"
+
+	case CHANNEL_SMS:
+		return "sms"
+	}
+
+	return "unknown"
+}
+
+type Recipient struct {
+	Channel Channel
+	// Email or phone number depending on channel
+	Address string
+}
+
+type Message struct {
+	Subject string
+	Text    string
+	// One-time code contained in text, if any
+	Code string
+}
+
+// Delivers messages to account owners. Real email and sms gateways are
+// expected to be plugged in here, local implementations are for development
+// and tests.
+type Notifier interface {
+	Send(Recipient, Message) error
+}
@@ -0,0 +1,21 @@
+package oidc
+
+import "fmt"
+
+// Identity provider rejected the authorization code, e.g. it is expired,
+// used or code verifier does not match
+type CodeRejected struct {
+	Reason string
+}
+
+func (e CodeRejected) Error() string {
+	return fmt.Sprintf("authorization code rejected by provider: %s", e.Reason)
+}
+
+type InvalidIdToken struct {
+	Reason string
+}
+
+func (e InvalidIdToken) Error() string {
+	return fmt.Sprintf("invalid id token: %s", e.Reason)
+}
@@ -0,0 +1,7 @@
+package oidc
+
+import "time"
+
+func SetKeysRefetchInterval(p *Provider, interval time.Duration) {
+	p.keysRefetchInterval = interval
+}
@@ -0,0 +1,304 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const DISCOVERY_PATH = "/.well-known/openid-configuration"
+
+// Scopes requested from identity providers, enough for subject, email and name
+const REQUESTED_SCOPES = "openid email profile"
+
+// Limit of identity provider responses
+const MAX_RESPONSE_SIZE = 1 << 20
+
+// Allowed clock difference with identity provider for token times
+const CLOCK_LEEWAY = time.Minute
+
+type ProviderConfig struct {
+	// Short name used in api paths, e.g. "corp"
+	Name string
+	// Issuer url, configuration is discovered at its well-known path
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	// Where identity provider sends user agent back with code and state,
+	// must be registered at the provider
+	RedirectUri string
+	// Creates accounts for unknown users on their first login, otherwise
+	// identities must be linked to existing accounts first
+	AutoProvision bool
+}
+
+// Claims of a verified ID token
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	GivenName         string
+	FamilyName        string
+	PreferredUsername string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IdToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// Relying party of a single identity provider using authorization code flow
+// with PKCE. Provider configuration and keys are fetched on first use, so
+// unavailable provider does not prevent the service from starting.
+type Provider struct {
+	cfg    ProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *keySet
+	// Minimal time between key set fetches caused by unknown key ids
+	keysRefetchInterval time.Duration
+}
+
+func NewProvider(cfg ProviderConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Provider{
+		cfg:                 cfg,
+		client:              client,
+		keysRefetchInterval: KEYS_REFETCH_INTERVAL,
+	}
+}
+
+// PKCE code challenge of the verifier (RFC 7636, S256 method)
+func CodeChallengeS256(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func (p *Provider) Config() ProviderConfig {
+	return p.cfg
+}
+
+// Url of the provider's login page, state and nonce are returned back with
+// the code and in the ID token respectively
+func (p *Provider) AuthorizationUrl(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	uri, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := uri.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientId)
+	query.Set("redirect_uri", p.cfg.RedirectUri)
+	query.Set("scope", REQUESTED_SCOPES)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	uri.RawQuery = query.Encode()
+
+	return uri.String(), nil
+}
+
+// Exchanges the code at token endpoint and verifies the returned ID token
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (Identity, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectUri)
+	form.Set("client_id", p.cfg.ClientId)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Identity{}, err
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	err = json.NewDecoder(io.LimitReader(resp.Body, MAX_RESPONSE_SIZE)).Decode(&tokens)
+	if err != nil {
+		return Identity{}, fmt.Errorf("malformed token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if tokens.Error != "" {
+			return Identity{}, CodeRejected{Reason: tokens.Error}
+		}
+
+		return Identity{}, fmt.Errorf("token endpoint responded with status %d", resp.StatusCode)
+	}
+
+	if tokens.IdToken == "" {
+		return Identity{}, InvalidIdToken{Reason: "no id token in response"}
+	}
+
+	return p.verifyIdToken(ctx, discovery, tokens.IdToken, nonce)
+}
+
+func (p *Provider) verifyIdToken(ctx context.Context, discovery discoveryDocument, rawToken string, nonce string) (Identity, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(rawToken, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods(supported_signing_methods),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.cfg.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(CLOCK_LEEWAY),
+	)
+	if err != nil {
+		return Identity{}, InvalidIdToken{Reason: err.Error()}
+	}
+
+	// nonce binds the token to the login attempt, so that a token
+	// intercepted elsewhere cannot be replayed
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return Identity{}, InvalidIdToken{Reason: "nonce mismatch"}
+	}
+
+	if claims.Subject == "" {
+		return Identity{}, InvalidIdToken{Reason: "no subject"}
+	}
+
+	return Identity{
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		Name:              claims.Name,
+		GivenName:         claims.GivenName,
+		FamilyName:        claims.FamilyName,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return *p.discovery, nil
+	}
+
+	var discovery discoveryDocument
+	err := p.getJson(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+DISCOVERY_PATH, &discovery)
+	if err != nil {
+		return discoveryDocument{}, fmt.Errorf("cannot discover provider %s: %w", p.cfg.Name, err)
+	}
+
+	// issuer must be the one trusted by configuration, see OpenID Connect
+	// Discovery 4.3
+	if discovery.Issuer != p.cfg.Issuer {
+		return discoveryDocument{}, fmt.Errorf("provider %s reports issuer %q instead of %q", p.cfg.Name, discovery.Issuer, p.cfg.Issuer)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksUri == "" {
+		return discoveryDocument{}, fmt.Errorf("provider %s configuration misses endpoints", p.cfg.Name)
+	}
+
+	p.discovery = &discovery
+	return discovery, nil
+}
+
+// Keys are refetched when a token is signed by unknown key, as providers
+// rotate them, but not more often than once per keysRefetchInterval
+func (p *Provider) key(ctx context.Context, discovery discoveryDocument, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil {
+		key, err := p.keys.find(kid)
+		if err == nil || time.Since(p.keys.fetchedAt) < p.keysRefetchInterval {
+			return key, err
+		}
+	}
+
+	var jwks jsonWebKeySet
+	err := p.getJson(ctx, discovery.JwksUri, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch keys of provider %s: %w", p.cfg.Name, err)
+	}
+
+	keys, err := newKeySet(jwks)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	return keys.find(kid)
+}
+
+func (p *Provider) getJson(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, MAX_RESPONSE_SIZE)).Decode(v)
+}
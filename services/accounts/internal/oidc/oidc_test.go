@@ -0,0 +1,154 @@
+package oidc_test
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"soa-socialnetwork/services/accounts/internal/oidc/oidctest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectUri = "https://soa.example.com/oidc/callback"
+
+var testUser = oidctest.User{
+	Subject:           "248289761001",
+	Email:             "jane.doe@corp.example.com",
+	EmailVerified:     true,
+	Name:              "Jane Doe",
+	GivenName:         "Jane",
+	FamilyName:        "Doe",
+	PreferredUsername: "jane",
+}
+
+func newMockIdP(t *testing.T) *oidctest.MockIdP {
+	idp, err := oidctest.NewMockIdP("soa-client", "soa-secret")
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+type loginAttempt struct {
+	code     string
+	verifier string
+	nonce    string
+}
+
+func startLogin(t *testing.T, provider *oidc.Provider, idp *oidctest.MockIdP, user oidctest.User) loginAttempt {
+	verifier := strings.Repeat("v", 43)
+	authorizationUrl, err := provider.AuthorizationUrl(context.Background(), "some-state", "some-nonce", oidc.CodeChallengeS256(verifier))
+	require.NoError(t, err)
+
+	code, state, err := idp.Login(authorizationUrl, user)
+	require.NoError(t, err)
+	require.Equal(t, "some-state", state)
+
+	return loginAttempt{
+		code:     code,
+		verifier: verifier,
+		nonce:    "some-nonce",
+	}
+}
+
+func TestProviderLogin(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := oidc.NewProvider(idp.ProviderConfig("corp", redirectUri), nil)
+
+	login := startLogin(t, provider, idp, testUser)
+	identity, err := provider.Exchange(context.Background(), login.code, login.verifier, login.nonce)
+	require.NoError(t, err)
+
+	assert.Equal(t, oidc.Identity{
+		Subject:           testUser.Subject,
+		Email:             testUser.Email,
+		EmailVerified:     true,
+		Name:              testUser.Name,
+		GivenName:         testUser.GivenName,
+		FamilyName:        testUser.FamilyName,
+		PreferredUsername: testUser.PreferredUsername,
+	}, identity)
+
+	_, err = provider.Exchange(context.Background(), login.code, login.verifier, login.nonce)
+	require.ErrorAs(t, err, &oidc.CodeRejected{}, "code must be single-use")
+}
+
+func TestProviderRejectsWrongVerifierAndNonce(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := oidc.NewProvider(idp.ProviderConfig("corp", redirectUri), nil)
+
+	login := startLogin(t, provider, idp, testUser)
+	_, err := provider.Exchange(context.Background(), login.code, strings.Repeat("w", 43), login.nonce)
+	require.ErrorAs(t, err, &oidc.CodeRejected{})
+
+	login = startLogin(t, provider, idp, testUser)
+	_, err = provider.Exchange(context.Background(), login.code, login.verifier, "other-nonce")
+	require.ErrorAs(t, err, &oidc.InvalidIdToken{})
+}
+
+func TestProviderRejectsInvalidIdTokens(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := oidc.NewProvider(idp.ProviderConfig("corp", redirectUri), nil)
+
+	tweaks := map[string]func(jwt.MapClaims){
+		"other audience": func(c jwt.MapClaims) { c["aud"] = "other-client" },
+		"other issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no expiration":  func(c jwt.MapClaims) { delete(c, "exp") },
+		"no subject":     func(c jwt.MapClaims) { delete(c, "sub") },
+	}
+
+	for name, tweak := range tweaks {
+		idp.TweakClaims = tweak
+		login := startLogin(t, provider, idp, testUser)
+		_, err := provider.Exchange(context.Background(), login.code, login.verifier, login.nonce)
+		assert.ErrorAs(t, err, &oidc.InvalidIdToken{}, name)
+	}
+}
+
+func TestProviderKeyRotation(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := oidc.NewProvider(idp.ProviderConfig("corp", redirectUri), nil)
+
+	login := startLogin(t, provider, idp, testUser)
+	_, err := provider.Exchange(context.Background(), login.code, login.verifier, login.nonce)
+	require.NoError(t, err)
+
+	require.NoError(t, idp.RotateKey())
+
+	// keys were fetched just now, unknown key id does not cause refetch
+	login = startLogin(t, provider, idp, testUser)
+	_, err = provider.Exchange(context.Background(), login.code, login.verifier, login.nonce)
+	require.ErrorAs(t, err, &oidc.InvalidIdToken{})
+
+	oidc.SetKeysRefetchInterval(provider, 0)
+	login = startLogin(t, provider, idp, testUser)
+	_, err = provider.Exchange(context.Background(), login.code, login.verifier, login.nonce)
+	require.NoError(t, err)
+}
+
+func TestProviderIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	cfg := idp.ProviderConfig("corp", redirectUri)
+	cfg.Issuer += "/tenant"
+	provider := oidc.NewProvider(cfg, nil)
+
+	_, err := provider.AuthorizationUrl(context.Background(), "state", "nonce", "challenge")
+	require.Error(t, err)
+}
+
+func TestProviderAuthorizationUrl(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := oidc.NewProvider(idp.ProviderConfig("corp", redirectUri), nil)
+
+	authorizationUrl, err := provider.AuthorizationUrl(context.Background(), "state", "nonce", "challenge")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(authorizationUrl, idp.Issuer()+"/authorize?"))
+	for _, param := range []string{"client_id=soa-client", "scope=openid+email+profile", "code_challenge_method=S256", "nonce=nonce", "state=state"} {
+		assert.Contains(t, authorizationUrl, param)
+	}
+}
@@ -0,0 +1,269 @@
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const ID_TOKEN_TTL = 5 * time.Minute
+
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	GivenName         string
+	FamilyName        string
+	PreferredUsername string
+}
+
+type pendingCode struct {
+	user          User
+	redirectUri   string
+	nonce         string
+	codeChallenge string
+}
+
+// In-process OpenID Connect identity provider for tests. It serves
+// discovery, keys and token endpoints over http; user login on the
+// authorization page is simulated with Login.
+type MockIdP struct {
+	ClientId     string
+	ClientSecret string
+	// Changes claims of issued ID tokens, e.g. to test their validation
+	TweakClaims func(jwt.MapClaims)
+
+	server *httptest.Server
+
+	mu    sync.Mutex
+	key   *rsa.PrivateKey
+	kid   string
+	codes map[string]pendingCode
+}
+
+func NewMockIdP(clientId string, clientSecret string) (*MockIdP, error) {
+	m := &MockIdP{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		codes:        make(map[string]pendingCode),
+	}
+
+	err := m.RotateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+oidc.DISCOVERY_PATH, m.handleDiscovery)
+	mux.HandleFunc("GET /jwks", m.handleJwks)
+	mux.HandleFunc("POST /token", m.handleToken)
+	m.server = httptest.NewServer(mux)
+
+	return m, nil
+}
+
+func (m *MockIdP) Close() {
+	m.server.Close()
+}
+
+func (m *MockIdP) Issuer() string {
+	return m.server.URL
+}
+
+// Provider configuration of a relying party registered at this provider
+func (m *MockIdP) ProviderConfig(name string, redirectUri string) oidc.ProviderConfig {
+	return oidc.ProviderConfig{
+		Name:         name,
+		Issuer:       m.Issuer(),
+		ClientId:     m.ClientId,
+		ClientSecret: m.ClientSecret,
+		RedirectUri:  redirectUri,
+	}
+}
+
+// Replaces the signing key, only the new one is published
+func (m *MockIdP) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	kid, err := randomString()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.key = key
+	m.kid = kid
+	return nil
+}
+
+// Simulates successful login of the user on the authorization page, returns
+// code and state the provider would pass to the redirect uri
+func (m *MockIdP) Login(authorizationUrl string, user User) (code string, state string, err error) {
+	uri, err := url.Parse(authorizationUrl)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := uri.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != m.ClientId {
+		return "", "", errors.New("unexpected response type or client")
+	}
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		return "", "", errors.New("pkce is required")
+	}
+
+	code, err = randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes[code] = pendingCode{
+		user:          user,
+		redirectUri:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+
+	return code, query.Get("state"), nil
+}
+
+func (m *MockIdP) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJson(w, http.StatusOK, map[string]any{
+		"issuer":                 m.Issuer(),
+		"authorization_endpoint": m.Issuer() + "/authorize",
+		"token_endpoint":         m.Issuer() + "/token",
+		"jwks_uri":               m.Issuer() + "/jwks",
+	})
+}
+
+func (m *MockIdP) handleJwks(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	jwk := oidc.NewRsaJwk(m.kid, &m.key.PublicKey)
+	m.mu.Unlock()
+
+	writeJson(w, http.StatusOK, map[string]any{
+		"keys": []oidc.JsonWebKey{jwk},
+	})
+}
+
+func (m *MockIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	if r.PostForm.Get("client_id") != m.ClientId || r.PostForm.Get("client_secret") != m.ClientSecret {
+		writeTokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeTokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	m.mu.Lock()
+	code, ok := m.codes[r.PostForm.Get("code")]
+	// codes are single-use
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	if !ok || code.redirectUri != r.PostForm.Get("redirect_uri") {
+		writeTokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	hash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(hash[:]) != code.codeChallenge {
+		writeTokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	idToken, err := m.issueIdToken(code)
+	if err != nil {
+		writeTokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	writeJson(w, http.StatusOK, map[string]any{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   int(ID_TOKEN_TTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func (m *MockIdP) issueIdToken(code pendingCode) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.Issuer(),
+		"sub":            code.user.Subject,
+		"aud":            m.ClientId,
+		"iat":            now.Unix(),
+		"exp":            now.Add(ID_TOKEN_TTL).Unix(),
+		"nonce":          code.nonce,
+		"email":          code.user.Email,
+		"email_verified": code.user.EmailVerified,
+		"name":           code.user.Name,
+		"given_name":     code.user.GivenName,
+		"family_name":    code.user.FamilyName,
+	}
+	if code.user.PreferredUsername != "" {
+		claims["preferred_username"] = code.user.PreferredUsername
+	}
+
+	if m.TweakClaims != nil {
+		m.TweakClaims(claims)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	return token.SignedString(m.key)
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeTokenError(w http.ResponseWriter, status int, code string) {
+	writeJson(w, status, map[string]any{
+		"error": code,
+	})
+}
+
+func randomString() (string, error) {
+	var raw [16]byte
+	_, err := rand.Read(raw[:])
+	if err != nil {
+		return "", fmt.Errorf("cannot generate random string: %w", err)
+	}
+
+	return hex.EncodeToString(raw[:]), nil
+}
@@ -0,0 +1,147 @@
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Params are argon2id cost parameters. They are stored inside every produced
+// hash, so the cost can be raised later without invalidating old passwords.
+type Params struct {
+	// Memory in KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Second recommended option from RFC 9106
+func DefaultParams() Params {
+	return Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+const ALGORITHM_ARGON2ID = "argon2id"
+
+// Stored values without this prefix are legacy plaintext passwords, which
+// may start with '$' too
+const argon2id_prefix = "$" + ALGORITHM_ARGON2ID + "$"
+
+var error_malformed_hash = errors.New("malformed password hash")
+var error_unknown_algorithm = errors.New("unknown password hashing algorithm")
+
+type Hasher struct {
+	params Params
+}
+
+func New(params Params) Hasher {
+	return Hasher{
+		params: params,
+	}
+}
+
+// Hash produces PHC-formatted string:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func (h *Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		ALGORITHM_ARGON2ID,
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks password against stored hash in constant time. needsRehash is set
+// when the stored value is a legacy plaintext password or was produced with
+// parameters that differ from hasher ones.
+func (h *Hasher) Verify(password string, encoded string) (match bool, needsRehash bool, err error) {
+	if !strings.HasPrefix(encoded, argon2id_prefix) {
+		match := subtle.ConstantTimeCompare([]byte(password), []byte(encoded)) == 1
+		return match, true, nil
+	}
+
+	decoded, err := decode(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	key := argon2.IDKey([]byte(password), decoded.salt, decoded.params.Iterations, decoded.params.Memory, decoded.params.Parallelism, decoded.params.KeyLength)
+	if subtle.ConstantTimeCompare(key, decoded.key) != 1 {
+		return false, false, nil
+	}
+
+	return true, decoded.params != h.params, nil
+}
+
+type decodedHash struct {
+	params Params
+	salt   []byte
+	key    []byte
+}
+
+func decode(encoded string) (decodedHash, error) {
+	// leading '$' produces empty first part
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return decodedHash{}, error_malformed_hash
+	}
+
+	if parts[1] != ALGORITHM_ARGON2ID {
+		return decodedHash{}, error_unknown_algorithm
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return decodedHash{}, error_malformed_hash
+	}
+
+	var params Params
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return decodedHash{}, error_malformed_hash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return decodedHash{}, error_malformed_hash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return decodedHash{}, error_malformed_hash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return decodedHash{
+		params: params,
+		salt:   salt,
+		key:    key,
+	}, nil
+}
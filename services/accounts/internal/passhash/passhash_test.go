@@ -0,0 +1,91 @@
+package passhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testParams() Params {
+	return Params{
+		Memory:      1024,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func TestHashAndVerify(t *testing.T) {
+	hasher := New(testParams())
+
+	hash, err := hasher.Hash("testpasswd")
+	require.NoError(t, err, "cannot hash password")
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), "unexpected hash prefix: %s", hash)
+
+	match, needsRehash, err := hasher.Verify("testpasswd", hash)
+	require.NoError(t, err)
+	assert.True(t, match, "valid password does not match")
+	assert.False(t, needsRehash, "fresh hash requires rehash")
+
+	match, _, err = hasher.Verify("wrongpasswd", hash)
+	require.NoError(t, err)
+	assert.False(t, match, "wrong password matches")
+}
+
+func TestHashIsSalted(t *testing.T) {
+	hasher := New(testParams())
+
+	hash1, err := hasher.Hash("testpasswd")
+	require.NoError(t, err)
+
+	hash2, err := hasher.Hash("testpasswd")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, hash1, hash2, "same password produced same hashes")
+}
+
+func TestVerifyLegacyPlaintext(t *testing.T) {
+	hasher := New(testParams())
+
+	match, needsRehash, err := hasher.Verify("testpasswd", "testpasswd")
+	require.NoError(t, err)
+	assert.True(t, match, "legacy plaintext password does not match")
+	assert.True(t, needsRehash, "legacy plaintext password must be rehashed")
+
+	match, _, err = hasher.Verify("wrongpasswd", "testpasswd")
+	require.NoError(t, err)
+	assert.False(t, match, "wrong password matches legacy plaintext one")
+
+	match, needsRehash, err = hasher.Verify("$ecret$", "$ecret$")
+	require.NoError(t, err)
+	assert.True(t, match, "legacy plaintext password starting with '$' does not match")
+	assert.True(t, needsRehash, "legacy plaintext password must be rehashed")
+}
+
+func TestVerifyParamsChanged(t *testing.T) {
+	oldHasher := New(testParams())
+	hash, err := oldHasher.Hash("testpasswd")
+	require.NoError(t, err)
+
+	newParams := testParams()
+	newParams.Iterations = 2
+	newHasher := New(newParams)
+
+	match, needsRehash, err := newHasher.Verify("testpasswd", hash)
+	require.NoError(t, err)
+	assert.True(t, match, "hash with old params does not match")
+	assert.True(t, needsRehash, "hash with old params must be rehashed")
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	hasher := New(testParams())
+
+	_, _, err := hasher.Verify("testpasswd", "$argon2id$broken")
+	require.Error(t, err, "malformed hash is verifiable")
+
+	_, _, err = hasher.Verify("testpasswd", "$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5")
+	require.Error(t, err, "unknown version is verifiable")
+}
@@ -2,13 +2,33 @@ package repo
 
 import (
 	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
 )
 
 type AccountsRepo interface {
-	CheckPasswordByLogin(login string, password string) (models.AccountParams, error)
-	CheckPasswordByEmail(email string, password string) (models.AccountParams, error)
-	CheckPasswordByPhoneNumber(phoneNumber string, password string) (models.AccountParams, error)
+	GetCredentialsByLogin(login string) (models.AccountCredentials, error)
+	GetCredentialsByEmail(email string) (models.AccountCredentials, error)
+	GetCredentialsByPhoneNumber(phoneNumber string) (models.AccountCredentials, error)
+	GetCredentialsById(models.AccountId) (models.AccountCredentials, error)
+	GetContacts(models.AccountId) (models.AccountContacts, error)
+	GetLogin(models.AccountId) (string, error)
+	// Password is random until it is updated
+	IsPasswordRandom(models.AccountId) (bool, error)
+	UpdatePasswordHash(models.AccountId, models.PasswordHash) error
+	// Sets new contact value and resets its verification
+	UpdateContact(models.AccountId, models.ContactKind, string) error
+	// Contact is verified only if its current value equals to the given one
+	MarkContactVerified(models.AccountId, models.ContactKind, string) error
 
 	New(models.RegistrationData) (models.AccountId, error)
+	GetStatus(models.AccountId) (models.AccountStatus, error)
+	SetRole(models.AccountId, soajwt.Role) error
+	// Accounts with unknown logins are skipped
+	SetRoleByLogins(logins []string, role soajwt.Role) error
+	// Replaces the current suspension if there is one
+	Suspend(id models.AccountId, reason string, until opt.Option[time.Time]) error
+	Unsuspend(models.AccountId) error
 	Delete(models.AccountId) error
 }
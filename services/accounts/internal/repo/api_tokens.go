@@ -2,17 +2,41 @@ package repo
 
 import (
 	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
 	"time"
 )
 
 type ApiTokensRepo interface {
-	Put(models.ApiToken, ApiTokenParams) (validUntil time.Time, err error)
-	Get(models.ApiToken) (models.ApiTokenData, error)
+	Put(models.ApiTokenHash, ApiTokenParams) (id models.ApiTokenId, validUntil time.Time, err error)
+	Get(models.ApiTokenHash) (models.ApiTokenData, error)
+	// Token hashes are not filled
+	ListByAccountId(models.AccountId) ([]models.ApiTokenData, error)
+
+	Touch(models.ApiTokenHash) error
+	// Returns false if rejection of the token was already registered within
+	// the period
+	RegisterRejection(models.ApiTokenHash, time.Duration) (bool, error)
+	Revoke(models.AccountId, models.ApiTokenId) error
+	RevokeAll(models.AccountId) (revokedCount int, err error)
+
+	// Applications with unrevoked and unexpired tokens of the account
+	ListAuthorizedApps(models.AccountId) ([]models.AuthorizedApp, error)
+	RevokeByClient(models.AccountId, models.OAuthClientId) (revokedCount int, err error)
+	// Revokes tokens of the application in all accounts
+	RevokeAllOfClient(models.OAuthClientId) (accountIds []models.AccountId, err error)
+
+	// Tokens created before hashing was introduced. They stay locked until the
+	// end of transaction, tokens locked by other transactions are skipped
+	FetchUnhashed(limit int) ([]models.ApiToken, error)
+	SetHash(models.ApiToken, models.ApiTokenHash) error
 }
 
 type ApiTokenParams struct {
-	AccountId   int
-	ReadAccess  bool
-	WriteAccess bool
-	Ttl         time.Duration
+	AccountId int
+	Name      string
+	Scopes    []soatoken.Scope
+	Ttl       time.Duration
+	// Has value for tokens issued to third-party applications
+	ClientId opt.Option[models.OAuthClientId]
 }
@@ -0,0 +1,15 @@
+package repo
+
+import "soa-socialnetwork/services/accounts/internal/models"
+
+// Entries can be neither changed nor deleted
+type AuditLogRepo interface {
+	Append(models.AuditEventParams) (models.AuditEvent, error)
+	// Events of account, the most recent first
+	List(models.AccountId, PagiToken) (AuditEventsPage, error)
+}
+
+type AuditEventsPage struct {
+	Events        []models.AuditEvent
+	NextPagiToken PagiToken
+}
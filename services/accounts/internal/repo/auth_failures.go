@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type AuthFailuresRepo interface {
+	// Waits for attempts of the keys in other transactions and makes further
+	// ones wait until the end of transaction
+	BeginAttempt([]models.AuthFailureKey) error
+	// Keys without failures are omitted
+	Get([]models.AuthFailureKey) ([]models.AuthFailureData, error)
+	// Failures older than window are forgotten before counting the new one
+	RegisterFailure(key models.AuthFailureKey, window time.Duration) (failedCount int, err error)
+	Lock(models.AuthFailureKey, time.Time) error
+	Clear([]models.AuthFailureKey) error
+}
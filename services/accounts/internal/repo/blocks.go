@@ -0,0 +1,15 @@
+package repo
+
+import "soa-socialnetwork/services/accounts/internal/models"
+
+type BlocksRepo interface {
+	// Both return false if nothing has changed
+	Block(blocker models.AccountId, blocked models.AccountId) (bool, error)
+	Unblock(blocker models.AccountId, blocked models.AccountId) (bool, error)
+
+	// Profiles blocked by account, the most recently blocked first
+	ListBlocked(models.AccountId, PagiToken) (ProfileCardsPage, error)
+
+	// Removes blocks of account in both directions
+	DeleteAll(models.AccountId) error
+}
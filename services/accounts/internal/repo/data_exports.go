@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type DataExportsRepo interface {
+	// Creates pending export with the id, if the account already has
+	// a pending export, returns it instead
+	New(models.DataExportId, models.AccountId) (models.DataExport, error)
+	Get(models.DataExportId) (models.DataExport, error)
+	// Returns DataExportNotFound if the account has no pending export
+	GetPending(models.AccountId) (models.DataExport, error)
+	// Exports of the account requested within the period, including pending,
+	// serializes requests of the account until the end of transaction
+	CountCreatedWithin(models.AccountId, time.Duration) (int, error)
+	// Pending exports, the oldest first. They stay locked until the end of
+	// transaction, exports locked by other transactions are skipped
+	ClaimPending(limit int) ([]models.DataExport, error)
+	Finish(models.DataExportId, models.DataExportStatus) error
+	// Returns ids of deleted exports
+	DeleteAll(models.AccountId) ([]models.DataExportId, error)
+	// Deletes exports finished before the period, returns their ids
+	DeleteFinishedBefore(time.Duration) ([]models.DataExportId, error)
+}
@@ -6,6 +6,22 @@ type RepoProvider interface {
 	Accounts() AccountsRepo
 	Profiles() ProfilesRepo
 	ApiTokens() ApiTokensRepo
+	OAuthClients() OAuthClientsRepo
+	OAuthCodes() OAuthCodesRepo
+	OidcIdentities() OidcIdentitiesRepo
+	OidcLoginStates() OidcLoginStatesRepo
+	RefreshTokens() RefreshTokensRepo
+	Sessions() SessionsRepo
+	Follows() FollowsRepo
+	Blocks() BlocksRepo
+	DataExports() DataExportsRepo
+	AuditLog() AuditLogRepo
+	PasswordResetCodes() PasswordResetCodesRepo
+	VerificationCodes() VerificationCodesRepo
+	AuthFailures() AuthFailuresRepo
+	TotpSecrets() TotpSecretsRepo
+	BackupCodes() BackupCodesRepo
+	SecondFactorChallenges() SecondFactorChallengesRepo
 	Outbox() OutboxRepo
 }
 
@@ -0,0 +1,21 @@
+package repo
+
+import "soa-socialnetwork/services/accounts/internal/models"
+
+type FollowsRepo interface {
+	// Both return false if nothing has changed
+	Follow(follower models.AccountId, followee models.AccountId) (bool, error)
+	Unfollow(follower models.AccountId, followee models.AccountId) (bool, error)
+
+	// Lists are ordered by follow time, the most recent first
+	ListFollowers(models.AccountId, PagiToken) (ProfileCardsPage, error)
+	ListFollowing(models.AccountId, PagiToken) (ProfileCardsPage, error)
+	// Profiles followed by account and following it back
+	ListFriends(models.AccountId, PagiToken) (ProfileCardsPage, error)
+
+	Counts(models.AccountId) (models.FollowCounts, error)
+	// Accounts without follows are missing in the result
+	CountsMany([]models.AccountId) (map[models.AccountId]models.FollowCounts, error)
+	// Removes follows of account in both directions
+	DeleteAll(models.AccountId) error
+}
@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type OAuthClientsRepo interface {
+	Create(models.OAuthClientId, models.OAuthClientParams) (models.OAuthClient, error)
+	Get(models.OAuthClientId) (models.OAuthClient, error)
+	ListByOwner(models.AccountId) ([]models.OAuthClient, error)
+	// Only owner can delete client
+	Delete(owner models.AccountId, clientId models.OAuthClientId) error
+}
+
+type OAuthCodesRepo interface {
+	Put(models.OAuthCodeHash, models.OAuthCodeParams, time.Duration) error
+	// Returns used and expired codes too, so that replays can be detected,
+	// locks its row until the end of transaction
+	Get(models.OAuthCodeHash) (models.OAuthCodeData, error)
+	MarkUsed(models.OAuthCodeId) error
+}
@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type OidcIdentitiesRepo interface {
+	GetBySubject(provider string, subject string) (models.OidcIdentity, error)
+	ListByAccount(models.AccountId) ([]models.OidcIdentity, error)
+	// Returns OidcIdentityAlreadyLinked if the subject is linked to some
+	// account or the account is linked to another subject of the provider
+	Link(models.OidcIdentityParams) error
+	TouchLogin(provider string, subject string) error
+	Unlink(accountId models.AccountId, provider string) error
+	DeleteAll(models.AccountId) error
+}
+
+type OidcLoginStatesRepo interface {
+	Put(models.OidcLoginStateHash, models.OidcLoginStateParams, time.Duration) error
+	// Deletes the state, so it can be used once; expired states are
+	// returned as well
+	Take(models.OidcLoginStateHash) (models.OidcLoginState, error)
+}
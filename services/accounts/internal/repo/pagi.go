@@ -0,0 +1,3 @@
+package repo
+
+type PagiToken string
@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type PasswordResetCodesRepo interface {
+	Put(models.AccountId, models.PasswordResetCodeHash, time.Duration) error
+	// Returns the latest unused and unexpired code, locks its row until the end of transaction
+	GetActive(models.AccountId) (models.PasswordResetCodeData, error)
+	IncrementAttempts(models.PasswordResetCodeId) error
+	MarkUsed(models.PasswordResetCodeId) error
+	// Marks all account codes as used
+	InvalidateAll(models.AccountId) error
+	// Number of codes issued to the account within the period, serializes
+	// requests of the account until the end of transaction
+	CountIssuedWithin(models.AccountId, time.Duration) (int, error)
+}
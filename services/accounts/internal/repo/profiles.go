@@ -10,11 +10,24 @@ type ProfilesRepo interface {
 	GetByAccountId(models.AccountId) (models.ProfileData, error)
 	GetByProfileId(models.ProfileId) (models.ProfileData, error)
 
+	// Unknown ids are missing in the result of batch methods
+	GetManyByProfileIds([]models.ProfileId) ([]models.ProfileData, error)
+
 	ResolveProfileId(models.ProfileId) (models.AccountId, error)
 	ResolveAccountId(models.AccountId) (models.ProfileId, error)
+	ResolveProfileIds([]models.ProfileId) (map[models.ProfileId]models.AccountId, error)
+	ResolveAccountIds([]models.AccountId) (map[models.AccountId]models.ProfileId, error)
+
+	// Finds profiles which name, surname or login starts with or is similar
+	// to the lowercase query, the most relevant go first.
+	Search(query string, token PagiToken) (ProfileCardsPage, error)
 
 	New(models.ProfileId, models.AccountId, models.RegistrationData) error
 	Edit(models.ProfileId, EditedProfileData) error
+	EditPrivacy(models.ProfileId, EditedPrivacySettings) error
+	// Sets image name of kind, empty name removes image.
+	// Returns the previous name, empty if there was no image.
+	SetImage(id models.ProfileId, kind models.ProfileImageKind, name string) (string, error)
 	Delete(models.ProfileId) error
 }
 
@@ -24,3 +37,16 @@ type EditedProfileData struct {
 	Bio      opt.Option[string]
 	Birthday opt.Option[time.Time]
 }
+
+type EditedPrivacySettings struct {
+	Birthday    opt.Option[models.Visibility]
+	Bio         opt.Option[models.Visibility]
+	Email       opt.Option[models.Visibility]
+	PhoneNumber opt.Option[models.Visibility]
+}
+
+// Page of profiles listed with cursor pagination
+type ProfileCardsPage struct {
+	Profiles      []models.ProfileCard
+	NextPagiToken PagiToken
+}
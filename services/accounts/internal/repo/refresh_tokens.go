@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type RefreshTokensRepo interface {
+	Put(models.RefreshTokenHash, RefreshTokenParams) (validUntil time.Time, err error)
+	// Locks token row until the end of transaction
+	Get(models.RefreshTokenHash) (models.RefreshTokenData, error)
+	MarkUsed(models.RefreshTokenHash) error
+	RevokeFamily(models.RefreshTokenFamilyId) error
+	RevokeAll(models.AccountId) error
+}
+
+type RefreshTokenParams struct {
+	AccountId models.AccountId
+	FamilyId  models.RefreshTokenFamilyId
+	Ttl       time.Duration
+}
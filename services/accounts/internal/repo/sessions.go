@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type SessionsRepo interface {
+	Put(params models.SessionParams, ttl time.Duration) error
+	Get(models.SessionId) (models.SessionData, error)
+	// Updates last usage time and prolongs session by ttl
+	Touch(id models.SessionId, ttl time.Duration) error
+	// Sessions which are neither revoked nor expired, most recently used first
+	ListActive(models.AccountId) ([]models.SessionData, error)
+	Revoke(models.AccountId, models.SessionId) error
+	// Returns ids of sessions revoked by this call
+	RevokeAll(accountId models.AccountId, except []models.SessionId) ([]models.SessionId, error)
+}
@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type TotpSecretsRepo interface {
+	// Replaces not enabled secret of the account, enabled one is kept
+	Put(models.AccountId, []byte) error
+	// Locks the row until the end of transaction
+	Get(models.AccountId) (models.TotpSecretData, error)
+	Enable(models.AccountId) error
+	// Stores step of accepted code, returns false if the same or a later step
+	// is already used
+	UseStep(models.AccountId, int64) (bool, error)
+	Delete(models.AccountId) error
+}
+
+type BackupCodesRepo interface {
+	// Removes all previous codes of the account
+	Replace(models.AccountId, []models.BackupCodeHash) error
+	// Marks unused code as used, returns false if there is no such code
+	Use(models.AccountId, models.BackupCodeHash) (bool, error)
+	DeleteAll(models.AccountId) error
+}
+
+type SecondFactorChallengesRepo interface {
+	Put(models.SecondFactorChallengeHash, models.SecondFactorChallengeParams, time.Duration) (validUntil time.Time, err error)
+	// Returns unused and unexpired challenge, locks its row until the end of transaction
+	GetActive(models.SecondFactorChallengeHash) (models.SecondFactorChallengeData, error)
+	IncrementAttempts(models.SecondFactorChallengeId) error
+	MarkUsed(models.SecondFactorChallengeId) error
+}
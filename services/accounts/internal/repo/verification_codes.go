@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+type VerificationCodesRepo interface {
+	Put(models.AccountId, models.ContactKind, string, models.VerificationCodeHash, time.Duration) error
+	// Returns the latest unused and unexpired code, locks its row until the end of transaction
+	GetActive(models.AccountId, models.ContactKind) (models.VerificationCodeData, error)
+	IncrementAttempts(models.VerificationCodeId) error
+	MarkUsed(models.VerificationCodeId) error
+	// Marks all account codes of the contact kind as used
+	InvalidateAll(models.AccountId, models.ContactKind) error
+	// Number of codes of the contact kind issued to the account within the
+	// period, serializes requests of the account until the end of transaction
+	CountIssuedWithin(models.AccountId, models.ContactKind, time.Duration) (int, error)
+}
@@ -11,6 +11,9 @@ import (
 	"google.golang.org/grpc"
 )
 
+// Uploaded profile images are passed in a single message
+const MAX_RECV_MESSAGE_SIZE = 8 << 20
+
 type Server struct {
 	grpcServer *grpc.Server
 	service    *service.AccountsService
@@ -23,6 +26,7 @@ func Create(cfg service.Config) (*Server, error) {
 	}
 
 	grpcServer := grpc.NewServer(
+		grpc.MaxRecvMsgSize(MAX_RECV_MESSAGE_SIZE),
 		grpc.ChainUnaryInterceptor(
 			interceptors.ConvertErrors(),
 			interceptors.Auth(interceptors.TokenVerifiers{
@@ -30,6 +34,13 @@ func Create(cfg service.Config) (*Server, error) {
 				Soa: service.SoaVerifier,
 			}),
 		),
+		grpc.ChainStreamInterceptor(
+			interceptors.ConvertErrorsStream(),
+			interceptors.AuthStream(interceptors.TokenVerifiers{
+				Jwt: service.JwtVerifier,
+				Soa: service.SoaVerifier,
+			}),
+		),
 	)
 	pb.RegisterAccountsServiceServer(grpcServer, service)
 
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+)
+
+// Key must be at least as long as SHA-256 output
+const API_TOKEN_HMAC_KEY_MIN_LENGTH = sha256.Size
+
+// Whole token is hashed rather than only its salt, so neither account id nor
+// profile id can be substituted in a token that matches stored hash
+type apiTokenHasher struct {
+	key []byte
+}
+
+func (h *apiTokenHasher) Hash(token models.ApiToken) models.ApiTokenHash {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(token))
+	return models.ApiTokenHash(hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Replaces raw tokens left from versions that stored them in plain form.
+// Instances starting at once convert different tokens, as fetched ones are locked
+func hashLegacyApiTokens(ctx context.Context, db repo.Database, hasher *apiTokenHasher) error {
+	const BATCH_SIZE = 100
+
+	total := 0
+	for {
+		converted, err := hashLegacyApiTokensBatch(ctx, db, hasher, BATCH_SIZE)
+		if err != nil {
+			return err
+		}
+
+		total += converted
+		if converted < BATCH_SIZE {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("%d legacy api tokens hashed", total)
+	}
+
+	return nil
+}
+
+func hashLegacyApiTokensBatch(ctx context.Context, db repo.Database, hasher *apiTokenHasher, batchSize int) (int, error) {
+	tx, err := db.BeginTransaction(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+
+	tokens, err := tx.ApiTokens().FetchUnhashed(batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, token := range tokens {
+		err = tx.ApiTokens().SetHash(token, hasher.Hash(token))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(tokens), nil
+}
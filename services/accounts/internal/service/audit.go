@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/interceptors"
+	"soa-socialnetwork/services/accounts/pkg/audit"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Event is stored in the audit log and published via outbox, so that both
+// happen or fail together when provider is a transaction
+func recordAuditEvent(ctx context.Context, provider repo.RepoProvider, accountId models.AccountId, eventType audit.EventType, identifierType audit.IdentifierType) error {
+	event, err := provider.AuditLog().Append(models.AuditEventParams{
+		AccountId:      accountId,
+		Type:           eventType,
+		IdentifierType: identifierType,
+		ClientIp:       clientIpFromContext(ctx),
+		UserAgent:      userAgentFromContext(ctx),
+	})
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(audit.Event{
+		EventId:        int64(event.Id),
+		Type:           event.Type,
+		AccountId:      int(event.AccountId),
+		IdentifierType: event.IdentifierType,
+		ClientIp:       event.ClientIp,
+		UserAgent:      event.UserAgent,
+		Timestamp:      event.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return provider.Outbox().Put(models.OutboxEvent{
+		Type:      audit.AUDIT_EVENT_TOPIC,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	})
+}
+
+func passwordIdentifierType(authData *pb.AuthByPassword) audit.IdentifierType {
+	switch authData.UserId.(type) {
+	case *pb.AuthByPassword_Login:
+		return audit.IDENTIFIER_LOGIN
+
+	case *pb.AuthByPassword_Email:
+		return audit.IDENTIFIER_EMAIL
+
+	case *pb.AuthByPassword_PhoneNumber:
+		return audit.IDENTIFIER_PHONE_NUMBER
+
+	default:
+		panic("unknown user id")
+	}
+}
+
+// Identifier of the caller of authenticated method, JWTs without session
+// (e.g., of data exports) are JWTs too
+func callerIdentifierType(ctx context.Context) audit.IdentifierType {
+	if getAuthInfo(ctx).Kind == interceptors.AUTH_KIND_SOA {
+		return audit.IDENTIFIER_API_TOKEN
+	}
+
+	return audit.IDENTIFIER_JWT
+}
+
+func (s *AccountsService) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	page, err := conn.AuditLog().List(models.AccountId(authInfo.AccountId), repo.PagiToken(req.PageToken))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*pb.AuditEvent, 0, len(page.Events))
+	for _, event := range page.Events {
+		events = append(events, &pb.AuditEvent{
+			EventId:        int64(event.Id),
+			Type:           string(event.Type),
+			IdentifierType: string(event.IdentifierType),
+			ClientIp:       event.ClientIp,
+			UserAgent:      event.UserAgent,
+			CreatedAt:      timestamppb.New(event.CreatedAt),
+		})
+	}
+
+	return &pb.ListAuditEventsResponse{
+		Events:        events,
+		NextPageToken: string(page.NextPagiToken),
+	}, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Metadata key with address of the end client, set by gateway
+const CLIENT_IP_METADATA_KEY = "x-client-ip"
+
+type lockoutPolicy struct {
+	// Failures count that triggers lockout
+	threshold int
+	// Failures older than window are forgotten
+	window      time.Duration
+	baseLockout time.Duration
+	maxLockout  time.Duration
+}
+
+var user_id_lockout_policy = lockoutPolicy{
+	threshold:   5,
+	window:      15 * time.Minute,
+	baseLockout: time.Minute,
+	maxLockout:  time.Hour,
+}
+
+// Many users may share one address, so client limit is softer
+var client_lockout_policy = lockoutPolicy{
+	threshold:   20,
+	window:      15 * time.Minute,
+	baseLockout: time.Minute,
+	maxLockout:  time.Hour,
+}
+
+// Lockout doubles with every failure after threshold
+func (p lockoutPolicy) lockoutDuration(failedCount int) time.Duration {
+	if failedCount < p.threshold {
+		return 0
+	}
+
+	lockout := p.baseLockout
+	for i := p.threshold; i < failedCount && lockout < p.maxLockout; i++ {
+		lockout *= 2
+	}
+
+	return min(lockout, p.maxLockout)
+}
+
+type authFailureTarget struct {
+	key    models.AuthFailureKey
+	policy lockoutPolicy
+}
+
+func authFailureTargets(ctx context.Context, authData *pb.AuthByPassword) []authFailureTarget {
+	return withClientAuthFailureTarget(ctx, authFailureTarget{
+		key:    userIdAuthFailureKey(authData),
+		policy: user_id_lockout_policy,
+	})
+}
+
+// Password of authenticated caller is checked by account id, as the caller
+// gives no user id
+func accountAuthFailureTargets(ctx context.Context, accountId models.AccountId) []authFailureTarget {
+	return withClientAuthFailureTarget(ctx, authFailureTarget{
+		key:    accountAuthFailureKey(accountId),
+		policy: user_id_lockout_policy,
+	})
+}
+
+func withClientAuthFailureTarget(ctx context.Context, userTarget authFailureTarget) []authFailureTarget {
+	targets := []authFailureTarget{userTarget}
+
+	clientIp := clientIpFromContext(ctx)
+	if clientIp != "" {
+		targets = append(targets, authFailureTarget{
+			key:    models.AuthFailureKey("client:" + clientIp),
+			policy: client_lockout_policy,
+		})
+	}
+
+	return targets
+}
+
+// Must be called in transaction which also registers failure of the attempt,
+// otherwise parallel attempts could all pass the check before any of them
+// is counted
+func checkLockouts(tx repo.Transaction, targets []authFailureTarget) error {
+	keys := make([]models.AuthFailureKey, 0, len(targets))
+	for _, target := range targets {
+		keys = append(keys, target.key)
+	}
+
+	err := tx.AuthFailures().BeginAttempt(keys)
+	if err != nil {
+		return err
+	}
+
+	failures, err := tx.AuthFailures().Get(keys)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	retryAfter := time.Duration(0)
+	for _, failure := range failures {
+		if failure.LockedUntil.HasValue && failure.LockedUntil.Value.After(now) {
+			retryAfter = max(retryAfter, failure.LockedUntil.Value.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		return errs.TooManyAuthAttempts{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func registerAuthFailure(provider repo.RepoProvider, targets []authFailureTarget) error {
+	for _, target := range targets {
+		failedCount, err := provider.AuthFailures().RegisterFailure(target.key, target.policy.window)
+		if err != nil {
+			return err
+		}
+
+		lockout := target.policy.lockoutDuration(failedCount)
+		if lockout == 0 {
+			continue
+		}
+
+		err = provider.AuthFailures().Lock(target.key, time.Now().Add(lockout))
+		if err != nil {
+			return err
+		}
+
+		log.Printf("%s locked out for %s after %d failed authentication attempts", target.key, lockout, failedCount)
+	}
+
+	return nil
+}
+
+func userIdAuthFailureKey(authData *pb.AuthByPassword) models.AuthFailureKey {
+	switch userId := authData.UserId.(type) {
+	case *pb.AuthByPassword_Login:
+		return loginAuthFailureKey(userId.Login)
+
+	case *pb.AuthByPassword_Email:
+		return emailAuthFailureKey(userId.Email)
+
+	case *pb.AuthByPassword_PhoneNumber:
+		return phoneNumberAuthFailureKey(userId.PhoneNumber)
+
+	default:
+		panic("unknown user id")
+	}
+}
+
+// Keys of all user ids of the account
+func accountAuthFailureKeys(provider repo.RepoProvider, accountId models.AccountId) ([]models.AuthFailureKey, error) {
+	login, err := provider.Accounts().GetLogin(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := provider.Accounts().GetContacts(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []models.AuthFailureKey{loginAuthFailureKey(login), accountAuthFailureKey(accountId), secondFactorAuthFailureKey(accountId)}
+	if contacts.Email != "" {
+		keys = append(keys, emailAuthFailureKey(contacts.Email))
+	}
+	if contacts.PhoneNumber != "" {
+		keys = append(keys, phoneNumberAuthFailureKey(contacts.PhoneNumber))
+	}
+
+	return keys, nil
+}
+
+func clearAccountLockouts(provider repo.RepoProvider, accountId models.AccountId) error {
+	keys, err := accountAuthFailureKeys(provider, accountId)
+	if err != nil {
+		return err
+	}
+
+	return provider.AuthFailures().Clear(keys)
+}
+
+func loginAuthFailureKey(login string) models.AuthFailureKey {
+	return models.AuthFailureKey("login:" + login)
+}
+
+func emailAuthFailureKey(email string) models.AuthFailureKey {
+	return models.AuthFailureKey("email:" + email)
+}
+
+func phoneNumberAuthFailureKey(phoneNumber string) models.AuthFailureKey {
+	return models.AuthFailureKey("phone_number:" + phoneNumber)
+}
+
+func accountAuthFailureKey(accountId models.AccountId) models.AuthFailureKey {
+	return models.AuthFailureKey(fmt.Sprintf("account:%d", accountId))
+}
+
+func secondFactorAuthFailureKey(accountId models.AccountId) models.AuthFailureKey {
+	return models.AuthFailureKey(fmt.Sprintf("second_factor:%d", accountId))
+}
+
+// Address passed by gateway is preferred, as peer address is gateway's one
+func clientIpFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		if values := md.Get(CLIENT_IP_METADATA_KEY); len(values) == 1 && values[0] != "" {
+			return values[0]
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	policy := lockoutPolicy{
+		threshold:   3,
+		window:      time.Hour,
+		baseLockout: time.Minute,
+		maxLockout:  10 * time.Minute,
+	}
+
+	assert.Equal(t, time.Duration(0), policy.lockoutDuration(1))
+	assert.Equal(t, time.Duration(0), policy.lockoutDuration(2))
+	assert.Equal(t, time.Minute, policy.lockoutDuration(3))
+	assert.Equal(t, 2*time.Minute, policy.lockoutDuration(4))
+	assert.Equal(t, 8*time.Minute, policy.lockoutDuration(6))
+	assert.Equal(t, 10*time.Minute, policy.lockoutDuration(7))
+	assert.Equal(t, 10*time.Minute, policy.lockoutDuration(1000))
+}
+
+func TestAccountAuthFailureTargets(t *testing.T) {
+	targets := accountAuthFailureTargets(context.Background(), 42)
+	require.Len(t, targets, 1)
+	assert.Equal(t, models.AuthFailureKey("account:42"), targets[0].key)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CLIENT_IP_METADATA_KEY, "10.0.0.1"))
+	targets = accountAuthFailureTargets(ctx, 42)
+	require.Len(t, targets, 2)
+	assert.Equal(t, models.AuthFailureKey("account:42"), targets[0].key)
+	assert.Equal(t, user_id_lockout_policy, targets[0].policy)
+	assert.Equal(t, models.AuthFailureKey("client:10.0.0.1"), targets[1].key)
+	assert.Equal(t, client_lockout_policy, targets[1].policy)
+}
@@ -1,6 +1,12 @@
 package service
 
-import "crypto/ed25519"
+import (
+	"crypto/ed25519"
+	"soa-socialnetwork/services/accounts/internal/blobstore"
+	"soa-socialnetwork/services/accounts/internal/notify"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"soa-socialnetwork/services/accounts/internal/passhash"
+)
 
 type Config struct {
 	DbHost        string
@@ -8,4 +14,26 @@ type Config struct {
 	DbPassword    string
 	DbPoolSize    int
 	JwtPrivateKey ed25519.PrivateKey
+	// Keys of previous issuers, their tokens remain valid until expiration
+	JwtPreviousPublicKeys []ed25519.PublicKey
+	ApiTokenHmacKey       []byte
+	PasswordHashParams    passhash.Params
+	Notifier              notify.Notifier
+	// Storage of uploaded profile images
+	BlobStore blobstore.Store
+	// Prefix of urls of stored images, image key is appended to it
+	ImagesBaseUrl string
+	// Storage of personal data export archives
+	ExportStore blobstore.Store
+	// Data exports gather posts, comments and their stats from these services
+	PostsServiceHost string
+	PostsServicePort int
+	StatsServiceHost string
+	StatsServicePort int
+	// Forbids authentication by unverified email or phone number
+	RequireVerifiedContacts bool
+	// Accounts with these logins are given admin role on start and registration
+	AdminLogins []string
+	// External identity providers users may sign in with
+	OidcProviders []oidc.ProviderConfig
 }
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"soa-socialnetwork/services/accounts/internal/dataexport"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/soajwtissuer"
+	"soa-socialnetwork/services/common/backjob"
+	postsPb "soa-socialnetwork/services/posts/proto"
+	statsPb "soa-socialnetwork/services/stats/proto"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const DATA_EXPORTS_BATCH_SIZE = 10
+
+// Posts service is called on behalf of the exported account
+const DATA_EXPORT_JWT_TTL = time.Minute
+
+func processDataExportsJob(s *AccountsService) backjob.JobCallback {
+	return func(ctx context.Context) error {
+		err := s.deleteExpiredDataExports(ctx)
+		if err != nil {
+			return err
+		}
+
+		for range DATA_EXPORTS_BATCH_SIZE {
+			processed, err := s.processNextDataExport(ctx)
+			if err != nil {
+				return err
+			}
+
+			if !processed {
+				break
+			}
+		}
+
+		return nil
+	}
+}
+
+// Export is kept locked while it is built, so other instances of the service
+// skip it. Returns false if there are no pending exports left
+func (s *AccountsService) processNextDataExport(ctx context.Context) (bool, error) {
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Close()
+
+	exports, err := tx.DataExports().ClaimPending(1)
+	if err != nil {
+		return false, err
+	}
+
+	if len(exports) == 0 {
+		return false, nil
+	}
+	export := exports[0]
+
+	status := models.DATA_EXPORT_READY
+	err = s.buildDataExport(ctx, export)
+	if err != nil {
+		log.Printf("data export %s failed: %v", export.Id, err)
+		status = models.DATA_EXPORT_FAILED
+	}
+
+	err = tx.DataExports().Finish(export.Id, status)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (s *AccountsService) deleteExpiredDataExports(ctx context.Context) error {
+	ids, err := func() ([]models.DataExportId, error) {
+		conn, err := s.Db.OpenConnection(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		return conn.DataExports().DeleteFinishedBefore(DATA_EXPORT_RETENTION)
+	}()
+	if err != nil {
+		return err
+	}
+
+	s.deleteDataExportArchives(ctx, ids)
+	return nil
+}
+
+// Gathers data of the account from all services and stores the archive
+func (s *AccountsService) buildDataExport(ctx context.Context, export models.DataExport) error {
+	account, err := s.exportAccountData(ctx, export.AccountId)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.jwtIssuer.Issue(soajwtissuer.PersonalData{
+		AccountId: int(export.AccountId),
+		ProfileId: account.ProfileId,
+	}, DATA_EXPORT_JWT_TTL)
+	if err != nil {
+		return err
+	}
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	postsData, err := s.postsClient.ExportAccountData(authCtx, &postsPb.Empty{})
+	if err != nil {
+		return err
+	}
+
+	posts := make([]dataexport.Post, len(postsData.Posts))
+	postStats := make([]dataexport.PostStats, len(postsData.Posts))
+	for i, post := range postsData.Posts {
+		posts[i] = dataexport.Post{
+			PostId:       post.Id,
+			Text:         post.Text,
+			SourcePostId: post.SourcePostId,
+			Pinned:       post.Pinned,
+			ViewsCount:   post.ViewsCount,
+			CreatedAt:    post.CreatedAt.AsTime(),
+		}
+
+		postStats[i], err = s.exportPostStats(ctx, post.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	comments := make([]dataexport.Comment, len(postsData.Comments))
+	for i, comment := range postsData.Comments {
+		comments[i] = dataexport.Comment{
+			CommentId:      comment.Id,
+			PostId:         comment.PostId,
+			Content:        comment.Content,
+			ReplyCommentId: comment.ReplyCommentId,
+			CreatedAt:      comment.CreatedAt.AsTime(),
+		}
+	}
+
+	likes := make([]dataexport.Like, len(postsData.Likes))
+	for i, like := range postsData.Likes {
+		likes[i] = dataexport.Like{
+			PostId:    like.PostId,
+			CreatedAt: like.CreatedAt.AsTime(),
+		}
+	}
+
+	archive, err := dataexport.Pack([]dataexport.Document{
+		{Name: "account.json", Content: account},
+		{Name: "posts.json", Content: posts},
+		{Name: "comments.json", Content: comments},
+		{Name: "likes.json", Content: likes},
+		{Name: "post_stats.json", Content: postStats},
+	}, account.ExportedAt)
+	if err != nil {
+		return err
+	}
+
+	return s.exportStore.Put(ctx, dataExportKey(export.Id), archive)
+}
+
+func (s *AccountsService) exportAccountData(ctx context.Context, accountId models.AccountId) (dataexport.Account, error) {
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return dataexport.Account{}, err
+	}
+	defer conn.Close()
+
+	profile, err := conn.Profiles().GetByAccountId(accountId)
+	if err != nil {
+		return dataexport.Account{}, err
+	}
+
+	login, err := conn.Accounts().GetLogin(accountId)
+	if err != nil {
+		return dataexport.Account{}, err
+	}
+
+	contacts, err := conn.Accounts().GetContacts(accountId)
+	if err != nil {
+		return dataexport.Account{}, err
+	}
+
+	account := dataexport.Account{
+		AccountId:   int32(accountId),
+		ProfileId:   string(profile.ProfileId),
+		Login:       login,
+		Email:       contacts.Email,
+		PhoneNumber: contacts.PhoneNumber,
+		Name:        profile.Name,
+		Surname:     profile.Surname,
+		Bio:         profile.Bio,
+		Privacy: dataexport.Privacy{
+			Birthday:    string(profile.Privacy.Birthday),
+			Bio:         string(profile.Privacy.Bio),
+			Email:       string(profile.Privacy.Email),
+			PhoneNumber: string(profile.Privacy.PhoneNumber),
+		},
+		ExportedAt: time.Now().UTC(),
+	}
+	if !profile.Birthday.IsZero() {
+		account.Birthday = profile.Birthday.Format(time.DateOnly)
+	}
+	if avatar := s.profileImageToProto(models.PROFILE_IMAGE_AVATAR, profile.AvatarImage); avatar != nil {
+		account.Avatar = avatar.Url
+	}
+	if cover := s.profileImageToProto(models.PROFILE_IMAGE_COVER, profile.CoverImage); cover != nil {
+		account.Cover = cover.Url
+	}
+
+	if account.Following, err = exportProfileCards(conn.Follows().ListFollowing, accountId); err != nil {
+		return dataexport.Account{}, err
+	}
+	if account.Followers, err = exportProfileCards(conn.Follows().ListFollowers, accountId); err != nil {
+		return dataexport.Account{}, err
+	}
+	if account.Blocked, err = exportProfileCards(conn.Blocks().ListBlocked, accountId); err != nil {
+		return dataexport.Account{}, err
+	}
+
+	sessions, err := conn.Sessions().ListActive(accountId)
+	if err != nil {
+		return dataexport.Account{}, err
+	}
+
+	account.Sessions = make([]dataexport.Session, len(sessions))
+	for i, session := range sessions {
+		account.Sessions[i] = dataexport.Session{
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IpAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		}
+	}
+
+	return account, nil
+}
+
+// Walks through all pages of the list
+func exportProfileCards(list func(models.AccountId, repo.PagiToken) (repo.ProfileCardsPage, error), accountId models.AccountId) ([]dataexport.Profile, error) {
+	profiles := make([]dataexport.Profile, 0)
+	token := repo.PagiToken("")
+	for {
+		page, err := list(accountId, token)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, card := range page.Profiles {
+			profiles = append(profiles, dataexport.Profile{
+				ProfileId: string(card.ProfileId),
+				Name:      card.Name,
+				Surname:   card.Surname,
+			})
+		}
+
+		if page.NextPagiToken == "" {
+			return profiles, nil
+		}
+		token = page.NextPagiToken
+	}
+}
+
+func (s *AccountsService) exportPostStats(ctx context.Context, postId int32) (dataexport.PostStats, error) {
+	dynamics := func(metric statsPb.Metric) ([]dataexport.DayCount, error) {
+		resp, err := s.statsClient.GetPostMetricDynamics(ctx, &statsPb.GetPostMetricDynamicsRequest{
+			PostId: postId,
+			Metric: metric,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		days := make([]dataexport.DayCount, len(resp.Dynamics))
+		for i, day := range resp.Dynamics {
+			days[i] = dataexport.DayCount{
+				Date:  day.Date.AsTime().Format(time.DateOnly),
+				Count: day.Count,
+			}
+		}
+
+		return days, nil
+	}
+
+	stats := dataexport.PostStats{
+		PostId: postId,
+	}
+
+	var err error
+	if stats.Views, err = dynamics(statsPb.Metric_METRIC_VIEW_COUNT); err != nil {
+		return dataexport.PostStats{}, err
+	}
+	if stats.Likes, err = dynamics(statsPb.Metric_METRIC_LIKE_COUNT); err != nil {
+		return dataexport.PostStats{}, err
+	}
+	if stats.Comments, err = dynamics(statsPb.Metric_METRIC_COMMENT_COUNT); err != nil {
+		return dataexport.PostStats{}, err
+	}
+
+	return stats, nil
+}
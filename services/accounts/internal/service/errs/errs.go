@@ -1,22 +1,155 @@
 package errs
 
+import (
+	"fmt"
+	"time"
+)
+
 type AccessDenied struct{}
-type NoWriteAccess struct{}
-type NoReadAccess struct{}
 type TokenExpired struct{}
+type TokenRevoked struct{}
+type PasswordsDoNotMatch struct{}
+type RefreshTokenRevoked struct{}
+type RefreshTokenReused struct{}
 
 func (AccessDenied) Error() string {
 	return "access denied"
 }
 
-func (NoWriteAccess) Error() string {
-	return "no write access"
+func (TokenExpired) Error() string {
+	return "token expired"
 }
 
-func (NoReadAccess) Error() string {
-	return "no read access"
+func (TokenRevoked) Error() string {
+	return "token revoked"
 }
 
-func (TokenExpired) Error() string {
-	return "token expired"
+func (PasswordsDoNotMatch) Error() string {
+	return "passwords do not match"
+}
+
+func (RefreshTokenRevoked) Error() string {
+	return "refresh token revoked"
+}
+
+func (RefreshTokenReused) Error() string {
+	return "refresh token reused, all tokens of its family revoked"
+}
+
+type InvalidResetCode struct{}
+
+func (InvalidResetCode) Error() string {
+	return "invalid or expired password reset code"
+}
+
+type InvalidVerificationCode struct{}
+
+func (InvalidVerificationCode) Error() string {
+	return "invalid or expired verification code"
+}
+
+type InvalidCredentials struct{}
+
+// Returned without checking credentials while user id or client is locked out
+type TooManyAuthAttempts struct {
+	RetryAfter time.Duration
+}
+
+func (InvalidCredentials) Error() string {
+	return "invalid credentials"
+}
+
+func (e TooManyAuthAttempts) Error() string {
+	return fmt.Sprintf("too many failed authentication attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+type TooManyDataExports struct{}
+
+func (TooManyDataExports) Error() string {
+	return "too many data exports requested, retry tomorrow"
+}
+
+type TooManyVerificationCodes struct{}
+
+func (TooManyVerificationCodes) Error() string {
+	return "too many verification codes requested, retry later"
+}
+
+type TwoFactorAlreadyEnabled struct{}
+type TwoFactorNotEnabled struct{}
+type InvalidSecondFactorCode struct{}
+type InvalidSecondFactorChallenge struct{}
+
+func (TwoFactorAlreadyEnabled) Error() string {
+	return "two-factor authentication is already enabled"
+}
+
+func (TwoFactorNotEnabled) Error() string {
+	return "two-factor authentication is not enabled"
+}
+
+func (InvalidSecondFactorCode) Error() string {
+	return "invalid second factor code"
+}
+
+func (InvalidSecondFactorChallenge) Error() string {
+	return "invalid or expired second factor challenge"
+}
+
+// Returned instead of tokens and by token validation while account is suspended
+type AccountSuspended struct {
+	Reason string
+	// Zero for permanent suspension
+	Until time.Time
+}
+
+func (e AccountSuspended) Error() string {
+	if e.Until.IsZero() {
+		return fmt.Sprintf("account is suspended: %s", e.Reason)
+	}
+
+	return fmt.Sprintf("account is suspended until %s: %s", e.Until.UTC().Format(time.RFC3339), e.Reason)
+}
+
+type CannotSuspendAdmin struct{}
+
+func (CannotSuspendAdmin) Error() string {
+	return "administrators cannot be suspended"
+}
+
+// Code is unknown, expired, used or does not match client, redirect uri or
+// code verifier; the reason is not revealed to the caller
+type InvalidOAuthGrant struct{}
+
+func (InvalidOAuthGrant) Error() string {
+	return "invalid authorization code"
+}
+
+// State is unknown, expired or used, or identity provider rejected the code
+// or returned invalid ID token; the reason is only logged
+type InvalidOidcLogin struct{}
+
+func (InvalidOidcLogin) Error() string {
+	return "invalid or expired external login"
+}
+
+// Provider does not provision accounts, identity must be linked first
+type OidcIdentityNotLinked struct{}
+
+func (OidcIdentityNotLinked) Error() string {
+	return "external identity is not linked to any account, sign in and link it first"
+}
+
+// Account would be left without a way to sign in
+type LastSignInMethod struct{}
+
+func (LastSignInMethod) Error() string {
+	return "cannot unlink the only way to sign in, verify a contact or reset the password first"
+}
+
+// Provisioning would take over an account with the same email
+type OidcEmailTaken struct{}
+
+func (OidcEmailTaken) Error() string {
+	return "account with this email already exists, sign in and link the external identity to it"
 }
@@ -18,60 +18,99 @@ type TokenVerifiers struct {
 
 func Auth(verifiers TokenVerifiers) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
-		authReqs := getAuthRequirements(info.FullMethod)
-		if !authReqs.needAuth {
-			return handler(ctx, req)
+		ctx, err = authenticate(ctx, info.FullMethod, verifiers)
+		if err != nil {
+			return nil, err
 		}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, errs.NoMetadata{}
-		}
+		return handler(ctx, req)
+	}
+}
 
-		parsedToken, err := parseTokenFromMetadata(md)
+func AuthStream(verifiers TokenVerifiers) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), info.FullMethod, verifiers)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		authInfo, err := func() (AuthInfo, error) {
-			switch parsedToken.kind {
-			case auth_kind_jwt:
-				return verifyJwtToken(parsedToken.value, verifiers.Jwt)
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
 
-			case auth_kind_soa:
-				return verifySoaToken(parsedToken.value, verifiers.Soa, authReqs)
+// Passes auth info to stream handlers through the stream context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
 
-			default:
-				panic("unknown auth token kind")
-			}
-		}()
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
 
-		if err != nil {
-			return nil, err
+// Returns context with auth info if the method needs it and the caller is authenticated
+func authenticate(ctx context.Context, fullMethod string, verifiers TokenVerifiers) (context.Context, error) {
+	authReqs := getAuthRequirements(fullMethod)
+	if !authReqs.needAuth && !authReqs.optionalAuth {
+		return ctx, nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, errs.NoMetadata{}
+	}
+
+	parsedToken, err := parseTokenFromMetadata(md)
+	if _, noAuth := err.(errs.NoAuth); noAuth && authReqs.optionalAuth {
+		return ctx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	authInfo, err := func() (AuthInfo, error) {
+		switch parsedToken.kind {
+		case AUTH_KIND_JWT:
+			return verifyJwtToken(parsedToken.value, verifiers.Jwt)
+
+		case AUTH_KIND_SOA:
+			return verifySoaToken(parsedToken.value, verifiers.Soa, authReqs)
+
+		default:
+			panic("unknown auth token kind")
 		}
+	}()
 
-		return handler(context.WithValue(ctx, AuthInfoKey, authInfo), req)
+	if err != nil {
+		return nil, err
 	}
+
+	return context.WithValue(ctx, AuthInfoKey, authInfo), nil
 }
 
 type AuthInfo struct {
 	ProfileId string
 	AccountId int32
+	// Empty if authenticated by api token
+	SessionId string
+	// Api tokens always have user role
+	Role soajwt.Role
+	Kind AuthKind
 }
 
 type AuthInfoKeyType struct{}
 
 var AuthInfoKey AuthInfoKeyType
 
-type authKind int
+type AuthKind int
 
 const (
-	auth_kind_jwt = iota
-	auth_kind_soa
+	AUTH_KIND_JWT AuthKind = iota
+	AUTH_KIND_SOA
 )
 
 type parsedToken struct {
-	kind  authKind
+	kind  AuthKind
 	value string
 }
 
@@ -91,13 +130,13 @@ func parseTokenFromMetadata(md metadata.MD) (parsedToken, error) {
 	switch authKind {
 	case "Bearer":
 		return parsedToken{
-			kind:  auth_kind_jwt,
+			kind:  AUTH_KIND_JWT,
 			value: authToken,
 		}, nil
 
 	case "SoaToken":
 		return parsedToken{
-			kind:  auth_kind_soa,
+			kind:  AUTH_KIND_SOA,
 			value: authToken,
 		}, nil
 
@@ -115,6 +154,9 @@ func verifyJwtToken(token string, verifier soajwt.Verifier) (AuthInfo, error) {
 	return AuthInfo{
 		ProfileId: parsedToken.Subject,
 		AccountId: int32(parsedToken.AccountId),
+		SessionId: parsedToken.SessionId,
+		Role:      parsedToken.GetRole(),
+		Kind:      AUTH_KIND_JWT,
 	}, nil
 }
 
@@ -125,8 +167,7 @@ func verifySoaToken(token string, verifier soatoken.Verifier, reqs authRequireme
 	}
 
 	err = verifier.Verify(token, soatoken.RightsRequirements{
-		Read:  reqs.needReadAccess,
-		Write: reqs.needWriteAccess,
+		Scope: reqs.scope,
 	})
 	if err != nil {
 		return AuthInfo{}, err
@@ -135,5 +176,7 @@ func verifySoaToken(token string, verifier soatoken.Verifier, reqs authRequireme
 	return AuthInfo{
 		ProfileId: parsedToken.ProfileId.String(),
 		AccountId: parsedToken.AccountId,
+		Role:      soajwt.ROLE_USER,
+		Kind:      AUTH_KIND_SOA,
 	}, nil
 }
@@ -1,58 +1,282 @@
 package interceptors
 
-import pb "soa-socialnetwork/services/accounts/proto"
+import (
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	pb "soa-socialnetwork/services/accounts/proto"
+)
 
 type authRequirements struct {
-	needAuth        bool
-	needReadAccess  bool
-	needWriteAccess bool
+	needAuth bool
+	// Token is verified if given, otherwise the method is called without auth info
+	optionalAuth bool
+	// Required from api tokens, jwt has all scopes
+	scope soatoken.Scope
 }
 
 var methods_auth_requirements = map[string]authRequirements{
 	pb.AccountsService_RegisterUser_FullMethodName: {
-		needAuth:        false,
-		needReadAccess:  false,
-		needWriteAccess: false,
+		needAuth: false,
 	},
 	pb.AccountsService_UnregisterUser_FullMethodName: {
-		needAuth:        true,
-		needReadAccess:  false,
-		needWriteAccess: true,
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
 	},
 	pb.AccountsService_GetProfile_FullMethodName: {
-		needAuth:        false,
-		needReadAccess:  false,
-		needWriteAccess: false,
+		needAuth:     false,
+		optionalAuth: true,
+	},
+	pb.AccountsService_GetProfiles_FullMethodName: {
+		needAuth:     false,
+		optionalAuth: true,
+	},
+	pb.AccountsService_SearchProfiles_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_Follow_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_Unfollow_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_ListFollowers_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_ListFollowing_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_ListFriends_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_Block_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_Unblock_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_ListBlocked_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
 	},
 	pb.AccountsService_EditProfile_FullMethodName: {
-		needAuth:        true,
-		needReadAccess:  false,
-		needWriteAccess: true,
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_GetPrivacySettings_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_EditPrivacySettings_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_UploadProfileImage_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_DeleteProfileImage_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_PROFILE_WRITE,
+	},
+	pb.AccountsService_GetImage_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_RequestDataExport_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_GetDataExport_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_DownloadDataExport_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_GetAccountStatus_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_SetAccountRole_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_SuspendAccount_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_UnsuspendAccount_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
 	},
 	pb.AccountsService_Authenticate_FullMethodName: {
-		needAuth:        false,
-		needReadAccess:  false,
-		needWriteAccess: false,
+		needAuth: false,
+	},
+	pb.AccountsService_RefreshToken_FullMethodName: {
+		needAuth: false,
 	},
 	pb.AccountsService_CreateApiToken_FullMethodName: {
-		needAuth:        false,
-		needReadAccess:  false,
-		needWriteAccess: false,
+		needAuth: false,
 	},
 	pb.AccountsService_ValidateApiToken_FullMethodName: {
-		needAuth:        false,
-		needReadAccess:  false,
-		needWriteAccess: false,
+		needAuth: false,
+	},
+	pb.AccountsService_ListApiTokens_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_TOKENS_READ,
+	},
+	pb.AccountsService_RevokeApiToken_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_TOKENS_MANAGE,
+	},
+	pb.AccountsService_RevokeAllApiTokens_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_TOKENS_MANAGE,
+	},
+	pb.AccountsService_ChangePassword_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_RequestPasswordReset_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_ResetPassword_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_GetContacts_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_ChangeContact_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_SendVerificationCode_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_ConfirmContact_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_AuthenticateSecondFactor_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_CreateApiTokenSecondFactor_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_EnrollTotp_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_ConfirmTotp_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_DisableTotp_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_RegenerateBackupCodes_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_ListAuthLockouts_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_ClearAuthLockouts_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_ListAuditEvents_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_RegisterOAuthClient_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_ListOAuthClients_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_GetOAuthClient_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_DeleteOAuthClient_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_AuthorizeOAuthClient_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_TOKENS_MANAGE,
+	},
+	pb.AccountsService_ExchangeOAuthCode_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_ListAuthorizedApps_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_TOKENS_READ,
+	},
+	pb.AccountsService_RevokeAuthorizedApp_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_TOKENS_MANAGE,
+	},
+	pb.AccountsService_ListOidcProviders_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_BeginOidcLogin_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_CompleteOidcLogin_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_BeginOidcLink_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_CompleteOidcLink_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_ListOidcIdentities_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_UnlinkOidcIdentity_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_ListSessions_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_READ,
+	},
+	pb.AccountsService_TerminateSession_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_TerminateAllSessions_FullMethodName: {
+		needAuth: true,
+		scope:    soatoken.SCOPE_ACCOUNT_MANAGE,
+	},
+	pb.AccountsService_GetJwks_FullMethodName: {
+		needAuth: false,
 	},
 	pb.AccountsService_ResolveProfileId_FullMethodName: {
-		needAuth:        false,
-		needReadAccess:  false,
-		needWriteAccess: false,
+		needAuth: false,
 	},
 	pb.AccountsService_ResolveAccountId_FullMethodName: {
-		needAuth:        false,
-		needReadAccess:  false,
-		needWriteAccess: false,
+		needAuth: false,
+	},
+	pb.AccountsService_ResolveProfileIds_FullMethodName: {
+		needAuth: false,
+	},
+	pb.AccountsService_ResolveAccountIds_FullMethodName: {
+		needAuth: false,
 	},
 }
 
@@ -60,9 +284,7 @@ func getAuthRequirements(fullMethodName string) authRequirements {
 	reqs, ok := methods_auth_requirements[fullMethodName]
 	if !ok {
 		return authRequirements{
-			needAuth:        false,
-			needReadAccess:  false,
-			needWriteAccess: false,
+			needAuth: false,
 		}
 	}
 
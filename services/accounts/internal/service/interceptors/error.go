@@ -2,9 +2,13 @@ package interceptors
 
 import (
 	"context"
+	"soa-socialnetwork/services/accounts/internal/blobstore"
+	"soa-socialnetwork/services/accounts/internal/images"
 	serviceErrs "soa-socialnetwork/services/accounts/internal/service/errs"
 	"soa-socialnetwork/services/accounts/internal/service/interceptors/errs"
 	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -16,20 +20,32 @@ func convertToGrpcCode(err error) (code codes.Code, knownError bool) {
 	case errs.InvalidToken, errs.NoAuth:
 		return codes.PermissionDenied, true
 
-	case errs.UnknownAuthKind:
+	case pgErrs.ContactAlreadyUsed, pgErrs.OidcIdentityAlreadyLinked, pgErrs.DataExportConflict:
+		return codes.AlreadyExists, true
+
+	case errs.UnknownAuthKind, pgErrs.InvalidPagiToken, images.InvalidImage:
 		return codes.InvalidArgument, true
 
 	case errs.NoMetadata:
 		return codes.Internal, true
 
-	case pgErrs.TokenNotFound, pgErrs.AccountNotFound, pgErrs.ProfileNotFound, pgErrs.UserIdNotFound:
+	case pgErrs.TokenNotFound, pgErrs.AccountNotFound, pgErrs.ProfileNotFound, pgErrs.UserIdNotFound, pgErrs.ContactNotFound,
+		pgErrs.SessionNotFound, pgErrs.DataExportNotFound, pgErrs.OAuthClientNotFound, pgErrs.OidcIdentityNotFound, blobstore.BlobNotFound:
 		return codes.NotFound, true
 
-	case pgErrs.PasswordsDoNotMatch:
+	case soatoken.MissingScope, soajwt.SessionRevoked, serviceErrs.TokenExpired, serviceErrs.TokenRevoked, serviceErrs.AccessDenied, serviceErrs.PasswordsDoNotMatch,
+		serviceErrs.RefreshTokenRevoked, serviceErrs.RefreshTokenReused, serviceErrs.InvalidResetCode,
+		serviceErrs.InvalidVerificationCode, serviceErrs.InvalidCredentials,
+		serviceErrs.InvalidSecondFactorCode, serviceErrs.InvalidSecondFactorChallenge, serviceErrs.AccountSuspended:
 		return codes.PermissionDenied, true
 
-	case serviceErrs.NoReadAccess, serviceErrs.NoWriteAccess, serviceErrs.TokenExpired, serviceErrs.AccessDenied:
-		return codes.PermissionDenied, true
+	case serviceErrs.TwoFactorAlreadyEnabled, serviceErrs.TwoFactorNotEnabled, serviceErrs.CannotSuspendAdmin, serviceErrs.InvalidOAuthGrant,
+		serviceErrs.InvalidOidcLogin, serviceErrs.OidcIdentityNotLinked, serviceErrs.OidcEmailTaken,
+		serviceErrs.LastSignInMethod:
+		return codes.FailedPrecondition, true
+
+	case serviceErrs.TooManyAuthAttempts, serviceErrs.TooManyDataExports, serviceErrs.TooManyVerificationCodes:
+		return codes.ResourceExhausted, true
 
 	default:
 		return codes.Internal, false
@@ -55,3 +71,14 @@ func ConvertErrors() grpc.UnaryServerInterceptor {
 		return nil, convertToGrpcError(err)
 	}
 }
+
+func ConvertErrorsStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err == nil {
+			return nil
+		}
+
+		return convertToGrpcError(err)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	"time"
+)
+
+const OAUTH_CODE_TTL = 10 * time.Minute
+const OAUTH_TOKEN_TTL = 30 * 24 * time.Hour
+
+const OAUTH_CLIENT_ID_RAW_LENGTH = 16
+const OAUTH_CODE_RAW_LENGTH = 32
+
+const MAX_OAUTH_CLIENT_NAME_LENGTH = 64
+const MAX_OAUTH_REDIRECT_URIS = 10
+const MAX_OAUTH_CLIENTS_PER_ACCOUNT = 20
+
+const OAUTH_CODE_CHALLENGE_METHOD_S256 = "S256"
+const OAUTH_TOKEN_TYPE = "SoaToken"
+
+// Applications must not manage credentials or the account itself, it is
+// up to the user
+var oauth_forbidden_scopes = []soatoken.Scope{
+	soatoken.SCOPE_ACCOUNT_MANAGE,
+	soatoken.SCOPE_TOKENS_MANAGE,
+}
+
+// RFC 7636: 43-128 characters of unreserved set
+var code_verifier_regexp = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// Base64url of SHA-256 without padding
+var code_challenge_regexp = regexp.MustCompile(`^[A-Za-z0-9\-_]{43}$`)
+
+func newOAuthClientId() (models.OAuthClientId, error) {
+	var raw [OAUTH_CLIENT_ID_RAW_LENGTH]byte
+	_, err := rand.Read(raw[:])
+	if err != nil {
+		return "", err
+	}
+
+	return models.OAuthClientId(hex.EncodeToString(raw[:])), nil
+}
+
+func newOAuthCode() (string, error) {
+	var raw [OAUTH_CODE_RAW_LENGTH]byte
+	_, err := rand.Read(raw[:])
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw[:]), nil
+}
+
+func hashOAuthCode(code string) models.OAuthCodeHash {
+	hash := sha256.Sum256([]byte(code))
+	return models.OAuthCodeHash(hex.EncodeToString(hash[:]))
+}
+
+// Only https is allowed, except for loopback addresses of native
+// applications; fragments are forbidden by RFC 6749
+func validateRedirectUri(rawUri string) error {
+	uri, err := url.Parse(rawUri)
+	if err != nil {
+		return fmt.Errorf("invalid redirect uri %q", rawUri)
+	}
+
+	if !uri.IsAbs() || uri.Host == "" {
+		return fmt.Errorf("redirect uri %q must be absolute", rawUri)
+	}
+
+	if uri.Fragment != "" || uri.User != nil {
+		return fmt.Errorf("redirect uri %q must have neither fragment nor user info", rawUri)
+	}
+
+	switch uri.Scheme {
+	case "https":
+		return nil
+
+	case "http":
+		if isLoopbackHost(uri.Hostname()) {
+			return nil
+		}
+
+		return fmt.Errorf("redirect uri %q must use https", rawUri)
+
+	default:
+		return fmt.Errorf("redirect uri %q must use https", rawUri)
+	}
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func oauthScopes(raw []string) ([]soatoken.Scope, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("at least one scope must be requested")
+	}
+
+	scopes, err := soatoken.ParseScopes(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range scopes {
+		if slices.Contains(oauth_forbidden_scopes, scope) {
+			return nil, fmt.Errorf("scope %s cannot be granted to applications", scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+func validateCodeChallenge(challenge string, method string) error {
+	if method != OAUTH_CODE_CHALLENGE_METHOD_S256 {
+		return fmt.Errorf("code challenge method must be %s", OAUTH_CODE_CHALLENGE_METHOD_S256)
+	}
+
+	if !code_challenge_regexp.MatchString(challenge) {
+		return fmt.Errorf("code challenge must be base64url encoded SHA-256")
+	}
+
+	return nil
+}
+
+func verifyCodeVerifier(verifier string, challenge string) bool {
+	if !code_verifier_regexp.MatchString(verifier) {
+		return false
+	}
+
+	hash := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// Code and state are added to query of registered redirect uri
+func oauthRedirectTo(redirectUri string, code string, state string) (string, error) {
+	uri, err := url.Parse(redirectUri)
+	if err != nil {
+		return "", err
+	}
+
+	query := uri.Query()
+	query.Set("code", code)
+	if state != "" {
+		query.Set("state", state)
+	}
+	uri.RawQuery = query.Encode()
+
+	return uri.String(), nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const OIDC_LOGIN_STATE_TTL = 10 * time.Minute
+
+// State, nonce and PKCE verifier are base64url of this many random bytes
+const OIDC_SECRET_RAW_LENGTH = 32
+
+// Provisioned logins are a prefix derived from identity with random suffix
+const PROVISIONED_LOGIN_PREFIX_MAX_LENGTH = 24
+const PROVISIONED_LOGIN_SUFFIX_RAW_LENGTH = 3
+const PROVISIONED_LOGIN_ATTEMPTS = 5
+
+// Names of profiles are limited by profiles table
+const PROVISIONED_NAME_MAX_LENGTH = 32
+
+// Provider names are used in api paths and stored with identities
+var oidc_provider_name_regexp = regexp.MustCompile(`^[a-z0-9_\-]{1,32}$`)
+
+func newOidcProviders(configs []oidc.ProviderConfig) (map[string]*oidc.Provider, error) {
+	providers := make(map[string]*oidc.Provider, len(configs))
+	for _, cfg := range configs {
+		if !oidc_provider_name_regexp.MatchString(cfg.Name) {
+			return nil, fmt.Errorf("invalid oidc provider name %q", cfg.Name)
+		}
+
+		if _, ok := providers[cfg.Name]; ok {
+			return nil, fmt.Errorf("duplicate oidc provider %q", cfg.Name)
+		}
+
+		if cfg.Issuer == "" || cfg.ClientId == "" || cfg.RedirectUri == "" {
+			return nil, fmt.Errorf("oidc provider %q must have issuer, client id and redirect uri", cfg.Name)
+		}
+
+		providers[cfg.Name] = oidc.NewProvider(cfg, nil)
+	}
+
+	return providers, nil
+}
+
+func newOidcSecret() (string, error) {
+	var raw [OIDC_SECRET_RAW_LENGTH]byte
+	_, err := rand.Read(raw[:])
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw[:]), nil
+}
+
+func hashOidcState(state string) models.OidcLoginStateHash {
+	hash := sha256.Sum256([]byte(state))
+	return models.OidcLoginStateHash(hex.EncodeToString(hash[:]))
+}
+
+func isOidcBindingValid(state models.OidcLoginState, binding string) bool {
+	return subtle.ConstantTimeCompare([]byte(state.BindingHash), []byte(hashOidcState(binding))) == 1
+}
+
+// Email is trusted only if the provider has verified it
+func verifiedOidcEmail(identity oidc.Identity) string {
+	if !identity.EmailVerified {
+		return ""
+	}
+
+	return identity.Email
+}
+
+// Preferred username or local part of email, reduced to lowercase letters,
+// digits and underscores
+func provisionedLoginPrefix(identity oidc.Identity) string {
+	source := identity.PreferredUsername
+	if source == "" {
+		source, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	var prefix strings.Builder
+	for _, r := range strings.ToLower(source) {
+		if prefix.Len() >= PROVISIONED_LOGIN_PREFIX_MAX_LENGTH {
+			break
+		}
+
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			prefix.WriteRune(r)
+		}
+	}
+
+	if prefix.Len() == 0 {
+		return "user"
+	}
+
+	return prefix.String()
+}
+
+func newProvisionedLogin(prefix string) (string, error) {
+	var raw [PROVISIONED_LOGIN_SUFFIX_RAW_LENGTH]byte
+	_, err := rand.Read(raw[:])
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + "_" + hex.EncodeToString(raw[:]), nil
+}
+
+// Given and family names if provided, otherwise full name is split at the
+// first space
+func provisionedName(identity oidc.Identity) (name string, surname string) {
+	name, surname = identity.GivenName, identity.FamilyName
+	if name == "" && surname == "" {
+		name, surname, _ = strings.Cut(strings.TrimSpace(identity.Name), " ")
+	}
+
+	if name == "" {
+		name = provisionedLoginPrefix(identity)
+	}
+
+	return truncateRunes(strings.TrimSpace(name), PROVISIONED_NAME_MAX_LENGTH), truncateRunes(strings.TrimSpace(surname), PROVISIONED_NAME_MAX_LENGTH)
+}
+
+func truncateRunes(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+
+	return string(runes[:maxLength])
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"soa-socialnetwork/services/accounts/internal/oidc/oidctest"
+	pb "soa-socialnetwork/services/accounts/proto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOidcProviders(t *testing.T) {
+	valid := oidc.ProviderConfig{
+		Name:        "corp",
+		Issuer:      "https://sso.corp.example.com",
+		ClientId:    "soa",
+		RedirectUri: "https://soa.example.com/oidc/callback",
+	}
+
+	providers, err := newOidcProviders([]oidc.ProviderConfig{valid})
+	require.NoError(t, err)
+	assert.Contains(t, providers, "corp")
+
+	for name, tweak := range map[string]func(*oidc.ProviderConfig){
+		"empty name":      func(c *oidc.ProviderConfig) { c.Name = "" },
+		"uppercase name":  func(c *oidc.ProviderConfig) { c.Name = "Corp" },
+		"long name":       func(c *oidc.ProviderConfig) { c.Name = strings.Repeat("a", 33) },
+		"no issuer":       func(c *oidc.ProviderConfig) { c.Issuer = "" },
+		"no client id":    func(c *oidc.ProviderConfig) { c.ClientId = "" },
+		"no redirect uri": func(c *oidc.ProviderConfig) { c.RedirectUri = "" },
+	} {
+		cfg := valid
+		tweak(&cfg)
+		_, err := newOidcProviders([]oidc.ProviderConfig{cfg})
+		assert.Error(t, err, name)
+	}
+
+	_, err = newOidcProviders([]oidc.ProviderConfig{valid, valid})
+	assert.Error(t, err, "duplicate name")
+}
+
+func TestListOidcProviders(t *testing.T) {
+	idp, err := oidctest.NewMockIdP("soa-client", "soa-secret")
+	require.NoError(t, err)
+	defer idp.Close()
+
+	staff := idp.ProviderConfig("staff", "https://soa.example.com/oidc/staff")
+	partners := idp.ProviderConfig("partners", "https://soa.example.com/oidc/partners")
+	staff.AutoProvision = true
+
+	providers, err := newOidcProviders([]oidc.ProviderConfig{staff, partners})
+	require.NoError(t, err)
+
+	s := &AccountsService{oidcProviders: providers}
+	resp, err := s.ListOidcProviders(context.Background(), &pb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, resp.Providers, 2)
+	assert.Equal(t, "partners", resp.Providers[0].Name)
+	assert.False(t, resp.Providers[0].AutoProvision)
+	assert.Equal(t, "staff", resp.Providers[1].Name)
+	assert.True(t, resp.Providers[1].AutoProvision)
+
+	_, err = s.getOidcProvider("unknown")
+	assert.Error(t, err)
+}
+
+func TestProvisionedLogin(t *testing.T) {
+	assert.Equal(t, "jane_doe", provisionedLoginPrefix(oidc.Identity{PreferredUsername: "Jane_Doe", Email: "jd@corp.example.com"}))
+	assert.Equal(t, "jd", provisionedLoginPrefix(oidc.Identity{Email: "j.d@corp.example.com"}))
+	assert.Equal(t, "user", provisionedLoginPrefix(oidc.Identity{PreferredUsername: "Жанна"}))
+	assert.Len(t, provisionedLoginPrefix(oidc.Identity{PreferredUsername: strings.Repeat("a", 100)}), PROVISIONED_LOGIN_PREFIX_MAX_LENGTH)
+
+	login, err := newProvisionedLogin("jane")
+	require.NoError(t, err)
+	assert.Regexp(t, `^jane_[0-9a-f]{6}$`, login)
+}
+
+func TestProvisionedName(t *testing.T) {
+	name, surname := provisionedName(oidc.Identity{GivenName: "Jane", FamilyName: "Doe", Name: "Dr. Jane Doe"})
+	assert.Equal(t, "Jane", name)
+	assert.Equal(t, "Doe", surname)
+
+	name, surname = provisionedName(oidc.Identity{Name: " Jane van Doe "})
+	assert.Equal(t, "Jane", name)
+	assert.Equal(t, "van Doe", surname)
+
+	name, surname = provisionedName(oidc.Identity{PreferredUsername: "jane"})
+	assert.Equal(t, "jane", name)
+	assert.Empty(t, surname)
+
+	name, _ = provisionedName(oidc.Identity{GivenName: strings.Repeat("ж", 40)})
+	assert.Equal(t, strings.Repeat("ж", PROVISIONED_NAME_MAX_LENGTH), name)
+}
+
+func TestOidcBinding(t *testing.T) {
+	state := models.OidcLoginState{
+		OidcLoginStateParams: models.OidcLoginStateParams{BindingHash: hashOidcState("binding")},
+	}
+	assert.True(t, isOidcBindingValid(state, "binding"))
+	assert.False(t, isOidcBindingValid(state, "other"))
+
+	// states stored before binding was introduced
+	assert.False(t, isOidcBindingValid(models.OidcLoginState{}, ""))
+}
+
+func TestVerifiedOidcEmail(t *testing.T) {
+	assert.Equal(t, "jane@corp.example.com", verifiedOidcEmail(oidc.Identity{Email: "jane@corp.example.com", EmailVerified: true}))
+	assert.Empty(t, verifiedOidcEmail(oidc.Identity{Email: "jane@corp.example.com"}))
+}
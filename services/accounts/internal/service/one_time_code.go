@@ -0,0 +1,38 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+const ONE_TIME_CODE_DIGITS = 6
+
+// Numeric codes sent to users for password reset and contacts verification
+func newOneTimeCode() (string, error) {
+	max := big.NewInt(1)
+	for range ONE_TIME_CODE_DIGITS {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", ONE_TIME_CODE_DIGITS, n), nil
+}
+
+// Codes are short, so hashing only hides them from casual database readers,
+// guessing is prevented by short ttl and attempts limit
+func hashOneTimeCode(code string) string {
+	hash := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(hash[:])
+}
+
+func oneTimeCodeMatches(code string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashOneTimeCode(code)), []byte(hash)) == 1
+}
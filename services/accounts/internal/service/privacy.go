@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/service/interceptors"
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Caller of a method with optional auth
+type viewer struct {
+	authenticated bool
+	profileId     models.ProfileId
+}
+
+func getViewer(ctx context.Context) viewer {
+	authInfo, ok := ctx.Value(interceptors.AuthInfoKey).(interceptors.AuthInfo)
+	if !ok {
+		return viewer{}
+	}
+
+	return viewer{
+		authenticated: true,
+		profileId:     models.ProfileId(authInfo.ProfileId),
+	}
+}
+
+// Owner sees all fields of own profile regardless of settings
+func (v viewer) canSee(owner models.ProfileId, visibility models.Visibility) bool {
+	if v.authenticated && v.profileId == owner {
+		return true
+	}
+
+	switch visibility {
+	case models.VISIBILITY_EVERYONE:
+		return true
+
+	case models.VISIBILITY_AUTHENTICATED:
+		return v.authenticated
+
+	default:
+		return false
+	}
+}
+
+// Clears birthday and bio hidden from viewer
+func hidePrivateFields(profile *pb.Profile, data models.ProfileData, v viewer) {
+	if !v.canSee(data.ProfileId, data.Privacy.Birthday) {
+		profile.Birthday = nil
+	}
+
+	if !v.canSee(data.ProfileId, data.Privacy.Bio) {
+		profile.Bio = ""
+	}
+}
+
+func visibilityToProto(visibility models.Visibility) pb.Visibility {
+	switch visibility {
+	case models.VISIBILITY_EVERYONE:
+		return pb.Visibility_VISIBILITY_EVERYONE
+
+	case models.VISIBILITY_AUTHENTICATED:
+		return pb.Visibility_VISIBILITY_AUTHENTICATED
+
+	case models.VISIBILITY_ONLY_ME:
+		return pb.Visibility_VISIBILITY_ONLY_ME
+	}
+
+	return pb.Visibility_VISIBILITY_UNSPECIFIED
+}
+
+func visibilityFromProto(visibility pb.Visibility) (models.Visibility, error) {
+	switch visibility {
+	case pb.Visibility_VISIBILITY_EVERYONE:
+		return models.VISIBILITY_EVERYONE, nil
+
+	case pb.Visibility_VISIBILITY_AUTHENTICATED:
+		return models.VISIBILITY_AUTHENTICATED, nil
+
+	case pb.Visibility_VISIBILITY_ONLY_ME:
+		return models.VISIBILITY_ONLY_ME, nil
+	}
+
+	return "", status.Error(codes.InvalidArgument, "unknown visibility")
+}
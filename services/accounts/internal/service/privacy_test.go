@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/service/interceptors"
+	pb "soa-socialnetwork/services/accounts/proto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestViewerCanSee(t *testing.T) {
+	const owner models.ProfileId = "owner"
+
+	anonymous := getViewer(context.Background())
+	stranger := getViewer(context.WithValue(context.Background(), interceptors.AuthInfoKey, interceptors.AuthInfo{ProfileId: "stranger"}))
+	self := getViewer(context.WithValue(context.Background(), interceptors.AuthInfoKey, interceptors.AuthInfo{ProfileId: string(owner)}))
+
+	assert.True(t, anonymous.canSee(owner, models.VISIBILITY_EVERYONE))
+	assert.False(t, anonymous.canSee(owner, models.VISIBILITY_AUTHENTICATED))
+	assert.False(t, anonymous.canSee(owner, models.VISIBILITY_ONLY_ME))
+
+	assert.True(t, stranger.canSee(owner, models.VISIBILITY_EVERYONE))
+	assert.True(t, stranger.canSee(owner, models.VISIBILITY_AUTHENTICATED))
+	assert.False(t, stranger.canSee(owner, models.VISIBILITY_ONLY_ME))
+
+	assert.True(t, self.canSee(owner, models.VISIBILITY_ONLY_ME))
+}
+
+func TestHidePrivateFields(t *testing.T) {
+	data := models.ProfileData{
+		ProfileId: "owner",
+		Privacy: models.PrivacySettings{
+			Birthday: models.VISIBILITY_ONLY_ME,
+			Bio:      models.VISIBILITY_AUTHENTICATED,
+		},
+	}
+	newProfile := func() *pb.Profile {
+		return &pb.Profile{
+			Birthday: timestamppb.Now(),
+			Bio:      "bio",
+		}
+	}
+
+	profile := newProfile()
+	hidePrivateFields(profile, data, viewer{})
+	assert.Nil(t, profile.Birthday)
+	assert.Empty(t, profile.Bio)
+
+	profile = newProfile()
+	hidePrivateFields(profile, data, viewer{authenticated: true, profileId: "stranger"})
+	assert.Nil(t, profile.Birthday)
+	assert.Equal(t, "bio", profile.Bio)
+
+	profile = newProfile()
+	hidePrivateFields(profile, data, viewer{authenticated: true, profileId: "owner"})
+	assert.NotNil(t, profile.Birthday)
+	assert.Equal(t, "bio", profile.Bio)
+}
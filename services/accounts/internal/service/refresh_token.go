@@ -0,0 +1,28 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"soa-socialnetwork/services/accounts/internal/models"
+)
+
+const REFRESH_TOKEN_RAW_LENGTH = 32
+
+func newRefreshToken() (string, error) {
+	var rawToken [REFRESH_TOKEN_RAW_LENGTH]byte
+	_, err := rand.Read(rawToken[:])
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(rawToken[:]), nil
+}
+
+// Refresh tokens have enough entropy, so plain SHA-256 is sufficient
+// to make leaked database rows useless
+func hashRefreshToken(token string) models.RefreshTokenHash {
+	hash := sha256.Sum256([]byte(token))
+	return models.RefreshTokenHash(hex.EncodeToString(hash[:]))
+}
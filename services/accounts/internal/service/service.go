@@ -3,8 +3,13 @@ package service
 import (
 	"context"
 	"crypto/ed25519"
+	"fmt"
 	"time"
 
+	"soa-socialnetwork/services/accounts/internal/blobstore"
+	"soa-socialnetwork/services/accounts/internal/notify"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"soa-socialnetwork/services/accounts/internal/passhash"
 	"soa-socialnetwork/services/accounts/internal/repo"
 	"soa-socialnetwork/services/accounts/internal/soajwtissuer"
 	"soa-socialnetwork/services/accounts/internal/storage/postgres"
@@ -12,6 +17,11 @@ import (
 	"soa-socialnetwork/services/accounts/pkg/soatoken"
 	pb "soa-socialnetwork/services/accounts/proto"
 	"soa-socialnetwork/services/common/backjob"
+	postsPb "soa-socialnetwork/services/posts/proto"
+	statsPb "soa-socialnetwork/services/stats/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type AccountsService struct {
@@ -21,8 +31,27 @@ type AccountsService struct {
 	JwtVerifier soajwt.Verifier
 	SoaVerifier soatoken.Verifier
 
-	outboxJob backjob.TickerJob
-	jwtIssuer soajwtissuer.Issuer
+	outboxJob               backjob.TickerJob
+	dataExportJob           backjob.TickerJob
+	soaTokenCacheStatsJob   backjob.TickerJob
+	revocationsJob          backjob.TickerJob
+	soaInvalidationsJob     backjob.TickerJob
+	revokedSessions         *soajwt.RevocationCache
+	soaTokenCache           *soatoken.CachingVerifier
+	jwtIssuer               soajwtissuer.Issuer
+	jwks                    []soajwt.Jwk
+	passwordHasher          passhash.Hasher
+	dummyPasswordHash       string
+	apiTokenHasher          *apiTokenHasher
+	notifier                notify.Notifier
+	blobStore               blobstore.Store
+	imagesBaseUrl           string
+	exportStore             blobstore.Store
+	postsClient             postsPb.PostsServiceClient
+	statsClient             statsPb.StatsServiceClient
+	requireVerifiedContacts bool
+	adminLogins             []string
+	oidcProviders           map[string]*oidc.Provider
 }
 
 func NewAccountsService(cfg Config) (*AccountsService, error) {
@@ -41,21 +70,88 @@ func NewAccountsService(cfg Config) (*AccountsService, error) {
 	pubkey := cfg.JwtPrivateKey.Public().(ed25519.PublicKey)
 
 	jwtIssuer := soajwtissuer.New(cfg.JwtPrivateKey)
-	jwtVerifier := soajwt.NewEd25519Verifier(pubkey)
-	soaVerifier := soaVerifier{db: &db}
+	trustedKeys := append([]ed25519.PublicKey{pubkey}, cfg.JwtPreviousPublicKeys...)
+	jwtVerifier := soajwt.NewKeySetVerifier(trustedKeys...)
+	revokedSessions := soajwt.NewRevocationCache()
+	jwks := make([]soajwt.Jwk, 0, len(trustedKeys))
+	for _, key := range trustedKeys {
+		jwks = append(jwks, soajwt.NewEd25519Jwk(key))
+	}
+	apiTokenHasher := &apiTokenHasher{key: cfg.ApiTokenHmacKey}
+	soaTokenCache := soatoken.NewCachingVerifier(&soaRightsFetcher{db: &db, hasher: apiTokenHasher}, SOA_TOKEN_CACHE_TTL, soatoken.DEFAULT_RIGHTS_CACHE_CAPACITY)
+
+	passwordHasher := passhash.New(cfg.PasswordHashParams)
+	dummyPasswordHash, err := passwordHasher.Hash("dummy password")
+	if err != nil {
+		return nil, err
+	}
+
+	oidcProviders, err := newOidcProviders(cfg.OidcProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hashLegacyApiTokens(ctx, &db, apiTokenHasher)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = conn.Accounts().SetRoleByLogins(cfg.AdminLogins, soajwt.ROLE_ADMIN)
+	if err != nil {
+		return nil, err
+	}
+
+	postsConn, err := grpc.NewClient(fmt.Sprintf("%s:%d", cfg.PostsServiceHost, cfg.PostsServicePort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	statsConn, err := grpc.NewClient(fmt.Sprintf("%s:%d", cfg.StatsServiceHost, cfg.StatsServicePort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
 
 	service := &AccountsService{
 		Db:          &db,
-		JwtVerifier: &jwtVerifier,
-		SoaVerifier: &soaVerifier,
+		JwtVerifier: soajwt.NewRevocationVerifier(jwtVerifier, revokedSessions),
+		SoaVerifier: soaTokenCache,
 
-		outboxJob: backjob.NewTickerJob(3*time.Second, checkOutboxJob(&db)),
-		jwtIssuer: jwtIssuer,
+		outboxJob:               backjob.NewTickerJob(3*time.Second, checkOutboxJob(&db)),
+		soaTokenCacheStatsJob:   backjob.NewTickerJob(time.Minute, logSoaTokenCacheStatsJob(soaTokenCache)),
+		revocationsJob:          backjob.NewTickerJob(REVOCATIONS_REFRESH_PERIOD, soajwt.NewKafkaRevocationCallback("stats-kafka:9092", revokedSessions)),
+		soaInvalidationsJob:     backjob.NewTickerJob(REVOCATIONS_REFRESH_PERIOD, soatoken.NewKafkaInvalidationCallback("stats-kafka:9092", soaTokenCache)),
+		revokedSessions:         revokedSessions,
+		soaTokenCache:           soaTokenCache,
+		jwtIssuer:               jwtIssuer,
+		jwks:                    jwks,
+		passwordHasher:          passwordHasher,
+		dummyPasswordHash:       dummyPasswordHash,
+		apiTokenHasher:          apiTokenHasher,
+		notifier:                cfg.Notifier,
+		blobStore:               cfg.BlobStore,
+		imagesBaseUrl:           cfg.ImagesBaseUrl,
+		exportStore:             cfg.ExportStore,
+		postsClient:             postsPb.NewPostsServiceClient(postsConn),
+		statsClient:             statsPb.NewStatsServiceClient(statsConn),
+		requireVerifiedContacts: cfg.RequireVerifiedContacts,
+		adminLogins:             cfg.AdminLogins,
+		oidcProviders:           oidcProviders,
 	}
+	service.dataExportJob = backjob.NewTickerJob(2*time.Second, processDataExportsJob(service))
 
 	return service, nil
 }
 
 func (s *AccountsService) Start() {
 	s.outboxJob.Run()
+	s.dataExportJob.Run()
+	s.soaTokenCacheStatsJob.Run()
+	s.revocationsJob.Run()
+	s.soaInvalidationsJob.Run()
 }
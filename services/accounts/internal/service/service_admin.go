@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+	"unicode/utf8"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const MAX_SUSPENSION_REASON_LENGTH = 256
+
+func (s *AccountsService) GetAccountStatus(ctx context.Context, req *pb.GetAccountStatusRequest) (*pb.AccountStatus, error) {
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = requireAdmin(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	accountId, err := conn.Profiles().ResolveProfileId(models.ProfileId(req.ProfileId))
+	if err != nil {
+		return nil, err
+	}
+
+	accountStatus, err := conn.Accounts().GetStatus(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := roleToProto(accountStatus.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.AccountStatus{
+		Role: role,
+	}
+	if accountStatus.Suspension.HasValue {
+		suspension := accountStatus.Suspension.Value
+		resp.Suspension = &pb.Suspension{
+			Reason:      suspension.Reason,
+			SuspendedAt: timestamppb.New(suspension.SuspendedAt),
+		}
+		if suspension.Until.HasValue {
+			resp.Suspension.Until = timestamppb.New(suspension.Until.Value)
+		}
+	}
+
+	return resp, nil
+}
+
+// Services trust the role claim of jwt tokens, so sessions of the account
+// are terminated and it must log in again to get tokens with the new role
+func (s *AccountsService) SetAccountRole(ctx context.Context, req *pb.SetAccountRoleRequest) (*pb.Empty, error) {
+	role, err := roleFromProto(req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = requireAdmin(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// otherwise the last administrator could lock everyone out
+	if getAuthInfo(ctx).ProfileId == req.ProfileId {
+		return nil, status.Error(codes.FailedPrecondition, "cannot change own role")
+	}
+
+	accountId, err := tx.Profiles().ResolveProfileId(models.ProfileId(req.ProfileId))
+	if err != nil {
+		return nil, err
+	}
+
+	accountStatus, err := tx.Accounts().GetStatus(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountStatus.Role == role {
+		return &pb.Empty{}, nil
+	}
+
+	err = tx.Accounts().SetRole(accountId, role)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIds, err := revokeAllSessions(tx, accountId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.rejectSessionJwts(sessionIds)
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) SuspendAccount(ctx context.Context, req *pb.SuspendAccountRequest) (*pb.Empty, error) {
+	if req.Reason == "" {
+		return nil, status.Error(codes.InvalidArgument, "suspension reason is required")
+	}
+	if utf8.RuneCountInString(req.Reason) > MAX_SUSPENSION_REASON_LENGTH {
+		return nil, status.Errorf(codes.InvalidArgument, "suspension reason is longer than %d characters", MAX_SUSPENSION_REASON_LENGTH)
+	}
+
+	until := opt.None[time.Time]()
+	if req.Until != nil {
+		until = opt.Some(req.Until.AsTime())
+		if !until.Value.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "suspension end must be in the future")
+		}
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = requireAdmin(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountId, err := tx.Profiles().ResolveProfileId(models.ProfileId(req.ProfileId))
+	if err != nil {
+		return nil, err
+	}
+
+	accountStatus, err := tx.Accounts().GetStatus(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountStatus.Role == soajwt.ROLE_ADMIN {
+		return nil, errs.CannotSuspendAdmin{}
+	}
+
+	err = tx.Accounts().Suspend(accountId, req.Reason, until)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIds, err := revokeAllSessions(tx, accountId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = publishApiTokensRevoked(tx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateSoaTokens(accountId)
+	s.rejectSessionJwts(sessionIds)
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) UnsuspendAccount(ctx context.Context, req *pb.UnsuspendAccountRequest) (*pb.Empty, error) {
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = requireAdmin(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountId, err := tx.Profiles().ResolveProfileId(models.ProfileId(req.ProfileId))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Accounts().Unsuspend(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	// rejections of suspended account tokens are cached by other services too
+	err = publishApiTokensRevoked(tx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateSoaTokens(accountId)
+
+	return &pb.Empty{}, nil
+}
+
+// Role is checked in the database as the one in jwt may be outdated
+func requireAdmin(ctx context.Context, provider repo.RepoProvider) error {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.Role != soajwt.ROLE_ADMIN {
+		return errs.AccessDenied{}
+	}
+
+	accountStatus, err := provider.Accounts().GetStatus(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return err
+	}
+
+	if accountStatus.Role != soajwt.ROLE_ADMIN {
+		return errs.AccessDenied{}
+	}
+
+	return nil
+}
+
+// Suspended accounts can neither receive new tokens nor use api tokens
+func checkNotSuspended(provider repo.RepoProvider, accountId models.AccountId) (models.AccountStatus, error) {
+	accountStatus, err := provider.Accounts().GetStatus(accountId)
+	if err != nil {
+		return models.AccountStatus{}, err
+	}
+
+	if accountStatus.Suspension.HasValue {
+		suspension := accountStatus.Suspension.Value
+		return models.AccountStatus{}, errs.AccountSuspended{
+			Reason: suspension.Reason,
+			Until:  suspension.Until.Value,
+		}
+	}
+
+	return accountStatus, nil
+}
+
+// Registered accounts with configured admin logins get admin role, so that
+// there is someone to grant roles to others
+func (s *AccountsService) isAdminLogin(login string) bool {
+	return slices.Contains(s.adminLogins, login)
+}
+
+// Roles come from the database, unknown ones are reported instead of crashing
+func roleToProto(role soajwt.Role) (pb.Role, error) {
+	switch role {
+	case soajwt.ROLE_USER:
+		return pb.Role_ROLE_USER, nil
+	case soajwt.ROLE_MODERATOR:
+		return pb.Role_ROLE_MODERATOR, nil
+	case soajwt.ROLE_ADMIN:
+		return pb.Role_ROLE_ADMIN, nil
+	default:
+		return pb.Role_ROLE_UNSPECIFIED, status.Errorf(codes.Internal, "unknown role %q", role)
+	}
+}
+
+func roleFromProto(role pb.Role) (soajwt.Role, error) {
+	switch role {
+	case pb.Role_ROLE_USER:
+		return soajwt.ROLE_USER, nil
+	case pb.Role_ROLE_MODERATOR:
+		return soajwt.ROLE_MODERATOR, nil
+	case pb.Role_ROLE_ADMIN:
+		return soajwt.ROLE_ADMIN, nil
+	default:
+		return "", status.Error(codes.InvalidArgument, "unknown role")
+	}
+}
@@ -2,11 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
 	"soa-socialnetwork/services/accounts/internal/models"
 	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
 	"soa-socialnetwork/services/accounts/internal/soajwtissuer"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/audit"
 	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
 	"time"
 
 	pb "soa-socialnetwork/services/accounts/proto"
@@ -18,35 +23,169 @@ import (
 )
 
 const JWT_DEFAULT_TTL = 30 * time.Second
+const REFRESH_TOKEN_DEFAULT_TTL = 30 * 24 * time.Hour
+const API_TOKEN_REJECTION_AUDIT_PERIOD = time.Hour
 
 func (s *AccountsService) Authenticate(ctx context.Context, req *pb.AuthByPassword) (*pb.AuthResponse, error) {
-	conn, err := s.Db.OpenConnection(ctx)
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	accountParams, err := s.checkPassword(ctx, tx, req)
+	if err != nil {
+		return nil, commitOnFailedPassword(tx, err)
+	}
+
+	challenge, err := s.startSecondFactor(tx, accountParams.Id, models.SECOND_FACTOR_AUTHENTICATE, opt.None[models.PendingApiToken]())
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge != nil {
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		return &pb.AuthResponse{
+			SecondFactor: challenge,
+		}, nil
+	}
+
+	sessionId, err := startSession(ctx, tx, accountParams.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.issueTokens(tx, accountParams.Id, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, accountParams.Id, audit.EVENT_LOGIN_SUCCEEDED, passwordIdentifierType(req))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
-	accountParams, err := s.checkPassword(conn, req)
+	return resp, nil
+}
+
+func (s *AccountsService) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.AuthResponse, error) {
+	tx, err := s.Db.BeginTransaction(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Close()
 
-	profileId, err := conn.Profiles().ResolveAccountId(accountParams.Id)
+	tokenHash := hashRefreshToken(req.RefreshToken)
+	tokenData, err := tx.RefreshTokens().Get(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenData.IsRevoked {
+		return nil, errs.RefreshTokenRevoked{}
+	}
+
+	if tokenData.IsUsed {
+		// replay of already rotated token means that it has leaked, so
+		// neither attacker nor legitimate client may continue the session
+		sessionId := models.SessionId(tokenData.FamilyId)
+		err = tx.Sessions().Revoke(tokenData.AccountId, sessionId)
+		if err != nil && !errors.As(err, &pgErrs.SessionNotFound{}) {
+			return nil, err
+		}
+
+		err = endSessions(tx, tokenData.AccountId, []models.SessionId{sessionId})
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+		s.rejectSessionJwts([]models.SessionId{sessionId})
+
+		log.Printf("refresh token reuse detected for account %d, family %s revoked", tokenData.AccountId, tokenData.FamilyId)
+		return nil, errs.RefreshTokenReused{}
+	}
+
+	if time.Now().After(tokenData.ValidUntil) {
+		return nil, errs.TokenExpired{}
+	}
+
+	err = tx.RefreshTokens().MarkUsed(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionId := models.SessionId(tokenData.FamilyId)
+	err = continueSession(tx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.issueTokens(tx, tokenData.AccountId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (s *AccountsService) issueTokens(provider repo.RepoProvider, accountId models.AccountId, sessionId models.SessionId) (*pb.AuthResponse, error) {
+	accountStatus, err := checkNotSuspended(provider, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	profileId, err := provider.Profiles().ResolveAccountId(accountId)
 	if err != nil {
 		return nil, err
 	}
 
 	token, err := s.jwtIssuer.Issue(soajwtissuer.PersonalData{
-		AccountId: int(accountParams.Id),
+		AccountId: int(accountId),
 		ProfileId: string(profileId),
+		SessionId: string(sessionId),
+		Role:      accountStatus.Role,
 	}, JWT_DEFAULT_TTL)
 	if err != nil {
 		log.Printf("cannot create jwt token: %v", err)
 		return nil, err
 	}
 
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshValidUntil, err := provider.RefreshTokens().Put(hashRefreshToken(refreshToken), repo.RefreshTokenParams{
+		AccountId: accountId,
+		FamilyId:  models.RefreshTokenFamilyId(sessionId),
+		Ttl:       REFRESH_TOKEN_DEFAULT_TTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &pb.AuthResponse{
-		Token: token,
+		Token:                  token,
+		RefreshToken:           refreshToken,
+		RefreshTokenValidUntil: timestamppb.New(refreshValidUntil),
 	}, nil
 }
 
@@ -59,32 +198,84 @@ func (s *AccountsService) CreateApiToken(ctx context.Context, req *pb.CreateApiT
 		return nil, status.Error(codes.InvalidArgument, "no auth params")
 	}
 
-	conn, err := s.Db.OpenConnection(ctx)
+	scopes, err := apiTokenScopes(req.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	accountParams, err := s.checkPassword(ctx, tx, req.Auth)
+	if err != nil {
+		return nil, commitOnFailedPassword(tx, err)
+	}
+
+	pendingToken := models.PendingApiToken{
+		Name:   req.Params.Name,
+		Scopes: scopes,
+		Ttl:    req.Params.Ttl.AsDuration(),
+	}
+
+	challenge, err := s.startSecondFactor(tx, accountParams.Id, models.SECOND_FACTOR_API_TOKEN, opt.Some(pendingToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge != nil {
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		return &pb.CreateApiTokenResponse{
+			SecondFactor: challenge,
+		}, nil
+	}
+
+	resp, err := s.issueApiToken(tx, accountParams.Id, pendingToken, opt.None[models.OAuthClientId]())
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, accountParams.Id, audit.EVENT_API_TOKEN_CREATED, passwordIdentifierType(req.Auth))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
 
-	accountParams, err := s.checkPassword(conn, req.Auth)
+	return resp, nil
+}
+
+func (s *AccountsService) issueApiToken(provider repo.RepoProvider, accountId models.AccountId, params models.PendingApiToken, clientId opt.Option[models.OAuthClientId]) (*pb.CreateApiTokenResponse, error) {
+	_, err := checkNotSuspended(provider, accountId)
 	if err != nil {
 		return nil, err
 	}
 
-	profileId, err := conn.Profiles().ResolveAccountId(accountParams.Id)
+	profileId, err := provider.Profiles().ResolveAccountId(accountId)
 	if err != nil {
 		return nil, err
 	}
 
 	token := soatoken.NewSoaToken(soatoken.Payload{
-		AccountId: int32(accountParams.Id),
+		AccountId: int32(accountId),
 		ProfileId: uuid.MustParse(string(profileId)),
 	})
 
-	validUntil, err := conn.ApiTokens().Put(models.ApiToken(token), repo.ApiTokenParams{
-		AccountId:   int(accountParams.Id),
-		ReadAccess:  req.Params.ReadAccess,
-		WriteAccess: req.Params.WriteAccess,
-		Ttl:         req.Params.Ttl.AsDuration(),
+	tokenId, validUntil, err := provider.ApiTokens().Put(s.apiTokenHasher.Hash(models.ApiToken(token)), repo.ApiTokenParams{
+		AccountId: int(accountId),
+		Name:      params.Name,
+		Scopes:    params.Scopes,
+		Ttl:       params.Ttl,
+		ClientId:  clientId,
 	})
 	if err != nil {
 		return nil, err
@@ -93,9 +284,30 @@ func (s *AccountsService) CreateApiToken(ctx context.Context, req *pb.CreateApiT
 	return &pb.CreateApiTokenResponse{
 		Token:      token,
 		ValidUntil: timestamppb.New(validUntil),
+		TokenId:    int32(tokenId),
 	}, nil
 }
 
+// Explicit scopes take precedence, read/write rights are kept for clients
+// which do not know about scopes
+func apiTokenScopes(params *pb.AuthTokenParams) ([]soatoken.Scope, error) {
+	if len(params.Scopes) == 0 {
+		scopes := soatoken.ScopesFromRights(params.ReadAccess, params.WriteAccess)
+		if len(scopes) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "api token must have at least one scope")
+		}
+
+		return scopes, nil
+	}
+
+	scopes, err := soatoken.ParseScopes(params.Scopes)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return scopes, nil
+}
+
 func (s *AccountsService) ValidateApiToken(ctx context.Context, req *pb.ApiToken) (*pb.ApiTokenValidity, error) {
 	conn, err := s.Db.OpenConnection(ctx)
 	if err != nil {
@@ -103,13 +315,22 @@ func (s *AccountsService) ValidateApiToken(ctx context.Context, req *pb.ApiToken
 	}
 	defer conn.Close()
 
-	tokenData, err := conn.ApiTokens().Get(models.ApiToken(req.Token))
+	tokenData, err := conn.ApiTokens().Get(s.apiTokenHasher.Hash(models.ApiToken(req.Token)))
 	if err != nil {
 		return nil, err
 	}
 
+	_, err = checkNotSuspended(conn, models.AccountId(tokenData.AccountId))
+	if err != nil && !errors.As(err, &errs.AccountSuspended{}) {
+		return nil, err
+	}
+	suspended := err != nil
+
 	now := time.Now()
-	if now.After(tokenData.ValidUntil) {
+	if tokenData.IsRevoked || suspended || now.After(tokenData.ValidUntil) {
+		// unknown tokens are not recorded, as they are bound to no account
+		s.recordRejectedApiToken(ctx, conn, tokenData)
+
 		return &pb.ApiTokenValidity{
 			Result: &pb.ApiTokenValidity_Invalid_{
 				Invalid: &pb.ApiTokenValidity_Invalid{},
@@ -117,6 +338,11 @@ func (s *AccountsService) ValidateApiToken(ctx context.Context, req *pb.ApiToken
 		}, nil
 	}
 
+	err = conn.ApiTokens().Touch(tokenData.TokenHash)
+	if err != nil {
+		log.Printf("warning: cannot update api token last usage time: %v", err)
+	}
+
 	return &pb.ApiTokenValidity{
 		Result: &pb.ApiTokenValidity_Valid_{
 			Valid: &pb.ApiTokenValidity_Valid{
@@ -124,21 +350,336 @@ func (s *AccountsService) ValidateApiToken(ctx context.Context, req *pb.ApiToken
 				WriteAccess: tokenData.WriteAccess,
 				ValidUntil:  timestamppb.New(tokenData.ValidUntil),
 				CreatedAt:   timestamppb.New(tokenData.CreatedAt),
+				Scopes:      soatoken.ScopesToStrings(tokenData.Scopes),
 			},
 		},
 	}, nil
 }
 
-func (s *AccountsService) checkPassword(conn repo.Connection, authData *pb.AuthByPassword) (models.AccountParams, error) {
+// Replayed token is recorded once per period, so that it cannot flood the
+// audit log
+func (s *AccountsService) recordRejectedApiToken(ctx context.Context, conn repo.Connection, tokenData models.ApiTokenData) {
+	registered, err := conn.ApiTokens().RegisterRejection(tokenData.TokenHash, API_TOKEN_REJECTION_AUDIT_PERIOD)
+	if err != nil {
+		log.Printf("warning: cannot register rejected api token: %v", err)
+		return
+	}
+
+	if !registered {
+		return
+	}
+
+	err = recordAuditEvent(ctx, conn, models.AccountId(tokenData.AccountId), audit.EVENT_API_TOKEN_REJECTED, audit.IDENTIFIER_API_TOKEN)
+	if err != nil {
+		log.Printf("warning: cannot record rejected api token: %v", err)
+	}
+}
+
+func (s *AccountsService) ListApiTokens(ctx context.Context, req *pb.Empty) (*pb.ListApiTokensResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tokens, err := conn.ApiTokens().ListByAccountId(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListApiTokensResponse{
+		Tokens: make([]*pb.ApiTokenInfo, 0, len(tokens)),
+	}
+	for _, token := range tokens {
+		var lastUsedAt *timestamppb.Timestamp
+		if token.LastUsedAt.HasValue {
+			lastUsedAt = timestamppb.New(token.LastUsedAt.Value)
+		}
+
+		resp.Tokens = append(resp.Tokens, &pb.ApiTokenInfo{
+			TokenId:     int32(token.Id),
+			Name:        token.Name,
+			ReadAccess:  token.ReadAccess,
+			WriteAccess: token.WriteAccess,
+			Revoked:     token.IsRevoked,
+			CreatedAt:   timestamppb.New(token.CreatedAt),
+			ValidUntil:  timestamppb.New(token.ValidUntil),
+			LastUsedAt:  lastUsedAt,
+			Scopes:      soatoken.ScopesToStrings(token.Scopes),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *AccountsService) RevokeApiToken(ctx context.Context, req *pb.RevokeApiTokenRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = tx.ApiTokens().Revoke(models.AccountId(authInfo.AccountId), models.ApiTokenId(req.TokenId))
+	if err != nil {
+		return nil, err
+	}
+
+	err = publishApiTokensRevoked(tx, models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, models.AccountId(authInfo.AccountId), audit.EVENT_API_TOKEN_REVOKED, callerIdentifierType(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateSoaTokens(models.AccountId(authInfo.AccountId))
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) RevokeAllApiTokens(ctx context.Context, req *pb.Empty) (*pb.RevokeAllApiTokensResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	revokedCount, err := tx.ApiTokens().RevokeAll(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	err = publishApiTokensRevoked(tx, models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedCount > 0 {
+		err = recordAuditEvent(ctx, tx, models.AccountId(authInfo.AccountId), audit.EVENT_API_TOKEN_REVOKED, callerIdentifierType(ctx))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateSoaTokens(models.AccountId(authInfo.AccountId))
+
+	return &pb.RevokeAllApiTokensResponse{
+		RevokedCount: int32(revokedCount),
+	}, nil
+}
+
+// Lockouts of login, email and phone number of the caller, client address
+// lockouts are not shown as they are not bound to account
+func (s *AccountsService) ListAuthLockouts(ctx context.Context, req *pb.Empty) (*pb.ListAuthLockoutsResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	keys, err := accountAuthFailureKeys(conn, models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	failures, err := conn.AuthFailures().Get(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	lockouts := make([]*pb.AuthLockout, 0, len(failures))
+	for _, failure := range failures {
+		lockout := &pb.AuthLockout{
+			Key:          string(failure.Key),
+			FailedCount:  int32(failure.FailedCount),
+			LastFailedAt: timestamppb.New(failure.LastFailedAt),
+		}
+		if failure.LockedUntil.HasValue && failure.LockedUntil.Value.After(time.Now()) {
+			lockout.LockedUntil = timestamppb.New(failure.LockedUntil.Value)
+		}
+
+		lockouts = append(lockouts, lockout)
+	}
+
+	return &pb.ListAuthLockoutsResponse{
+		Lockouts: lockouts,
+	}, nil
+}
+
+func (s *AccountsService) ClearAuthLockouts(ctx context.Context, req *pb.Empty) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = clearAccountLockouts(conn, models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Current key goes first, then the previous ones
+func (s *AccountsService) GetJwks(ctx context.Context, req *pb.Empty) (*pb.GetJwksResponse, error) {
+	keys := make([]*pb.Jwk, 0, len(s.jwks))
+	for _, jwk := range s.jwks {
+		keys = append(keys, &pb.Jwk{
+			Kty: jwk.Kty,
+			Crv: jwk.Crv,
+			Kid: jwk.Kid,
+			X:   jwk.X,
+			Use: jwk.Use,
+			Alg: jwk.Alg,
+		})
+	}
+
+	return &pb.GetJwksResponse{
+		Keys: keys,
+	}, nil
+}
+
+// Unknown user and wrong password are indistinguishable for the caller, both
+// count as failed attempt for user id and client address. Attempts of the
+// same user id or client are serialized until the end of transaction
+func (s *AccountsService) checkPassword(ctx context.Context, tx repo.Transaction, authData *pb.AuthByPassword) (models.AccountParams, error) {
+	targets := authFailureTargets(ctx, authData)
+	err := checkLockouts(tx, targets)
+	if err != nil {
+		return models.AccountParams{}, err
+	}
+
+	credentials, err := fetchCredentials(tx, authData)
+	if err != nil {
+		if !errors.As(err, &pgErrs.AccountNotFound{}) {
+			return models.AccountParams{}, err
+		}
+
+		// verify against dummy hash so that response time does not reveal
+		// whether account exists
+		s.passwordHasher.Verify(authData.Password, s.dummyPasswordHash)
+		return models.AccountParams{}, s.failAuthentication(tx, targets)
+	}
+
+	match, needsRehash, err := s.passwordHasher.Verify(authData.Password, string(credentials.PasswordHash))
+	if err != nil {
+		return models.AccountParams{}, err
+	}
+
+	if !match {
+		err = recordAuditEvent(ctx, tx, credentials.Id, audit.EVENT_LOGIN_FAILED, passwordIdentifierType(authData))
+		if err != nil {
+			return models.AccountParams{}, err
+		}
+
+		return models.AccountParams{}, s.failAuthentication(tx, targets)
+	}
+
+	// rejected like wrong password so that it is not revealed the password is right
+	allowed, err := s.isUserIdAllowed(tx, credentials.Id, authData)
+	if err != nil {
+		return models.AccountParams{}, err
+	}
+
+	if !allowed {
+		return models.AccountParams{}, s.failAuthentication(tx, targets)
+	}
+
+	// checked only after password so that suspension is not revealed to guessers
+	_, err = checkNotSuspended(tx, credentials.Id)
+	if err != nil {
+		return models.AccountParams{}, err
+	}
+
+	// client counter is not reset, otherwise attacker could interleave
+	// guesses with logins to own account
+	err = tx.AuthFailures().Clear([]models.AuthFailureKey{targets[0].key})
+	if err != nil {
+		return models.AccountParams{}, err
+	}
+
+	if needsRehash {
+		s.rehashPassword(ctx, credentials.Id, authData.Password)
+	}
+
+	return models.AccountParams{Id: credentials.Id}, nil
+}
+
+func (s *AccountsService) failAuthentication(tx repo.Transaction, targets []authFailureTarget) error {
+	err := registerAuthFailure(tx, targets)
+	if err != nil {
+		return err
+	}
+
+	return errs.InvalidCredentials{}
+}
+
+// Failed rehash must not break authentication, it will be retried on next
+// login. Stored outside of authentication transaction, so that its failure
+// does not abort it
+func (s *AccountsService) rehashPassword(ctx context.Context, id models.AccountId, password string) {
+	newHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		log.Printf("warning: cannot rehash password of account %d: %v", id, err)
+		return
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		log.Printf("warning: cannot store rehashed password of account %d: %v", id, err)
+		return
+	}
+	defer conn.Close()
+
+	err = conn.Accounts().UpdatePasswordHash(id, models.PasswordHash(newHash))
+	if err != nil {
+		log.Printf("warning: cannot store rehashed password of account %d: %v", id, err)
+	}
+}
+
+// Failed attempts must be stored even though request fails
+func commitOnFailedPassword(tx repo.Transaction, err error) error {
+	if errors.As(err, &errs.InvalidCredentials{}) || errors.As(err, &errs.PasswordsDoNotMatch{}) {
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			return commitErr
+		}
+	}
+
+	return err
+}
+
+func fetchCredentials(conn repo.Connection, authData *pb.AuthByPassword) (models.AccountCredentials, error) {
 	switch userId := authData.UserId.(type) {
 	case *pb.AuthByPassword_Login:
-		return conn.Accounts().CheckPasswordByLogin(userId.Login, authData.Password)
+		return conn.Accounts().GetCredentialsByLogin(userId.Login)
 
 	case *pb.AuthByPassword_Email:
-		return conn.Accounts().CheckPasswordByEmail(userId.Email, authData.Password)
+		return conn.Accounts().GetCredentialsByEmail(userId.Email)
 
 	case *pb.AuthByPassword_PhoneNumber:
-		return conn.Accounts().CheckPasswordByPhoneNumber(userId.PhoneNumber, authData.Password)
+		return conn.Accounts().GetCredentialsByPhoneNumber(userId.PhoneNumber)
 
 	default:
 		panic("unknown user id")
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	pb "soa-socialnetwork/services/accounts/proto"
+	statsModels "soa-socialnetwork/services/stats/pkg/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AccountsService) Block(ctx context.Context, req *pb.BlockRequest) (*pb.Empty, error) {
+	return s.changeBlock(ctx, req.ProfileId, "block", repo.BlocksRepo.Block)
+}
+
+func (s *AccountsService) Unblock(ctx context.Context, req *pb.BlockRequest) (*pb.Empty, error) {
+	return s.changeBlock(ctx, req.ProfileId, "unblock", repo.BlocksRepo.Unblock)
+}
+
+// Applies change to the block of profile by caller and emits event
+// of eventType if the block has been actually changed. Posts service
+// keeps its copy of block lists up to date by these events.
+func (s *AccountsService) changeBlock(
+	ctx context.Context,
+	profileId string,
+	eventType string,
+	change func(repo.BlocksRepo, models.AccountId, models.AccountId) (bool, error),
+) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.ProfileId == profileId {
+		return nil, status.Error(codes.InvalidArgument, "cannot block yourself")
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	blocked, err := tx.Profiles().ResolveProfileId(models.ProfileId(profileId))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	blocker := models.AccountId(authInfo.AccountId)
+	changed, err := change(tx.Blocks(), blocker, blocked)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if changed {
+		payload, err := json.Marshal(statsModels.BlockEvent{
+			BlockerAccountId: statsModels.AccountId(blocker),
+			BlockedAccountId: statsModels.AccountId(blocked),
+			Timestamp:        time.Now(),
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = tx.Outbox().Put(models.OutboxEvent{
+			Type:      eventType,
+			Payload:   payload,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) ListBlocked(ctx context.Context, req *pb.ListBlockedRequest) (*pb.ListBlockedResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	page, err := conn.Blocks().ListBlocked(models.AccountId(authInfo.AccountId), repo.PagiToken(req.PageToken))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListBlockedResponse{
+		Profiles:      profileCardsToProto(page.Profiles),
+		NextPageToken: string(page.NextPagiToken),
+	}, nil
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/notify"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const VERIFICATION_CODE_TTL = time.Hour
+const VERIFICATION_MAX_ATTEMPTS = 5
+
+// Every code allows VERIFICATION_MAX_ATTEMPTS guesses, so the number of
+// issued codes is limited to bound guesses across them and messages sent
+const VERIFICATION_MAX_CODES = 5
+const VERIFICATION_CODES_PERIOD = 24 * time.Hour
+const VERIFICATION_CODE_COOLDOWN = time.Minute
+
+func (s *AccountsService) GetContacts(ctx context.Context, req *pb.Empty) (*pb.GetContactsResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	contacts, err := conn.Accounts().GetContacts(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetContactsResponse{
+		Email:               contacts.Email,
+		EmailVerified:       contacts.EmailVerified,
+		PhoneNumber:         contacts.PhoneNumber,
+		PhoneNumberVerified: contacts.PhoneNumberVerified,
+	}, nil
+}
+
+// New contact value is unverified, verification code is sent to it at once.
+// Contacts receive password reset codes once verified, so changing them
+// requires a session and the current password, not only a token
+func (s *AccountsService) ChangeContact(ctx context.Context, req *pb.ChangeContactRequest) (*pb.Empty, error) {
+	kind, err := contactKindFromProto(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Value == "" {
+		return nil, status.Error(codes.InvalidArgument, "contact value is empty")
+	}
+
+	authInfo := getAuthInfo(ctx)
+	if authInfo.SessionId == "" {
+		return nil, errs.AccessDenied{}
+	}
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = s.checkCurrentPassword(ctx, tx, accountId, req.Password)
+	if err != nil {
+		return nil, commitOnFailedPassword(tx, err)
+	}
+
+	err = tx.Accounts().UpdateContact(accountId, kind, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := issueVerificationCode(tx, accountId, kind, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	s.sendVerificationCode(kind, req.Value, code)
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) SendVerificationCode(ctx context.Context, req *pb.SendVerificationCodeRequest) (*pb.Empty, error) {
+	kind, err := contactKindFromProto(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	contacts, err := tx.Accounts().GetContacts(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	address, verified := contactOfKind(contacts, kind)
+	if address == "" {
+		return nil, status.Error(codes.FailedPrecondition, "contact is not set")
+	}
+
+	if verified {
+		return &pb.Empty{}, nil
+	}
+
+	// the previous code is still valid, so resending it often only floods the address
+	recent, err := tx.VerificationCodes().CountIssuedWithin(accountId, kind, VERIFICATION_CODE_COOLDOWN)
+	if err != nil {
+		return nil, err
+	}
+
+	if recent > 0 {
+		return nil, errs.TooManyVerificationCodes{}
+	}
+
+	code, err := issueVerificationCode(tx, accountId, kind, address)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	s.sendVerificationCode(kind, address, code)
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) ConfirmContact(ctx context.Context, req *pb.ConfirmContactRequest) (*pb.Empty, error) {
+	kind, err := contactKindFromProto(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	codeData, err := tx.VerificationCodes().GetActive(accountId, kind)
+	if err != nil {
+		if errors.As(err, &pgErrs.VerificationCodeNotFound{}) {
+			return nil, errs.InvalidVerificationCode{}
+		}
+
+		return nil, err
+	}
+
+	if codeData.Attempts >= VERIFICATION_MAX_ATTEMPTS {
+		return nil, errs.InvalidVerificationCode{}
+	}
+
+	if !oneTimeCodeMatches(req.Code, string(codeData.CodeHash)) {
+		err = tx.VerificationCodes().IncrementAttempts(codeData.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, errs.InvalidVerificationCode{}
+	}
+
+	err = tx.VerificationCodes().MarkUsed(codeData.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// contact could have been changed after the code was sent
+	err = tx.Accounts().MarkContactVerified(accountId, kind, codeData.Address)
+	if err != nil {
+		if errors.As(err, &pgErrs.ContactNotFound{}) {
+			return nil, errs.InvalidVerificationCode{}
+		}
+
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Only the latest issued code of contact kind is valid. Returns
+// errs.TooManyVerificationCodes if daily limit of the contact kind is reached
+func issueVerificationCode(tx repo.Transaction, accountId models.AccountId, kind models.ContactKind, address string) (string, error) {
+	issued, err := tx.VerificationCodes().CountIssuedWithin(accountId, kind, VERIFICATION_CODES_PERIOD)
+	if err != nil {
+		return "", err
+	}
+
+	if issued >= VERIFICATION_MAX_CODES {
+		return "", errs.TooManyVerificationCodes{}
+	}
+
+	code, err := newOneTimeCode()
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.VerificationCodes().InvalidateAll(accountId, kind)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.VerificationCodes().Put(accountId, kind, address, models.VerificationCodeHash(hashOneTimeCode(code)), VERIFICATION_CODE_TTL)
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Failure is only logged, as the change is already committed and a new code
+// can be requested after the cooldown
+func (s *AccountsService) sendVerificationCode(kind models.ContactKind, address string, code string) {
+	channel := notify.CHANNEL_EMAIL
+	if kind == models.CONTACT_PHONE_NUMBER {
+		channel = notify.CHANNEL_SMS
+	}
+
+	err := s.notifier.Send(notify.Recipient{
+		Channel: channel,
+		Address: address,
+	}, notify.Message{
+		Subject: "Verification code",
+		Text:    fmt.Sprintf("Your verification code is %s. It is valid for %s.", code, VERIFICATION_CODE_TTL),
+		Code:    code,
+	})
+	if err != nil {
+		log.Printf("cannot send verification code via %s: %v", channel, err)
+	}
+}
+
+// Unverified contacts cannot be used as user id if policy requires so
+func (s *AccountsService) isUserIdAllowed(conn repo.Connection, accountId models.AccountId, authData *pb.AuthByPassword) (bool, error) {
+	if !s.requireVerifiedContacts {
+		return true, nil
+	}
+
+	var kind models.ContactKind
+	switch authData.UserId.(type) {
+	case *pb.AuthByPassword_Email:
+		kind = models.CONTACT_EMAIL
+
+	case *pb.AuthByPassword_PhoneNumber:
+		kind = models.CONTACT_PHONE_NUMBER
+
+	default:
+		return true, nil
+	}
+
+	contacts, err := conn.Accounts().GetContacts(accountId)
+	if err != nil {
+		return false, err
+	}
+
+	_, verified := contactOfKind(contacts, kind)
+	return verified, nil
+}
+
+func contactOfKind(contacts models.AccountContacts, kind models.ContactKind) (address string, verified bool) {
+	if kind == models.CONTACT_EMAIL {
+		return contacts.Email, contacts.EmailVerified
+	}
+
+	return contacts.PhoneNumber, contacts.PhoneNumberVerified
+}
+
+func contactKindFromProto(kind pb.ContactKind) (models.ContactKind, error) {
+	switch kind {
+	case pb.ContactKind_CONTACT_KIND_EMAIL:
+		return models.CONTACT_EMAIL, nil
+
+	case pb.ContactKind_CONTACT_KIND_PHONE_NUMBER:
+		return models.CONTACT_PHONE_NUMBER, nil
+	}
+
+	return "", status.Error(codes.InvalidArgument, "unknown contact kind")
+}
@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"soa-socialnetwork/services/accounts/internal/models"
 	"soa-socialnetwork/services/accounts/internal/repo"
 	"soa-socialnetwork/services/accounts/internal/service/errs"
 	"soa-socialnetwork/services/accounts/internal/service/interceptors"
+	"soa-socialnetwork/services/accounts/pkg/audit"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
 	pb "soa-socialnetwork/services/accounts/proto"
 	"soa-socialnetwork/services/common/option"
 	statsModels "soa-socialnetwork/services/stats/pkg/models"
@@ -19,6 +23,11 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const SEARCH_QUERY_MAX_LENGTH = 64
+
+// Limit of ids in requests of batch methods
+const BATCH_MAX_SIZE = 100
+
 func getAuthInfo(ctx context.Context) interceptors.AuthInfo {
 	authInfo := ctx.Value(interceptors.AuthInfoKey).(interceptors.AuthInfo)
 	return authInfo
@@ -31,36 +40,64 @@ func (s *AccountsService) RegisterUser(ctx context.Context, req *pb.RegisterUser
 	}
 	defer tx.Close()
 
-	profileId := uuid.New().String()
-	registrationData := models.RegistrationData{
-		Login:       req.Login,
-		Password:    req.Password,
-		Email:       req.Email,
-		PhoneNumber: req.PhoneNumber,
-		Name:        req.Name,
-		Surname:     req.Surname,
+	passwordHash, err := s.passwordHasher.Hash(req.Password)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
-	accountId, err := tx.Accounts().New(registrationData)
+	_, profileId, err := s.createAccount(tx, models.RegistrationData{
+		Login:        req.Login,
+		PasswordHash: models.PasswordHash(passwordHash),
+		Email:        req.Email,
+		PhoneNumber:  req.PhoneNumber,
+		Name:         req.Name,
+		Surname:      req.Surname,
+	})
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	err = tx.Profiles().New(models.ProfileId(profileId), accountId, registrationData)
+	err = tx.Commit()
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
+	return &pb.RegisterUserResponse{
+		ProfileId: string(profileId),
+	}, nil
+}
+
+// Creates account with its profile and emits registration event, used by
+// registration and provisioning of external identities
+func (s *AccountsService) createAccount(tx repo.Transaction, registrationData models.RegistrationData) (models.AccountId, models.ProfileId, error) {
+	profileId := uuid.New().String()
+
+	accountId, err := tx.Accounts().New(registrationData)
+	if err != nil {
+		return 0, "", err
+	}
+
+	err = tx.Profiles().New(models.ProfileId(profileId), accountId, registrationData)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if s.isAdminLogin(registrationData.Login) {
+		err = tx.Accounts().SetRole(accountId, soajwt.ROLE_ADMIN)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
 	payload, err := json.Marshal(statsModels.RegistrationEvent{
 		AccountId: statsModels.AccountId(accountId),
 		ProfileId: profileId,
 		Timestamp: time.Now(),
 	})
 	if err != nil {
-		tx.Rollback()
-		return nil, err
+		return 0, "", err
 	}
 
 	err = tx.Outbox().Put(models.OutboxEvent{
@@ -69,18 +106,10 @@ func (s *AccountsService) RegisterUser(ctx context.Context, req *pb.RegisterUser
 		CreatedAt: time.Now(),
 	})
 	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return nil, err
+		return 0, "", err
 	}
 
-	return &pb.RegisterUserResponse{
-		ProfileId: profileId,
-	}, nil
+	return accountId, models.ProfileId(profileId), nil
 }
 
 func (s *AccountsService) UnregisterUser(ctx context.Context, req *pb.UnregisterUserRequest) (*pb.Empty, error) {
@@ -90,18 +119,19 @@ func (s *AccountsService) UnregisterUser(ctx context.Context, req *pb.Unregister
 	}
 
 	profileId := models.ProfileId(req.ProfileId)
-	accountId, err := func() (models.AccountId, error) {
+	profile, err := func() (models.ProfileData, error) {
 		conn, err := s.Db.OpenConnection(ctx)
 		if err != nil {
-			return -1, err
+			return models.ProfileData{}, err
 		}
 		defer conn.Close()
 
-		return conn.Profiles().ResolveProfileId(profileId)
+		return conn.Profiles().GetByProfileId(profileId)
 	}()
 	if err != nil {
 		return nil, err
 	}
+	accountId := profile.AccountId
 
 	tx, err := s.Db.BeginTransaction(ctx)
 	if err != nil {
@@ -121,10 +151,81 @@ func (s *AccountsService) UnregisterUser(ctx context.Context, req *pb.Unregister
 		return nil, err
 	}
 
+	err = tx.Follows().DeleteAll(accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Blocks().DeleteAll(accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.OidcIdentities().DeleteAll(accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	exportIds, err := tx.DataExports().DeleteAll(accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	sessionIds, err := revokeAllSessions(tx, accountId, nil)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// audit log outlives the account, so that its compromise can be
+	// investigated after deletion
+	err = recordAuditEvent(ctx, tx, accountId, audit.EVENT_ACCOUNT_DELETED, callerIdentifierType(ctx))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// posts and stats services erase data of the account on this event
+	payload, err := json.Marshal(statsModels.UnregistrationEvent{
+		AccountId: statsModels.AccountId(accountId),
+		ProfileId: req.ProfileId,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Outbox().Put(models.OutboxEvent{
+		Type:      "unregistration",
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = publishApiTokensRevoked(tx, accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
 	}
+	s.invalidateSoaTokens(accountId)
+	s.rejectSessionJwts(sessionIds)
+
+	s.deleteProfileImageBlobs(ctx, models.PROFILE_IMAGE_AVATAR, profile.AvatarImage)
+	s.deleteProfileImageBlobs(ctx, models.PROFILE_IMAGE_COVER, profile.CoverImage)
+	s.deleteDataExportArchives(ctx, exportIds)
 
 	return &pb.Empty{}, nil
 }
@@ -143,12 +244,127 @@ func (s *AccountsService) GetProfile(ctx context.Context, req *pb.GetProfileRequ
 		return nil, err
 	}
 
+	followCounts, err := conn.Follows().Counts(data.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := getViewer(ctx)
+	profile := s.profileToProto(data, followCounts)
+	hidePrivateFields(profile, data, viewer)
+
+	showEmail := viewer.canSee(data.ProfileId, data.Privacy.Email)
+	showPhoneNumber := viewer.canSee(data.ProfileId, data.Privacy.PhoneNumber)
+	if showEmail || showPhoneNumber {
+		contacts, err := conn.Accounts().GetContacts(data.AccountId)
+		if err != nil {
+			return nil, err
+		}
+
+		if showEmail {
+			profile.Email = contacts.Email
+		}
+		if showPhoneNumber {
+			profile.PhoneNumber = contacts.PhoneNumber
+		}
+	}
+
+	return profile, nil
+}
+
+func (s *AccountsService) GetProfiles(ctx context.Context, req *pb.GetProfilesRequest) (*pb.GetProfilesResponse, error) {
+	if len(req.ProfileIds) > BATCH_MAX_SIZE {
+		return nil, status.Errorf(codes.InvalidArgument, "more than %d profile ids", BATCH_MAX_SIZE)
+	}
+
+	profileIds := validProfileIds(req.ProfileIds)
+	if len(profileIds) == 0 {
+		return &pb.GetProfilesResponse{}, nil
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	profiles, err := conn.Profiles().GetManyByProfileIds(profileIds)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIds := make([]models.AccountId, len(profiles))
+	for i, p := range profiles {
+		accountIds[i] = p.AccountId
+	}
+
+	followCounts, err := conn.Follows().CountsMany(accountIds)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer := getViewer(ctx)
+	pbProfiles := make([]*pb.Profile, len(profiles))
+	for i, p := range profiles {
+		pbProfiles[i] = s.profileToProto(p, followCounts[p.AccountId])
+		hidePrivateFields(pbProfiles[i], p, viewer)
+	}
+
+	return &pb.GetProfilesResponse{
+		Profiles: pbProfiles,
+	}, nil
+}
+
+func (s *AccountsService) profileToProto(data models.ProfileData, followCounts models.FollowCounts) *pb.Profile {
 	return &pb.Profile{
-		Name:      data.Name,
-		Surname:   data.Surname,
-		ProfileId: req.ProfileId,
-		Birthday:  timestamppb.New(data.Birthday),
-		Bio:       data.Bio,
+		Name:           data.Name,
+		Surname:        data.Surname,
+		ProfileId:      string(data.ProfileId),
+		Birthday:       timestamppb.New(data.Birthday),
+		Bio:            data.Bio,
+		FollowersCount: int32(followCounts.Followers),
+		FollowingCount: int32(followCounts.Following),
+		Avatar:         s.profileImageToProto(models.PROFILE_IMAGE_AVATAR, data.AvatarImage),
+		Cover:          s.profileImageToProto(models.PROFILE_IMAGE_COVER, data.CoverImage),
+	}
+}
+
+// Ids which are not uuids cannot belong to any profile, so they are
+// dropped instead of failing the whole batch
+func validProfileIds(ids []string) []models.ProfileId {
+	valid := make([]models.ProfileId, 0, len(ids))
+	for _, id := range ids {
+		if uuid.Validate(id) == nil {
+			valid = append(valid, models.ProfileId(id))
+		}
+	}
+	return valid
+}
+
+func (s *AccountsService) SearchProfiles(ctx context.Context, req *pb.SearchProfilesRequest) (*pb.SearchProfilesResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty search query")
+	}
+
+	if utf8.RuneCountInString(query) > SEARCH_QUERY_MAX_LENGTH {
+		return nil, status.Errorf(codes.InvalidArgument, "search query is longer than %d characters", SEARCH_QUERY_MAX_LENGTH)
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := conn.Profiles().Search(query, repo.PagiToken(req.PageToken))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SearchProfilesResponse{
+		Profiles:      profileCardsToProto(result.Profiles),
+		NextPageToken: string(result.NextPagiToken),
 	}, nil
 }
 
@@ -197,6 +413,11 @@ func (s *AccountsService) EditProfile(ctx context.Context, req *pb.EditProfileRe
 		return nil, err
 	}
 
+	err = recordAuditEvent(ctx, conn, models.AccountId(authInfo.AccountId), audit.EVENT_PROFILE_EDITED, callerIdentifierType(ctx))
+	if err != nil {
+		return nil, err
+	}
+
 	return &pb.Empty{}, nil
 }
 
@@ -236,3 +457,69 @@ func (s *AccountsService) ResolveAccountId(ctx context.Context, req *pb.ResolveA
 		ProfileId: string(profileId),
 	}, nil
 }
+
+func (s *AccountsService) ResolveProfileIds(ctx context.Context, req *pb.ResolveProfileIdsRequest) (*pb.ResolveProfileIdsResponse, error) {
+	if len(req.ProfileIds) > BATCH_MAX_SIZE {
+		return nil, status.Errorf(codes.InvalidArgument, "more than %d profile ids", BATCH_MAX_SIZE)
+	}
+
+	profileIds := validProfileIds(req.ProfileIds)
+	if len(profileIds) == 0 {
+		return &pb.ResolveProfileIdsResponse{}, nil
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resolved, err := conn.Profiles().ResolveProfileIds(profileIds)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIds := make(map[string]int32, len(resolved))
+	for profileId, accountId := range resolved {
+		accountIds[string(profileId)] = int32(accountId)
+	}
+
+	return &pb.ResolveProfileIdsResponse{
+		AccountIds: accountIds,
+	}, nil
+}
+
+func (s *AccountsService) ResolveAccountIds(ctx context.Context, req *pb.ResolveAccountIdsRequest) (*pb.ResolveAccountIdsResponse, error) {
+	if len(req.AccountIds) > BATCH_MAX_SIZE {
+		return nil, status.Errorf(codes.InvalidArgument, "more than %d account ids", BATCH_MAX_SIZE)
+	}
+
+	if len(req.AccountIds) == 0 {
+		return &pb.ResolveAccountIdsResponse{}, nil
+	}
+
+	accountIds := make([]models.AccountId, len(req.AccountIds))
+	for i, id := range req.AccountIds {
+		accountIds[i] = models.AccountId(id)
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resolved, err := conn.Profiles().ResolveAccountIds(accountIds)
+	if err != nil {
+		return nil, err
+	}
+
+	profileIds := make(map[int32]string, len(resolved))
+	for accountId, profileId := range resolved {
+		profileIds[int32(accountId)] = string(profileId)
+	}
+
+	return &pb.ResolveAccountIdsResponse{
+		ProfileIds: profileIds,
+	}, nil
+}
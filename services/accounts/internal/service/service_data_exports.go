@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Building an export queries all services, so their number is limited
+const DATA_EXPORTS_MAX_COUNT = 3
+const DATA_EXPORTS_COUNT_PERIOD = 24 * time.Hour
+
+// Archives are downloadable for this time after the export is finished
+const DATA_EXPORT_RETENTION = 7 * 24 * time.Hour
+
+// Archives are streamed in chunks to stay far below grpc message size limit
+const DATA_EXPORT_CHUNK_SIZE = 256 << 10
+
+func (s *AccountsService) RequestDataExport(ctx context.Context, req *pb.Empty) (*pb.DataExport, error) {
+	authInfo := getAuthInfo(ctx)
+
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	count, err := tx.DataExports().CountCreatedWithin(accountId, DATA_EXPORTS_COUNT_PERIOD)
+	if err != nil {
+		return nil, err
+	}
+
+	// repeated requests get the pending export even if the limit is reached
+	export, err := tx.DataExports().GetPending(accountId)
+	if err == nil {
+		return dataExportToProto(export), nil
+	}
+	if !errors.As(err, &pgErrs.DataExportNotFound{}) {
+		return nil, err
+	}
+
+	if count >= DATA_EXPORTS_MAX_COUNT {
+		return nil, errs.TooManyDataExports{}
+	}
+
+	export, err = tx.DataExports().New(models.DataExportId(uuid.NewString()), accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return dataExportToProto(export), nil
+}
+
+func (s *AccountsService) GetDataExport(ctx context.Context, req *pb.GetDataExportRequest) (*pb.DataExport, error) {
+	export, err := s.getOwnDataExport(ctx, req.ExportId)
+	if err != nil {
+		return nil, err
+	}
+
+	return dataExportToProto(export), nil
+}
+
+func (s *AccountsService) DownloadDataExport(req *pb.GetDataExportRequest, stream pb.AccountsService_DownloadDataExportServer) error {
+	ctx := stream.Context()
+
+	export, err := s.getOwnDataExport(ctx, req.ExportId)
+	if err != nil {
+		return err
+	}
+
+	if export.Status != models.DATA_EXPORT_READY {
+		return status.Errorf(codes.FailedPrecondition, "data export is %s", export.Status)
+	}
+
+	archive, err := s.exportStore.Open(ctx, dataExportKey(export.Id))
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	chunk := make([]byte, DATA_EXPORT_CHUNK_SIZE)
+	for {
+		n, err := io.ReadFull(archive, chunk)
+		if n > 0 {
+			sendErr := stream.Send(&pb.DataExportArchive{
+				Data: chunk[:n],
+			})
+			if sendErr != nil {
+				return sendErr
+			}
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Exports of other accounts are reported as missing
+func (s *AccountsService) getOwnDataExport(ctx context.Context, exportId string) (models.DataExport, error) {
+	authInfo := getAuthInfo(ctx)
+
+	if uuid.Validate(exportId) != nil {
+		return models.DataExport{}, pgErrs.DataExportNotFound{}
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return models.DataExport{}, err
+	}
+	defer conn.Close()
+
+	export, err := conn.DataExports().Get(models.DataExportId(exportId))
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	if export.AccountId != models.AccountId(authInfo.AccountId) {
+		return models.DataExport{}, pgErrs.DataExportNotFound{}
+	}
+
+	return export, nil
+}
+
+// Archives are deleted on a best effort basis like profile images
+func (s *AccountsService) deleteDataExportArchives(ctx context.Context, ids []models.DataExportId) {
+	for _, id := range ids {
+		err := s.exportStore.Delete(ctx, dataExportKey(id))
+		if err != nil {
+			log.Printf("warning: cannot delete data export %s: %v", id, err)
+		}
+	}
+}
+
+func dataExportKey(id models.DataExportId) string {
+	return string(id) + ".zip"
+}
+
+func dataExportToProto(export models.DataExport) *pb.DataExport {
+	result := &pb.DataExport{
+		ExportId:  string(export.Id),
+		Status:    dataExportStatusToProto(export.Status),
+		CreatedAt: timestamppb.New(export.CreatedAt),
+	}
+	if !export.FinishedAt.IsZero() {
+		result.FinishedAt = timestamppb.New(export.FinishedAt)
+	}
+
+	return result
+}
+
+func dataExportStatusToProto(status models.DataExportStatus) pb.DataExportStatus {
+	switch status {
+	case models.DATA_EXPORT_PENDING:
+		return pb.DataExportStatus_DATA_EXPORT_STATUS_PENDING
+
+	case models.DATA_EXPORT_READY:
+		return pb.DataExportStatus_DATA_EXPORT_STATUS_READY
+
+	case models.DATA_EXPORT_FAILED:
+		return pb.DataExportStatus_DATA_EXPORT_STATUS_FAILED
+	}
+
+	return pb.DataExportStatus_DATA_EXPORT_STATUS_UNSPECIFIED
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	pb "soa-socialnetwork/services/accounts/proto"
+	statsModels "soa-socialnetwork/services/stats/pkg/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *AccountsService) Follow(ctx context.Context, req *pb.FollowRequest) (*pb.Empty, error) {
+	return s.changeFollow(ctx, req.ProfileId, "follow", repo.FollowsRepo.Follow)
+}
+
+func (s *AccountsService) Unfollow(ctx context.Context, req *pb.FollowRequest) (*pb.Empty, error) {
+	return s.changeFollow(ctx, req.ProfileId, "unfollow", repo.FollowsRepo.Unfollow)
+}
+
+// Applies change to the follow of caller to profile and emits event
+// of eventType if the follow has been actually changed.
+func (s *AccountsService) changeFollow(
+	ctx context.Context,
+	profileId string,
+	eventType string,
+	change func(repo.FollowsRepo, models.AccountId, models.AccountId) (bool, error),
+) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.ProfileId == profileId {
+		return nil, status.Error(codes.InvalidArgument, "cannot follow yourself")
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	followee, err := tx.Profiles().ResolveProfileId(models.ProfileId(profileId))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	follower := models.AccountId(authInfo.AccountId)
+	changed, err := change(tx.Follows(), follower, followee)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if changed {
+		payload, err := json.Marshal(statsModels.FollowEvent{
+			FollowerAccountId: statsModels.AccountId(follower),
+			FolloweeAccountId: statsModels.AccountId(followee),
+			Timestamp:         time.Now(),
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		err = tx.Outbox().Put(models.OutboxEvent{
+			Type:      eventType,
+			Payload:   payload,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) ListFollowers(ctx context.Context, req *pb.ListFollowsRequest) (*pb.ListFollowsResponse, error) {
+	return s.listFollows(ctx, req, repo.FollowsRepo.ListFollowers)
+}
+
+func (s *AccountsService) ListFollowing(ctx context.Context, req *pb.ListFollowsRequest) (*pb.ListFollowsResponse, error) {
+	return s.listFollows(ctx, req, repo.FollowsRepo.ListFollowing)
+}
+
+func (s *AccountsService) ListFriends(ctx context.Context, req *pb.ListFollowsRequest) (*pb.ListFollowsResponse, error) {
+	return s.listFollows(ctx, req, repo.FollowsRepo.ListFriends)
+}
+
+func (s *AccountsService) listFollows(
+	ctx context.Context,
+	req *pb.ListFollowsRequest,
+	list func(repo.FollowsRepo, models.AccountId, repo.PagiToken) (repo.ProfileCardsPage, error),
+) (*pb.ListFollowsResponse, error) {
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	accountId, err := conn.Profiles().ResolveProfileId(models.ProfileId(req.ProfileId))
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := list(conn.Follows(), accountId, repo.PagiToken(req.PageToken))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListFollowsResponse{
+		Profiles:      profileCardsToProto(page.Profiles),
+		NextPageToken: string(page.NextPagiToken),
+	}, nil
+}
+
+func profileCardsToProto(cards []models.ProfileCard) []*pb.ProfileCard {
+	profiles := make([]*pb.ProfileCard, len(cards))
+	for i, card := range cards {
+		profiles[i] = &pb.ProfileCard{
+			ProfileId: string(card.ProfileId),
+			Name:      card.Name,
+			Surname:   card.Surname,
+		}
+	}
+	return profiles
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"log"
+	"mime"
+	"path"
+	"strings"
+
+	"soa-socialnetwork/services/accounts/internal/images"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Images are returned by GetImage in a single grpc message, so sizes
+// must stay below the default 4 MiB message limit of clients
+var profile_image_specs = map[models.ProfileImageKind]images.Spec{
+	models.PROFILE_IMAGE_AVATAR: {
+		MaxBytes:        2 << 20,
+		MinWidth:        64,
+		MinHeight:       64,
+		MaxWidth:        4096,
+		MaxHeight:       4096,
+		ThumbnailWidth:  128,
+		ThumbnailHeight: 128,
+	},
+	models.PROFILE_IMAGE_COVER: {
+		MaxBytes:        3 << 20,
+		MinWidth:        400,
+		MinHeight:       100,
+		MaxWidth:        6000,
+		MaxHeight:       4000,
+		ThumbnailWidth:  600,
+		ThumbnailHeight: 200,
+	},
+}
+
+// Blob key directories of images of kinds, only they are served by GetImage
+var profile_image_dirs = map[models.ProfileImageKind]string{
+	models.PROFILE_IMAGE_AVATAR: "avatars",
+	models.PROFILE_IMAGE_COVER:  "covers",
+}
+
+func (s *AccountsService) UploadProfileImage(ctx context.Context, req *pb.UploadProfileImageRequest) (*pb.ProfileImage, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.ProfileId != req.ProfileId {
+		return nil, errs.AccessDenied{}
+	}
+
+	kind, err := profileImageKindFromProto(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := images.Process(req.Data, profile_image_specs[kind])
+	if err != nil {
+		return nil, err
+	}
+
+	name := uuid.NewString() + "." + processed.Format
+	originalKey, thumbnailKey := profileImageKeys(kind, name)
+
+	err = s.blobStore.Put(ctx, originalKey, processed.Original)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.blobStore.Put(ctx, thumbnailKey, processed.Thumbnail)
+	if err != nil {
+		s.deleteProfileImageBlobs(ctx, kind, name)
+		return nil, err
+	}
+
+	previous, err := func() (string, error) {
+		conn, err := s.Db.OpenConnection(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+
+		return conn.Profiles().SetImage(models.ProfileId(req.ProfileId), kind, name)
+	}()
+	if err != nil {
+		s.deleteProfileImageBlobs(ctx, kind, name)
+		return nil, err
+	}
+
+	s.deleteProfileImageBlobs(ctx, kind, previous)
+
+	return s.profileImageToProto(kind, name), nil
+}
+
+func (s *AccountsService) DeleteProfileImage(ctx context.Context, req *pb.DeleteProfileImageRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.ProfileId != req.ProfileId {
+		return nil, errs.AccessDenied{}
+	}
+
+	kind, err := profileImageKindFromProto(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	previous, err := conn.Profiles().SetImage(models.ProfileId(req.ProfileId), kind, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteProfileImageBlobs(ctx, kind, previous)
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) GetImage(ctx context.Context, req *pb.GetImageRequest) (*pb.Image, error) {
+	if !isProfileImageKey(req.Key) {
+		return nil, status.Error(codes.NotFound, "image not found")
+	}
+
+	data, err := s.blobStore.Get(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Image{
+		Data:        data,
+		ContentType: mime.TypeByExtension(path.Ext(req.Key)),
+	}, nil
+}
+
+// Returns nil for empty name
+func (s *AccountsService) profileImageToProto(kind models.ProfileImageKind, name string) *pb.ProfileImage {
+	if name == "" {
+		return nil
+	}
+
+	originalKey, thumbnailKey := profileImageKeys(kind, name)
+	return &pb.ProfileImage{
+		Url:          s.imagesBaseUrl + originalKey,
+		ThumbnailUrl: s.imagesBaseUrl + thumbnailKey,
+	}
+}
+
+// Blobs of replaced and deleted images are deleted on a best effort basis,
+// failures leave garbage but do not fail the request
+func (s *AccountsService) deleteProfileImageBlobs(ctx context.Context, kind models.ProfileImageKind, name string) {
+	if name == "" {
+		return
+	}
+
+	originalKey, thumbnailKey := profileImageKeys(kind, name)
+	for _, key := range []string{originalKey, thumbnailKey} {
+		err := s.blobStore.Delete(ctx, key)
+		if err != nil {
+			log.Printf("warning: cannot delete blob %s: %v", key, err)
+		}
+	}
+}
+
+func profileImageKeys(kind models.ProfileImageKind, name string) (originalKey string, thumbnailKey string) {
+	dir := profile_image_dirs[kind]
+	return dir + "/" + name, dir + "/thumbnails/" + name
+}
+
+// Only keys made by profileImageKeys are served, other blobs and paths
+// escaping image directories are reported as missing
+func isProfileImageKey(key string) bool {
+	if path.Clean(key) != key {
+		return false
+	}
+
+	for _, dir := range profile_image_dirs {
+		name, ok := strings.CutPrefix(key, dir+"/")
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimPrefix(name, "thumbnails/")
+		return name != "" && name != "thumbnails" && !strings.Contains(name, "/")
+	}
+
+	return false
+}
+
+func profileImageKindFromProto(kind pb.ProfileImageKind) (models.ProfileImageKind, error) {
+	switch kind {
+	case pb.ProfileImageKind_PROFILE_IMAGE_KIND_AVATAR:
+		return models.PROFILE_IMAGE_AVATAR, nil
+
+	case pb.ProfileImageKind_PROFILE_IMAGE_KIND_COVER:
+		return models.PROFILE_IMAGE_COVER, nil
+	}
+
+	return 0, status.Error(codes.InvalidArgument, "unknown profile image kind")
+}
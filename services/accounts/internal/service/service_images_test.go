@@ -0,0 +1,18 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsProfileImageKey(t *testing.T) {
+	for _, key := range []string{"avatars/1.png", "avatars/thumbnails/1.png", "covers/1.jpg", "covers/thumbnails/1.jpg"} {
+		assert.True(t, isProfileImageKey(key), "key %q", key)
+	}
+
+	for _, key := range []string{"", "avatars", "avatars/", "avatars/thumbnails", "avatars/thumbnails/", "avatars/../x", "avatars/./1.png",
+		"avatars//1.png", "avatars/a/1.png", "exports/1.zip", "/avatars/1.png", "../avatars/1.png"} {
+		assert.False(t, isProfileImageKey(key), "key %q", key)
+	}
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/audit"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+	"unicode/utf8"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *AccountsService) RegisterOAuthClient(ctx context.Context, req *pb.RegisterOAuthClientRequest) (*pb.OAuthClient, error) {
+	authInfo := getAuthInfo(ctx)
+
+	nameLength := utf8.RuneCountInString(req.Name)
+	if nameLength == 0 || nameLength > MAX_OAUTH_CLIENT_NAME_LENGTH {
+		return nil, status.Errorf(codes.InvalidArgument, "client name must have 1 to %d characters", MAX_OAUTH_CLIENT_NAME_LENGTH)
+	}
+
+	if len(req.RedirectUris) == 0 || len(req.RedirectUris) > MAX_OAUTH_REDIRECT_URIS {
+		return nil, status.Errorf(codes.InvalidArgument, "client must have 1 to %d redirect uris", MAX_OAUTH_REDIRECT_URIS)
+	}
+
+	for _, uri := range req.RedirectUris {
+		err := validateRedirectUri(uri)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	clientId, err := newOAuthClientId()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	owner := models.AccountId(authInfo.AccountId)
+	clients, err := tx.OAuthClients().ListByOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(clients) >= MAX_OAUTH_CLIENTS_PER_ACCOUNT {
+		return nil, status.Errorf(codes.FailedPrecondition, "account cannot have more than %d clients", MAX_OAUTH_CLIENTS_PER_ACCOUNT)
+	}
+
+	client, err := tx.OAuthClients().Create(clientId, models.OAuthClientParams{
+		OwnerAccountId: owner,
+		Name:           req.Name,
+		RedirectUris:   req.RedirectUris,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return oauthClientToProto(client), nil
+}
+
+func (s *AccountsService) ListOAuthClients(ctx context.Context, req *pb.Empty) (*pb.ListOAuthClientsResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	clients, err := conn.OAuthClients().ListByOwner(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListOAuthClientsResponse{
+		Clients: make([]*pb.OAuthClient, 0, len(clients)),
+	}
+	for _, client := range clients {
+		resp.Clients = append(resp.Clients, oauthClientToProto(client))
+	}
+
+	return resp, nil
+}
+
+// Public information shown on consent screen
+func (s *AccountsService) GetOAuthClient(ctx context.Context, req *pb.OAuthClientRequest) (*pb.OAuthClient, error) {
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client, err := conn.OAuthClients().Get(models.OAuthClientId(req.ClientId))
+	if err != nil {
+		return nil, err
+	}
+
+	return oauthClientToProto(client), nil
+}
+
+func (s *AccountsService) DeleteOAuthClient(ctx context.Context, req *pb.OAuthClientRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	clientId := models.OAuthClientId(req.ClientId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = tx.OAuthClients().Delete(models.AccountId(authInfo.AccountId), clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIds, err := tx.ApiTokens().RevokeAllOfClient(clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, accountId := range accountIds {
+		err = publishApiTokensRevoked(tx, accountId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, accountId := range accountIds {
+		s.invalidateSoaTokens(accountId)
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Code is bound to the client, redirect uri and code challenge, so only
+// the party which started authorization can exchange it
+func (s *AccountsService) AuthorizeOAuthClient(ctx context.Context, req *pb.AuthorizeOAuthClientRequest) (*pb.AuthorizeOAuthClientResponse, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.SessionId == "" {
+		return nil, errs.AccessDenied{}
+	}
+
+	scopes, err := oauthScopes(req.Scopes)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = validateCodeChallenge(req.CodeChallenge, req.CodeChallengeMethod)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client, err := conn.OAuthClients().Get(models.OAuthClientId(req.ClientId))
+	if err != nil {
+		return nil, err
+	}
+
+	// unregistered uri would let anyone collect codes
+	if !slices.Contains(client.RedirectUris, req.RedirectUri) {
+		return nil, status.Error(codes.InvalidArgument, "redirect uri is not registered for client")
+	}
+
+	code, err := newOAuthCode()
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.OAuthCodes().Put(hashOAuthCode(code), models.OAuthCodeParams{
+		ClientId:      client.ClientId,
+		AccountId:     models.AccountId(authInfo.AccountId),
+		RedirectUri:   req.RedirectUri,
+		Scopes:        scopes,
+		CodeChallenge: req.CodeChallenge,
+	}, OAUTH_CODE_TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectTo, err := oauthRedirectTo(req.RedirectUri, code, req.State)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.AuthorizeOAuthClientResponse{
+		Code:       code,
+		RedirectTo: redirectTo,
+	}, nil
+}
+
+// Codes are single-use: a replayed code revokes tokens issued to the client
+// for the account, as the code has leaked
+func (s *AccountsService) ExchangeOAuthCode(ctx context.Context, req *pb.ExchangeOAuthCodeRequest) (*pb.ExchangeOAuthCodeResponse, error) {
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	code, err := tx.OAuthCodes().Get(hashOAuthCode(req.Code))
+	if err != nil {
+		if errors.As(err, &pgErrs.OAuthCodeNotFound{}) {
+			return nil, errs.InvalidOAuthGrant{}
+		}
+
+		return nil, err
+	}
+
+	if code.IsUsed {
+		_, err = tx.ApiTokens().RevokeByClient(code.AccountId, code.ClientId)
+		if err != nil {
+			return nil, err
+		}
+
+		err = publishApiTokensRevoked(tx, code.AccountId)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+		s.invalidateSoaTokens(code.AccountId)
+
+		log.Printf("oauth code reuse detected for account %d, tokens of client %s revoked", code.AccountId, code.ClientId)
+		return nil, errs.InvalidOAuthGrant{}
+	}
+
+	// failed attempt burns the code as well, so verifier cannot be guessed
+	err = tx.OAuthCodes().MarkUsed(code.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := time.Now().Before(code.ValidUntil) &&
+		string(code.ClientId) == req.ClientId &&
+		code.RedirectUri == req.RedirectUri &&
+		verifyCodeVerifier(req.CodeVerifier, code.CodeChallenge)
+	if !valid {
+		return nil, commitOnInvalidOAuthGrant(tx)
+	}
+
+	client, err := tx.OAuthClients().Get(code.ClientId)
+	if err != nil {
+		if errors.As(err, &pgErrs.OAuthClientNotFound{}) {
+			return nil, commitOnInvalidOAuthGrant(tx)
+		}
+
+		return nil, err
+	}
+
+	token, err := s.issueApiToken(tx, code.AccountId, models.PendingApiToken{
+		Name:   client.Name,
+		Scopes: code.Scopes,
+		Ttl:    OAUTH_TOKEN_TTL,
+	}, opt.Some(client.ClientId))
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, code.AccountId, audit.EVENT_API_TOKEN_CREATED, audit.IDENTIFIER_OAUTH_CODE)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ExchangeOAuthCodeResponse{
+		AccessToken: token.Token,
+		TokenType:   OAUTH_TOKEN_TYPE,
+		ValidUntil:  token.ValidUntil,
+		Scopes:      soatoken.ScopesToStrings(code.Scopes),
+		TokenId:     token.TokenId,
+	}, nil
+}
+
+func (s *AccountsService) ListAuthorizedApps(ctx context.Context, req *pb.Empty) (*pb.ListAuthorizedAppsResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	apps, err := conn.ApiTokens().ListAuthorizedApps(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListAuthorizedAppsResponse{
+		Apps: make([]*pb.AuthorizedApp, 0, len(apps)),
+	}
+	for _, app := range apps {
+		var lastUsedAt *timestamppb.Timestamp
+		if app.LastUsedAt.HasValue {
+			lastUsedAt = timestamppb.New(app.LastUsedAt.Value)
+		}
+
+		resp.Apps = append(resp.Apps, &pb.AuthorizedApp{
+			ClientId:     string(app.ClientId),
+			Name:         app.Name,
+			Scopes:       soatoken.ScopesToStrings(app.Scopes),
+			AuthorizedAt: timestamppb.New(app.AuthorizedAt),
+			LastUsedAt:   lastUsedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *AccountsService) RevokeAuthorizedApp(ctx context.Context, req *pb.OAuthClientRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	revokedCount, err := tx.ApiTokens().RevokeByClient(accountId, models.OAuthClientId(req.ClientId))
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedCount == 0 {
+		return nil, pgErrs.OAuthClientNotFound{}
+	}
+
+	err = publishApiTokensRevoked(tx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, accountId, audit.EVENT_API_TOKEN_REVOKED, callerIdentifierType(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateSoaTokens(accountId)
+
+	return &pb.Empty{}, nil
+}
+
+// Used code must stay burnt even though exchange fails
+func commitOnInvalidOAuthGrant(tx repo.Transaction) error {
+	err := tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return errs.InvalidOAuthGrant{}
+}
+
+func oauthClientToProto(client models.OAuthClient) *pb.OAuthClient {
+	return &pb.OAuthClient{
+		ClientId:     string(client.ClientId),
+		Name:         client.Name,
+		RedirectUris: client.RedirectUris,
+		CreatedAt:    timestamppb.New(client.CreatedAt),
+	}
+}
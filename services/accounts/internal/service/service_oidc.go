@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/oidc"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/audit"
+	opt "soa-socialnetwork/services/common/option"
+	"strings"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *AccountsService) ListOidcProviders(ctx context.Context, req *pb.Empty) (*pb.ListOidcProvidersResponse, error) {
+	resp := &pb.ListOidcProvidersResponse{
+		Providers: make([]*pb.OidcProvider, 0, len(s.oidcProviders)),
+	}
+	for _, provider := range s.oidcProviders {
+		resp.Providers = append(resp.Providers, &pb.OidcProvider{
+			Name:          provider.Config().Name,
+			AutoProvision: provider.Config().AutoProvision,
+		})
+	}
+
+	slices.SortFunc(resp.Providers, func(a, b *pb.OidcProvider) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return resp, nil
+}
+
+func (s *AccountsService) BeginOidcLogin(ctx context.Context, req *pb.OidcProviderRequest) (*pb.BeginOidcLoginResponse, error) {
+	return s.beginOidcLogin(ctx, req.Provider, opt.None[models.AccountId]())
+}
+
+// Signs in with external identity, provisioning a new account for unknown
+// identities if the provider allows it. Local second factor is requested
+// like after password check, if the account has it enabled.
+func (s *AccountsService) CompleteOidcLogin(ctx context.Context, req *pb.CompleteOidcLoginRequest) (*pb.AuthResponse, error) {
+	state, identity, err := s.completeOidcLogin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.LinkAccountId.HasValue {
+		return nil, errs.InvalidOidcLogin{}
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	var accountId models.AccountId
+	linked, err := tx.OidcIdentities().GetBySubject(req.Provider, identity.Subject)
+	switch {
+	case err == nil:
+		accountId = linked.AccountId
+		err = tx.OidcIdentities().TouchLogin(req.Provider, identity.Subject)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	case errors.As(err, &pgErrs.OidcIdentityNotFound{}):
+		if !s.oidcProviders[req.Provider].Config().AutoProvision {
+			tx.Rollback()
+			return nil, errs.OidcIdentityNotLinked{}
+		}
+
+		accountId, err = s.provisionOidcAccount(tx, req.Provider, identity)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	default:
+		tx.Rollback()
+		return nil, err
+	}
+
+	// checked before second factor so that suspended account gets no challenge
+	_, err = checkNotSuspended(tx, accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	challenge, err := s.startSecondFactor(tx, accountId, models.SECOND_FACTOR_AUTHENTICATE, opt.None[models.PendingApiToken]())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if challenge != nil {
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		return &pb.AuthResponse{
+			SecondFactor: challenge,
+		}, nil
+	}
+
+	sessionId, err := startSession(ctx, tx, accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	resp, err := s.issueTokens(tx, accountId, sessionId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, accountId, audit.EVENT_LOGIN_SUCCEEDED, audit.IDENTIFIER_OIDC)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Links external identity to the caller's account, requires a session so
+// that api tokens cannot attach new ways to sign in
+func (s *AccountsService) BeginOidcLink(ctx context.Context, req *pb.OidcProviderRequest) (*pb.BeginOidcLoginResponse, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.SessionId == "" {
+		return nil, errs.AccessDenied{}
+	}
+
+	return s.beginOidcLogin(ctx, req.Provider, opt.Some(models.AccountId(authInfo.AccountId)))
+}
+
+func (s *AccountsService) CompleteOidcLink(ctx context.Context, req *pb.CompleteOidcLoginRequest) (*pb.OidcIdentity, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.SessionId == "" {
+		return nil, errs.AccessDenied{}
+	}
+	accountId := models.AccountId(authInfo.AccountId)
+
+	state, identity, err := s.completeOidcLogin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// link started by another account must not be completed by the caller
+	if !state.LinkAccountId.HasValue || state.LinkAccountId.Value != accountId {
+		return nil, errs.InvalidOidcLogin{}
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = tx.OidcIdentities().Link(models.OidcIdentityParams{
+		AccountId: accountId,
+		Provider:  req.Provider,
+		Subject:   identity.Subject,
+		Email:     verifiedOidcEmail(identity),
+	})
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	linked, err := tx.OidcIdentities().GetBySubject(req.Provider, identity.Subject)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, accountId, audit.EVENT_OIDC_IDENTITY_LINKED, audit.IDENTIFIER_JWT)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return oidcIdentityToProto(linked), nil
+}
+
+func (s *AccountsService) ListOidcIdentities(ctx context.Context, req *pb.Empty) (*pb.ListOidcIdentitiesResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	identities, err := conn.OidcIdentities().ListByAccount(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListOidcIdentitiesResponse{
+		Identities: make([]*pb.OidcIdentity, 0, len(identities)),
+	}
+	for _, identity := range identities {
+		resp.Identities = append(resp.Identities, oidcIdentityToProto(identity))
+	}
+
+	return resp, nil
+}
+
+// The last identity is kept if the user could not sign in otherwise
+func (s *AccountsService) UnlinkOidcIdentity(ctx context.Context, req *pb.OidcProviderRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = tx.OidcIdentities().Unlink(accountId, req.Provider)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = checkCanSignIn(tx, accountId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, accountId, audit.EVENT_OIDC_IDENTITY_UNLINKED, callerIdentifierType(ctx))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) getOidcProvider(name string) (*oidc.Provider, error) {
+	provider, ok := s.oidcProviders[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown identity provider %q", name)
+	}
+
+	return provider, nil
+}
+
+// State, nonce and PKCE verifier are kept server-side, only hash of state is
+// stored so that leaked database rows cannot be used to complete logins.
+// Binding is returned to the caller only, so that a state obtained by
+// someone else cannot be used to sign the caller into a foreign account.
+func (s *AccountsService) beginOidcLogin(ctx context.Context, providerName string, linkAccountId opt.Option[models.AccountId]) (*pb.BeginOidcLoginResponse, error) {
+	provider, err := s.getOidcProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets [4]string
+	for i := range secrets {
+		secrets[i], err = newOidcSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+	state, nonce, codeVerifier, binding := secrets[0], secrets[1], secrets[2], secrets[3]
+
+	authorizationUrl, err := provider.AuthorizationUrl(ctx, state, nonce, oidc.CodeChallengeS256(codeVerifier))
+	if err != nil {
+		log.Printf("cannot discover identity provider %s: %v", providerName, err)
+		return nil, status.Error(codes.Unavailable, "identity provider is unavailable")
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = conn.OidcLoginStates().Put(hashOidcState(state), models.OidcLoginStateParams{
+		Provider:      providerName,
+		Nonce:         nonce,
+		CodeVerifier:  codeVerifier,
+		LinkAccountId: linkAccountId,
+		BindingHash:   hashOidcState(binding),
+	}, OIDC_LOGIN_STATE_TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BeginOidcLoginResponse{
+		AuthorizationUrl: authorizationUrl,
+		Binding:          binding,
+	}, nil
+}
+
+// Consumes login state and exchanges the code for verified identity
+func (s *AccountsService) completeOidcLogin(ctx context.Context, req *pb.CompleteOidcLoginRequest) (models.OidcLoginState, oidc.Identity, error) {
+	provider, err := s.getOidcProvider(req.Provider)
+	if err != nil {
+		return models.OidcLoginState{}, oidc.Identity{}, err
+	}
+
+	if req.Code == "" || req.State == "" || req.Binding == "" {
+		return models.OidcLoginState{}, oidc.Identity{}, status.Error(codes.InvalidArgument, "code, state and binding are required")
+	}
+
+	state, err := func() (models.OidcLoginState, error) {
+		conn, err := s.Db.OpenConnection(ctx)
+		if err != nil {
+			return models.OidcLoginState{}, err
+		}
+		defer conn.Close()
+
+		return conn.OidcLoginStates().Take(hashOidcState(req.State))
+	}()
+	if errors.As(err, &pgErrs.OidcLoginStateNotFound{}) {
+		return models.OidcLoginState{}, oidc.Identity{}, errs.InvalidOidcLogin{}
+	}
+	if err != nil {
+		return models.OidcLoginState{}, oidc.Identity{}, err
+	}
+
+	if state.Provider != req.Provider || state.ValidUntil.Before(time.Now()) || !isOidcBindingValid(state, req.Binding) {
+		return models.OidcLoginState{}, oidc.Identity{}, errs.InvalidOidcLogin{}
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if errors.As(err, &oidc.CodeRejected{}) || errors.As(err, &oidc.InvalidIdToken{}) {
+		log.Printf("external login with %s failed: %v", req.Provider, err)
+		return models.OidcLoginState{}, oidc.Identity{}, errs.InvalidOidcLogin{}
+	}
+	if err != nil {
+		log.Printf("cannot exchange code with identity provider %s: %v", req.Provider, err)
+		return models.OidcLoginState{}, oidc.Identity{}, status.Error(codes.Unavailable, "identity provider is unavailable")
+	}
+
+	return state, identity, nil
+}
+
+// New account gets a random password, user may set it with password reset
+// if the email is known
+func (s *AccountsService) provisionOidcAccount(tx repo.Transaction, providerName string, identity oidc.Identity) (models.AccountId, error) {
+	email := verifiedOidcEmail(identity)
+	if email != "" {
+		_, err := tx.Accounts().GetCredentialsByEmail(email)
+		if err == nil {
+			return 0, errs.OidcEmailTaken{}
+		}
+		if !errors.As(err, &pgErrs.AccountNotFound{}) {
+			return 0, err
+		}
+	}
+
+	login, err := s.newUnusedProvisionedLogin(tx, provisionedLoginPrefix(identity))
+	if err != nil {
+		return 0, err
+	}
+
+	password, err := newOidcSecret()
+	if err != nil {
+		return 0, err
+	}
+
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return 0, err
+	}
+
+	name, surname := provisionedName(identity)
+	accountId, _, err := s.createAccount(tx, models.RegistrationData{
+		Login:            login,
+		PasswordHash:     models.PasswordHash(passwordHash),
+		Email:            email,
+		Name:             name,
+		Surname:          surname,
+		PasswordIsRandom: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if email != "" {
+		err = tx.Accounts().MarkContactVerified(accountId, models.CONTACT_EMAIL, email)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.OidcIdentities().Link(models.OidcIdentityParams{
+		AccountId: accountId,
+		Provider:  providerName,
+		Subject:   identity.Subject,
+		Email:     email,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.OidcIdentities().TouchLogin(providerName, identity.Subject)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("provisioned account %d for %s identity %s", accountId, providerName, identity.Subject)
+	return accountId, nil
+}
+
+// Password is usable unless it was generated at provisioning, random one can
+// be replaced by password reset to a verified contact
+func checkCanSignIn(tx repo.Transaction, accountId models.AccountId) error {
+	identities, err := tx.OidcIdentities().ListByAccount(accountId)
+	if err != nil || len(identities) > 0 {
+		return err
+	}
+
+	isPasswordRandom, err := tx.Accounts().IsPasswordRandom(accountId)
+	if err != nil || !isPasswordRandom {
+		return err
+	}
+
+	contacts, err := tx.Accounts().GetContacts(accountId)
+	if err != nil {
+		return err
+	}
+
+	if (contacts.Email != "" && contacts.EmailVerified) || (contacts.PhoneNumber != "" && contacts.PhoneNumberVerified) {
+		return nil
+	}
+
+	return errs.LastSignInMethod{}
+}
+
+func (s *AccountsService) newUnusedProvisionedLogin(tx repo.Transaction, prefix string) (string, error) {
+	for range PROVISIONED_LOGIN_ATTEMPTS {
+		login, err := newProvisionedLogin(prefix)
+		if err != nil {
+			return "", err
+		}
+
+		_, err = tx.Accounts().GetCredentialsByLogin(login)
+		if errors.As(err, &pgErrs.AccountNotFound{}) {
+			return login, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", errors.New("cannot generate unused login")
+}
+
+func oidcIdentityToProto(identity models.OidcIdentity) *pb.OidcIdentity {
+	result := &pb.OidcIdentity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: timestamppb.New(identity.CreatedAt),
+	}
+	if identity.LastLoginAt.HasValue {
+		result.LastLoginAt = timestamppb.New(identity.LastLoginAt.Value)
+	}
+
+	return result
+}
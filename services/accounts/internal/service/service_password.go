@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/notify"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const PASSWORD_RESET_CODE_TTL = 15 * time.Minute
+const PASSWORD_RESET_MAX_ATTEMPTS = 5
+
+// Every code allows PASSWORD_RESET_MAX_ATTEMPTS guesses, so the number of
+// issued codes is limited to bound guesses across them
+const PASSWORD_RESET_MAX_CODES = 5
+const PASSWORD_RESET_CODES_PERIOD = 24 * time.Hour
+
+func (s *AccountsService) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.Empty, error) {
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "new password is empty")
+	}
+
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = s.checkCurrentPassword(ctx, tx, accountId, req.OldPassword)
+	if err != nil {
+		return nil, commitOnFailedPassword(tx, err)
+	}
+
+	sessionIds, err := s.setPassword(tx, accountId, req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateSoaTokens(accountId)
+	s.rejectSessionJwts(sessionIds)
+
+	return &pb.Empty{}, nil
+}
+
+// Always succeeds for unknown users and when the code cannot be sent, so
+// that it cannot be used to check whether account exists
+func (s *AccountsService) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.Empty, error) {
+	if req.UserId == nil {
+		return nil, status.Error(codes.InvalidArgument, "no user id")
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	credentials, err := fetchCredentialsByUserId(tx, req.UserId)
+	if err != nil {
+		if errors.As(err, &pgErrs.AccountNotFound{}) {
+			log.Printf("password reset requested for unknown user")
+			return &pb.Empty{}, nil
+		}
+
+		return nil, err
+	}
+
+	contacts, err := tx.Accounts().GetContacts(credentials.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient, ok := resetCodeRecipient(req.UserId, contacts)
+	if !ok {
+		log.Printf("account %d has no verified contacts to send password reset code to", credentials.Id)
+		return &pb.Empty{}, nil
+	}
+
+	issued, err := tx.PasswordResetCodes().CountIssuedWithin(credentials.Id, PASSWORD_RESET_CODES_PERIOD)
+	if err != nil {
+		return nil, err
+	}
+
+	if issued >= PASSWORD_RESET_MAX_CODES {
+		log.Printf("password reset codes limit reached for account %d", credentials.Id)
+		return &pb.Empty{}, nil
+	}
+
+	code, err := newOneTimeCode()
+	if err != nil {
+		return nil, err
+	}
+
+	// only the latest requested code is valid
+	err = tx.PasswordResetCodes().InvalidateAll(credentials.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.PasswordResetCodes().Put(credentials.Id, models.PasswordResetCodeHash(hashOneTimeCode(code)), PASSWORD_RESET_CODE_TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.notifier.Send(recipient, notify.Message{
+		Subject: "Password reset",
+		Text:    fmt.Sprintf("Your password reset code is %s. It is valid for %s.", code, PASSWORD_RESET_CODE_TTL),
+		Code:    code,
+	})
+	if err != nil {
+		log.Printf("cannot send password reset code to account %d: %v", credentials.Id, err)
+	}
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.Empty, error) {
+	if req.UserId == nil {
+		return nil, status.Error(codes.InvalidArgument, "no user id")
+	}
+
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "new password is empty")
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	credentials, err := fetchCredentialsByUserId(tx, req.UserId)
+	if err != nil {
+		if errors.As(err, &pgErrs.AccountNotFound{}) {
+			return nil, errs.InvalidResetCode{}
+		}
+
+		return nil, err
+	}
+
+	codeData, err := tx.PasswordResetCodes().GetActive(credentials.Id)
+	if err != nil {
+		if errors.As(err, &pgErrs.ResetCodeNotFound{}) {
+			return nil, errs.InvalidResetCode{}
+		}
+
+		return nil, err
+	}
+
+	if codeData.Attempts >= PASSWORD_RESET_MAX_ATTEMPTS {
+		return nil, errs.InvalidResetCode{}
+	}
+
+	if !oneTimeCodeMatches(req.Code, string(codeData.CodeHash)) {
+		err = tx.PasswordResetCodes().IncrementAttempts(codeData.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, errs.InvalidResetCode{}
+	}
+
+	err = tx.PasswordResetCodes().MarkUsed(codeData.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIds, err := s.setPassword(tx, credentials.Id, req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	// owner has proven access to the account, so guesses made before
+	// the reset must not keep it locked
+	err = clearAccountLockouts(tx, credentials.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateSoaTokens(credentials.Id)
+	s.rejectSessionJwts(sessionIds)
+
+	return &pb.Empty{}, nil
+}
+
+// Proves that the caller knows the password and does not only hold a token
+// of the account. Failures are counted like failed logins, so that a token
+// cannot be used to guess the password
+func (s *AccountsService) checkCurrentPassword(ctx context.Context, tx repo.Transaction, accountId models.AccountId, password string) error {
+	targets := accountAuthFailureTargets(ctx, accountId)
+	err := checkLockouts(tx, targets)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := tx.Accounts().GetCredentialsById(accountId)
+	if err != nil {
+		return err
+	}
+
+	match, _, err := s.passwordHasher.Verify(password, string(credentials.PasswordHash))
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		err = registerAuthFailure(tx, targets)
+		if err != nil {
+			return err
+		}
+
+		return errs.PasswordsDoNotMatch{}
+	}
+
+	return tx.AuthFailures().Clear([]models.AuthFailureKey{targets[0].key})
+}
+
+// Stores new password and ends all existing sessions, as old password
+// may be known to someone else
+func (s *AccountsService) setPassword(tx repo.Transaction, accountId models.AccountId, password string) ([]models.SessionId, error) {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Accounts().UpdatePasswordHash(accountId, models.PasswordHash(passwordHash))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ApiTokens().RevokeAll(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = publishApiTokensRevoked(tx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.RefreshTokens().RevokeAll(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	return revokeAllSessions(tx, accountId, nil)
+}
+
+// Code is sent to the contact used to identify user, or to any known
+// contact if user was identified by login. Unverified contacts are skipped,
+// as anyone holding a token of the account could have set them
+func resetCodeRecipient(userId *pb.UserId, contacts models.AccountContacts) (notify.Recipient, bool) {
+	type candidate struct {
+		recipient notify.Recipient
+		verified  bool
+	}
+
+	email := candidate{notify.Recipient{Channel: notify.CHANNEL_EMAIL, Address: contacts.Email}, contacts.EmailVerified}
+	phone := candidate{notify.Recipient{Channel: notify.CHANNEL_SMS, Address: contacts.PhoneNumber}, contacts.PhoneNumberVerified}
+
+	candidates := []candidate{email, phone}
+	if _, ok := userId.Id.(*pb.UserId_PhoneNumber); ok {
+		candidates = []candidate{phone, email}
+	}
+
+	for _, candidate := range candidates {
+		if candidate.recipient.Address != "" && candidate.verified {
+			return candidate.recipient, true
+		}
+	}
+
+	return notify.Recipient{}, false
+}
+
+func fetchCredentialsByUserId(provider repo.RepoProvider, userId *pb.UserId) (models.AccountCredentials, error) {
+	switch id := userId.Id.(type) {
+	case *pb.UserId_Login:
+		return provider.Accounts().GetCredentialsByLogin(id.Login)
+
+	case *pb.UserId_Email:
+		return provider.Accounts().GetCredentialsByEmail(id.Email)
+
+	case *pb.UserId_PhoneNumber:
+		return provider.Accounts().GetCredentialsByPhoneNumber(id.PhoneNumber)
+
+	default:
+		return models.AccountCredentials{}, status.Error(codes.InvalidArgument, "no user id")
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/notify"
+	"testing"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetCodeRecipient(t *testing.T) {
+	byLogin := &pb.UserId{Id: &pb.UserId_Login{Login: "login"}}
+	byPhone := &pb.UserId{Id: &pb.UserId_PhoneNumber{PhoneNumber: "+333333333333"}}
+	email := notify.Recipient{Channel: notify.CHANNEL_EMAIL, Address: "email@mail.com"}
+	phone := notify.Recipient{Channel: notify.CHANNEL_SMS, Address: "+333333333333"}
+
+	contacts := models.AccountContacts{
+		Email:               email.Address,
+		EmailVerified:       true,
+		PhoneNumber:         phone.Address,
+		PhoneNumberVerified: true,
+	}
+
+	recipient, ok := resetCodeRecipient(byLogin, contacts)
+	assert.True(t, ok)
+	assert.Equal(t, email, recipient)
+
+	recipient, ok = resetCodeRecipient(byPhone, contacts)
+	assert.True(t, ok)
+	assert.Equal(t, phone, recipient)
+
+	// unverified contacts are skipped
+	contacts.EmailVerified = false
+	recipient, ok = resetCodeRecipient(byLogin, contacts)
+	assert.True(t, ok)
+	assert.Equal(t, phone, recipient)
+
+	contacts.PhoneNumberVerified = false
+	_, ok = resetCodeRecipient(byPhone, contacts)
+	assert.False(t, ok)
+}
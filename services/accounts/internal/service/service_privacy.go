@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pb "soa-socialnetwork/services/accounts/proto"
+	"soa-socialnetwork/services/common/option"
+)
+
+func (s *AccountsService) GetPrivacySettings(ctx context.Context, req *pb.GetPrivacySettingsRequest) (*pb.PrivacySettings, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.ProfileId != req.ProfileId {
+		return nil, errs.AccessDenied{}
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	profile, err := conn.Profiles().GetByProfileId(models.ProfileId(req.ProfileId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.PrivacySettings{
+		Birthday:    visibilityToProto(profile.Privacy.Birthday),
+		Bio:         visibilityToProto(profile.Privacy.Bio),
+		Email:       visibilityToProto(profile.Privacy.Email),
+		PhoneNumber: visibilityToProto(profile.Privacy.PhoneNumber),
+	}, nil
+}
+
+func (s *AccountsService) EditPrivacySettings(ctx context.Context, req *pb.EditPrivacySettingsRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	if authInfo.ProfileId != req.ProfileId {
+		return nil, errs.AccessDenied{}
+	}
+
+	settings := req.Settings
+	if settings == nil {
+		settings = &pb.PrivacySettings{}
+	}
+
+	var edited repo.EditedPrivacySettings
+	var err error
+	if edited.Birthday, err = editedVisibility(settings.Birthday); err != nil {
+		return nil, err
+	}
+	if edited.Bio, err = editedVisibility(settings.Bio); err != nil {
+		return nil, err
+	}
+	if edited.Email, err = editedVisibility(settings.Email); err != nil {
+		return nil, err
+	}
+	if edited.PhoneNumber, err = editedVisibility(settings.PhoneNumber); err != nil {
+		return nil, err
+	}
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	err = conn.Profiles().EditPrivacy(models.ProfileId(req.ProfileId), edited)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Unspecified visibility is left unchanged
+func editedVisibility(visibility pb.Visibility) (option.Option[models.Visibility], error) {
+	if visibility == pb.Visibility_VISIBILITY_UNSPECIFIED {
+		return option.None[models.Visibility](), nil
+	}
+
+	v, err := visibilityFromProto(visibility)
+	if err != nil {
+		return option.Option[models.Visibility]{}, err
+	}
+
+	return option.Some(v), nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *AccountsService) ListSessions(ctx context.Context, req *pb.Empty) (*pb.ListSessionsResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	sessions, err := conn.Sessions().ListActive(models.AccountId(authInfo.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListSessionsResponse{
+		Sessions: make([]*pb.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, &pb.Session{
+			SessionId:  string(session.Id),
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IpAddress,
+			CreatedAt:  timestamppb.New(session.CreatedAt),
+			LastSeenAt: timestamppb.New(session.LastSeenAt),
+			Current:    string(session.Id) == authInfo.SessionId,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *AccountsService) TerminateSession(ctx context.Context, req *pb.TerminateSessionRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	if _, err := uuid.Parse(req.SessionId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "malformed session id")
+	}
+	sessionId := models.SessionId(req.SessionId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = tx.Sessions().Revoke(accountId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = endSessions(tx, accountId, []models.SessionId{sessionId})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.rejectSessionJwts([]models.SessionId{sessionId})
+
+	return &pb.Empty{}, nil
+}
+
+func (s *AccountsService) TerminateAllSessions(ctx context.Context, req *pb.TerminateAllSessionsRequest) (*pb.TerminateAllSessionsResponse, error) {
+	authInfo := getAuthInfo(ctx)
+
+	var except []models.SessionId
+	if req.KeepCurrent {
+		if authInfo.SessionId == "" {
+			return nil, status.Error(codes.FailedPrecondition, "request is not authenticated by session")
+		}
+
+		except = append(except, models.SessionId(authInfo.SessionId))
+	}
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	sessionIds, err := revokeAllSessions(tx, models.AccountId(authInfo.AccountId), except)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	s.rejectSessionJwts(sessionIds)
+
+	return &pb.TerminateAllSessionsResponse{
+		TerminatedCount: int32(len(sessionIds)),
+	}, nil
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	"soa-socialnetwork/services/accounts/pkg/audit"
+	"soa-socialnetwork/services/accounts/pkg/totp"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+)
+
+func (s *AccountsService) AuthenticateSecondFactor(ctx context.Context, req *pb.SecondFactorRequest) (*pb.AuthResponse, error) {
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	challenge, err := s.passSecondFactor(tx, req, models.SECOND_FACTOR_AUTHENTICATE)
+	if err != nil {
+		return nil, commitOnFailedSecondFactor(tx, err)
+	}
+
+	sessionId, err := startSession(ctx, tx, challenge.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.issueTokens(tx, challenge.AccountId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, challenge.AccountId, audit.EVENT_LOGIN_SUCCEEDED, audit.IDENTIFIER_SECOND_FACTOR)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (s *AccountsService) CreateApiTokenSecondFactor(ctx context.Context, req *pb.SecondFactorRequest) (*pb.CreateApiTokenResponse, error) {
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	challenge, err := s.passSecondFactor(tx, req, models.SECOND_FACTOR_API_TOKEN)
+	if err != nil {
+		return nil, commitOnFailedSecondFactor(tx, err)
+	}
+
+	if !challenge.ApiToken.HasValue {
+		return nil, errs.InvalidSecondFactorChallenge{}
+	}
+
+	resp, err := s.issueApiToken(tx, challenge.AccountId, challenge.ApiToken.Value, opt.None[models.OAuthClientId]())
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordAuditEvent(ctx, tx, challenge.AccountId, audit.EVENT_API_TOKEN_CREATED, audit.IDENTIFIER_SECOND_FACTOR)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// New secret replaces the previous unconfirmed one, it takes effect
+// only after ConfirmTotp
+func (s *AccountsService) EnrollTotp(ctx context.Context, req *pb.Empty) (*pb.EnrollTotpResponse, error) {
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	enabled, err := isTwoFactorEnabled(tx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled {
+		return nil, errs.TwoFactorAlreadyEnabled{}
+	}
+
+	login, err := tx.Accounts().GetLogin(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.TotpSecrets().Put(accountId, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.EnrollTotpResponse{
+		Secret:     totp.EncodeSecret(secret),
+		OtpauthUri: totp.KeyUri(TOTP_ISSUER, login, secret),
+	}, nil
+}
+
+func (s *AccountsService) ConfirmTotp(ctx context.Context, req *pb.TotpCodeRequest) (*pb.BackupCodesResponse, error) {
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	secret, err := tx.TotpSecrets().Get(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.IsEnabled {
+		return nil, errs.TwoFactorAlreadyEnabled{}
+	}
+
+	step, ok := matchTotpCode(secret, req.Code, time.Now())
+	if !ok {
+		return nil, errs.InvalidSecondFactorCode{}
+	}
+
+	err = tx.TotpSecrets().Enable(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := tx.TotpSecrets().UseStep(accountId, step)
+	if err != nil {
+		return nil, err
+	}
+
+	if !used {
+		return nil, errs.InvalidSecondFactorCode{}
+	}
+
+	codes, err := replaceBackupCodes(tx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BackupCodesResponse{
+		Codes: codes,
+	}, nil
+}
+
+func (s *AccountsService) DisableTotp(ctx context.Context, req *pb.TotpCodeRequest) (*pb.Empty, error) {
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = s.checkSecondFactorCode(tx, accountId, req.Code, true)
+	if err != nil {
+		return nil, commitOnFailedSecondFactor(tx, err)
+	}
+
+	err = tx.TotpSecrets().Delete(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.BackupCodes().DeleteAll(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
+// Previous backup codes stop working
+func (s *AccountsService) RegenerateBackupCodes(ctx context.Context, req *pb.TotpCodeRequest) (*pb.BackupCodesResponse, error) {
+	authInfo := getAuthInfo(ctx)
+	accountId := models.AccountId(authInfo.AccountId)
+
+	tx, err := s.Db.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+
+	err = s.checkSecondFactorCode(tx, accountId, req.Code, true)
+	if err != nil {
+		return nil, commitOnFailedSecondFactor(tx, err)
+	}
+
+	codes, err := replaceBackupCodes(tx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BackupCodesResponse{
+		Codes: codes,
+	}, nil
+}
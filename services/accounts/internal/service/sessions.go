@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// Must match USER_AGENT_METADATA_KEY of gateway service
+const USER_AGENT_METADATA_KEY = "x-user-agent"
+
+const SESSION_USER_AGENT_MAX_LENGTH = 512
+
+// How often session revocations published by accounts service instances are read
+const REVOCATIONS_REFRESH_PERIOD = time.Second
+
+func startSession(ctx context.Context, provider repo.RepoProvider, accountId models.AccountId) (models.SessionId, error) {
+	sessionId := models.SessionId(uuid.New().String())
+	err := provider.Sessions().Put(models.SessionParams{
+		Id:        sessionId,
+		AccountId: accountId,
+		UserAgent: userAgentFromContext(ctx),
+		IpAddress: clientIpFromContext(ctx),
+	}, REFRESH_TOKEN_DEFAULT_TTL)
+	if err != nil {
+		return "", err
+	}
+
+	return sessionId, nil
+}
+
+// Refresh tokens of revoked or deleted sessions are not rotated
+func continueSession(provider repo.RepoProvider, sessionId models.SessionId) error {
+	err := provider.Sessions().Touch(sessionId, REFRESH_TOKEN_DEFAULT_TTL)
+	if errors.As(err, &pgErrs.SessionNotFound{}) {
+		return errs.AccessDenied{}
+	}
+
+	return err
+}
+
+// Revokes refresh tokens of already revoked sessions and tells other
+// services to reject jwts issued to them until these jwts expire
+func endSessions(provider repo.RepoProvider, accountId models.AccountId, sessionIds []models.SessionId) error {
+	for _, sessionId := range sessionIds {
+		err := provider.RefreshTokens().RevokeFamily(models.RefreshTokenFamilyId(sessionId))
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(soajwt.SessionRevokedEvent{
+			SessionId:    string(sessionId),
+			AccountId:    int(accountId),
+			RevokedUntil: time.Now().Add(JWT_DEFAULT_TTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = provider.Outbox().Put(models.OutboxEvent{
+			Type:      soajwt.SESSION_REVOKED_TOPIC,
+			Payload:   payload,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns ids of revoked sessions
+func revokeAllSessions(provider repo.RepoProvider, accountId models.AccountId, except []models.SessionId) ([]models.SessionId, error) {
+	sessionIds, err := provider.Sessions().RevokeAll(accountId, except)
+	if err != nil {
+		return nil, err
+	}
+
+	err = endSessions(provider, accountId, sessionIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionIds, nil
+}
+
+// Must be called after the revocation is committed, revocation events
+// reach the cache only after outbox is processed
+func (s *AccountsService) rejectSessionJwts(sessionIds []models.SessionId) {
+	until := time.Now().Add(JWT_DEFAULT_TTL)
+	for _, sessionId := range sessionIds {
+		s.revokedSessions.Revoke(string(sessionId), until)
+	}
+}
+
+func userAgentFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(USER_AGENT_METADATA_KEY)
+	if len(values) != 1 {
+		return ""
+	}
+
+	userAgent := values[0]
+	if len(userAgent) > SESSION_USER_AGENT_MAX_LENGTH {
+		userAgent = strings.ToValidUTF8(userAgent[:SESSION_USER_AGENT_MAX_LENGTH], "")
+	}
+
+	return userAgent
+}
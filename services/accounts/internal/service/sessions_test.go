@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUserAgentFromContext(t *testing.T) {
+	assert.Equal(t, "", userAgentFromContext(context.Background()))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(USER_AGENT_METADATA_KEY, "Mozilla/5.0"))
+	assert.Equal(t, "Mozilla/5.0", userAgentFromContext(ctx))
+
+	// multibyte character is cut at the length limit
+	longUserAgent := strings.Repeat("a", SESSION_USER_AGENT_MAX_LENGTH-1) + "я"
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(USER_AGENT_METADATA_KEY, longUserAgent))
+	userAgent := userAgentFromContext(ctx)
+	assert.True(t, utf8.ValidString(userAgent))
+	assert.Equal(t, strings.Repeat("a", SESSION_USER_AGENT_MAX_LENGTH-1), userAgent)
+}
@@ -2,41 +2,107 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"soa-socialnetwork/services/accounts/internal/models"
 	"soa-socialnetwork/services/accounts/internal/repo"
 	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
 	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	"soa-socialnetwork/services/common/backjob"
 	"time"
 )
 
-type soaVerifier struct {
-	db repo.Database
+// Rights are cached longer than in other services, as revocations happen here
+// and drop cached rights of the account immediately. Revocations made by other
+// instances of the service arrive through kafka like in other services
+const SOA_TOKEN_CACHE_TTL = time.Minute
+
+// Fetches rights of api tokens from the database for caching verifier. Last
+// usage time is updated on fetch only, so it is precise up to cache ttl.
+type soaRightsFetcher struct {
+	db     repo.Database
+	hasher *apiTokenHasher
 }
 
-func (v *soaVerifier) Verify(token string, reqs soatoken.RightsRequirements) error {
-	conn, err := v.db.OpenConnection(context.Background())
+func (f *soaRightsFetcher) FetchRights(token string) (soatoken.Rights, error) {
+	conn, err := f.db.OpenConnection(context.Background())
 	if err != nil {
-		return err
+		return soatoken.Rights{}, err
 	}
 	defer conn.Close()
 
-	tokenData, err := conn.ApiTokens().Get(models.ApiToken(token))
+	tokenHash := f.hasher.Hash(models.ApiToken(token))
+	tokenData, err := conn.ApiTokens().Get(tokenHash)
 	if err != nil {
-		return err
+		if errors.As(err, &pgErrs.TokenNotFound{}) {
+			return soatoken.Rights{Rejection: err}, nil
+		}
+
+		return soatoken.Rights{}, err
+	}
+
+	rights := soatoken.Rights{
+		AccountId:  int32(tokenData.AccountId),
+		Scopes:     tokenData.Scopes,
+		ValidUntil: tokenData.ValidUntil,
+	}
+
+	if tokenData.IsRevoked {
+		rights.Rejection = errs.TokenRevoked{}
+		return rights, nil
+	}
+
+	_, err = checkNotSuspended(conn, models.AccountId(tokenData.AccountId))
+	if err != nil {
+		if !errors.As(err, &errs.AccountSuspended{}) {
+			return soatoken.Rights{}, err
+		}
+
+		rights.Rejection = err
+		return rights, nil
 	}
 
-	if reqs.Read && !tokenData.ReadAccess {
-		return errs.NoReadAccess{}
+	if time.Now().After(tokenData.ValidUntil) {
+		rights.Rejection = errs.TokenExpired{}
+		return rights, nil
 	}
 
-	if reqs.Write && !tokenData.WriteAccess {
-		return errs.NoWriteAccess{}
+	err = conn.ApiTokens().Touch(tokenHash)
+	if err != nil {
+		log.Printf("warning: cannot update api token last usage time: %v", err)
 	}
 
-	now := time.Now()
-	if now.After(tokenData.ValidUntil) {
-		return errs.TokenExpired{}
+	return rights, nil
+}
+
+// Must be called after the change of account tokens or status is committed
+func (s *AccountsService) invalidateSoaTokens(accountId models.AccountId) {
+	s.soaTokenCache.InvalidateAccount(int32(accountId))
+}
+
+// Tells other services to drop cached rights of account tokens, must be
+// written together with the change of account tokens or status
+func publishApiTokensRevoked(provider repo.RepoProvider, accountId models.AccountId) error {
+	payload, err := json.Marshal(soatoken.ApiTokensRevokedEvent{
+		AccountId: int(accountId),
+	})
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return provider.Outbox().Put(models.OutboxEvent{
+		Type:      soatoken.API_TOKENS_REVOKED_TOPIC,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	})
+}
+
+func logSoaTokenCacheStatsJob(cache *soatoken.CachingVerifier) backjob.JobCallback {
+	return func(ctx context.Context) error {
+		stats := cache.Stats()
+		log.Printf("soa token cache: %d hits, %d misses, %d entries", stats.Hits, stats.Misses, stats.Size)
+		return nil
+	}
 }
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/service/errs"
+	pgErrs "soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/totp"
+	opt "soa-socialnetwork/services/common/option"
+	"strings"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const TOTP_ISSUER = "SOA Social Network"
+const SECOND_FACTOR_CHALLENGE_TTL = 5 * time.Minute
+const SECOND_FACTOR_CHALLENGE_MAX_ATTEMPTS = 5
+const BACKUP_CODES_COUNT = 10
+const BACKUP_CODE_RAW_LENGTH = 5
+const SECOND_FACTOR_CHALLENGE_RAW_LENGTH = 32
+
+var backup_code_encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Returns nil if account has no second factor enabled
+func (s *AccountsService) startSecondFactor(provider repo.RepoProvider, accountId models.AccountId, purpose models.SecondFactorPurpose, apiToken opt.Option[models.PendingApiToken]) (*pb.SecondFactorChallenge, error) {
+	enabled, err := isTwoFactorEnabled(provider, accountId)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	challenge, err := newSecondFactorChallenge()
+	if err != nil {
+		return nil, err
+	}
+
+	validUntil, err := provider.SecondFactorChallenges().Put(hashSecondFactorChallenge(challenge), models.SecondFactorChallengeParams{
+		AccountId: accountId,
+		Purpose:   purpose,
+		ApiToken:  apiToken,
+	}, SECOND_FACTOR_CHALLENGE_TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SecondFactorChallenge{
+		Challenge:  challenge,
+		ValidUntil: timestamppb.New(validUntil),
+	}, nil
+}
+
+// Consumes challenge if code matches, failed attempt is counted both for
+// the challenge and for the account second factor
+func (s *AccountsService) passSecondFactor(tx repo.Transaction, req *pb.SecondFactorRequest, purpose models.SecondFactorPurpose) (models.SecondFactorChallengeData, error) {
+	challenge, err := tx.SecondFactorChallenges().GetActive(hashSecondFactorChallenge(req.Challenge))
+	if err != nil {
+		if errors.As(err, &pgErrs.SecondFactorChallengeNotFound{}) {
+			return models.SecondFactorChallengeData{}, errs.InvalidSecondFactorChallenge{}
+		}
+
+		return models.SecondFactorChallengeData{}, err
+	}
+
+	if challenge.Purpose != purpose || challenge.Attempts >= SECOND_FACTOR_CHALLENGE_MAX_ATTEMPTS {
+		return models.SecondFactorChallengeData{}, errs.InvalidSecondFactorChallenge{}
+	}
+
+	err = s.checkSecondFactorCode(tx, challenge.AccountId, req.Code, true)
+	if errors.As(err, &errs.InvalidSecondFactorCode{}) {
+		incErr := tx.SecondFactorChallenges().IncrementAttempts(challenge.Id)
+		if incErr != nil {
+			return models.SecondFactorChallengeData{}, incErr
+		}
+	}
+	if err != nil {
+		return models.SecondFactorChallengeData{}, err
+	}
+
+	err = tx.SecondFactorChallenges().MarkUsed(challenge.Id)
+	if err != nil {
+		return models.SecondFactorChallengeData{}, err
+	}
+
+	return challenge, nil
+}
+
+// Accepts TOTP code and, if allowed, unused backup code. Returns
+// errs.TwoFactorNotEnabled if account has no enabled second factor
+func (s *AccountsService) checkSecondFactorCode(tx repo.Transaction, accountId models.AccountId, code string, allowBackupCode bool) error {
+	targets := []authFailureTarget{{
+		key:    secondFactorAuthFailureKey(accountId),
+		policy: user_id_lockout_policy,
+	}}
+	err := checkLockouts(tx, targets)
+	if err != nil {
+		return err
+	}
+
+	secret, err := tx.TotpSecrets().Get(accountId)
+	if err != nil {
+		if errors.As(err, &pgErrs.TotpSecretNotFound{}) {
+			return errs.TwoFactorNotEnabled{}
+		}
+
+		return err
+	}
+
+	if !secret.IsEnabled {
+		return errs.TwoFactorNotEnabled{}
+	}
+
+	if step, ok := matchTotpCode(secret, code, time.Now()); ok {
+		// concurrent request may have used the same code since secret was read
+		used, err := tx.TotpSecrets().UseStep(accountId, step)
+		if err != nil {
+			return err
+		}
+
+		if used {
+			return tx.AuthFailures().Clear([]models.AuthFailureKey{targets[0].key})
+		}
+	}
+
+	if allowBackupCode {
+		used, err := tx.BackupCodes().Use(accountId, hashBackupCode(code))
+		if err != nil {
+			return err
+		}
+
+		if used {
+			return tx.AuthFailures().Clear([]models.AuthFailureKey{targets[0].key})
+		}
+	}
+
+	err = registerAuthFailure(tx, targets)
+	if err != nil {
+		return err
+	}
+
+	return errs.InvalidSecondFactorCode{}
+}
+
+func isTwoFactorEnabled(provider repo.RepoProvider, accountId models.AccountId) (bool, error) {
+	secret, err := provider.TotpSecrets().Get(accountId)
+	if err != nil {
+		if errors.As(err, &pgErrs.TotpSecretNotFound{}) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return secret.IsEnabled, nil
+}
+
+// Returns time step of accepted code. Codes of already used steps are
+// rejected, so that intercepted code cannot be replayed
+func matchTotpCode(secret models.TotpSecretData, code string, now time.Time) (int64, bool) {
+	step, ok := totp.Validate(secret.Secret, code, now)
+	if !ok || step <= secret.LastUsedStep {
+		return 0, false
+	}
+
+	return step, true
+}
+
+// Failed second factor attempts must be stored even though request fails
+func commitOnFailedSecondFactor(tx repo.Transaction, err error) error {
+	if errors.As(err, &errs.InvalidSecondFactorCode{}) {
+		commitErr := tx.Commit()
+		if commitErr != nil {
+			return commitErr
+		}
+	}
+
+	return err
+}
+
+func newSecondFactorChallenge() (string, error) {
+	var rawChallenge [SECOND_FACTOR_CHALLENGE_RAW_LENGTH]byte
+	_, err := rand.Read(rawChallenge[:])
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(rawChallenge[:]), nil
+}
+
+func hashSecondFactorChallenge(challenge string) models.SecondFactorChallengeHash {
+	hash := sha256.Sum256([]byte(challenge))
+	return models.SecondFactorChallengeHash(hex.EncodeToString(hash[:]))
+}
+
+func replaceBackupCodes(provider repo.RepoProvider, accountId models.AccountId) ([]string, error) {
+	codes, hashes, err := newBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = provider.BackupCodes().Replace(accountId, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Codes look like "abcd-efgh", hash does not depend on case and dashes
+func newBackupCodes() ([]string, []models.BackupCodeHash, error) {
+	codes := make([]string, 0, BACKUP_CODES_COUNT)
+	hashes := make([]models.BackupCodeHash, 0, BACKUP_CODES_COUNT)
+	for range BACKUP_CODES_COUNT {
+		var rawCode [BACKUP_CODE_RAW_LENGTH]byte
+		_, err := rand.Read(rawCode[:])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		encoded := strings.ToLower(backup_code_encoding.EncodeToString(rawCode[:]))
+		code := encoded[:len(encoded)/2] + "-" + encoded[len(encoded)/2:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashBackupCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashBackupCode(code string) models.BackupCodeHash {
+	normalized := strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	hash := sha256.Sum256([]byte(normalized))
+	return models.BackupCodeHash(hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/pkg/totp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchTotpCode(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	secret := models.TotpSecretData{
+		Secret: []byte("12345678901234567890"),
+	}
+	code := totp.Generate(secret.Secret, now)
+
+	step, ok := matchTotpCode(secret, code, now)
+	require.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	// code of already used step cannot be replayed
+	secret.LastUsedStep = step
+	_, ok = matchTotpCode(secret, code, now)
+	assert.False(t, ok)
+
+	nextCode := totp.Generate(secret.Secret, now.Add(totp.PERIOD))
+	step, ok = matchTotpCode(secret, nextCode, now.Add(totp.PERIOD))
+	require.True(t, ok)
+	assert.Equal(t, totp.Step(now)+1, step)
+
+	_, ok = matchTotpCode(secret, code, now.Add(10*totp.PERIOD))
+	assert.False(t, ok)
+}
+
+func TestBackupCodes(t *testing.T) {
+	codes, hashes, err := newBackupCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, BACKUP_CODES_COUNT)
+	require.Len(t, hashes, BACKUP_CODES_COUNT)
+
+	for i, code := range codes {
+		assert.Len(t, code, 9)
+		assert.Equal(t, hashes[i], hashBackupCode(code))
+		assert.Equal(t, hashes[i], hashBackupCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))))
+	}
+
+	assert.NotEqual(t, codes[0], codes[1])
+}
@@ -12,16 +12,20 @@ import (
 
 type Issuer struct {
 	privateKey ed25519.PrivateKey
+	keyId      string
 }
 
 type PersonalData struct {
 	AccountId int
 	ProfileId string
+	SessionId string
+	Role      soajwt.Role
 }
 
 func New(privateKey ed25519.PrivateKey) Issuer {
 	return Issuer{
 		privateKey: privateKey,
+		keyId:      soajwt.KeyId(privateKey.Public().(ed25519.PublicKey)),
 	}
 }
 
@@ -42,12 +46,21 @@ func (j *Issuer) Issue(data PersonalData, ttl time.Duration) (string, error) {
 		IssuedAt:  now,
 		JwtId:     jwtUuid.String(),
 		AccountId: data.AccountId,
+		SessionId: data.SessionId,
+		Role:      data.Role,
 	}
 
-	tokenStr, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &token).SignedString(j.privateKey)
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, &token)
+	jwtToken.Header["kid"] = j.keyId
+
+	tokenStr, err := jwtToken.SignedString(j.privateKey)
 	if err != nil {
 		return "", err
 	}
 
 	return tokenStr, nil
 }
+
+func (j *Issuer) KeyId() string {
+	return j.keyId
+}
@@ -24,9 +24,11 @@ func TestJwtIssueAndVerifySimple(t *testing.T) {
 
 	for i := 0; i < 10; i++ {
 		profileId := uuid.New().String()
+		sessionId := uuid.New().String()
 		jwt, err := issuer.Issue(PersonalData{
 			AccountId: i,
 			ProfileId: profileId,
+			SessionId: sessionId,
 		}, time.Hour)
 
 		require.NoError(t, err, "error while issuing token number %d", i)
@@ -36,6 +38,31 @@ func TestJwtIssueAndVerifySimple(t *testing.T) {
 
 		assert.Equal(t, i, token.AccountId)
 		assert.Equal(t, profileId, token.Subject)
+		assert.Equal(t, sessionId, token.SessionId)
+	}
+}
+
+func TestJwtIssueWithRole(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	issuer := New(priv)
+	verifier := soajwt.NewEd25519Verifier(pub)
+
+	for _, role := range []soajwt.Role{"", soajwt.ROLE_MODERATOR, soajwt.ROLE_ADMIN} {
+		jwt, err := issuer.Issue(PersonalData{
+			AccountId: 1,
+			ProfileId: uuid.New().String(),
+			Role:      role,
+		}, time.Hour)
+		require.NoError(t, err, "error while issuing token with role %q", role)
+
+		token, err := verifier.Verify(jwt)
+		require.NoError(t, err, "error while veryfing token with role %q", role)
+
+		assert.Equal(t, role, token.Role)
 	}
 }
 
@@ -96,3 +123,33 @@ func TestJwtIssueAndVerifyTimeout(t *testing.T) {
 	_, err = verifier.Verify(jwt)
 	require.Error(t, err, "expired token verified successfully")
 }
+
+func TestJwtIssueWithKeyRotation(t *testing.T) {
+	prevPub, prevPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	curPub, curPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	prevIssuer := New(prevPriv)
+	curIssuer := New(curPriv)
+	assert.Equal(t, soajwt.KeyId(prevPub), prevIssuer.KeyId())
+	assert.Equal(t, soajwt.KeyId(curPub), curIssuer.KeyId())
+
+	verifier := soajwt.NewKeySetVerifier(curPub, prevPub)
+
+	for _, issuer := range []Issuer{prevIssuer, curIssuer} {
+		jwt, err := issuer.Issue(PersonalData{
+			AccountId: 1,
+			ProfileId: uuid.New().String(),
+		}, time.Hour)
+		require.NoError(t, err, "error while issuing token")
+
+		_, err = verifier.Verify(jwt)
+		require.NoError(t, err, "error while verifying token with kid %s", issuer.KeyId())
+	}
+}
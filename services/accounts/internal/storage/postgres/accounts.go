@@ -7,61 +7,199 @@ import (
 	"log"
 	"soa-socialnetwork/services/accounts/internal/models"
 	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const pg_unique_violation_code = "23505"
+
 type accountsRepo struct {
 	ctx   context.Context
 	scope pgxScope
 }
 
-func (r accountsRepo) CheckPasswordByLogin(login string, password string) (models.AccountParams, error) {
-	params, err := r.fetchAccountParams("login", login)
+func (r accountsRepo) GetCredentialsByLogin(login string) (models.AccountCredentials, error) {
+	return r.fetchCredentials("login", login)
+}
+
+func (r accountsRepo) GetCredentialsByEmail(email string) (models.AccountCredentials, error) {
+	return r.fetchCredentials("email", email)
+}
+
+func (r accountsRepo) GetCredentialsByPhoneNumber(phoneNumber string) (models.AccountCredentials, error) {
+	return r.fetchCredentials("phone_number", phoneNumber)
+}
+
+func (r accountsRepo) GetCredentialsById(id models.AccountId) (models.AccountCredentials, error) {
+	sql := `
+	SELECT id, password_hash
+	FROM accounts
+	WHERE id = $1;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, id)
+	return scanCredentials(row)
+}
+
+func (r accountsRepo) GetContacts(id models.AccountId) (models.AccountContacts, error) {
+	sql := `
+	SELECT email, email_verified, phone_number, phone_number_verified
+	FROM accounts
+	WHERE id = $1;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var (
+		contacts    models.AccountContacts
+		email       *string
+		phoneNumber *string
+	)
+	err := row.Scan(&email, &contacts.EmailVerified, &phoneNumber, &contacts.PhoneNumberVerified)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.AccountContacts{}, errs.AccountNotFound{}
+		}
+
+		return models.AccountContacts{}, err
+	}
+
+	if email != nil {
+		contacts.Email = *email
+	}
+	if phoneNumber != nil {
+		contacts.PhoneNumber = *phoneNumber
+	}
+
+	return contacts, nil
+}
+
+func (r accountsRepo) GetLogin(id models.AccountId) (string, error) {
+	sql := `
+	SELECT login
+	FROM accounts
+	WHERE id = $1;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var login string
+	err := row.Scan(&login)
 	if err != nil {
-		return models.AccountParams{}, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errs.AccountNotFound{}
+		}
+
+		return "", err
 	}
 
-	if params.password != password {
-		return models.AccountParams{}, errs.PasswordsDoNotMatch{}
+	return login, nil
+}
+
+func (r accountsRepo) IsPasswordRandom(id models.AccountId) (bool, error) {
+	sql := `
+	SELECT password_is_random
+	FROM accounts
+	WHERE id = $1;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var isRandom bool
+	err := row.Scan(&isRandom)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, errs.AccountNotFound{}
+		}
+
+		return false, err
 	}
 
-	return models.AccountParams{Id: models.AccountId(params.id)}, nil
+	return isRandom, nil
 }
 
-func (r accountsRepo) CheckPasswordByEmail(email string, password string) (models.AccountParams, error) {
-	params, err := r.fetchAccountParams("email", email)
+func (r accountsRepo) UpdatePasswordHash(id models.AccountId, hash models.PasswordHash) error {
+	sql := `
+	WITH cte AS (
+		UPDATE accounts
+		SET password_hash = $1, password_is_random = FALSE
+		WHERE id = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, hash, id)
+
+	var cnt int
+	err := row.Scan(&cnt)
 	if err != nil {
-		return models.AccountParams{}, err
+		return err
 	}
 
-	if params.password != password {
-		return models.AccountParams{}, errs.PasswordsDoNotMatch{}
+	if cnt == 0 {
+		return errs.AccountNotFound{}
 	}
 
-	return models.AccountParams{Id: models.AccountId(params.id)}, nil
+	return nil
 }
 
-func (r accountsRepo) CheckPasswordByPhoneNumber(phoneNumber string, password string) (models.AccountParams, error) {
-	params, err := r.fetchAccountParams("phone_number", phoneNumber)
+func (r accountsRepo) UpdateContact(id models.AccountId, kind models.ContactKind, value string) error {
+	valueColumn, verifiedColumn, err := contactColumns(kind)
 	if err != nil {
-		return models.AccountParams{}, err
+		return err
+	}
+
+	sql := fmt.Sprintf(`
+	WITH cte AS (
+		UPDATE accounts
+		SET %s = $1, %s = FALSE
+		WHERE id = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`, valueColumn, verifiedColumn)
+
+	err = r.updateOne(sql, errs.AccountNotFound{}, value, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pg_unique_violation_code {
+		return errs.ContactAlreadyUsed{}
 	}
 
-	if params.password != password {
-		return models.AccountParams{}, errs.PasswordsDoNotMatch{}
+	return err
+}
+
+func (r accountsRepo) MarkContactVerified(id models.AccountId, kind models.ContactKind, value string) error {
+	valueColumn, verifiedColumn, err := contactColumns(kind)
+	if err != nil {
+		return err
 	}
 
-	return models.AccountParams{Id: models.AccountId(params.id)}, nil
+	sql := fmt.Sprintf(`
+	WITH cte AS (
+		UPDATE accounts
+		SET %s = TRUE
+		WHERE id = $1 AND %s = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`, verifiedColumn, valueColumn)
+
+	return r.updateOne(sql, errs.ContactNotFound{}, id, value)
 }
 
 func (r accountsRepo) New(data models.RegistrationData) (models.AccountId, error) {
 	sql := `
-	INSERT INTO accounts (login, password, email, phone_number)
-	VALUES ($1, $2, $3, $4)
+	INSERT INTO accounts (login, password_hash, email, phone_number, password_is_random)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
 	RETURNING id
 	`
-	row := r.scope.QueryRow(r.ctx, sql, data.Login, data.Password, data.Email, data.PhoneNumber)
+	row := r.scope.QueryRow(r.ctx, sql, data.Login, data.PasswordHash, data.Email, data.PhoneNumber, data.PasswordIsRandom)
 
 	var id int
 	err := row.Scan(&id)
@@ -101,29 +239,161 @@ func (r accountsRepo) Delete(id models.AccountId) error {
 	return nil
 }
 
-type accountParams struct {
-	id       int
-	password string
+func (r accountsRepo) GetStatus(id models.AccountId) (models.AccountStatus, error) {
+	sql := `
+	SELECT role, suspended_at, suspended_until, suspension_reason
+	FROM accounts
+	WHERE id = $1;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var (
+		role             string
+		pgSuspendedAt    pgtype.Timestamptz
+		pgSuspendedUntil pgtype.Timestamptz
+		reason           *string
+	)
+	err := row.Scan(&role, &pgSuspendedAt, &pgSuspendedUntil, &reason)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.AccountStatus{}, errs.AccountNotFound{}
+		}
+
+		return models.AccountStatus{}, err
+	}
+
+	status := models.AccountStatus{
+		Role: soajwt.Role(role),
+	}
+
+	suspensionEnded := pgSuspendedUntil.Valid && !pgSuspendedUntil.Time.After(time.Now())
+	if pgSuspendedAt.Valid && !suspensionEnded {
+		suspension := models.Suspension{
+			SuspendedAt: pgSuspendedAt.Time,
+		}
+		if reason != nil {
+			suspension.Reason = *reason
+		}
+		if pgSuspendedUntil.Valid {
+			suspension.Until = opt.Some(pgSuspendedUntil.Time)
+		}
+
+		status.Suspension = opt.Some(suspension)
+	}
+
+	return status, nil
+}
+
+func (r accountsRepo) SetRole(id models.AccountId, role soajwt.Role) error {
+	sql := `
+	WITH cte AS (
+		UPDATE accounts
+		SET role = $1
+		WHERE id = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, errs.AccountNotFound{}, role, id)
+}
+
+func (r accountsRepo) SetRoleByLogins(logins []string, role soajwt.Role) error {
+	sql := `
+	UPDATE accounts
+	SET role = $1
+	WHERE login = ANY($2) AND role <> $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, role, logins)
+	return err
+}
+
+func (r accountsRepo) Suspend(id models.AccountId, reason string, until opt.Option[time.Time]) error {
+	sql := `
+	WITH cte AS (
+		UPDATE accounts
+		SET suspended_at = NOW(), suspended_until = $1, suspension_reason = $2
+		WHERE id = $3
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	pgUntil := pgtype.Timestamptz{Time: until.Value, Valid: until.HasValue}
+	return r.updateOne(sql, errs.AccountNotFound{}, pgUntil, reason, id)
+}
+
+func (r accountsRepo) Unsuspend(id models.AccountId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE accounts
+		SET suspended_at = NULL, suspended_until = NULL, suspension_reason = NULL
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, errs.AccountNotFound{}, id)
 }
 
-func (r accountsRepo) fetchAccountParams(colName string, colValue string) (accountParams, error) {
+func (r accountsRepo) fetchCredentials(colName string, colValue string) (models.AccountCredentials, error) {
 	sql := fmt.Sprintf(`
-	SELECT id, password
+	SELECT id, password_hash
 	FROM accounts
 	WHERE %s = $1;
 	`, colName)
 
 	row := r.scope.QueryRow(r.ctx, sql, colValue)
+	return scanCredentials(row)
+}
+
+func (r accountsRepo) updateOne(sql string, notFoundErr error, args ...any) error {
+	row := r.scope.QueryRow(r.ctx, sql, args...)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return notFoundErr
+	}
+
+	return nil
+}
+
+func contactColumns(kind models.ContactKind) (valueColumn string, verifiedColumn string, err error) {
+	switch kind {
+	case models.CONTACT_EMAIL:
+		return "email", "email_verified", nil
 
-	var params accountParams
-	err := row.Scan(&params.id, &params.password)
+	case models.CONTACT_PHONE_NUMBER:
+		return "phone_number", "phone_number_verified", nil
+	}
+
+	return "", "", fmt.Errorf("unknown contact kind %q", kind)
+}
+
+func scanCredentials(row pgx.Row) (models.AccountCredentials, error) {
+	var (
+		id           int
+		passwordHash string
+	)
+	err := row.Scan(&id, &passwordHash)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return accountParams{}, errs.AccountNotFound{}
+			return models.AccountCredentials{}, errs.AccountNotFound{}
 		}
 
-		return accountParams{}, err
+		return models.AccountCredentials{}, err
 	}
 
-	return params, nil
+	return models.AccountCredentials{
+		Id:           models.AccountId(id),
+		PasswordHash: models.PasswordHash(passwordHash),
+	}, nil
 }
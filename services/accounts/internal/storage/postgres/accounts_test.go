@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	opt "soa-socialnetwork/services/common/option"
 	"sync"
+	"time"
 )
 
 func (s *testSuite) TestAccountsSimple() {
@@ -14,27 +18,111 @@ func (s *testSuite) TestAccountsSimple() {
 	defer conn.Close()
 
 	registrationData := models.RegistrationData{
-		Login:       "login",
-		Password:    "password",
-		Email:       "email@mail.com",
-		PhoneNumber: "+333333333333",
-		Name:        "name",
-		Surname:     "surname",
+		Login:        "login",
+		PasswordHash: "password_hash",
+		Email:        "email@mail.com",
+		PhoneNumber:  "+333333333333",
+		Name:         "name",
+		Surname:      "surname",
 	}
 
 	id, err := conn.Accounts().New(registrationData)
 	s.Require().NoError(err)
 
-	verifyCheckOk := func(params models.AccountParams, err error) {
+	verifyCredentials := func(credentials models.AccountCredentials, err error) {
 		s.Assert().NoError(err)
-		if err != nil {
-			s.Assert().Equal(id, params.Id)
+		if err == nil {
+			s.Assert().Equal(id, credentials.Id)
+			s.Assert().Equal(registrationData.PasswordHash, credentials.PasswordHash)
 		}
 	}
 
-	verifyCheckOk(conn.Accounts().CheckPasswordByLogin(registrationData.Login, registrationData.Password))
-	verifyCheckOk(conn.Accounts().CheckPasswordByLogin(registrationData.Login, registrationData.Password))
-	verifyCheckOk(conn.Accounts().CheckPasswordByLogin(registrationData.Login, registrationData.Password))
+	verifyCredentials(conn.Accounts().GetCredentialsByLogin(registrationData.Login))
+	verifyCredentials(conn.Accounts().GetCredentialsByEmail(registrationData.Email))
+	verifyCredentials(conn.Accounts().GetCredentialsByPhoneNumber(registrationData.PhoneNumber))
+}
+
+func (s *testSuite) TestAccountsUpdatePasswordHash() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err, "cannot create db connection")
+	defer conn.Close()
+
+	registrationData := models.RegistrationData{
+		Login:            "login",
+		PasswordHash:     "password_hash",
+		Email:            "email@mail.com",
+		PhoneNumber:      "+333333333333",
+		Name:             "name",
+		Surname:          "surname",
+		PasswordIsRandom: true,
+	}
+
+	id, err := conn.Accounts().New(registrationData)
+	s.Require().NoError(err)
+
+	isRandom, err := conn.Accounts().IsPasswordRandom(id)
+	s.Require().NoError(err)
+	s.Assert().True(isRandom)
+
+	err = conn.Accounts().UpdatePasswordHash(id, "new_password_hash")
+	s.Require().NoError(err)
+
+	isRandom, err = conn.Accounts().IsPasswordRandom(id)
+	s.Require().NoError(err)
+	s.Assert().False(isRandom)
+
+	credentials, err := conn.Accounts().GetCredentialsByLogin(registrationData.Login)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.PasswordHash("new_password_hash"), credentials.PasswordHash)
+
+	err = conn.Accounts().UpdatePasswordHash(id+1, "new_password_hash")
+	s.Require().Error(err)
+}
+
+func (s *testSuite) TestAccountsContacts() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err, "cannot create db connection")
+	defer conn.Close()
+
+	registrationData := models.RegistrationData{
+		Login:        "login",
+		PasswordHash: "password_hash",
+		Email:        "email@mail.com",
+		PhoneNumber:  "+333333333333",
+		Name:         "name",
+		Surname:      "surname",
+	}
+
+	id, err := conn.Accounts().New(registrationData)
+	s.Require().NoError(err)
+
+	contacts, err := conn.Accounts().GetContacts(id)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.AccountContacts{
+		Email:       registrationData.Email,
+		PhoneNumber: registrationData.PhoneNumber,
+	}, contacts)
+
+	err = conn.Accounts().MarkContactVerified(id, models.CONTACT_EMAIL, "other@mail.com")
+	s.Require().ErrorAs(err, &errs.ContactNotFound{})
+
+	err = conn.Accounts().MarkContactVerified(id, models.CONTACT_EMAIL, registrationData.Email)
+	s.Require().NoError(err)
+
+	contacts, err = conn.Accounts().GetContacts(id)
+	s.Require().NoError(err)
+	s.Assert().True(contacts.EmailVerified)
+	s.Assert().False(contacts.PhoneNumberVerified)
+
+	err = conn.Accounts().UpdateContact(id, models.CONTACT_EMAIL, "new@mail.com")
+	s.Require().NoError(err)
+
+	contacts, err = conn.Accounts().GetContacts(id)
+	s.Require().NoError(err)
+	s.Assert().Equal("new@mail.com", contacts.Email)
+	s.Assert().False(contacts.EmailVerified, "changed contact must be verified again")
 }
 
 func (s *testSuite) TestAccountNew() {
@@ -46,12 +134,12 @@ func (s *testSuite) TestAccountNew() {
 	registrations := make([]models.RegistrationData, 100)
 	for i := range registrations {
 		registrations[i] = models.RegistrationData{
-			Login:       fmt.Sprintf("login_%d", i),
-			Password:    "password",
-			Email:       fmt.Sprintf("email_%d@mail.com", i),
-			PhoneNumber: fmt.Sprintf("+333333333%d", i),
-			Name:        fmt.Sprintf("name_%d", i),
-			Surname:     fmt.Sprintf("surname_%d", i),
+			Login:        fmt.Sprintf("login_%d", i),
+			PasswordHash: "password_hash",
+			Email:        fmt.Sprintf("email_%d@mail.com", i),
+			PhoneNumber:  fmt.Sprintf("+333333333%d", i),
+			Name:         fmt.Sprintf("name_%d", i),
+			Surname:      fmt.Sprintf("surname_%d", i),
 		}
 	}
 
@@ -67,13 +155,13 @@ func (s *testSuite) TestAccountNew() {
 		WITH cte AS (
 			SELECT id
 			FROM accounts
-			WHERE id = $1 AND login = $2 AND password = $3 AND email = $4 AND phone_number = $5
+			WHERE id = $1 AND login = $2 AND password_hash = $3 AND email = $4 AND phone_number = $5
 		)
 		SELECT count(*) FROM cte;
 		`
 
 		globalConn := s.db.globalConn
-		row := globalConn.QueryRow(ctx, sql, ids[i], data.Login, data.Password, data.Email, data.PhoneNumber)
+		row := globalConn.QueryRow(ctx, sql, ids[i], data.Login, data.PasswordHash, data.Email, data.PhoneNumber)
 
 		var cnt int
 		err := row.Scan(&cnt)
@@ -88,12 +176,12 @@ func (s *testSuite) TestAccountNewConcurrent() {
 	registrations := make([]models.RegistrationData, 100)
 	for i := range registrations {
 		registrations[i] = models.RegistrationData{
-			Login:       fmt.Sprintf("login_%d", i),
-			Password:    "password",
-			Email:       fmt.Sprintf("email_%d@mail.com", i),
-			PhoneNumber: fmt.Sprintf("+333333333%d", i),
-			Name:        fmt.Sprintf("name_%d", i),
-			Surname:     fmt.Sprintf("surname_%d", i),
+			Login:        fmt.Sprintf("login_%d", i),
+			PasswordHash: "password_hash",
+			Email:        fmt.Sprintf("email_%d@mail.com", i),
+			PhoneNumber:  fmt.Sprintf("+333333333%d", i),
+			Name:         fmt.Sprintf("name_%d", i),
+			Surname:      fmt.Sprintf("surname_%d", i),
 		}
 	}
 
@@ -134,13 +222,13 @@ func (s *testSuite) TestAccountNewConcurrent() {
 		WITH cte AS (
 			SELECT id
 			FROM accounts
-			WHERE id = $1 AND login = $2 AND password = $3 AND email = $4 AND phone_number = $5
+			WHERE id = $1 AND login = $2 AND password_hash = $3 AND email = $4 AND phone_number = $5
 		)
 		SELECT count(*) FROM cte;
 		`
 
 		globalConn := s.db.globalConn
-		row := globalConn.QueryRow(ctx, sql, newResults[i].id, data.Login, data.Password, data.Email, data.PhoneNumber)
+		row := globalConn.QueryRow(ctx, sql, newResults[i].id, data.Login, data.PasswordHash, data.Email, data.PhoneNumber)
 
 		var cnt int
 		err := row.Scan(&cnt)
@@ -156,22 +244,22 @@ func (s *testSuite) TestAccountsDelete() {
 	defer conn.Close()
 
 	registrationData := models.RegistrationData{
-		Login:       "login",
-		Password:    "password",
-		Email:       "email@mail.com",
-		PhoneNumber: "+333333333333",
-		Name:        "name",
-		Surname:     "surname",
+		Login:        "login",
+		PasswordHash: "password_hash",
+		Email:        "email@mail.com",
+		PhoneNumber:  "+333333333333",
+		Name:         "name",
+		Surname:      "surname",
 	}
 
 	id, err := conn.Accounts().New(registrationData)
 	s.Require().NoError(err)
 
 	{
-		params, err := conn.Accounts().CheckPasswordByLogin(registrationData.Login, registrationData.Password)
+		credentials, err := conn.Accounts().GetCredentialsByLogin(registrationData.Login)
 		s.Assert().NoError(err)
-		if err != nil {
-			s.Assert().Equal(id, params.Id)
+		if err == nil {
+			s.Assert().Equal(id, credentials.Id)
 		}
 	}
 
@@ -179,7 +267,73 @@ func (s *testSuite) TestAccountsDelete() {
 	s.Require().NoError(err)
 
 	{
-		_, err := conn.Accounts().CheckPasswordByLogin(registrationData.Login, registrationData.Password)
+		_, err := conn.Accounts().GetCredentialsByLogin(registrationData.Login)
 		s.Require().Error(err)
 	}
 }
+
+func (s *testSuite) TestAccountsRoleAndSuspension() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err, "cannot create db connection")
+	defer conn.Close()
+
+	id, err := conn.Accounts().New(models.RegistrationData{
+		Login:        "login",
+		PasswordHash: "password_hash",
+		Email:        "email@mail.com",
+		PhoneNumber:  "+333333333333",
+		Name:         "name",
+		Surname:      "surname",
+	})
+	s.Require().NoError(err)
+
+	status, err := conn.Accounts().GetStatus(id)
+	s.Require().NoError(err)
+	s.Assert().Equal(soajwt.ROLE_USER, status.Role)
+	s.Assert().False(status.Suspension.HasValue)
+
+	s.Require().NoError(conn.Accounts().SetRole(id, soajwt.ROLE_MODERATOR))
+	s.Require().NoError(conn.Accounts().SetRoleByLogins([]string{"login", "unknown"}, soajwt.ROLE_ADMIN))
+
+	status, err = conn.Accounts().GetStatus(id)
+	s.Require().NoError(err)
+	s.Assert().Equal(soajwt.ROLE_ADMIN, status.Role)
+
+	s.Require().NoError(conn.Accounts().Suspend(id, "spam", opt.None[time.Time]()))
+
+	status, err = conn.Accounts().GetStatus(id)
+	s.Require().NoError(err)
+	s.Require().True(status.Suspension.HasValue)
+	s.Assert().Equal("spam", status.Suspension.Value.Reason)
+	s.Assert().False(status.Suspension.Value.Until.HasValue)
+	s.Assert().WithinDuration(time.Now(), status.Suspension.Value.SuspendedAt, time.Minute)
+
+	until := time.Now().Add(time.Hour)
+	s.Require().NoError(conn.Accounts().Suspend(id, "flood", opt.Some(until)))
+
+	status, err = conn.Accounts().GetStatus(id)
+	s.Require().NoError(err)
+	s.Require().True(status.Suspension.HasValue)
+	s.Assert().Equal("flood", status.Suspension.Value.Reason)
+	s.Require().True(status.Suspension.Value.Until.HasValue)
+	s.Assert().WithinDuration(until, status.Suspension.Value.Until.Value, time.Second)
+
+	// ended suspension is not reported
+	s.Require().NoError(conn.Accounts().Suspend(id, "flood", opt.Some(time.Now().Add(-time.Minute))))
+	status, err = conn.Accounts().GetStatus(id)
+	s.Require().NoError(err)
+	s.Assert().False(status.Suspension.HasValue)
+
+	s.Require().NoError(conn.Accounts().Suspend(id, "spam", opt.None[time.Time]()))
+	s.Require().NoError(conn.Accounts().Unsuspend(id))
+	status, err = conn.Accounts().GetStatus(id)
+	s.Require().NoError(err)
+	s.Assert().False(status.Suspension.HasValue)
+
+	_, err = conn.Accounts().GetStatus(id + 1)
+	s.Require().ErrorAs(err, &errs.AccountNotFound{})
+
+	err = conn.Accounts().Suspend(id+1, "spam", opt.None[time.Time]())
+	s.Require().ErrorAs(err, &errs.AccountNotFound{})
+}
@@ -6,9 +6,12 @@ import (
 	"soa-socialnetwork/services/accounts/internal/models"
 	"soa-socialnetwork/services/accounts/internal/repo"
 	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type apiTokensRepo struct {
@@ -16,35 +19,43 @@ type apiTokensRepo struct {
 	scope pgxScope
 }
 
-func (r apiTokensRepo) Put(token models.ApiToken, params repo.ApiTokenParams) (time.Time, error) {
+func (r apiTokensRepo) Put(tokenHash models.ApiTokenHash, params repo.ApiTokenParams) (models.ApiTokenId, time.Time, error) {
 	sql := `
-	INSERT INTO api_tokens(account_id, token, valid_until, read_access, write_access)
-	VALUES ($1, $2, NOW() + $3, $4, $5)
-	RETURNING valid_until;
+	INSERT INTO api_tokens(account_id, token_hash, name, valid_until, read_access, write_access, scopes, client_id)
+	VALUES ($1, $2, $3, NOW() + $4, $5, $6, $7, $8)
+	RETURNING id, valid_until;
 	`
 
-	row := r.scope.QueryRow(r.ctx, sql, params.AccountId, token, params.Ttl, params.ReadAccess, params.WriteAccess)
+	var clientId *models.OAuthClientId
+	if params.ClientId.HasValue {
+		clientId = &params.ClientId.Value
+	}
+
+	readAccess, writeAccess := soatoken.RightsFromScopes(params.Scopes)
+	row := r.scope.QueryRow(r.ctx, sql, params.AccountId, tokenHash, params.Name, params.Ttl, readAccess, writeAccess, soatoken.ScopesToStrings(params.Scopes), clientId)
 
-	var validUntil time.Time
-	err := row.Scan(&validUntil)
+	var (
+		id         models.ApiTokenId
+		validUntil time.Time
+	)
+	err := row.Scan(&id, &validUntil)
 	if err != nil {
-		return time.Time{}, err
+		return 0, time.Time{}, err
 	}
 
-	return validUntil, nil
+	return id, validUntil, nil
 }
 
-func (r apiTokensRepo) Get(token models.ApiToken) (models.ApiTokenData, error) {
+func (r apiTokensRepo) Get(tokenHash models.ApiTokenHash) (models.ApiTokenData, error) {
 	sql := `
-	SELECT account_id, read_access, write_access, created_at, valid_until
+	SELECT id, name, account_id, read_access, write_access, scopes, is_revoked, created_at, valid_until, last_used_at
 	FROM api_tokens
-	WHERE token = $1;
+	WHERE token_hash = $1;
 	`
 
-	row := r.scope.QueryRow(r.ctx, sql, token)
+	row := r.scope.QueryRow(r.ctx, sql, tokenHash)
 
-	var data models.ApiTokenData
-	err := row.Scan(&data.AccountId, &data.ReadAccess, &data.WriteAccess, &data.CreatedAt, &data.ValidUntil)
+	data, err := scanApiTokenData(row)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.ApiTokenData{}, errs.TokenNotFound{}
@@ -52,7 +63,294 @@ func (r apiTokensRepo) Get(token models.ApiToken) (models.ApiTokenData, error) {
 
 		return models.ApiTokenData{}, err
 	}
-	data.Token = token
+	data.TokenHash = tokenHash
 
 	return data, nil
 }
+
+func (r apiTokensRepo) ListByAccountId(accountId models.AccountId) ([]models.ApiTokenData, error) {
+	sql := `
+	SELECT id, name, account_id, read_access, write_access, scopes, is_revoked, created_at, valid_until, last_used_at
+	FROM api_tokens
+	WHERE account_id = $1
+	ORDER BY created_at DESC, id DESC;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]models.ApiTokenData, 0)
+	for rows.Next() {
+		data, err := scanApiTokenData(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r apiTokensRepo) Touch(tokenHash models.ApiTokenHash) error {
+	sql := `
+	UPDATE api_tokens
+	SET last_used_at = NOW()
+	WHERE token_hash = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, tokenHash)
+	return err
+}
+
+func (r apiTokensRepo) RegisterRejection(tokenHash models.ApiTokenHash, period time.Duration) (bool, error) {
+	sql := `
+	WITH cte AS (
+		UPDATE api_tokens
+		SET last_rejected_at = NOW()
+		WHERE token_hash = $1 AND (last_rejected_at IS NULL OR last_rejected_at < NOW() - $2::INTERVAL)
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, tokenHash, period)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
+
+func (r apiTokensRepo) Revoke(accountId models.AccountId, tokenId models.ApiTokenId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE api_tokens
+		SET is_revoked = TRUE
+		WHERE id = $1 AND account_id = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, tokenId, accountId)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.TokenNotFound{}
+	}
+
+	return nil
+}
+
+func (r apiTokensRepo) RevokeAll(accountId models.AccountId) (int, error) {
+	sql := `
+	UPDATE api_tokens
+	SET is_revoked = TRUE
+	WHERE account_id = $1 AND NOT is_revoked;
+	`
+
+	tag, err := r.scope.Exec(r.ctx, sql, accountId)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (r apiTokensRepo) ListAuthorizedApps(accountId models.AccountId) ([]models.AuthorizedApp, error) {
+	sql := `
+	SELECT t.client_id, c.name, array_agg(DISTINCT s.scope), MIN(t.created_at), MAX(t.last_used_at)
+	FROM api_tokens t
+	JOIN oauth_clients c ON c.client_id = t.client_id
+	CROSS JOIN LATERAL unnest(t.scopes) AS s(scope)
+	WHERE t.account_id = $1 AND NOT t.is_revoked AND t.valid_until > NOW()
+	GROUP BY t.client_id, c.name
+	ORDER BY MIN(t.created_at) DESC;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apps := make([]models.AuthorizedApp, 0)
+	for rows.Next() {
+		var (
+			app        models.AuthorizedApp
+			scopes     []string
+			lastUsedAt pgtype.Timestamptz
+		)
+		err := rows.Scan(&app.ClientId, &app.Name, &scopes, &app.AuthorizedAt, &lastUsedAt)
+		if err != nil {
+			return nil, err
+		}
+		app.Scopes = scopesFromDb(scopes)
+
+		if lastUsedAt.Valid {
+			app.LastUsedAt = opt.Some(lastUsedAt.Time)
+		}
+
+		apps = append(apps, app)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return apps, nil
+}
+
+func (r apiTokensRepo) RevokeByClient(accountId models.AccountId, clientId models.OAuthClientId) (int, error) {
+	sql := `
+	UPDATE api_tokens
+	SET is_revoked = TRUE
+	WHERE account_id = $1 AND client_id = $2 AND NOT is_revoked;
+	`
+
+	tag, err := r.scope.Exec(r.ctx, sql, accountId, clientId)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (r apiTokensRepo) RevokeAllOfClient(clientId models.OAuthClientId) ([]models.AccountId, error) {
+	sql := `
+	WITH cte AS (
+		UPDATE api_tokens
+		SET is_revoked = TRUE
+		WHERE client_id = $1 AND NOT is_revoked
+		RETURNING account_id
+	)
+	SELECT DISTINCT account_id FROM cte;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, clientId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accountIds := make([]models.AccountId, 0)
+	for rows.Next() {
+		var accountId models.AccountId
+		err := rows.Scan(&accountId)
+		if err != nil {
+			return nil, err
+		}
+
+		accountIds = append(accountIds, accountId)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return accountIds, nil
+}
+
+func (r apiTokensRepo) FetchUnhashed(limit int) ([]models.ApiToken, error) {
+	sql := `
+	SELECT token
+	FROM api_tokens
+	WHERE token IS NOT NULL
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]models.ApiToken, 0)
+	for rows.Next() {
+		var token string
+		err := rows.Scan(&token)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, models.ApiToken(token))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r apiTokensRepo) SetHash(token models.ApiToken, tokenHash models.ApiTokenHash) error {
+	sql := `
+	WITH cte AS (
+		UPDATE api_tokens
+		SET token_hash = $2, token = NULL
+		WHERE token = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, token, tokenHash)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.TokenNotFound{}
+	}
+
+	return nil
+}
+
+func scanApiTokenData(row pgx.Row) (models.ApiTokenData, error) {
+	var (
+		data       models.ApiTokenData
+		scopes     []string
+		lastUsedAt pgtype.Timestamptz
+	)
+	err := row.Scan(&data.Id, &data.Name, &data.AccountId, &data.ReadAccess, &data.WriteAccess, &scopes, &data.IsRevoked, &data.CreatedAt, &data.ValidUntil, &lastUsedAt)
+	if err != nil {
+		return models.ApiTokenData{}, err
+	}
+	data.Scopes = scopesFromDb(scopes)
+
+	if lastUsedAt.Valid {
+		data.LastUsedAt = opt.Some(lastUsedAt.Time)
+	}
+
+	return data, nil
+}
+
+// Scopes are validated before being stored, so unknown ones are kept as is
+func scopesFromDb(raw []string) []soatoken.Scope {
+	scopes := make([]soatoken.Scope, 0, len(raw))
+	for _, s := range raw {
+		scopes = append(scopes, soatoken.Scope(s))
+	}
+
+	return scopes
+}
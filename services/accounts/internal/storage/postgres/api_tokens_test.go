@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"soa-socialnetwork/services/accounts/internal/models"
 	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
 	"sync"
 	"time"
 )
@@ -14,23 +15,28 @@ func (s *testSuite) TestApiTokensSimple() {
 	conn, err := s.db.OpenConnection(ctx)
 	s.Require().NoError(err)
 
-	token := models.ApiToken("some_api_token")
+	tokenHash := models.ApiTokenHash("some_api_token_hash")
 	tokenParams := repo.ApiTokenParams{
-		AccountId:   111,
-		ReadAccess:  true,
-		WriteAccess: false,
-		Ttl:         time.Hour,
+		AccountId: 111,
+		Name:      "some_api_token_name",
+		Scopes:    []soatoken.Scope{soatoken.SCOPE_POSTS_READ, soatoken.SCOPE_COMMENTS_WRITE},
+		Ttl:       time.Hour,
 	}
 
-	validUntil, err := conn.ApiTokens().Put(token, tokenParams)
+	tokenId, validUntil, err := conn.ApiTokens().Put(tokenHash, tokenParams)
 	s.Require().NoError(err)
 
-	tokenData, err := conn.ApiTokens().Get(token)
+	tokenData, err := conn.ApiTokens().Get(tokenHash)
 	s.Require().NoError(err)
 
+	s.Assert().Equal(tokenId, tokenData.Id)
+	s.Assert().Equal(tokenParams.Name, tokenData.Name)
+	s.Assert().False(tokenData.IsRevoked)
+	s.Assert().False(tokenData.LastUsedAt.HasValue)
 	s.Assert().Equal(tokenParams.AccountId, tokenData.AccountId)
-	s.Assert().Equal(tokenParams.ReadAccess, tokenData.ReadAccess)
-	s.Assert().Equal(tokenParams.WriteAccess, tokenData.WriteAccess)
+	s.Assert().Equal(tokenParams.Scopes, tokenData.Scopes)
+	s.Assert().True(tokenData.ReadAccess)
+	s.Assert().True(tokenData.WriteAccess)
 	s.Assert().Equal(validUntil, tokenData.ValidUntil)
 }
 
@@ -47,10 +53,9 @@ func (s *testSuite) TestApiTokensConcurrent() {
 
 	for i := range 100 {
 		tokensParams[i] = repo.ApiTokenParams{
-			AccountId:   i,
-			ReadAccess:  true,
-			WriteAccess: false,
-			Ttl:         time.Hour,
+			AccountId: i,
+			Scopes:    soatoken.ScopesFromRights(true, false),
+			Ttl:       time.Hour,
 		}
 	}
 
@@ -63,8 +68,8 @@ func (s *testSuite) TestApiTokensConcurrent() {
 			defer conn.Close()
 			for j := range 10 {
 				idx := 10*i + j
-				token := models.ApiToken(fmt.Sprintf("some_api_token_%d", idx))
-				validUntil, err := conn.ApiTokens().Put(token, tokensParams[idx])
+				tokenHash := models.ApiTokenHash(fmt.Sprintf("some_api_token_hash_%d", idx))
+				_, validUntil, err := conn.ApiTokens().Put(tokenHash, tokensParams[idx])
 				results[idx] = result{
 					validUntil: validUntil,
 					err:        err,
@@ -85,11 +90,162 @@ func (s *testSuite) TestApiTokensConcurrent() {
 	s.Require().NoError(err)
 
 	for i, result := range results {
-		tokenData, err := conn.ApiTokens().Get(models.ApiToken(fmt.Sprintf("some_api_token_%d", i)))
+		tokenData, err := conn.ApiTokens().Get(models.ApiTokenHash(fmt.Sprintf("some_api_token_hash_%d", i)))
 		s.Require().NoError(err)
 		s.Assert().Equal(tokensParams[i].AccountId, tokenData.AccountId)
-		s.Assert().Equal(tokensParams[i].ReadAccess, tokenData.ReadAccess)
-		s.Assert().Equal(tokensParams[i].WriteAccess, tokenData.WriteAccess)
+		s.Assert().Equal(tokensParams[i].Scopes, tokenData.Scopes)
+		s.Assert().True(tokenData.ReadAccess)
+		s.Assert().False(tokenData.WriteAccess)
 		s.Assert().Equal(result.validUntil, tokenData.ValidUntil)
 	}
 }
+
+func (s *testSuite) TestApiTokensTouch() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	tokenHash := models.ApiTokenHash("some_api_token_hash")
+	_, _, err = conn.ApiTokens().Put(tokenHash, repo.ApiTokenParams{
+		AccountId: 111,
+		Scopes:    soatoken.AllScopes(),
+		Ttl:       time.Hour,
+	})
+	s.Require().NoError(err)
+
+	err = conn.ApiTokens().Touch(tokenHash)
+	s.Require().NoError(err)
+
+	tokenData, err := conn.ApiTokens().Get(tokenHash)
+	s.Require().NoError(err)
+	s.Assert().True(tokenData.LastUsedAt.HasValue)
+}
+
+func (s *testSuite) TestApiTokensRegisterRejection() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	tokenHash := models.ApiTokenHash("rejected_api_token_hash")
+	_, _, err = conn.ApiTokens().Put(tokenHash, repo.ApiTokenParams{
+		AccountId: 112,
+		Scopes:    soatoken.AllScopes(),
+		Ttl:       time.Hour,
+	})
+	s.Require().NoError(err)
+
+	registered, err := conn.ApiTokens().RegisterRejection(tokenHash, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().True(registered)
+
+	// replays within the period are not registered again
+	registered, err = conn.ApiTokens().RegisterRejection(tokenHash, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().False(registered)
+
+	registered, err = conn.ApiTokens().RegisterRejection(tokenHash, 0)
+	s.Require().NoError(err)
+	s.Assert().True(registered)
+}
+
+func (s *testSuite) TestApiTokensListAndRevoke() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+	otherAccountId := models.AccountId(222)
+
+	ids := make([]models.ApiTokenId, 3)
+	for i := range ids {
+		id, _, err := conn.ApiTokens().Put(models.ApiTokenHash(fmt.Sprintf("some_api_token_hash_%d", i)), repo.ApiTokenParams{
+			AccountId: int(accountId),
+			Name:      fmt.Sprintf("some_api_token_name_%d", i),
+			Scopes:    soatoken.ScopesFromRights(true, false),
+			Ttl:       time.Hour,
+		})
+		s.Require().NoError(err)
+		ids[i] = id
+	}
+
+	otherId, _, err := conn.ApiTokens().Put("other_api_token_hash", repo.ApiTokenParams{
+		AccountId: int(otherAccountId),
+		Scopes:    soatoken.AllScopes(),
+		Ttl:       time.Hour,
+	})
+	s.Require().NoError(err)
+
+	tokens, err := conn.ApiTokens().ListByAccountId(accountId)
+	s.Require().NoError(err)
+	s.Require().Len(tokens, 3)
+	for _, token := range tokens {
+		s.Assert().Contains(ids, token.Id)
+		s.Assert().Empty(token.TokenHash)
+	}
+
+	// token of another account must not be revocable
+	err = conn.ApiTokens().Revoke(accountId, otherId)
+	s.Require().Error(err)
+
+	err = conn.ApiTokens().Revoke(accountId, ids[0])
+	s.Require().NoError(err)
+
+	tokenData, err := conn.ApiTokens().Get("some_api_token_hash_0")
+	s.Require().NoError(err)
+	s.Assert().True(tokenData.IsRevoked)
+
+	revokedCount, err := conn.ApiTokens().RevokeAll(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(2, revokedCount)
+
+	tokens, err = conn.ApiTokens().ListByAccountId(accountId)
+	s.Require().NoError(err)
+	for _, token := range tokens {
+		s.Assert().True(token.IsRevoked)
+	}
+
+	tokenData, err = conn.ApiTokens().Get("other_api_token_hash")
+	s.Require().NoError(err)
+	s.Assert().False(tokenData.IsRevoked)
+}
+
+func (s *testSuite) TestApiTokensHashLegacy() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	_, err = s.db.globalConn.Exec(ctx, `
+	INSERT INTO api_tokens(account_id, token, valid_until, read_access, write_access)
+	VALUES (111, 'legacy_api_token', NOW() + INTERVAL '1 hour', TRUE, FALSE);
+	`)
+	s.Require().NoError(err)
+
+	tx, err := s.db.BeginTransaction(ctx)
+	s.Require().NoError(err)
+	defer tx.Close()
+
+	tokens, err := tx.ApiTokens().FetchUnhashed(10)
+	s.Require().NoError(err)
+	s.Require().Equal([]models.ApiToken{"legacy_api_token"}, tokens)
+
+	// token fetched by another transaction is skipped
+	tokens, err = conn.ApiTokens().FetchUnhashed(10)
+	s.Require().NoError(err)
+	s.Assert().Empty(tokens)
+
+	err = tx.ApiTokens().SetHash("legacy_api_token", "legacy_api_token_hash")
+	s.Require().NoError(err)
+	s.Require().NoError(tx.Commit())
+
+	tokenData, err := conn.ApiTokens().Get("legacy_api_token_hash")
+	s.Require().NoError(err)
+	s.Assert().Equal(111, tokenData.AccountId)
+
+	tokens, err = conn.ApiTokens().FetchUnhashed(10)
+	s.Require().NoError(err)
+	s.Assert().Empty(tokens)
+}
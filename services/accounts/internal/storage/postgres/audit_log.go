@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+)
+
+const AUDIT_EVENTS_PAGE_SIZE = 20
+
+type auditLogRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+type auditEventsPagiToken struct {
+	LastId models.AuditEventId `json:"lid"`
+}
+
+func (r auditLogRepo) Append(params models.AuditEventParams) (models.AuditEvent, error) {
+	sql := `
+	INSERT INTO audit_events(account_id, event_type, identifier_type, client_ip, user_agent)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at;
+	`
+
+	event := models.AuditEvent{AuditEventParams: params}
+	row := r.scope.QueryRow(r.ctx, sql, params.AccountId, params.Type, params.IdentifierType, params.ClientIp, params.UserAgent)
+	err := row.Scan(&event.Id, &event.CreatedAt)
+	if err != nil {
+		return models.AuditEvent{}, err
+	}
+
+	return event, nil
+}
+
+func (r auditLogRepo) List(accountId models.AccountId, encodedPagiToken repo.PagiToken) (repo.AuditEventsPage, error) {
+	var pagiToken auditEventsPagiToken
+	if encodedPagiToken != "" {
+		var err error
+		pagiToken, err = decodePagiToken[auditEventsPagiToken](encodedPagiToken)
+		if err != nil {
+			return repo.AuditEventsPage{}, err
+		}
+	}
+
+	sql := fmt.Sprintf(`
+	SELECT id, event_type, identifier_type, client_ip, user_agent, created_at
+	FROM audit_events
+	WHERE account_id = $1 AND ($2 = 0 OR id < $2)
+	ORDER BY id DESC
+	LIMIT %d;
+	`, AUDIT_EVENTS_PAGE_SIZE)
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId, pagiToken.LastId)
+	if err != nil {
+		return repo.AuditEventsPage{}, err
+	}
+	defer rows.Close()
+
+	events := make([]models.AuditEvent, 0, AUDIT_EVENTS_PAGE_SIZE)
+	for rows.Next() {
+		event := models.AuditEvent{
+			AuditEventParams: models.AuditEventParams{AccountId: accountId},
+		}
+		err := rows.Scan(&event.Id, &event.Type, &event.IdentifierType, &event.ClientIp, &event.UserAgent, &event.CreatedAt)
+		if err != nil {
+			return repo.AuditEventsPage{}, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return repo.AuditEventsPage{}, err
+	}
+
+	var nextPagiToken repo.PagiToken
+	if len(events) == AUDIT_EVENTS_PAGE_SIZE {
+		token := auditEventsPagiToken{LastId: events[len(events)-1].Id}
+		encodedToken, err := encodePagiToken(token)
+		if err != nil {
+			log.Printf("warning: cannot encode audit events paginating token (%v): %v", token, err)
+		} else {
+			nextPagiToken = encodedToken
+		}
+	}
+
+	return repo.AuditEventsPage{
+		Events:        events,
+		NextPagiToken: nextPagiToken,
+	}, nil
+}
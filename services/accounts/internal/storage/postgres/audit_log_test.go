@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/pkg/audit"
+)
+
+func (s *testSuite) TestAuditLog() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(121)
+	appended := make([]models.AuditEvent, 0, AUDIT_EVENTS_PAGE_SIZE+1)
+	for range AUDIT_EVENTS_PAGE_SIZE + 1 {
+		event, err := conn.AuditLog().Append(models.AuditEventParams{
+			AccountId:      accountId,
+			Type:           audit.EVENT_LOGIN_SUCCEEDED,
+			IdentifierType: audit.IDENTIFIER_LOGIN,
+			ClientIp:       "10.0.0.1",
+			UserAgent:      "test agent",
+		})
+		s.Require().NoError(err)
+		s.Assert().False(event.CreatedAt.IsZero())
+		appended = append(appended, event)
+	}
+
+	_, err = conn.AuditLog().Append(models.AuditEventParams{
+		AccountId:      models.AccountId(122),
+		Type:           audit.EVENT_LOGIN_FAILED,
+		IdentifierType: audit.IDENTIFIER_EMAIL,
+	})
+	s.Require().NoError(err)
+
+	page, err := conn.AuditLog().List(accountId, "")
+	s.Require().NoError(err)
+	s.Require().Len(page.Events, AUDIT_EVENTS_PAGE_SIZE)
+	s.Require().NotEmpty(page.NextPagiToken)
+
+	latest := appended[len(appended)-1]
+	s.Assert().Equal(latest.Id, page.Events[0].Id)
+	s.Assert().Equal(audit.EVENT_LOGIN_SUCCEEDED, page.Events[0].Type)
+	s.Assert().Equal(audit.IDENTIFIER_LOGIN, page.Events[0].IdentifierType)
+	s.Assert().Equal("10.0.0.1", page.Events[0].ClientIp)
+	s.Assert().Equal("test agent", page.Events[0].UserAgent)
+
+	page, err = conn.AuditLog().List(accountId, page.NextPagiToken)
+	s.Require().NoError(err)
+	s.Require().Len(page.Events, 1)
+	s.Assert().Equal(appended[0].Id, page.Events[0].Id)
+	s.Assert().Empty(page.NextPagiToken)
+
+	_, err = conn.AuditLog().List(accountId, repo.PagiToken("broken"))
+	s.Assert().Error(err)
+
+	// log is append-only
+	_, err = s.db.globalConn.Exec(ctx, "DELETE FROM audit_events WHERE account_id = $1", accountId)
+	s.Assert().Error(err)
+}
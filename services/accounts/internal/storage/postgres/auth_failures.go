@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"soa-socialnetwork/services/accounts/internal/models"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+type authFailuresRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r authFailuresRepo) BeginAttempt(keys []models.AuthFailureKey) error {
+	sql := `
+	SELECT pg_advisory_xact_lock(hashtext('auth_failures'), hashtext($1));
+	`
+
+	// keys are locked in the same order by every transaction, so that
+	// they cannot deadlock
+	sortedKeys := authFailureKeysToStrings(keys)
+	slices.Sort(sortedKeys)
+	for _, key := range sortedKeys {
+		_, err := r.scope.Exec(r.ctx, sql, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r authFailuresRepo) Get(keys []models.AuthFailureKey) ([]models.AuthFailureData, error) {
+	sql := `
+	SELECT key, failed_count, last_failed_at, locked_until
+	FROM auth_failures
+	WHERE key = ANY($1)
+	ORDER BY key;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, authFailureKeysToStrings(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	failures := make([]models.AuthFailureData, 0, len(keys))
+	for rows.Next() {
+		var (
+			data        models.AuthFailureData
+			key         string
+			lockedUntil *time.Time
+		)
+		err := rows.Scan(&key, &data.FailedCount, &data.LastFailedAt, &lockedUntil)
+		if err != nil {
+			return nil, err
+		}
+		data.Key = models.AuthFailureKey(key)
+		data.LockedUntil = opt.FromPointer(lockedUntil)
+
+		failures = append(failures, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return failures, nil
+}
+
+func (r authFailuresRepo) RegisterFailure(key models.AuthFailureKey, window time.Duration) (int, error) {
+	sql := `
+	INSERT INTO auth_failures AS f (key, failed_count, last_failed_at)
+	VALUES ($1, 1, NOW())
+	ON CONFLICT (key) DO UPDATE
+	SET failed_count = CASE
+			WHEN f.last_failed_at < NOW() - $2::INTERVAL THEN 1
+			ELSE f.failed_count + 1
+		END,
+		last_failed_at = NOW()
+	RETURNING failed_count;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, key, window)
+
+	var failedCount int
+	err := row.Scan(&failedCount)
+	if err != nil {
+		return 0, err
+	}
+
+	return failedCount, nil
+}
+
+func (r authFailuresRepo) Lock(key models.AuthFailureKey, until time.Time) error {
+	sql := `
+	UPDATE auth_failures
+	SET locked_until = $1
+	WHERE key = $2;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, until, key)
+	return err
+}
+
+func (r authFailuresRepo) Clear(keys []models.AuthFailureKey) error {
+	sql := `
+	DELETE FROM auth_failures
+	WHERE key = ANY($1);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, authFailureKeysToStrings(keys))
+	return err
+}
+
+func authFailureKeysToStrings(keys []models.AuthFailureKey) []string {
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, string(key))
+	}
+
+	return result
+}
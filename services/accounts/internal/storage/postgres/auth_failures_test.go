@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"time"
+)
+
+func (s *testSuite) TestAuthFailuresSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	loginKey := models.AuthFailureKey("login:some_login")
+	clientKey := models.AuthFailureKey("client:127.0.0.1")
+
+	for i := range 3 {
+		failedCount, err := conn.AuthFailures().RegisterFailure(loginKey, time.Hour)
+		s.Require().NoError(err)
+		s.Assert().Equal(i+1, failedCount)
+	}
+
+	_, err = conn.AuthFailures().RegisterFailure(clientKey, time.Hour)
+	s.Require().NoError(err)
+
+	lockedUntil := time.Now().Add(time.Minute).Truncate(time.Microsecond)
+	err = conn.AuthFailures().Lock(loginKey, lockedUntil)
+	s.Require().NoError(err)
+
+	failures, err := conn.AuthFailures().Get([]models.AuthFailureKey{loginKey, clientKey, "email:unknown"})
+	s.Require().NoError(err)
+	s.Require().Len(failures, 2)
+
+	s.Assert().Equal(clientKey, failures[0].Key)
+	s.Assert().Equal(1, failures[0].FailedCount)
+	s.Assert().False(failures[0].LockedUntil.HasValue)
+
+	s.Assert().Equal(loginKey, failures[1].Key)
+	s.Assert().Equal(3, failures[1].FailedCount)
+	s.Require().True(failures[1].LockedUntil.HasValue)
+	s.Assert().True(lockedUntil.Equal(failures[1].LockedUntil.Value))
+
+	err = conn.AuthFailures().Clear([]models.AuthFailureKey{loginKey})
+	s.Require().NoError(err)
+
+	failures, err = conn.AuthFailures().Get([]models.AuthFailureKey{loginKey, clientKey})
+	s.Require().NoError(err)
+	s.Require().Len(failures, 1)
+	s.Assert().Equal(clientKey, failures[0].Key)
+}
+
+func (s *testSuite) TestAuthFailuresWindow() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	key := models.AuthFailureKey("login:some_login")
+
+	_, err = conn.AuthFailures().RegisterFailure(key, time.Hour)
+	s.Require().NoError(err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	// previous failure is out of window, so counting starts over
+	failedCount, err := conn.AuthFailures().RegisterFailure(key, time.Millisecond)
+	s.Require().NoError(err)
+	s.Assert().Equal(1, failedCount)
+}
+
+func (s *testSuite) TestAuthFailuresBeginAttempt() {
+	ctx := context.Background()
+	loginKey := models.AuthFailureKey("login:some_login")
+	clientKey := models.AuthFailureKey("client:127.0.0.1")
+
+	tx, err := s.db.BeginTransaction(ctx)
+	s.Require().NoError(err)
+	defer tx.Close()
+
+	err = tx.AuthFailures().BeginAttempt([]models.AuthFailureKey{loginKey, clientKey})
+	s.Require().NoError(err)
+
+	// attempt of the same client waits for the first one
+	{
+		waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		otherTx, err := s.db.BeginTransaction(waitCtx)
+		s.Require().NoError(err)
+		defer otherTx.Close()
+
+		err = otherTx.AuthFailures().BeginAttempt([]models.AuthFailureKey{clientKey})
+		s.Require().Error(err)
+	}
+
+	// attempt of other keys does not wait
+	{
+		otherTx, err := s.db.BeginTransaction(ctx)
+		s.Require().NoError(err)
+		defer otherTx.Close()
+
+		err = otherTx.AuthFailures().BeginAttempt([]models.AuthFailureKey{"login:other_login"})
+		s.Require().NoError(err)
+	}
+
+	s.Require().NoError(tx.Commit())
+
+	otherTx, err := s.db.BeginTransaction(ctx)
+	s.Require().NoError(err)
+	defer otherTx.Close()
+
+	err = otherTx.AuthFailures().BeginAttempt([]models.AuthFailureKey{clientKey})
+	s.Require().NoError(err)
+}
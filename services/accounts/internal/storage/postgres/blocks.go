@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+)
+
+const BLOCKS_PAGE_SIZE = 20
+
+type blocksRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r blocksRepo) Block(blocker models.AccountId, blocked models.AccountId) (bool, error) {
+	sql := `
+	WITH cte AS (
+		INSERT INTO blocks(blocker_account_id, blocked_account_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.changed(sql, blocker, blocked)
+}
+
+func (r blocksRepo) Unblock(blocker models.AccountId, blocked models.AccountId) (bool, error) {
+	sql := `
+	WITH cte AS (
+		DELETE FROM blocks
+		WHERE blocker_account_id = $1 AND blocked_account_id = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.changed(sql, blocker, blocked)
+}
+
+func (r blocksRepo) ListBlocked(accountId models.AccountId, token repo.PagiToken) (repo.ProfileCardsPage, error) {
+	sql := fmt.Sprintf(`
+	SELECT b.created_at, p.account_id, p.profile_id, p.name, p.surname
+	FROM blocks b
+	JOIN profiles p ON p.account_id = b.blocked_account_id
+	WHERE
+		b.blocker_account_id = $1
+		AND ($2::TIMESTAMPTZ IS NULL OR (b.created_at, b.blocked_account_id) < ($2, $3))
+	ORDER BY b.created_at DESC, b.blocked_account_id DESC
+	LIMIT %d;
+	`, BLOCKS_PAGE_SIZE)
+
+	return listProfileCardsPage(r.ctx, r.scope, sql, BLOCKS_PAGE_SIZE, accountId, token)
+}
+
+func (r blocksRepo) DeleteAll(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM blocks
+	WHERE blocker_account_id = $1 OR blocked_account_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
+
+func (r blocksRepo) changed(sql string, args ...any) (bool, error) {
+	var cnt int
+	err := r.scope.QueryRow(r.ctx, sql, args...).Scan(&cnt)
+	if err != nil {
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+)
+
+func (s *testSuite) TestBlocks() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+
+	alice, _ := s.newTestAccountWithProfile(conn, 0)
+	bob, bobProfile := s.newTestAccountWithProfile(conn, 1)
+	carol, carolProfile := s.newTestAccountWithProfile(conn, 2)
+
+	changed, err := conn.Blocks().Block(alice, bob)
+	s.Require().NoError(err)
+	s.Assert().True(changed)
+
+	changed, err = conn.Blocks().Block(alice, bob)
+	s.Require().NoError(err)
+	s.Assert().False(changed)
+
+	_, err = conn.Blocks().Block(alice, carol)
+	s.Require().NoError(err)
+	_, err = conn.Blocks().Block(bob, alice)
+	s.Require().NoError(err)
+
+	blocked, err := conn.Blocks().ListBlocked(alice, "")
+	s.Require().NoError(err)
+	s.Require().Len(blocked.Profiles, 2)
+	s.Assert().Equal(carolProfile, blocked.Profiles[0].ProfileId)
+	s.Assert().Equal(bobProfile, blocked.Profiles[1].ProfileId)
+	s.Assert().Empty(blocked.NextPagiToken)
+
+	changed, err = conn.Blocks().Unblock(alice, carol)
+	s.Require().NoError(err)
+	s.Assert().True(changed)
+
+	changed, err = conn.Blocks().Unblock(alice, carol)
+	s.Require().NoError(err)
+	s.Assert().False(changed)
+
+	blocked, err = conn.Blocks().ListBlocked(alice, "")
+	s.Require().NoError(err)
+	s.Require().Len(blocked.Profiles, 1)
+	s.Assert().Equal(bobProfile, blocked.Profiles[0].ProfileId)
+
+	err = conn.Blocks().DeleteAll(alice)
+	s.Require().NoError(err)
+
+	for _, account := range []models.AccountId{alice, bob} {
+		blocked, err = conn.Blocks().ListBlocked(account, "")
+		s.Require().NoError(err)
+		s.Assert().Empty(blocked.Profiles)
+	}
+}
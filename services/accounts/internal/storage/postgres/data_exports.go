@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type dataExportsRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+// Concurrent request of the same account may insert pending export that is
+// not yet visible to the statement, it is seen by the next attempt
+const DATA_EXPORT_NEW_ATTEMPTS = 3
+
+func (r dataExportsRepo) New(id models.DataExportId, accountId models.AccountId) (models.DataExport, error) {
+	sql := `
+	WITH inserted AS (
+		INSERT INTO data_exports(id, account_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING id, account_id, status, created_at, finished_at
+	)
+	SELECT id, account_id, status, created_at, finished_at FROM inserted
+	UNION ALL
+	SELECT id, account_id, status, created_at, finished_at
+	FROM data_exports
+	WHERE account_id = $2 AND status = 'pending' AND NOT EXISTS (SELECT 1 FROM inserted);
+	`
+
+	for range DATA_EXPORT_NEW_ATTEMPTS {
+		export, err := scanDataExport(r.scope.QueryRow(r.ctx, sql, id, accountId))
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return export, err
+		}
+	}
+
+	return models.DataExport{}, errs.DataExportConflict{}
+}
+
+func (r dataExportsRepo) CountCreatedWithin(accountId models.AccountId, period time.Duration) (int, error) {
+	lockSql := `
+	SELECT pg_advisory_xact_lock(hashtext('data_exports'), $1);
+	`
+
+	_, err := r.scope.Exec(r.ctx, lockSql, accountId)
+	if err != nil {
+		return 0, err
+	}
+
+	sql := `
+	SELECT count(*)
+	FROM data_exports
+	WHERE account_id = $1 AND created_at > NOW() - $2::INTERVAL;
+	`
+
+	var cnt int
+	err = r.scope.QueryRow(r.ctx, sql, accountId, period).Scan(&cnt)
+	return cnt, err
+}
+
+func (r dataExportsRepo) Get(id models.DataExportId) (models.DataExport, error) {
+	sql := `
+	SELECT id, account_id, status, created_at, finished_at
+	FROM data_exports
+	WHERE id = $1;
+	`
+
+	return r.getOne(sql, id)
+}
+
+func (r dataExportsRepo) GetPending(accountId models.AccountId) (models.DataExport, error) {
+	sql := `
+	SELECT id, account_id, status, created_at, finished_at
+	FROM data_exports
+	WHERE account_id = $1 AND status = 'pending';
+	`
+
+	return r.getOne(sql, accountId)
+}
+
+func (r dataExportsRepo) getOne(sql string, args ...any) (models.DataExport, error) {
+	export, err := scanDataExport(r.scope.QueryRow(r.ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.DataExport{}, errs.DataExportNotFound{}
+		}
+
+		return models.DataExport{}, err
+	}
+
+	return export, nil
+}
+
+func (r dataExportsRepo) ClaimPending(limit int) ([]models.DataExport, error) {
+	sql := `
+	SELECT id, account_id, status, created_at, finished_at
+	FROM data_exports
+	WHERE status = 'pending'
+	ORDER BY created_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exports := make([]models.DataExport, 0)
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		exports = append(exports, export)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exports, nil
+}
+
+func (r dataExportsRepo) Finish(id models.DataExportId, status models.DataExportStatus) error {
+	sql := `
+	WITH cte AS (
+		UPDATE data_exports
+		SET status = $2, finished_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	var cnt int
+	err := r.scope.QueryRow(r.ctx, sql, id, status).Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.DataExportNotFound{}
+	}
+
+	return nil
+}
+
+func (r dataExportsRepo) DeleteAll(accountId models.AccountId) ([]models.DataExportId, error) {
+	sql := `
+	DELETE FROM data_exports
+	WHERE account_id = $1
+	RETURNING id;
+	`
+
+	return r.deleteReturningIds(sql, accountId)
+}
+
+func (r dataExportsRepo) DeleteFinishedBefore(period time.Duration) ([]models.DataExportId, error) {
+	sql := `
+	DELETE FROM data_exports
+	WHERE status <> 'pending' AND finished_at < NOW() - $1::INTERVAL
+	RETURNING id;
+	`
+
+	return r.deleteReturningIds(sql, period)
+}
+
+func (r dataExportsRepo) deleteReturningIds(sql string, args ...any) ([]models.DataExportId, error) {
+	rows, err := r.scope.Query(r.ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]models.DataExportId, 0)
+	for rows.Next() {
+		var id string
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, models.DataExportId(id))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func scanDataExport(row pgx.Row) (models.DataExport, error) {
+	var (
+		export       models.DataExport
+		id           string
+		status       string
+		pgFinishedAt pgtype.Timestamptz
+	)
+	err := row.Scan(&id, &export.AccountId, &status, &export.CreatedAt, &pgFinishedAt)
+	if err != nil {
+		return models.DataExport{}, err
+	}
+	export.Id = models.DataExportId(id)
+	export.Status = models.DataExportStatus(status)
+	if pgFinishedAt.Valid {
+		export.FinishedAt = pgFinishedAt.Time
+	}
+
+	return export, nil
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+)
+
+func (s *testSuite) TestDataExports() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+	firstId := models.DataExportId("0b5ab2f4-6f4f-4b1c-9d8e-1f2a3b4c5d6e")
+	secondId := models.DataExportId("1c6bc3a5-7a5a-4c2d-8e9f-2a3b4c5d6e7f")
+	otherId := models.DataExportId("2d7cd4b6-8b6b-4d3e-9fa0-3b4c5d6e7f80")
+
+	export, err := conn.DataExports().New(firstId, accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(firstId, export.Id)
+	s.Assert().Equal(accountId, export.AccountId)
+	s.Assert().Equal(models.DATA_EXPORT_PENDING, export.Status)
+	s.Assert().True(export.FinishedAt.IsZero())
+
+	// pending export is reused
+	export, err = conn.DataExports().New(secondId, accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(firstId, export.Id)
+
+	export, err = conn.DataExports().GetPending(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(firstId, export.Id)
+
+	_, err = conn.DataExports().GetPending(models.AccountId(222))
+	s.Require().ErrorAs(err, &errs.DataExportNotFound{})
+
+	_, err = conn.DataExports().New(otherId, models.AccountId(222))
+	s.Require().NoError(err)
+
+	pending, err := conn.DataExports().ClaimPending(10)
+	s.Require().NoError(err)
+	s.Require().Len(pending, 2)
+	s.Assert().Equal(firstId, pending[0].Id)
+	s.Assert().Equal(otherId, pending[1].Id)
+
+	err = conn.DataExports().Finish(firstId, models.DATA_EXPORT_READY)
+	s.Require().NoError(err)
+
+	err = conn.DataExports().Finish(firstId, models.DATA_EXPORT_FAILED)
+	s.Require().ErrorAs(err, &errs.DataExportNotFound{})
+
+	export, err = conn.DataExports().Get(firstId)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.DATA_EXPORT_READY, export.Status)
+	s.Assert().False(export.FinishedAt.IsZero())
+
+	_, err = conn.DataExports().GetPending(accountId)
+	s.Require().ErrorAs(err, &errs.DataExportNotFound{})
+
+	// finished export does not block a new one
+	export, err = conn.DataExports().New(secondId, accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(secondId, export.Id)
+
+	count, err := conn.DataExports().CountCreatedWithin(accountId, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal(2, count)
+
+	// only finished exports expire
+	expired, err := conn.DataExports().DeleteFinishedBefore(-time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal([]models.DataExportId{firstId}, expired)
+
+	expired, err = conn.DataExports().DeleteFinishedBefore(time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Empty(expired)
+
+	deleted, err := conn.DataExports().DeleteAll(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal([]models.DataExportId{secondId}, deleted)
+
+	_, err = conn.DataExports().Get(firstId)
+	s.Require().ErrorAs(err, &errs.DataExportNotFound{})
+
+	pending, err = conn.DataExports().ClaimPending(10)
+	s.Require().NoError(err)
+	s.Require().Len(pending, 1)
+	s.Assert().Equal(otherId, pending[0].Id)
+}
+
+func (s *testSuite) TestDataExportsClaimPending() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	firstId := models.DataExportId("3e8de5c7-9c7c-4e4f-afb1-4c5d6e7f8091")
+	secondId := models.DataExportId("4f9ef6d8-ad8d-4f5a-b0c2-5d6e7f8091a2")
+
+	_, err = conn.DataExports().New(firstId, models.AccountId(333))
+	s.Require().NoError(err)
+	_, err = conn.DataExports().New(secondId, models.AccountId(444))
+	s.Require().NoError(err)
+
+	firstTx, err := s.db.BeginTransaction(ctx)
+	s.Require().NoError(err)
+	defer firstTx.Close()
+
+	claimed, err := firstTx.DataExports().ClaimPending(1)
+	s.Require().NoError(err)
+	s.Require().Len(claimed, 1)
+	s.Assert().Equal(firstId, claimed[0].Id)
+
+	// export claimed by another transaction is skipped
+	secondTx, err := s.db.BeginTransaction(ctx)
+	s.Require().NoError(err)
+	defer secondTx.Close()
+
+	claimed, err = secondTx.DataExports().ClaimPending(10)
+	s.Require().NoError(err)
+	s.Require().Len(claimed, 1)
+	s.Assert().Equal(secondId, claimed[0].Id)
+
+	s.Require().NoError(firstTx.DataExports().Finish(firstId, models.DATA_EXPORT_READY))
+	s.Require().NoError(firstTx.Commit())
+	s.Require().NoError(secondTx.Commit())
+}
@@ -2,7 +2,6 @@ package errs
 
 type ProfileNotFound struct{}
 type TokenNotFound struct{}
-type PasswordsDoNotMatch struct{}
 type AccountNotFound struct{}
 type UserIdNotFound struct{}
 
@@ -14,10 +13,6 @@ func (TokenNotFound) Error() string {
 	return "token not found"
 }
 
-func (PasswordsDoNotMatch) Error() string {
-	return "passwords do not match"
-}
-
 func (AccountNotFound) Error() string {
 	return "account not found"
 }
@@ -25,3 +20,88 @@ func (AccountNotFound) Error() string {
 func (UserIdNotFound) Error() string {
 	return "user id not found"
 }
+
+type ResetCodeNotFound struct{}
+
+func (ResetCodeNotFound) Error() string {
+	return "password reset code not found"
+}
+
+type VerificationCodeNotFound struct{}
+type ContactNotFound struct{}
+type ContactAlreadyUsed struct{}
+
+func (VerificationCodeNotFound) Error() string {
+	return "verification code not found"
+}
+
+func (ContactNotFound) Error() string {
+	return "contact not found"
+}
+
+func (ContactAlreadyUsed) Error() string {
+	return "contact is already used by another account"
+}
+
+type TotpSecretNotFound struct{}
+type SecondFactorChallengeNotFound struct{}
+
+func (TotpSecretNotFound) Error() string {
+	return "totp secret not found"
+}
+
+func (SecondFactorChallengeNotFound) Error() string {
+	return "second factor challenge not found"
+}
+
+type SessionNotFound struct{}
+
+func (SessionNotFound) Error() string {
+	return "session not found"
+}
+
+type InvalidPagiToken struct{}
+
+func (InvalidPagiToken) Error() string {
+	return "invalid page token"
+}
+
+type DataExportNotFound struct{}
+
+func (DataExportNotFound) Error() string {
+	return "data export not found"
+}
+
+// Pending export of the account kept changing while a new one was requested
+type DataExportConflict struct{}
+
+func (DataExportConflict) Error() string {
+	return "data export is being requested concurrently, retry later"
+}
+
+type OAuthClientNotFound struct{}
+type OAuthCodeNotFound struct{}
+
+func (OAuthClientNotFound) Error() string {
+	return "oauth client not found"
+}
+
+func (OAuthCodeNotFound) Error() string {
+	return "oauth authorization code not found"
+}
+
+type OidcIdentityNotFound struct{}
+type OidcIdentityAlreadyLinked struct{}
+type OidcLoginStateNotFound struct{}
+
+func (OidcIdentityNotFound) Error() string {
+	return "external identity is not linked"
+}
+
+func (OidcIdentityAlreadyLinked) Error() string {
+	return "external identity or provider is already linked"
+}
+
+func (OidcLoginStateNotFound) Error() string {
+	return "oidc login state not found"
+}
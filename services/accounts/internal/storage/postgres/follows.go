@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+)
+
+const FOLLOWS_PAGE_SIZE = 20
+
+type followsRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r followsRepo) Follow(follower models.AccountId, followee models.AccountId) (bool, error) {
+	sql := `
+	WITH cte AS (
+		INSERT INTO follows(follower_account_id, followee_account_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.changed(sql, follower, followee)
+}
+
+func (r followsRepo) Unfollow(follower models.AccountId, followee models.AccountId) (bool, error) {
+	sql := `
+	WITH cte AS (
+		DELETE FROM follows
+		WHERE follower_account_id = $1 AND followee_account_id = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.changed(sql, follower, followee)
+}
+
+func (r followsRepo) ListFollowers(accountId models.AccountId, token repo.PagiToken) (repo.ProfileCardsPage, error) {
+	sql := fmt.Sprintf(`
+	SELECT f.created_at, p.account_id, p.profile_id, p.name, p.surname
+	FROM follows f
+	JOIN profiles p ON p.account_id = f.follower_account_id
+	WHERE
+		f.followee_account_id = $1
+		AND ($2::TIMESTAMPTZ IS NULL OR (f.created_at, f.follower_account_id) < ($2, $3))
+	ORDER BY f.created_at DESC, f.follower_account_id DESC
+	LIMIT %d;
+	`, FOLLOWS_PAGE_SIZE)
+
+	return listProfileCardsPage(r.ctx, r.scope, sql, FOLLOWS_PAGE_SIZE, accountId, token)
+}
+
+func (r followsRepo) ListFollowing(accountId models.AccountId, token repo.PagiToken) (repo.ProfileCardsPage, error) {
+	sql := fmt.Sprintf(`
+	SELECT f.created_at, p.account_id, p.profile_id, p.name, p.surname
+	FROM follows f
+	JOIN profiles p ON p.account_id = f.followee_account_id
+	WHERE
+		f.follower_account_id = $1
+		AND ($2::TIMESTAMPTZ IS NULL OR (f.created_at, f.followee_account_id) < ($2, $3))
+	ORDER BY f.created_at DESC, f.followee_account_id DESC
+	LIMIT %d;
+	`, FOLLOWS_PAGE_SIZE)
+
+	return listProfileCardsPage(r.ctx, r.scope, sql, FOLLOWS_PAGE_SIZE, accountId, token)
+}
+
+func (r followsRepo) ListFriends(accountId models.AccountId, token repo.PagiToken) (repo.ProfileCardsPage, error) {
+	sql := fmt.Sprintf(`
+	SELECT f.created_at, p.account_id, p.profile_id, p.name, p.surname
+	FROM follows f
+	JOIN follows back ON back.follower_account_id = f.followee_account_id AND back.followee_account_id = f.follower_account_id
+	JOIN profiles p ON p.account_id = f.followee_account_id
+	WHERE
+		f.follower_account_id = $1
+		AND ($2::TIMESTAMPTZ IS NULL OR (f.created_at, f.followee_account_id) < ($2, $3))
+	ORDER BY f.created_at DESC, f.followee_account_id DESC
+	LIMIT %d;
+	`, FOLLOWS_PAGE_SIZE)
+
+	return listProfileCardsPage(r.ctx, r.scope, sql, FOLLOWS_PAGE_SIZE, accountId, token)
+}
+
+func (r followsRepo) Counts(accountId models.AccountId) (models.FollowCounts, error) {
+	sql := `
+	SELECT
+		(SELECT count(*) FROM follows WHERE followee_account_id = $1),
+		(SELECT count(*) FROM follows WHERE follower_account_id = $1);
+	`
+
+	var counts models.FollowCounts
+	err := r.scope.QueryRow(r.ctx, sql, accountId).Scan(&counts.Followers, &counts.Following)
+	if err != nil {
+		return models.FollowCounts{}, err
+	}
+
+	return counts, nil
+}
+
+func (r followsRepo) CountsMany(accountIds []models.AccountId) (map[models.AccountId]models.FollowCounts, error) {
+	sql := `
+	SELECT account_id, sum(followers), sum(following)
+	FROM (
+		SELECT followee_account_id AS account_id, 1 AS followers, 0 AS following
+		FROM follows
+		WHERE followee_account_id = ANY($1)
+		UNION ALL
+		SELECT follower_account_id, 0, 1
+		FROM follows
+		WHERE follower_account_id = ANY($1)
+	) AS f
+	GROUP BY account_id;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[models.AccountId]models.FollowCounts, len(accountIds))
+	for rows.Next() {
+		var (
+			accountId int
+			c         models.FollowCounts
+		)
+		err := rows.Scan(&accountId, &c.Followers, &c.Following)
+		if err != nil {
+			return nil, err
+		}
+		counts[models.AccountId(accountId)] = c
+	}
+
+	return counts, rows.Err()
+}
+
+func (r followsRepo) DeleteAll(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM follows
+	WHERE follower_account_id = $1 OR followee_account_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
+
+func (r followsRepo) changed(sql string, args ...any) (bool, error) {
+	var cnt int
+	err := r.scope.QueryRow(r.ctx, sql, args...).Scan(&cnt)
+	if err != nil {
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func (s *testSuite) newTestAccountWithProfile(conn repo.Connection, i int) (models.AccountId, models.ProfileId) {
+	registrationData := models.RegistrationData{
+		Login:        fmt.Sprintf("user_%d", i),
+		PasswordHash: "password_hash",
+		Email:        fmt.Sprintf("user%d@mail.com", i),
+		PhoneNumber:  fmt.Sprintf("+7100000%04d", i),
+		Name:         "name",
+		Surname:      "surname",
+	}
+
+	accountId, err := conn.Accounts().New(registrationData)
+	s.Require().NoError(err)
+
+	profileId := models.ProfileId(uuid.NewString())
+	err = conn.Profiles().New(profileId, accountId, registrationData)
+	s.Require().NoError(err)
+
+	return accountId, profileId
+}
+
+func (s *testSuite) TestFollowsSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+
+	alice, aliceProfile := s.newTestAccountWithProfile(conn, 0)
+	bob, bobProfile := s.newTestAccountWithProfile(conn, 1)
+	carol, carolProfile := s.newTestAccountWithProfile(conn, 2)
+
+	changed, err := conn.Follows().Follow(alice, bob)
+	s.Require().NoError(err)
+	s.Assert().True(changed)
+
+	changed, err = conn.Follows().Follow(alice, bob)
+	s.Require().NoError(err)
+	s.Assert().False(changed)
+
+	_, err = conn.Follows().Follow(bob, alice)
+	s.Require().NoError(err)
+	_, err = conn.Follows().Follow(carol, alice)
+	s.Require().NoError(err)
+
+	counts, err := conn.Follows().Counts(alice)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.FollowCounts{Followers: 2, Following: 1}, counts)
+
+	manyCounts, err := conn.Follows().CountsMany([]models.AccountId{alice, bob, carol, -1})
+	s.Require().NoError(err)
+	s.Assert().Equal(map[models.AccountId]models.FollowCounts{
+		alice: {Followers: 2, Following: 1},
+		bob:   {Followers: 1, Following: 1},
+		carol: {Followers: 0, Following: 1},
+	}, manyCounts)
+
+	followers, err := conn.Follows().ListFollowers(alice, "")
+	s.Require().NoError(err)
+	s.Require().Len(followers.Profiles, 2)
+	s.Assert().Equal(carolProfile, followers.Profiles[0].ProfileId)
+	s.Assert().Equal(bobProfile, followers.Profiles[1].ProfileId)
+	s.Assert().Empty(followers.NextPagiToken)
+
+	following, err := conn.Follows().ListFollowing(bob, "")
+	s.Require().NoError(err)
+	s.Require().Len(following.Profiles, 1)
+	s.Assert().Equal(aliceProfile, following.Profiles[0].ProfileId)
+
+	friends, err := conn.Follows().ListFriends(alice, "")
+	s.Require().NoError(err)
+	s.Require().Len(friends.Profiles, 1)
+	s.Assert().Equal(bobProfile, friends.Profiles[0].ProfileId)
+
+	changed, err = conn.Follows().Unfollow(bob, alice)
+	s.Require().NoError(err)
+	s.Assert().True(changed)
+
+	changed, err = conn.Follows().Unfollow(bob, alice)
+	s.Require().NoError(err)
+	s.Assert().False(changed)
+
+	friends, err = conn.Follows().ListFriends(alice, "")
+	s.Require().NoError(err)
+	s.Assert().Empty(friends.Profiles)
+
+	err = conn.Follows().DeleteAll(alice)
+	s.Require().NoError(err)
+
+	counts, err = conn.Follows().Counts(alice)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.FollowCounts{}, counts)
+}
+
+func (s *testSuite) TestFollowsPagination() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+
+	followee, _ := s.newTestAccountWithProfile(conn, 0)
+
+	const followers_count = FOLLOWS_PAGE_SIZE*2 + 3
+	for i := range followers_count {
+		follower, _ := s.newTestAccountWithProfile(conn, i+1)
+		_, err := conn.Follows().Follow(follower, followee)
+		s.Require().NoError(err)
+	}
+
+	seen := map[models.ProfileId]bool{}
+	var token repo.PagiToken
+	pages := 0
+	for {
+		page, err := conn.Follows().ListFollowers(followee, token)
+		s.Require().NoError(err)
+		pages++
+
+		for _, card := range page.Profiles {
+			s.Assert().False(seen[card.ProfileId], "profile %s is listed twice", card.ProfileId)
+			seen[card.ProfileId] = true
+		}
+
+		token = page.NextPagiToken
+		if token == "" {
+			break
+		}
+	}
+
+	s.Assert().Equal(3, pages)
+	s.Assert().Len(seen, followers_count)
+}
@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type oauthClientsRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r oauthClientsRepo) Create(clientId models.OAuthClientId, params models.OAuthClientParams) (models.OAuthClient, error) {
+	sql := `
+	INSERT INTO oauth_clients(client_id, owner_account_id, name, redirect_uris)
+	VALUES ($1, $2, $3, $4)
+	RETURNING created_at;
+	`
+
+	client := models.OAuthClient{
+		OAuthClientParams: params,
+		ClientId:          clientId,
+	}
+	row := r.scope.QueryRow(r.ctx, sql, clientId, params.OwnerAccountId, params.Name, params.RedirectUris)
+	err := row.Scan(&client.CreatedAt)
+	if err != nil {
+		return models.OAuthClient{}, err
+	}
+
+	return client, nil
+}
+
+func (r oauthClientsRepo) Get(clientId models.OAuthClientId) (models.OAuthClient, error) {
+	sql := `
+	SELECT client_id, owner_account_id, name, redirect_uris, created_at
+	FROM oauth_clients
+	WHERE client_id = $1;
+	`
+
+	client, err := scanOAuthClient(r.scope.QueryRow(r.ctx, sql, clientId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OAuthClient{}, errs.OAuthClientNotFound{}
+		}
+
+		return models.OAuthClient{}, err
+	}
+
+	return client, nil
+}
+
+func (r oauthClientsRepo) ListByOwner(owner models.AccountId) ([]models.OAuthClient, error) {
+	sql := `
+	SELECT client_id, owner_account_id, name, redirect_uris, created_at
+	FROM oauth_clients
+	WHERE owner_account_id = $1
+	ORDER BY created_at DESC;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := make([]models.OAuthClient, 0)
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+func (r oauthClientsRepo) Delete(owner models.AccountId, clientId models.OAuthClientId) error {
+	sql := `
+	WITH affected_rows AS (
+		DELETE FROM oauth_clients
+		WHERE client_id = $1 AND owner_account_id = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM affected_rows;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, clientId, owner)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.OAuthClientNotFound{}
+	}
+
+	return nil
+}
+
+func scanOAuthClient(row pgx.Row) (models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := row.Scan(&client.ClientId, &client.OwnerAccountId, &client.Name, &client.RedirectUris, &client.CreatedAt)
+	if err != nil {
+		return models.OAuthClient{}, err
+	}
+
+	return client, nil
+}
+
+type oauthCodesRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+// Expired codes are cleaned up by later authorizations, used ones are kept
+// until expiration so that replay is detected
+func (r oauthCodesRepo) Put(hash models.OAuthCodeHash, params models.OAuthCodeParams, ttl time.Duration) error {
+	sql := `
+	WITH expired AS (
+		DELETE FROM oauth_codes
+		WHERE valid_until < NOW()
+	)
+	INSERT INTO oauth_codes(code_hash, client_id, account_id, redirect_uri, scopes, code_challenge, valid_until)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW() + $7);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, hash, params.ClientId, params.AccountId, params.RedirectUri, soatoken.ScopesToStrings(params.Scopes), params.CodeChallenge, ttl)
+	return err
+}
+
+func (r oauthCodesRepo) Get(hash models.OAuthCodeHash) (models.OAuthCodeData, error) {
+	sql := `
+	SELECT id, client_id, account_id, redirect_uri, scopes, code_challenge, is_used, valid_until
+	FROM oauth_codes
+	WHERE code_hash = $1
+	FOR UPDATE;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, hash)
+
+	var (
+		data   models.OAuthCodeData
+		scopes []string
+	)
+	err := row.Scan(&data.Id, &data.ClientId, &data.AccountId, &data.RedirectUri, &scopes, &data.CodeChallenge, &data.IsUsed, &data.ValidUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OAuthCodeData{}, errs.OAuthCodeNotFound{}
+		}
+
+		return models.OAuthCodeData{}, err
+	}
+	data.Scopes = scopesFromDb(scopes)
+
+	return data, nil
+}
+
+func (r oauthCodesRepo) MarkUsed(id models.OAuthCodeId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE oauth_codes
+		SET is_used = TRUE
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.OAuthCodeNotFound{}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+func (s *testSuite) TestOAuthClients() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	params := models.OAuthClientParams{
+		OwnerAccountId: 131,
+		Name:           "Partner app",
+		RedirectUris:   []string{"https://partner.example/callback", "http://localhost:8080/callback"},
+	}
+	created, err := conn.OAuthClients().Create("client_1", params)
+	s.Require().NoError(err)
+	s.Assert().False(created.CreatedAt.IsZero())
+
+	client, err := conn.OAuthClients().Get("client_1")
+	s.Require().NoError(err)
+	s.Assert().Equal(params, client.OAuthClientParams)
+	s.Assert().Equal(models.OAuthClientId("client_1"), client.ClientId)
+
+	_, err = conn.OAuthClients().Create("client_2", models.OAuthClientParams{
+		OwnerAccountId: 132,
+		Name:           "Other app",
+		RedirectUris:   []string{"https://other.example/callback"},
+	})
+	s.Require().NoError(err)
+
+	clients, err := conn.OAuthClients().ListByOwner(131)
+	s.Require().NoError(err)
+	s.Require().Len(clients, 1)
+	s.Assert().Equal(models.OAuthClientId("client_1"), clients[0].ClientId)
+
+	// only owner can delete client
+	err = conn.OAuthClients().Delete(132, "client_1")
+	s.Assert().ErrorAs(err, &errs.OAuthClientNotFound{})
+
+	err = conn.OAuthClients().Delete(131, "client_1")
+	s.Require().NoError(err)
+
+	_, err = conn.OAuthClients().Get("client_1")
+	s.Assert().ErrorAs(err, &errs.OAuthClientNotFound{})
+}
+
+func (s *testSuite) TestOAuthCodes() {
+	ctx := context.Background()
+	tx, err := s.db.BeginTransaction(ctx)
+	s.Require().NoError(err)
+	defer tx.Close()
+
+	params := models.OAuthCodeParams{
+		ClientId:      "client",
+		AccountId:     133,
+		RedirectUri:   "https://partner.example/callback",
+		Scopes:        []soatoken.Scope{soatoken.SCOPE_POSTS_READ},
+		CodeChallenge: "challenge",
+	}
+	err = tx.OAuthCodes().Put("code_hash", params, time.Minute)
+	s.Require().NoError(err)
+
+	code, err := tx.OAuthCodes().Get("code_hash")
+	s.Require().NoError(err)
+	s.Assert().Equal(params, code.OAuthCodeParams)
+	s.Assert().False(code.IsUsed)
+	s.Assert().True(code.ValidUntil.After(time.Now()))
+
+	err = tx.OAuthCodes().MarkUsed(code.Id)
+	s.Require().NoError(err)
+
+	// used codes are still returned, so that replay is detected
+	code, err = tx.OAuthCodes().Get("code_hash")
+	s.Require().NoError(err)
+	s.Assert().True(code.IsUsed)
+
+	_, err = tx.OAuthCodes().Get("unknown_hash")
+	s.Assert().ErrorAs(err, &errs.OAuthCodeNotFound{})
+
+	// expired codes are deleted by later authorizations
+	err = tx.OAuthCodes().Put("expired_hash", params, -time.Minute)
+	s.Require().NoError(err)
+	err = tx.OAuthCodes().Put("other_hash", params, time.Minute)
+	s.Require().NoError(err)
+
+	_, err = tx.OAuthCodes().Get("expired_hash")
+	s.Assert().ErrorAs(err, &errs.OAuthCodeNotFound{})
+
+	s.Require().NoError(tx.Commit())
+}
+
+func (s *testSuite) TestAuthorizedApps() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	for _, clientId := range []models.OAuthClientId{"app_1", "app_2"} {
+		_, err = conn.OAuthClients().Create(clientId, models.OAuthClientParams{
+			OwnerAccountId: 140,
+			Name:           string(clientId),
+			RedirectUris:   []string{"https://partner.example/callback"},
+		})
+		s.Require().NoError(err)
+	}
+
+	put := func(hash string, accountId int, clientId opt.Option[models.OAuthClientId], scopes ...soatoken.Scope) {
+		_, _, err := conn.ApiTokens().Put(models.ApiTokenHash(hash), repo.ApiTokenParams{
+			AccountId: accountId,
+			Name:      "token",
+			Scopes:    scopes,
+			Ttl:       time.Hour,
+			ClientId:  clientId,
+		})
+		s.Require().NoError(err)
+	}
+
+	put("app_1_read", 141, opt.Some[models.OAuthClientId]("app_1"), soatoken.SCOPE_POSTS_READ)
+	put("app_1_write", 141, opt.Some[models.OAuthClientId]("app_1"), soatoken.SCOPE_POSTS_READ, soatoken.SCOPE_POSTS_WRITE)
+	put("app_2", 141, opt.Some[models.OAuthClientId]("app_2"), soatoken.SCOPE_ACCOUNT_READ)
+	put("app_1_other_account", 142, opt.Some[models.OAuthClientId]("app_1"), soatoken.SCOPE_POSTS_READ)
+	put("own", 141, opt.None[models.OAuthClientId](), soatoken.SCOPE_POSTS_READ)
+
+	apps, err := conn.ApiTokens().ListAuthorizedApps(141)
+	s.Require().NoError(err)
+	s.Require().Len(apps, 2)
+
+	appsById := make(map[models.OAuthClientId]models.AuthorizedApp)
+	for _, app := range apps {
+		appsById[app.ClientId] = app
+	}
+	s.Assert().ElementsMatch([]soatoken.Scope{soatoken.SCOPE_POSTS_READ, soatoken.SCOPE_POSTS_WRITE}, appsById["app_1"].Scopes)
+	s.Assert().Equal("app_1", appsById["app_1"].Name)
+	s.Assert().False(appsById["app_1"].LastUsedAt.HasValue)
+
+	revoked, err := conn.ApiTokens().RevokeByClient(141, "app_1")
+	s.Require().NoError(err)
+	s.Assert().Equal(2, revoked)
+
+	apps, err = conn.ApiTokens().ListAuthorizedApps(141)
+	s.Require().NoError(err)
+	s.Require().Len(apps, 1)
+	s.Assert().Equal(models.OAuthClientId("app_2"), apps[0].ClientId)
+
+	accountIds, err := conn.ApiTokens().RevokeAllOfClient("app_1")
+	s.Require().NoError(err)
+	s.Assert().Equal([]models.AccountId{142}, accountIds)
+
+	tokenData, err := conn.ApiTokens().Get("own")
+	s.Require().NoError(err)
+	s.Assert().False(tokenData.IsRevoked)
+}
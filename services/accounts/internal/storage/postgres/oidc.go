@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type oidcIdentitiesRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r oidcIdentitiesRepo) GetBySubject(provider string, subject string) (models.OidcIdentity, error) {
+	sql := `
+	SELECT account_id, provider, subject, email, created_at, last_login_at
+	FROM oidc_identities
+	WHERE provider = $1 AND subject = $2;
+	`
+
+	identity, err := scanOidcIdentity(r.scope.QueryRow(r.ctx, sql, provider, subject))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OidcIdentity{}, errs.OidcIdentityNotFound{}
+		}
+
+		return models.OidcIdentity{}, err
+	}
+
+	return identity, nil
+}
+
+func (r oidcIdentitiesRepo) ListByAccount(accountId models.AccountId) ([]models.OidcIdentity, error) {
+	sql := `
+	SELECT account_id, provider, subject, email, created_at, last_login_at
+	FROM oidc_identities
+	WHERE account_id = $1
+	ORDER BY provider;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := make([]models.OidcIdentity, 0)
+	for rows.Next() {
+		identity, err := scanOidcIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func (r oidcIdentitiesRepo) Link(params models.OidcIdentityParams) error {
+	sql := `
+	INSERT INTO oidc_identities(account_id, provider, subject, email)
+	VALUES ($1, $2, $3, NULLIF($4, ''));
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, params.AccountId, params.Provider, params.Subject, params.Email)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pg_unique_violation_code {
+		return errs.OidcIdentityAlreadyLinked{}
+	}
+
+	return err
+}
+
+func (r oidcIdentitiesRepo) TouchLogin(provider string, subject string) error {
+	sql := `
+	WITH cte AS (
+		UPDATE oidc_identities
+		SET last_login_at = NOW()
+		WHERE provider = $1 AND subject = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.affectOne(sql, provider, subject)
+}
+
+func (r oidcIdentitiesRepo) Unlink(accountId models.AccountId, provider string) error {
+	sql := `
+	WITH cte AS (
+		DELETE FROM oidc_identities
+		WHERE account_id = $1 AND provider = $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.affectOne(sql, accountId, provider)
+}
+
+func (r oidcIdentitiesRepo) DeleteAll(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM oidc_identities
+	WHERE account_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
+
+func (r oidcIdentitiesRepo) affectOne(sql string, args ...any) error {
+	row := r.scope.QueryRow(r.ctx, sql, args...)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.OidcIdentityNotFound{}
+	}
+
+	return nil
+}
+
+func scanOidcIdentity(row pgx.Row) (models.OidcIdentity, error) {
+	var (
+		identity    models.OidcIdentity
+		email       *string
+		lastLoginAt pgtype.Timestamptz
+	)
+	err := row.Scan(&identity.AccountId, &identity.Provider, &identity.Subject, &email, &identity.CreatedAt, &lastLoginAt)
+	if err != nil {
+		return models.OidcIdentity{}, err
+	}
+
+	if email != nil {
+		identity.Email = *email
+	}
+	if lastLoginAt.Valid {
+		identity.LastLoginAt = opt.Some(lastLoginAt.Time)
+	}
+
+	return identity, nil
+}
+
+type oidcLoginStatesRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+// Abandoned logins are cleaned up by later ones
+func (r oidcLoginStatesRepo) Put(hash models.OidcLoginStateHash, params models.OidcLoginStateParams, ttl time.Duration) error {
+	sql := `
+	WITH expired AS (
+		DELETE FROM oidc_login_states
+		WHERE valid_until < NOW()
+	)
+	INSERT INTO oidc_login_states(state_hash, provider, nonce, code_verifier, link_account_id, binding_hash, valid_until)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW() + $7);
+	`
+
+	linkAccountId := pgtype.Int4{
+		Int32: int32(params.LinkAccountId.Value),
+		Valid: params.LinkAccountId.HasValue,
+	}
+
+	_, err := r.scope.Exec(r.ctx, sql, hash, params.Provider, params.Nonce, params.CodeVerifier, linkAccountId, params.BindingHash, ttl)
+	return err
+}
+
+func (r oidcLoginStatesRepo) Take(hash models.OidcLoginStateHash) (models.OidcLoginState, error) {
+	sql := `
+	DELETE FROM oidc_login_states
+	WHERE state_hash = $1
+	RETURNING provider, nonce, code_verifier, link_account_id, binding_hash, valid_until;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, hash)
+
+	var (
+		state         models.OidcLoginState
+		linkAccountId pgtype.Int4
+	)
+	err := row.Scan(&state.Provider, &state.Nonce, &state.CodeVerifier, &linkAccountId, &state.BindingHash, &state.ValidUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OidcLoginState{}, errs.OidcLoginStateNotFound{}
+		}
+
+		return models.OidcLoginState{}, err
+	}
+
+	if linkAccountId.Valid {
+		state.LinkAccountId = opt.Some(models.AccountId(linkAccountId.Int32))
+	}
+
+	return state, nil
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+func (s *testSuite) TestOidcIdentities() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	params := models.OidcIdentityParams{
+		AccountId: 141,
+		Provider:  "corp",
+		Subject:   "subject_1",
+		Email:     "user@corp.example",
+	}
+	err = conn.OidcIdentities().Link(params)
+	s.Require().NoError(err)
+
+	identity, err := conn.OidcIdentities().GetBySubject("corp", "subject_1")
+	s.Require().NoError(err)
+	s.Assert().Equal(params, identity.OidcIdentityParams)
+	s.Assert().False(identity.LastLoginAt.HasValue)
+
+	_, err = conn.OidcIdentities().GetBySubject("other", "subject_1")
+	s.Assert().ErrorAs(err, &errs.OidcIdentityNotFound{})
+
+	// subject belongs to one account, account has one subject per provider
+	err = conn.OidcIdentities().Link(models.OidcIdentityParams{AccountId: 142, Provider: "corp", Subject: "subject_1"})
+	s.Assert().ErrorAs(err, &errs.OidcIdentityAlreadyLinked{})
+
+	err = conn.OidcIdentities().Link(models.OidcIdentityParams{AccountId: 141, Provider: "corp", Subject: "subject_2"})
+	s.Assert().ErrorAs(err, &errs.OidcIdentityAlreadyLinked{})
+
+	err = conn.OidcIdentities().Link(models.OidcIdentityParams{AccountId: 141, Provider: "other", Subject: "subject_1"})
+	s.Require().NoError(err)
+
+	err = conn.OidcIdentities().TouchLogin("corp", "subject_1")
+	s.Require().NoError(err)
+
+	identities, err := conn.OidcIdentities().ListByAccount(141)
+	s.Require().NoError(err)
+	s.Require().Len(identities, 2)
+	s.Assert().Equal("corp", identities[0].Provider)
+	s.Assert().True(identities[0].LastLoginAt.HasValue)
+	s.Assert().Equal("other", identities[1].Provider)
+	s.Assert().Empty(identities[1].Email)
+
+	err = conn.OidcIdentities().Unlink(142, "corp")
+	s.Assert().ErrorAs(err, &errs.OidcIdentityNotFound{})
+
+	err = conn.OidcIdentities().Unlink(141, "corp")
+	s.Require().NoError(err)
+
+	_, err = conn.OidcIdentities().GetBySubject("corp", "subject_1")
+	s.Assert().ErrorAs(err, &errs.OidcIdentityNotFound{})
+
+	err = conn.OidcIdentities().DeleteAll(141)
+	s.Require().NoError(err)
+
+	identities, err = conn.OidcIdentities().ListByAccount(141)
+	s.Require().NoError(err)
+	s.Assert().Empty(identities)
+}
+
+func (s *testSuite) TestOidcLoginStates() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	params := models.OidcLoginStateParams{
+		Provider:      "corp",
+		Nonce:         "nonce",
+		CodeVerifier:  "verifier",
+		LinkAccountId: opt.Some(models.AccountId(151)),
+		BindingHash:   "binding_hash",
+	}
+	err = conn.OidcLoginStates().Put("state_hash", params, time.Minute)
+	s.Require().NoError(err)
+
+	err = conn.OidcLoginStates().Put("expired_hash", models.OidcLoginStateParams{
+		Provider:     "corp",
+		Nonce:        "nonce",
+		CodeVerifier: "verifier",
+	}, -time.Minute)
+	s.Require().NoError(err)
+
+	state, err := conn.OidcLoginStates().Take("state_hash")
+	s.Require().NoError(err)
+	s.Assert().Equal(params, state.OidcLoginStateParams)
+	s.Assert().True(state.ValidUntil.After(time.Now()))
+
+	// states are single-use
+	_, err = conn.OidcLoginStates().Take("state_hash")
+	s.Assert().ErrorAs(err, &errs.OidcLoginStateNotFound{})
+
+	state, err = conn.OidcLoginStates().Take("expired_hash")
+	s.Require().NoError(err)
+	s.Assert().False(state.LinkAccountId.HasValue)
+	s.Assert().True(state.ValidUntil.Before(time.Now()))
+
+	// expired states are deleted by later logins
+	err = conn.OidcLoginStates().Put("expired_hash", models.OidcLoginStateParams{Provider: "corp"}, -time.Minute)
+	s.Require().NoError(err)
+	err = conn.OidcLoginStates().Put("other_hash", models.OidcLoginStateParams{Provider: "corp"}, time.Minute)
+	s.Require().NoError(err)
+
+	_, err = conn.OidcLoginStates().Take("expired_hash")
+	s.Assert().ErrorAs(err, &errs.OidcLoginStateNotFound{})
+}
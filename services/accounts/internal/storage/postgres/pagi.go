@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+)
+
+func decodePagiToken[DecodedToken any](encoded repo.PagiToken) (decoded DecodedToken, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(string(encoded))
+	if err != nil {
+		err = errs.InvalidPagiToken{}
+		return
+	}
+
+	err = json.Unmarshal(raw, &decoded)
+	if err != nil {
+		err = errs.InvalidPagiToken{}
+	}
+	return
+}
+
+func encodePagiToken[EncodedToken any](token EncodedToken) (repo.PagiToken, error) {
+	raw, err := json.Marshal(&token)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(raw)
+	return repo.PagiToken(encoded), nil
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type passwordResetCodesRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r passwordResetCodesRepo) Put(accountId models.AccountId, codeHash models.PasswordResetCodeHash, ttl time.Duration) error {
+	sql := `
+	INSERT INTO password_reset_codes(account_id, code_hash, valid_until)
+	VALUES ($1, $2, NOW() + $3);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId, codeHash, ttl)
+	return err
+}
+
+func (r passwordResetCodesRepo) GetActive(accountId models.AccountId) (models.PasswordResetCodeData, error) {
+	sql := `
+	SELECT id, account_id, code_hash, attempts, valid_until
+	FROM password_reset_codes
+	WHERE account_id = $1 AND NOT is_used AND valid_until > NOW()
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1
+	FOR UPDATE;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, accountId)
+
+	var (
+		data     models.PasswordResetCodeData
+		codeHash string
+	)
+	err := row.Scan(&data.Id, &data.AccountId, &codeHash, &data.Attempts, &data.ValidUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PasswordResetCodeData{}, errs.ResetCodeNotFound{}
+		}
+
+		return models.PasswordResetCodeData{}, err
+	}
+	data.CodeHash = models.PasswordResetCodeHash(codeHash)
+
+	return data, nil
+}
+
+func (r passwordResetCodesRepo) IncrementAttempts(id models.PasswordResetCodeId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE password_reset_codes
+		SET attempts = attempts + 1
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id)
+}
+
+func (r passwordResetCodesRepo) MarkUsed(id models.PasswordResetCodeId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE password_reset_codes
+		SET is_used = TRUE
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id)
+}
+
+func (r passwordResetCodesRepo) InvalidateAll(accountId models.AccountId) error {
+	sql := `
+	UPDATE password_reset_codes
+	SET is_used = TRUE
+	WHERE account_id = $1 AND NOT is_used;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
+
+func (r passwordResetCodesRepo) CountIssuedWithin(accountId models.AccountId, period time.Duration) (int, error) {
+	lockSql := `
+	SELECT pg_advisory_xact_lock(hashtext('password_reset_codes'), $1);
+	`
+
+	_, err := r.scope.Exec(r.ctx, lockSql, accountId)
+	if err != nil {
+		return 0, err
+	}
+
+	sql := `
+	SELECT count(*)
+	FROM password_reset_codes
+	WHERE account_id = $1 AND created_at > NOW() - $2::INTERVAL;
+	`
+
+	var cnt int
+	err = r.scope.QueryRow(r.ctx, sql, accountId, period).Scan(&cnt)
+	return cnt, err
+}
+
+func (r passwordResetCodesRepo) updateOne(sql string, id models.PasswordResetCodeId) error {
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.ResetCodeNotFound{}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+)
+
+func (s *testSuite) TestPasswordResetCodesSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+
+	err = conn.PasswordResetCodes().Put(accountId, "old_code_hash", time.Hour)
+	s.Require().NoError(err)
+	err = conn.PasswordResetCodes().Put(accountId, "new_code_hash", time.Hour)
+	s.Require().NoError(err)
+
+	codeData, err := conn.PasswordResetCodes().GetActive(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(accountId, codeData.AccountId)
+	s.Assert().Equal(models.PasswordResetCodeHash("new_code_hash"), codeData.CodeHash)
+	s.Assert().Equal(0, codeData.Attempts)
+
+	err = conn.PasswordResetCodes().IncrementAttempts(codeData.Id)
+	s.Require().NoError(err)
+
+	codeData, err = conn.PasswordResetCodes().GetActive(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(1, codeData.Attempts)
+
+	err = conn.PasswordResetCodes().MarkUsed(codeData.Id)
+	s.Require().NoError(err)
+
+	codeData, err = conn.PasswordResetCodes().GetActive(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.PasswordResetCodeHash("old_code_hash"), codeData.CodeHash)
+
+	err = conn.PasswordResetCodes().InvalidateAll(accountId)
+	s.Require().NoError(err)
+
+	_, err = conn.PasswordResetCodes().GetActive(accountId)
+	s.Require().ErrorAs(err, &errs.ResetCodeNotFound{})
+}
+
+func (s *testSuite) TestPasswordResetCodesExpired() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+
+	err = conn.PasswordResetCodes().Put(accountId, "code_hash", -time.Second)
+	s.Require().NoError(err)
+
+	_, err = conn.PasswordResetCodes().GetActive(accountId)
+	s.Require().ErrorAs(err, &errs.ResetCodeNotFound{})
+}
+
+func (s *testSuite) TestPasswordResetCodesCountIssued() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(112)
+
+	cnt, err := conn.PasswordResetCodes().CountIssuedWithin(accountId, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal(0, cnt)
+
+	for range 3 {
+		err = conn.PasswordResetCodes().Put(accountId, "code_hash", time.Hour)
+		s.Require().NoError(err)
+	}
+
+	// used codes are counted as well
+	err = conn.PasswordResetCodes().InvalidateAll(accountId)
+	s.Require().NoError(err)
+
+	_, err = s.db.globalConn.Exec(ctx, `
+	UPDATE password_reset_codes
+	SET created_at = NOW() - INTERVAL '2 hours'
+	WHERE id = (SELECT min(id) FROM password_reset_codes WHERE account_id = $1);
+	`, accountId)
+	s.Require().NoError(err)
+
+	cnt, err = conn.PasswordResetCodes().CountIssuedWithin(accountId, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal(2, cnt)
+
+	cnt, err = conn.PasswordResetCodes().CountIssuedWithin(models.AccountId(113), time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal(0, cnt)
+}
@@ -89,6 +89,22 @@ func (d *testPgContainerDatabase) cleanup(t *testing.T) {
 		TRUNCATE TABLE accounts;
 		TRUNCATE TABLE profiles;
 		TRUNCATE TABLE api_tokens;
+		TRUNCATE TABLE oauth_clients;
+		TRUNCATE TABLE oauth_codes;
+		TRUNCATE TABLE oidc_identities;
+		TRUNCATE TABLE oidc_login_states;
+		TRUNCATE TABLE refresh_tokens;
+		TRUNCATE TABLE sessions;
+		TRUNCATE TABLE password_reset_codes;
+		TRUNCATE TABLE verification_codes;
+		TRUNCATE TABLE auth_failures;
+		TRUNCATE TABLE totp_secrets;
+		TRUNCATE TABLE backup_codes;
+		TRUNCATE TABLE second_factor_challenges;
+		TRUNCATE TABLE follows;
+		TRUNCATE TABLE blocks;
+		TRUNCATE TABLE data_exports;
+		TRUNCATE TABLE audit_events;
 		TRUNCATE TABLE outbox;
 	`)
 
@@ -153,6 +169,118 @@ func (p *testRepoProvider) ApiTokens() repo.ApiTokensRepo {
 	}
 }
 
+func (p *testRepoProvider) OAuthClients() repo.OAuthClientsRepo {
+	return oauthClientsRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) OAuthCodes() repo.OAuthCodesRepo {
+	return oauthCodesRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) OidcIdentities() repo.OidcIdentitiesRepo {
+	return oidcIdentitiesRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) OidcLoginStates() repo.OidcLoginStatesRepo {
+	return oidcLoginStatesRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) RefreshTokens() repo.RefreshTokensRepo {
+	return refreshTokensRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) Sessions() repo.SessionsRepo {
+	return sessionsRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) PasswordResetCodes() repo.PasswordResetCodesRepo {
+	return passwordResetCodesRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) VerificationCodes() repo.VerificationCodesRepo {
+	return verificationCodesRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) AuthFailures() repo.AuthFailuresRepo {
+	return authFailuresRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) TotpSecrets() repo.TotpSecretsRepo {
+	return totpSecretsRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) BackupCodes() repo.BackupCodesRepo {
+	return backupCodesRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) SecondFactorChallenges() repo.SecondFactorChallengesRepo {
+	return secondFactorChallengesRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) Follows() repo.FollowsRepo {
+	return followsRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) Blocks() repo.BlocksRepo {
+	return blocksRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) DataExports() repo.DataExportsRepo {
+	return dataExportsRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
+func (p *testRepoProvider) AuditLog() repo.AuditLogRepo {
+	return auditLogRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
 func (p *testRepoProvider) Outbox() repo.OutboxRepo {
 	return outboxRepo{
 		ctx:   context.Background(),
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type profileCardsPagiToken struct {
+	LastCreatedAt time.Time `json:"lca"`
+	LastAccountId int       `json:"lid"`
+}
+
+// Queries page of profile cards by sql with parameters: account id, created_at and
+// account id of the last card of the previous page (null created_at for the first page).
+// Rows must be (created_at, account_id, profile_id, name, surname).
+func listProfileCardsPage(ctx context.Context, scope pgxScope, sql string, pageSize int, accountId models.AccountId, encodedPagiToken repo.PagiToken) (repo.ProfileCardsPage, error) {
+	var (
+		pgLastCreatedAt pgtype.Timestamptz
+		lastAccountId   int
+	)
+	if encodedPagiToken != "" {
+		pagiToken, err := decodePagiToken[profileCardsPagiToken](encodedPagiToken)
+		if err != nil {
+			return repo.ProfileCardsPage{}, err
+		}
+
+		pgLastCreatedAt = pgtype.Timestamptz{Time: pagiToken.LastCreatedAt, Valid: true}
+		lastAccountId = pagiToken.LastAccountId
+	}
+
+	rows, err := scope.Query(ctx, sql, accountId, pgLastCreatedAt, lastAccountId)
+	if err != nil {
+		return repo.ProfileCardsPage{}, err
+	}
+	defer rows.Close()
+
+	profiles := make([]models.ProfileCard, 0, pageSize)
+	var last profileCardsPagiToken
+	for rows.Next() {
+		var (
+			card      models.ProfileCard
+			profileId string
+		)
+		err := rows.Scan(&last.LastCreatedAt, &last.LastAccountId, &profileId, &card.Name, &card.Surname)
+		if err != nil {
+			return repo.ProfileCardsPage{}, err
+		}
+
+		card.ProfileId = models.ProfileId(profileId)
+		profiles = append(profiles, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return repo.ProfileCardsPage{}, err
+	}
+
+	var nextPagiToken repo.PagiToken
+	if len(profiles) == pageSize {
+		encodedToken, err := encodePagiToken(last)
+		if err != nil {
+			log.Printf("warning: cannot encode paginating token (%v): %v", last, err)
+		} else {
+			nextPagiToken = encodedToken
+		}
+	}
+
+	return repo.ProfileCardsPage{
+		Profiles:      profiles,
+		NextPagiToken: nextPagiToken,
+	}, nil
+}
@@ -3,10 +3,14 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"soa-socialnetwork/services/accounts/internal/models"
 	"soa-socialnetwork/services/accounts/internal/repo"
 	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	opt "soa-socialnetwork/services/common/option"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -20,7 +24,8 @@ type profilesRepo struct {
 
 func (r profilesRepo) GetByAccountId(id models.AccountId) (models.ProfileData, error) {
 	sql := `
-	SELECT profile_id, name, surname, birthday, bio
+	SELECT profile_id, name, surname, birthday, bio, avatar_image, cover_image,
+		birthday_visibility, bio_visibility, email_visibility, phone_number_visibility
 	FROM profiles
 	WHERE account_id = $1;
 	`
@@ -33,8 +38,12 @@ func (r profilesRepo) GetByAccountId(id models.AccountId) (models.ProfileData, e
 		surname    string
 		pgBirthday pgtype.Date
 		pgBio      pgtype.Text
+		pgAvatar   pgtype.Text
+		pgCover    pgtype.Text
+		privacy    models.PrivacySettings
 	)
-	err := row.Scan(&profileId, &name, &surname, &pgBirthday, &pgBio)
+	err := row.Scan(&profileId, &name, &surname, &pgBirthday, &pgBio, &pgAvatar, &pgCover,
+		&privacy.Birthday, &privacy.Bio, &privacy.Email, &privacy.PhoneNumber)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.ProfileData{}, errs.ProfileNotFound{}
@@ -60,12 +69,17 @@ func (r profilesRepo) GetByAccountId(id models.AccountId) (models.ProfileData, e
 		Surname:   surname,
 		Birthday:  birthday,
 		Bio:       bio,
+
+		AvatarImage: pgAvatar.String,
+		CoverImage:  pgCover.String,
+		Privacy:     privacy,
 	}, nil
 }
 
 func (r profilesRepo) GetByProfileId(id models.ProfileId) (models.ProfileData, error) {
 	sql := `
-	SELECT account_id, name, surname, birthday, bio
+	SELECT account_id, name, surname, birthday, bio, avatar_image, cover_image,
+		birthday_visibility, bio_visibility, email_visibility, phone_number_visibility
 	FROM profiles
 	WHERE profile_id = $1;
 	`
@@ -78,8 +92,12 @@ func (r profilesRepo) GetByProfileId(id models.ProfileId) (models.ProfileData, e
 		surname    string
 		pgBirthday pgtype.Date
 		pgBio      pgtype.Text
+		pgAvatar   pgtype.Text
+		pgCover    pgtype.Text
+		privacy    models.PrivacySettings
 	)
-	err := row.Scan(&accountId, &name, &surname, &pgBirthday, &pgBio)
+	err := row.Scan(&accountId, &name, &surname, &pgBirthday, &pgBio, &pgAvatar, &pgCover,
+		&privacy.Birthday, &privacy.Bio, &privacy.Email, &privacy.PhoneNumber)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.ProfileData{}, errs.ProfileNotFound{}
@@ -105,6 +123,10 @@ func (r profilesRepo) GetByProfileId(id models.ProfileId) (models.ProfileData, e
 		Surname:   surname,
 		Birthday:  birthday,
 		Bio:       bio,
+
+		AvatarImage: pgAvatar.String,
+		CoverImage:  pgCover.String,
+		Privacy:     privacy,
 	}, nil
 }
 
@@ -150,6 +172,109 @@ func (r profilesRepo) ResolveAccountId(id models.AccountId) (models.ProfileId, e
 	return models.ProfileId(profileId), nil
 }
 
+func (r profilesRepo) GetManyByProfileIds(ids []models.ProfileId) ([]models.ProfileData, error) {
+	sql := `
+	SELECT account_id, profile_id, name, surname, birthday, bio, avatar_image, cover_image,
+		birthday_visibility, bio_visibility, email_visibility, phone_number_visibility
+	FROM profiles
+	WHERE profile_id = ANY($1::uuid[]);
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make([]models.ProfileData, 0, len(ids))
+	for rows.Next() {
+		var (
+			accountId  int
+			profileId  string
+			pgBirthday pgtype.Date
+			pgBio      pgtype.Text
+			pgAvatar   pgtype.Text
+			pgCover    pgtype.Text
+			profile    models.ProfileData
+		)
+		err := rows.Scan(&accountId, &profileId, &profile.Name, &profile.Surname, &pgBirthday, &pgBio, &pgAvatar, &pgCover,
+			&profile.Privacy.Birthday, &profile.Privacy.Bio, &profile.Privacy.Email, &profile.Privacy.PhoneNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		profile.AccountId = models.AccountId(accountId)
+		profile.ProfileId = models.ProfileId(profileId)
+		if pgBirthday.Valid {
+			profile.Birthday = pgBirthday.Time
+		}
+		profile.Bio = pgBio.String
+		profile.AvatarImage = pgAvatar.String
+		profile.CoverImage = pgCover.String
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+func (r profilesRepo) ResolveProfileIds(ids []models.ProfileId) (map[models.ProfileId]models.AccountId, error) {
+	sql := `
+	SELECT profile_id, account_id
+	FROM profiles
+	WHERE profile_id = ANY($1::uuid[]);
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resolved := make(map[models.ProfileId]models.AccountId, len(ids))
+	for rows.Next() {
+		var (
+			profileId string
+			accountId int
+		)
+		err := rows.Scan(&profileId, &accountId)
+		if err != nil {
+			return nil, err
+		}
+		resolved[models.ProfileId(profileId)] = models.AccountId(accountId)
+	}
+
+	return resolved, rows.Err()
+}
+
+func (r profilesRepo) ResolveAccountIds(ids []models.AccountId) (map[models.AccountId]models.ProfileId, error) {
+	sql := `
+	SELECT account_id, profile_id
+	FROM profiles
+	WHERE account_id = ANY($1);
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resolved := make(map[models.AccountId]models.ProfileId, len(ids))
+	for rows.Next() {
+		var (
+			accountId int
+			profileId string
+		)
+		err := rows.Scan(&accountId, &profileId)
+		if err != nil {
+			return nil, err
+		}
+		resolved[models.AccountId(accountId)] = models.ProfileId(profileId)
+	}
+
+	return resolved, rows.Err()
+}
+
 func (r profilesRepo) New(profileId models.ProfileId, accountId models.AccountId, data models.RegistrationData) error {
 	sql := `
 	INSERT INTO profiles(account_id, profile_id, name, surname)
@@ -211,6 +336,92 @@ func (r profilesRepo) Edit(id models.ProfileId, data repo.EditedProfileData) err
 	return nil
 }
 
+func (r profilesRepo) EditPrivacy(id models.ProfileId, settings repo.EditedPrivacySettings) error {
+	sql := `
+	WITH cte AS (
+		UPDATE profiles
+		SET
+			birthday_visibility = COALESCE($1, birthday_visibility),
+			bio_visibility = COALESCE($2, bio_visibility),
+			email_visibility = COALESCE($3, email_visibility),
+			phone_number_visibility = COALESCE($4, phone_number_visibility)
+		WHERE profile_id = $5
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	visibility := func(v opt.Option[models.Visibility]) pgtype.Text {
+		return pgtype.Text{
+			String: string(v.Value),
+			Valid:  v.HasValue,
+		}
+	}
+
+	var cnt int
+	err := r.scope.QueryRow(r.ctx, sql,
+		visibility(settings.Birthday),
+		visibility(settings.Bio),
+		visibility(settings.Email),
+		visibility(settings.PhoneNumber),
+		id,
+	).Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.ProfileNotFound{}
+	}
+
+	return nil
+}
+
+func (r profilesRepo) SetImage(id models.ProfileId, kind models.ProfileImageKind, name string) (string, error) {
+	var column string
+	switch kind {
+	case models.PROFILE_IMAGE_AVATAR:
+		column = "avatar_image"
+
+	case models.PROFILE_IMAGE_COVER:
+		column = "cover_image"
+
+	default:
+		return "", fmt.Errorf("unknown profile image kind %d", kind)
+	}
+
+	sql := fmt.Sprintf(`
+	WITH previous AS (
+		SELECT profile_id, %[1]s
+		FROM profiles
+		WHERE profile_id = $1
+		FOR UPDATE
+	)
+	UPDATE profiles p
+	SET %[1]s = $2
+	FROM previous
+	WHERE p.profile_id = previous.profile_id
+	RETURNING previous.%[1]s;
+	`, column)
+
+	pgName := pgtype.Text{
+		String: name,
+		Valid:  name != "",
+	}
+
+	var pgPrevious pgtype.Text
+	err := r.scope.QueryRow(r.ctx, sql, id, pgName).Scan(&pgPrevious)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errs.ProfileNotFound{}
+		}
+
+		return "", err
+	}
+
+	return pgPrevious.String, nil
+}
+
 func (r profilesRepo) Delete(id models.ProfileId) error {
 	sql := `
 	WITH cte AS (
@@ -239,3 +450,104 @@ func (r profilesRepo) Delete(id models.ProfileId) error {
 
 	return nil
 }
+
+const PROFILES_SEARCH_PAGE_SIZE = 20
+
+type profilesSearchPagiToken struct {
+	LastScore float32 `json:"ls"`
+	LastId    int     `json:"lid"`
+}
+
+func decodeProfilesSearchPagiToken(token repo.PagiToken) (profilesSearchPagiToken, error) {
+	if token == "" {
+		return profilesSearchPagiToken{LastScore: math.MaxFloat32}, nil
+	}
+	return decodePagiToken[profilesSearchPagiToken](token)
+}
+
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r profilesRepo) Search(query string, encodedPagiToken repo.PagiToken) (repo.ProfileCardsPage, error) {
+	pagiToken, err := decodeProfilesSearchPagiToken(encodedPagiToken)
+	if err != nil {
+		return repo.ProfileCardsPage{}, err
+	}
+
+	// prefix matches rank above fuzzy ones, ties are broken by trigram similarity;
+	// login is matched but not returned
+	sql := fmt.Sprintf(`
+	WITH matched AS (
+		SELECT
+			p.id, p.profile_id, p.name, p.surname,
+			(
+				CASE
+					WHEN lower(a.login) LIKE $2 OR lower(p.name) LIKE $2 OR lower(p.surname) LIKE $2
+						OR lower(p.name || ' ' || p.surname) LIKE $2 THEN 1
+					ELSE 0
+				END +
+				GREATEST(
+					similarity(lower(a.login), $1),
+					similarity(lower(p.name), $1),
+					similarity(lower(p.surname), $1),
+					similarity(lower(p.name || ' ' || p.surname), $1)
+				)
+			)::REAL AS score
+		FROM profiles p
+		JOIN accounts a ON a.id = p.account_id
+		WHERE
+			lower(a.login) LIKE $2 OR lower(p.name) LIKE $2 OR lower(p.surname) LIKE $2
+			OR lower(p.name || ' ' || p.surname) LIKE $2
+			OR lower(a.login) %% $1 OR lower(p.name) %% $1 OR lower(p.surname) %% $1
+			OR lower(p.name || ' ' || p.surname) %% $1
+	)
+	SELECT id, profile_id, name, surname, score
+	FROM matched
+	WHERE score < $3::REAL OR (score = $3::REAL AND id > $4)
+	ORDER BY score DESC, id
+	LIMIT %d;
+	`, PROFILES_SEARCH_PAGE_SIZE)
+
+	query = strings.ToLower(query)
+	rows, err := r.scope.Query(r.ctx, sql, query, escapeLikePattern(query)+"%", pagiToken.LastScore, pagiToken.LastId)
+	if err != nil {
+		return repo.ProfileCardsPage{}, err
+	}
+	defer rows.Close()
+
+	profiles := make([]models.ProfileCard, 0, PROFILES_SEARCH_PAGE_SIZE)
+	var last profilesSearchPagiToken
+	for rows.Next() {
+		var (
+			card      models.ProfileCard
+			profileId string
+		)
+		err := rows.Scan(&last.LastId, &profileId, &card.Name, &card.Surname, &last.LastScore)
+		if err != nil {
+			return repo.ProfileCardsPage{}, err
+		}
+
+		card.ProfileId = models.ProfileId(profileId)
+		profiles = append(profiles, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		return repo.ProfileCardsPage{}, err
+	}
+
+	var nextPagiToken repo.PagiToken
+	if len(profiles) == PROFILES_SEARCH_PAGE_SIZE {
+		encodedToken, err := encodePagiToken(last)
+		if err != nil {
+			log.Printf("warning: cannot encode paginating token (%v): %v", last, err)
+		} else {
+			nextPagiToken = encodedToken
+		}
+	}
+
+	return repo.ProfileCardsPage{
+		Profiles:      profiles,
+		NextPagiToken: nextPagiToken,
+	}, nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	opt "soa-socialnetwork/services/common/option"
 	"sync"
 
 	"github.com/google/uuid"
@@ -15,12 +18,12 @@ func (s *testSuite) TestProfilesSimple() {
 	s.Require().NoError(err)
 
 	registrationData := models.RegistrationData{
-		Login:       "login",
-		Password:    "password",
-		Email:       "email@mail.com",
-		PhoneNumber: "+333333333333",
-		Name:        "name",
-		Surname:     "surname",
+		Login:        "login",
+		PasswordHash: "password_hash",
+		Email:        "email@mail.com",
+		PhoneNumber:  "+333333333333",
+		Name:         "name",
+		Surname:      "surname",
 	}
 
 	accountId, err := conn.Accounts().New(registrationData)
@@ -57,12 +60,12 @@ func (s *testSuite) TestProfilesResolving() {
 	s.Require().NoError(err)
 
 	registrationData := models.RegistrationData{
-		Login:       "login",
-		Password:    "password",
-		Email:       "email@mail.com",
-		PhoneNumber: "+333333333333",
-		Name:        "name",
-		Surname:     "surname",
+		Login:        "login",
+		PasswordHash: "password_hash",
+		Email:        "email@mail.com",
+		PhoneNumber:  "+333333333333",
+		Name:         "name",
+		Surname:      "surname",
 	}
 
 	accountId, err := conn.Accounts().New(registrationData)
@@ -85,18 +88,72 @@ func (s *testSuite) TestProfilesResolving() {
 	}
 }
 
+func (s *testSuite) TestProfilesBatch() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+
+	accountIds := make([]models.AccountId, 3)
+	profileIds := make([]models.ProfileId, 3)
+	for i := range accountIds {
+		accountIds[i], profileIds[i] = s.newTestAccountWithProfile(conn, i)
+	}
+	unknownProfileId := models.ProfileId(uuid.NewString())
+
+	{
+		resolved, err := conn.Profiles().ResolveProfileIds([]models.ProfileId{profileIds[0], profileIds[2], unknownProfileId})
+		s.Require().NoError(err)
+		s.Assert().Equal(map[models.ProfileId]models.AccountId{
+			profileIds[0]: accountIds[0],
+			profileIds[2]: accountIds[2],
+		}, resolved)
+	}
+
+	{
+		resolved, err := conn.Profiles().ResolveAccountIds([]models.AccountId{accountIds[1], accountIds[2], -1})
+		s.Require().NoError(err)
+		s.Assert().Equal(map[models.AccountId]models.ProfileId{
+			accountIds[1]: profileIds[1],
+			accountIds[2]: profileIds[2],
+		}, resolved)
+	}
+
+	{
+		profiles, err := conn.Profiles().GetManyByProfileIds([]models.ProfileId{profileIds[1], unknownProfileId, profileIds[0]})
+		s.Require().NoError(err)
+		s.Require().Len(profiles, 2)
+
+		got := map[models.ProfileId]models.AccountId{}
+		for _, p := range profiles {
+			s.Assert().Equal("name", p.Name)
+			s.Assert().Equal("surname", p.Surname)
+			got[p.ProfileId] = p.AccountId
+		}
+		s.Assert().Equal(map[models.ProfileId]models.AccountId{
+			profileIds[0]: accountIds[0],
+			profileIds[1]: accountIds[1],
+		}, got)
+	}
+
+	{
+		profiles, err := conn.Profiles().GetManyByProfileIds(nil)
+		s.Require().NoError(err)
+		s.Assert().Empty(profiles)
+	}
+}
+
 func (s *testSuite) TestProfilesConcurrent() {
 	ctx := context.Background()
 
 	registrations := make([]models.RegistrationData, 100)
 	for i := range registrations {
 		registrations[i] = models.RegistrationData{
-			Login:       fmt.Sprintf("login_%d", i),
-			Password:    "password",
-			Email:       fmt.Sprintf("email_%d@mail.com", i),
-			PhoneNumber: fmt.Sprintf("+333333333%d", i),
-			Name:        fmt.Sprintf("name_%d", i),
-			Surname:     fmt.Sprintf("surname_%d", i),
+			Login:        fmt.Sprintf("login_%d", i),
+			PasswordHash: "password_hash",
+			Email:        fmt.Sprintf("email_%d@mail.com", i),
+			PhoneNumber:  fmt.Sprintf("+333333333%d", i),
+			Name:         fmt.Sprintf("name_%d", i),
+			Surname:      fmt.Sprintf("surname_%d", i),
 		}
 	}
 
@@ -159,3 +216,169 @@ func (s *testSuite) TestProfilesConcurrent() {
 		s.Assert().Equal(registrations[i].Surname, data.Surname)
 	}
 }
+
+func (s *testSuite) TestProfilesSearch() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+
+	newProfile := func(i int, login string, name string, surname string) models.ProfileId {
+		registrationData := models.RegistrationData{
+			Login:        login,
+			PasswordHash: "password_hash",
+			Email:        fmt.Sprintf("search%d@mail.com", i),
+			PhoneNumber:  fmt.Sprintf("+7000000%04d", i),
+			Name:         name,
+			Surname:      surname,
+		}
+
+		accountId, err := conn.Accounts().New(registrationData)
+		s.Require().NoError(err)
+
+		profileId := models.ProfileId(uuid.NewString())
+		err = conn.Profiles().New(profileId, accountId, registrationData)
+		s.Require().NoError(err)
+		return profileId
+	}
+
+	alexander := newProfile(0, "alex_k", "Alexander", "Kuznetsov")
+	alexey := newProfile(1, "lesha", "Alexey", "Smirnov")
+	newProfile(2, "maria", "Maria", "Ivanova")
+
+	{
+		result, err := conn.Profiles().Search("alex", "")
+		s.Require().NoError(err)
+		s.Require().Len(result.Profiles, 2)
+		s.Assert().Equal(alexander, result.Profiles[0].ProfileId)
+		s.Assert().Equal(alexey, result.Profiles[1].ProfileId)
+		s.Assert().Empty(result.NextPagiToken)
+	}
+
+	{
+		// typo is matched by trigram similarity
+		result, err := conn.Profiles().Search("kuznetsof", "")
+		s.Require().NoError(err)
+		s.Require().Len(result.Profiles, 1)
+		s.Assert().Equal(alexander, result.Profiles[0].ProfileId)
+	}
+
+	{
+		result, err := conn.Profiles().Search("alexey smir", "")
+		s.Require().NoError(err)
+		s.Require().NotEmpty(result.Profiles)
+		s.Assert().Equal(alexey, result.Profiles[0].ProfileId)
+	}
+
+	{
+		// like wildcards are matched literally
+		result, err := conn.Profiles().Search("%", "")
+		s.Require().NoError(err)
+		s.Assert().Empty(result.Profiles)
+	}
+
+	for i := range PROFILES_SEARCH_PAGE_SIZE + 5 {
+		newProfile(10+i, fmt.Sprintf("paged_%d", i), "Paged", fmt.Sprintf("User%d", i))
+	}
+
+	found := map[models.ProfileId]bool{}
+	var token repo.PagiToken
+	pages := 0
+	for {
+		result, err := conn.Profiles().Search("paged", token)
+		s.Require().NoError(err)
+		pages++
+
+		for _, card := range result.Profiles {
+			s.Assert().False(found[card.ProfileId], "profile %s is returned twice", card.ProfileId)
+			found[card.ProfileId] = true
+		}
+
+		token = result.NextPagiToken
+		if token == "" {
+			break
+		}
+	}
+	s.Assert().Equal(2, pages)
+	s.Assert().Len(found, PROFILES_SEARCH_PAGE_SIZE+5)
+
+	_, err = conn.Profiles().Search("paged", "broken token")
+	s.Require().ErrorIs(err, errs.InvalidPagiToken{})
+}
+
+func (s *testSuite) TestProfilesImages() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+
+	_, profileId := s.newTestAccountWithProfile(conn, 0)
+
+	previous, err := conn.Profiles().SetImage(profileId, models.PROFILE_IMAGE_AVATAR, "first.png")
+	s.Require().NoError(err)
+	s.Assert().Empty(previous)
+
+	previous, err = conn.Profiles().SetImage(profileId, models.PROFILE_IMAGE_AVATAR, "second.jpeg")
+	s.Require().NoError(err)
+	s.Assert().Equal("first.png", previous)
+
+	_, err = conn.Profiles().SetImage(profileId, models.PROFILE_IMAGE_COVER, "cover.png")
+	s.Require().NoError(err)
+
+	data, err := conn.Profiles().GetByProfileId(profileId)
+	s.Require().NoError(err)
+	s.Assert().Equal("second.jpeg", data.AvatarImage)
+	s.Assert().Equal("cover.png", data.CoverImage)
+
+	previous, err = conn.Profiles().SetImage(profileId, models.PROFILE_IMAGE_COVER, "")
+	s.Require().NoError(err)
+	s.Assert().Equal("cover.png", previous)
+
+	data, err = conn.Profiles().GetByAccountId(data.AccountId)
+	s.Require().NoError(err)
+	s.Assert().Equal("second.jpeg", data.AvatarImage)
+	s.Assert().Empty(data.CoverImage)
+
+	_, err = conn.Profiles().SetImage(models.ProfileId(uuid.NewString()), models.PROFILE_IMAGE_AVATAR, "first.png")
+	s.Require().ErrorAs(err, &errs.ProfileNotFound{})
+}
+
+func (s *testSuite) TestProfilesPrivacy() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+
+	accountId, profileId := s.newTestAccountWithProfile(conn, 0)
+
+	profile, err := conn.Profiles().GetByProfileId(profileId)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.PrivacySettings{
+		Birthday:    models.VISIBILITY_EVERYONE,
+		Bio:         models.VISIBILITY_EVERYONE,
+		Email:       models.VISIBILITY_ONLY_ME,
+		PhoneNumber: models.VISIBILITY_ONLY_ME,
+	}, profile.Privacy)
+
+	err = conn.Profiles().EditPrivacy(profileId, repo.EditedPrivacySettings{
+		Bio:   opt.Some(models.VISIBILITY_AUTHENTICATED),
+		Email: opt.Some(models.VISIBILITY_EVERYONE),
+	})
+	s.Require().NoError(err)
+
+	expected := models.PrivacySettings{
+		Birthday:    models.VISIBILITY_EVERYONE,
+		Bio:         models.VISIBILITY_AUTHENTICATED,
+		Email:       models.VISIBILITY_EVERYONE,
+		PhoneNumber: models.VISIBILITY_ONLY_ME,
+	}
+
+	profile, err = conn.Profiles().GetByAccountId(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal(expected, profile.Privacy)
+
+	profiles, err := conn.Profiles().GetManyByProfileIds([]models.ProfileId{profileId})
+	s.Require().NoError(err)
+	s.Require().Len(profiles, 1)
+	s.Assert().Equal(expected, profiles[0].Privacy)
+
+	err = conn.Profiles().EditPrivacy(models.ProfileId(uuid.NewString()), repo.EditedPrivacySettings{})
+	s.Assert().ErrorIs(err, errs.ProfileNotFound{})
+}
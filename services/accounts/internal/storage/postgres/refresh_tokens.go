@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type refreshTokensRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r refreshTokensRepo) Put(tokenHash models.RefreshTokenHash, params repo.RefreshTokenParams) (time.Time, error) {
+	sql := `
+	INSERT INTO refresh_tokens(account_id, family_id, token_hash, valid_until)
+	VALUES ($1, $2, $3, NOW() + $4)
+	RETURNING valid_until;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, params.AccountId, params.FamilyId, tokenHash, params.Ttl)
+
+	var validUntil time.Time
+	err := row.Scan(&validUntil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return validUntil, nil
+}
+
+func (r refreshTokensRepo) Get(tokenHash models.RefreshTokenHash) (models.RefreshTokenData, error) {
+	sql := `
+	SELECT account_id, family_id, is_used, is_revoked, created_at, valid_until
+	FROM refresh_tokens
+	WHERE token_hash = $1
+	FOR UPDATE;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, tokenHash)
+
+	var (
+		data     models.RefreshTokenData
+		familyId string
+	)
+	err := row.Scan(&data.AccountId, &familyId, &data.IsUsed, &data.IsRevoked, &data.CreatedAt, &data.ValidUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.RefreshTokenData{}, errs.TokenNotFound{}
+		}
+
+		return models.RefreshTokenData{}, err
+	}
+	data.FamilyId = models.RefreshTokenFamilyId(familyId)
+
+	return data, nil
+}
+
+func (r refreshTokensRepo) MarkUsed(tokenHash models.RefreshTokenHash) error {
+	sql := `
+	WITH cte AS (
+		UPDATE refresh_tokens
+		SET is_used = TRUE
+		WHERE token_hash = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, tokenHash)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.TokenNotFound{}
+	}
+
+	return nil
+}
+
+func (r refreshTokensRepo) RevokeFamily(familyId models.RefreshTokenFamilyId) error {
+	sql := `
+	UPDATE refresh_tokens
+	SET is_revoked = TRUE
+	WHERE family_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, familyId)
+	return err
+}
+
+func (r refreshTokensRepo) RevokeAll(accountId models.AccountId) error {
+	sql := `
+	UPDATE refresh_tokens
+	SET is_revoked = TRUE
+	WHERE account_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
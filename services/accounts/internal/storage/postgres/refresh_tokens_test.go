@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/repo"
+	"time"
+)
+
+func (s *testSuite) TestRefreshTokensSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	tokenHash := models.RefreshTokenHash("some_refresh_token_hash")
+	tokenParams := repo.RefreshTokenParams{
+		AccountId: 111,
+		FamilyId:  "0b5ab2f4-6f4f-4b1c-9d8e-1f2a3b4c5d6e",
+		Ttl:       time.Hour,
+	}
+
+	validUntil, err := conn.RefreshTokens().Put(tokenHash, tokenParams)
+	s.Require().NoError(err)
+
+	tokenData, err := conn.RefreshTokens().Get(tokenHash)
+	s.Require().NoError(err)
+
+	s.Assert().Equal(tokenParams.AccountId, tokenData.AccountId)
+	s.Assert().Equal(tokenParams.FamilyId, tokenData.FamilyId)
+	s.Assert().False(tokenData.IsUsed)
+	s.Assert().False(tokenData.IsRevoked)
+	s.Assert().Equal(validUntil, tokenData.ValidUntil)
+
+	err = conn.RefreshTokens().MarkUsed(tokenHash)
+	s.Require().NoError(err)
+
+	tokenData, err = conn.RefreshTokens().Get(tokenHash)
+	s.Require().NoError(err)
+	s.Assert().True(tokenData.IsUsed)
+
+	_, err = conn.RefreshTokens().Get("unknown_refresh_token_hash")
+	s.Require().Error(err)
+}
+
+func (s *testSuite) TestRefreshTokensRevokeFamily() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	familyId := models.RefreshTokenFamilyId("0b5ab2f4-6f4f-4b1c-9d8e-1f2a3b4c5d6e")
+	otherFamilyId := models.RefreshTokenFamilyId("9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b")
+
+	familyTokens := []models.RefreshTokenHash{"refresh_token_hash_1", "refresh_token_hash_2"}
+	for _, tokenHash := range familyTokens {
+		_, err := conn.RefreshTokens().Put(tokenHash, repo.RefreshTokenParams{
+			AccountId: 111,
+			FamilyId:  familyId,
+			Ttl:       time.Hour,
+		})
+		s.Require().NoError(err)
+	}
+
+	otherToken := models.RefreshTokenHash("refresh_token_hash_3")
+	_, err = conn.RefreshTokens().Put(otherToken, repo.RefreshTokenParams{
+		AccountId: 111,
+		FamilyId:  otherFamilyId,
+		Ttl:       time.Hour,
+	})
+	s.Require().NoError(err)
+
+	err = conn.RefreshTokens().RevokeFamily(familyId)
+	s.Require().NoError(err)
+
+	for _, tokenHash := range familyTokens {
+		tokenData, err := conn.RefreshTokens().Get(tokenHash)
+		s.Require().NoError(err)
+		s.Assert().True(tokenData.IsRevoked)
+	}
+
+	tokenData, err := conn.RefreshTokens().Get(otherToken)
+	s.Require().NoError(err)
+	s.Assert().False(tokenData.IsRevoked)
+}
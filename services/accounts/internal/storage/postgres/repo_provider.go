@@ -31,6 +31,118 @@ func (p *repoProvider) ApiTokens() repo.ApiTokensRepo {
 	}
 }
 
+func (p *repoProvider) OAuthClients() repo.OAuthClientsRepo {
+	return oauthClientsRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) OAuthCodes() repo.OAuthCodesRepo {
+	return oauthCodesRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) OidcIdentities() repo.OidcIdentitiesRepo {
+	return oidcIdentitiesRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) OidcLoginStates() repo.OidcLoginStatesRepo {
+	return oidcLoginStatesRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) RefreshTokens() repo.RefreshTokensRepo {
+	return refreshTokensRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) Sessions() repo.SessionsRepo {
+	return sessionsRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) PasswordResetCodes() repo.PasswordResetCodesRepo {
+	return passwordResetCodesRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) VerificationCodes() repo.VerificationCodesRepo {
+	return verificationCodesRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) AuthFailures() repo.AuthFailuresRepo {
+	return authFailuresRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) TotpSecrets() repo.TotpSecretsRepo {
+	return totpSecretsRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) BackupCodes() repo.BackupCodesRepo {
+	return backupCodesRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) SecondFactorChallenges() repo.SecondFactorChallengesRepo {
+	return secondFactorChallengesRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) Follows() repo.FollowsRepo {
+	return followsRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) Blocks() repo.BlocksRepo {
+	return blocksRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) DataExports() repo.DataExportsRepo {
+	return dataExportsRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
+func (p *repoProvider) AuditLog() repo.AuditLogRepo {
+	return auditLogRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
+
 func (p *repoProvider) Outbox() repo.OutboxRepo {
 	return outboxRepo{
 		ctx:   p.ctx,
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type sessionsRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r sessionsRepo) Put(params models.SessionParams, ttl time.Duration) error {
+	sql := `
+	INSERT INTO sessions(id, account_id, user_agent, ip_address, valid_until)
+	VALUES ($1, $2, $3, $4, NOW() + $5);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, params.Id, params.AccountId, params.UserAgent, params.IpAddress, ttl)
+	return err
+}
+
+func (r sessionsRepo) Get(id models.SessionId) (models.SessionData, error) {
+	sql := `
+	SELECT id, account_id, user_agent, ip_address, is_revoked, created_at, last_seen_at, valid_until
+	FROM sessions
+	WHERE id = $1;
+	`
+
+	data, err := scanSessionData(r.scope.QueryRow(r.ctx, sql, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.SessionData{}, errs.SessionNotFound{}
+		}
+
+		return models.SessionData{}, err
+	}
+
+	return data, nil
+}
+
+func (r sessionsRepo) Touch(id models.SessionId, ttl time.Duration) error {
+	sql := `
+	WITH cte AS (
+		UPDATE sessions
+		SET last_seen_at = NOW(), valid_until = NOW() + $2
+		WHERE id = $1 AND NOT is_revoked
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id, ttl)
+}
+
+func (r sessionsRepo) ListActive(accountId models.AccountId) ([]models.SessionData, error) {
+	sql := `
+	SELECT id, account_id, user_agent, ip_address, is_revoked, created_at, last_seen_at, valid_until
+	FROM sessions
+	WHERE account_id = $1 AND NOT is_revoked AND valid_until > NOW()
+	ORDER BY last_seen_at DESC, created_at DESC;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]models.SessionData, 0)
+	for rows.Next() {
+		data, err := scanSessionData(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r sessionsRepo) Revoke(accountId models.AccountId, id models.SessionId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE sessions
+		SET is_revoked = TRUE
+		WHERE id = $1 AND account_id = $2 AND NOT is_revoked AND valid_until > NOW()
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id, accountId)
+}
+
+func (r sessionsRepo) RevokeAll(accountId models.AccountId, except []models.SessionId) ([]models.SessionId, error) {
+	sql := `
+	UPDATE sessions
+	SET is_revoked = TRUE
+	WHERE
+		account_id = $1
+		AND NOT is_revoked
+		AND valid_until > NOW()
+		AND NOT (id::TEXT = ANY($2::TEXT[]))
+	RETURNING id;
+	`
+
+	exceptIds := make([]string, 0, len(except))
+	for _, id := range except {
+		exceptIds = append(exceptIds, string(id))
+	}
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId, exceptIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]models.SessionId, 0)
+	for rows.Next() {
+		var id string
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, models.SessionId(id))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r sessionsRepo) updateOne(sql string, args ...any) error {
+	row := r.scope.QueryRow(r.ctx, sql, args...)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.SessionNotFound{}
+	}
+
+	return nil
+}
+
+func scanSessionData(row pgx.Row) (models.SessionData, error) {
+	var (
+		data models.SessionData
+		id   string
+	)
+	err := row.Scan(&id, &data.AccountId, &data.UserAgent, &data.IpAddress, &data.IsRevoked, &data.CreatedAt, &data.LastSeenAt, &data.ValidUntil)
+	if err != nil {
+		return models.SessionData{}, err
+	}
+	data.Id = models.SessionId(id)
+
+	return data, nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+)
+
+func (s *testSuite) TestSessionsSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	params := models.SessionParams{
+		Id:        "0b5ab2f4-6f4f-4b1c-9d8e-1f2a3b4c5d6e",
+		AccountId: 111,
+		UserAgent: "Mozilla/5.0",
+		IpAddress: "10.0.0.1",
+	}
+
+	err = conn.Sessions().Put(params, time.Hour)
+	s.Require().NoError(err)
+
+	session, err := conn.Sessions().Get(params.Id)
+	s.Require().NoError(err)
+	s.Assert().Equal(params, session.SessionParams)
+	s.Assert().False(session.IsRevoked)
+	s.Assert().WithinDuration(time.Now().Add(time.Hour), session.ValidUntil, time.Minute)
+
+	err = conn.Sessions().Touch(params.Id, 2*time.Hour)
+	s.Require().NoError(err)
+
+	session, err = conn.Sessions().Get(params.Id)
+	s.Require().NoError(err)
+	s.Assert().WithinDuration(time.Now().Add(2*time.Hour), session.ValidUntil, time.Minute)
+
+	_, err = conn.Sessions().Get("9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b")
+	s.Require().ErrorAs(err, &errs.SessionNotFound{})
+}
+
+func (s *testSuite) TestSessionsListAndRevoke() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+	ids := []models.SessionId{
+		"0b5ab2f4-6f4f-4b1c-9d8e-1f2a3b4c5d6e",
+		"1c6bc3a5-7a5a-4c2d-8e9f-2a3b4c5d6e7f",
+		"2d7cd4b6-8b6b-4d3e-9fa0-3b4c5d6e7f80",
+	}
+	for _, id := range ids {
+		err := conn.Sessions().Put(models.SessionParams{
+			Id:        id,
+			AccountId: accountId,
+		}, time.Hour)
+		s.Require().NoError(err)
+	}
+
+	otherId := models.SessionId("9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b")
+	err = conn.Sessions().Put(models.SessionParams{
+		Id:        otherId,
+		AccountId: 222,
+	}, time.Hour)
+	s.Require().NoError(err)
+
+	sessions, err := conn.Sessions().ListActive(accountId)
+	s.Require().NoError(err)
+	s.Require().Len(sessions, 3)
+
+	err = conn.Sessions().Revoke(accountId, otherId)
+	s.Require().ErrorAs(err, &errs.SessionNotFound{}, "session of another account is revoked")
+
+	err = conn.Sessions().Revoke(accountId, ids[0])
+	s.Require().NoError(err)
+
+	err = conn.Sessions().Revoke(accountId, ids[0])
+	s.Require().ErrorAs(err, &errs.SessionNotFound{})
+
+	err = conn.Sessions().Touch(ids[0], time.Hour)
+	s.Require().ErrorAs(err, &errs.SessionNotFound{}, "revoked session is prolonged")
+
+	revoked, err := conn.Sessions().RevokeAll(accountId, []models.SessionId{ids[1]})
+	s.Require().NoError(err)
+	s.Assert().Equal([]models.SessionId{ids[2]}, revoked)
+
+	sessions, err = conn.Sessions().ListActive(accountId)
+	s.Require().NoError(err)
+	s.Require().Len(sessions, 1)
+	s.Assert().Equal(ids[1], sessions[0].Id)
+
+	session, err := conn.Sessions().Get(otherId)
+	s.Require().NoError(err)
+	s.Assert().False(session.IsRevoked)
+}
@@ -0,0 +1,296 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type totpSecretsRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r totpSecretsRepo) Put(accountId models.AccountId, secret []byte) error {
+	sql := `
+	INSERT INTO totp_secrets AS s (account_id, secret)
+	VALUES ($1, $2)
+	ON CONFLICT (account_id) DO UPDATE
+	SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+	WHERE NOT s.is_enabled;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId, secret)
+	return err
+}
+
+func (r totpSecretsRepo) Get(accountId models.AccountId) (models.TotpSecretData, error) {
+	sql := `
+	SELECT account_id, secret, is_enabled, last_used_step
+	FROM totp_secrets
+	WHERE account_id = $1
+	FOR UPDATE;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, accountId)
+
+	var data models.TotpSecretData
+	err := row.Scan(&data.AccountId, &data.Secret, &data.IsEnabled, &data.LastUsedStep)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TotpSecretData{}, errs.TotpSecretNotFound{}
+		}
+
+		return models.TotpSecretData{}, err
+	}
+
+	return data, nil
+}
+
+func (r totpSecretsRepo) Enable(accountId models.AccountId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE totp_secrets
+		SET is_enabled = TRUE
+		WHERE account_id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, accountId)
+}
+
+func (r totpSecretsRepo) UseStep(accountId models.AccountId, step int64) (bool, error) {
+	sql := `
+	WITH cte AS (
+		UPDATE totp_secrets
+		SET last_used_step = $2
+		WHERE account_id = $1 AND last_used_step < $2
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, accountId, step)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
+
+func (r totpSecretsRepo) Delete(accountId models.AccountId) error {
+	sql := `
+	WITH cte AS (
+		DELETE FROM totp_secrets
+		WHERE account_id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, accountId)
+}
+
+func (r totpSecretsRepo) updateOne(sql string, args ...any) error {
+	row := r.scope.QueryRow(r.ctx, sql, args...)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.TotpSecretNotFound{}
+	}
+
+	return nil
+}
+
+type backupCodesRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r backupCodesRepo) Replace(accountId models.AccountId, hashes []models.BackupCodeHash) error {
+	err := r.DeleteAll(accountId)
+	if err != nil {
+		return err
+	}
+
+	rawHashes := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		rawHashes = append(rawHashes, string(hash))
+	}
+
+	sql := `
+	INSERT INTO backup_codes(account_id, code_hash)
+	SELECT $1, unnest($2::VARCHAR[]);
+	`
+
+	_, err = r.scope.Exec(r.ctx, sql, accountId, rawHashes)
+	return err
+}
+
+func (r backupCodesRepo) Use(accountId models.AccountId, hash models.BackupCodeHash) (bool, error) {
+	sql := `
+	WITH cte AS (
+		UPDATE backup_codes
+		SET is_used = TRUE
+		WHERE id = (
+			SELECT id FROM backup_codes
+			WHERE account_id = $1 AND code_hash = $2 AND NOT is_used
+			LIMIT 1
+		)
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, accountId, hash)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
+
+func (r backupCodesRepo) DeleteAll(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM backup_codes
+	WHERE account_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
+
+type secondFactorChallengesRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r secondFactorChallengesRepo) Put(hash models.SecondFactorChallengeHash, params models.SecondFactorChallengeParams, ttl time.Duration) (time.Time, error) {
+	sql := `
+	INSERT INTO second_factor_challenges(challenge_hash, account_id, purpose, token_name, token_scopes, token_ttl, valid_until)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW() + $7)
+	RETURNING valid_until;
+	`
+
+	var (
+		tokenName   *string
+		tokenScopes []string
+		tokenTtl    *time.Duration
+	)
+	if params.ApiToken.HasValue {
+		tokenName = &params.ApiToken.Value.Name
+		tokenScopes = soatoken.ScopesToStrings(params.ApiToken.Value.Scopes)
+		tokenTtl = &params.ApiToken.Value.Ttl
+	}
+
+	row := r.scope.QueryRow(r.ctx, sql, hash, params.AccountId, params.Purpose, tokenName, tokenScopes, tokenTtl, ttl)
+
+	var validUntil time.Time
+	err := row.Scan(&validUntil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return validUntil, nil
+}
+
+func (r secondFactorChallengesRepo) GetActive(hash models.SecondFactorChallengeHash) (models.SecondFactorChallengeData, error) {
+	sql := `
+	SELECT id, account_id, purpose, token_name, token_scopes, token_ttl, attempts
+	FROM second_factor_challenges
+	WHERE challenge_hash = $1 AND NOT is_used AND valid_until > NOW()
+	FOR UPDATE;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, hash)
+
+	var (
+		data        models.SecondFactorChallengeData
+		purpose     string
+		tokenName   *string
+		tokenScopes []string
+		tokenTtl    *time.Duration
+	)
+	err := row.Scan(&data.Id, &data.AccountId, &purpose, &tokenName, &tokenScopes, &tokenTtl, &data.Attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.SecondFactorChallengeData{}, errs.SecondFactorChallengeNotFound{}
+		}
+
+		return models.SecondFactorChallengeData{}, err
+	}
+	data.Purpose = models.SecondFactorPurpose(purpose)
+
+	if tokenName != nil && tokenTtl != nil {
+		data.ApiToken = opt.Some(models.PendingApiToken{
+			Name:   *tokenName,
+			Scopes: scopesFromDb(tokenScopes),
+			Ttl:    *tokenTtl,
+		})
+	}
+
+	return data, nil
+}
+
+func (r secondFactorChallengesRepo) IncrementAttempts(id models.SecondFactorChallengeId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE second_factor_challenges
+		SET attempts = attempts + 1
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id)
+}
+
+func (r secondFactorChallengesRepo) MarkUsed(id models.SecondFactorChallengeId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE second_factor_challenges
+		SET is_used = TRUE
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id)
+}
+
+func (r secondFactorChallengesRepo) updateOne(sql string, id models.SecondFactorChallengeId) error {
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.SecondFactorChallengeNotFound{}
+	}
+
+	return nil
+}
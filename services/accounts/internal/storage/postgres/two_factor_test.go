@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	opt "soa-socialnetwork/services/common/option"
+	"time"
+)
+
+func (s *testSuite) TestTotpSecretsSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+
+	_, err = conn.TotpSecrets().Get(accountId)
+	s.Require().ErrorAs(err, &errs.TotpSecretNotFound{})
+
+	err = conn.TotpSecrets().Put(accountId, []byte("first secret"))
+	s.Require().NoError(err)
+
+	// not enabled secret may be replaced
+	err = conn.TotpSecrets().Put(accountId, []byte("second secret"))
+	s.Require().NoError(err)
+
+	secret, err := conn.TotpSecrets().Get(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal([]byte("second secret"), secret.Secret)
+	s.Assert().False(secret.IsEnabled)
+
+	err = conn.TotpSecrets().Enable(accountId)
+	s.Require().NoError(err)
+	used, err := conn.TotpSecrets().UseStep(accountId, 12345)
+	s.Require().NoError(err)
+	s.Assert().True(used)
+
+	// the same or earlier step cannot be used again
+	used, err = conn.TotpSecrets().UseStep(accountId, 12345)
+	s.Require().NoError(err)
+	s.Assert().False(used)
+	used, err = conn.TotpSecrets().UseStep(accountId, 12344)
+	s.Require().NoError(err)
+	s.Assert().False(used)
+
+	// enabled secret is kept
+	err = conn.TotpSecrets().Put(accountId, []byte("third secret"))
+	s.Require().NoError(err)
+
+	secret, err = conn.TotpSecrets().Get(accountId)
+	s.Require().NoError(err)
+	s.Assert().Equal([]byte("second secret"), secret.Secret)
+	s.Assert().True(secret.IsEnabled)
+	s.Assert().Equal(int64(12345), secret.LastUsedStep)
+
+	err = conn.TotpSecrets().Delete(accountId)
+	s.Require().NoError(err)
+
+	err = conn.TotpSecrets().Delete(accountId)
+	s.Require().ErrorAs(err, &errs.TotpSecretNotFound{})
+}
+
+func (s *testSuite) TestBackupCodesSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+
+	err = conn.BackupCodes().Replace(accountId, []models.BackupCodeHash{"hash1", "hash2"})
+	s.Require().NoError(err)
+
+	used, err := conn.BackupCodes().Use(accountId, "hash1")
+	s.Require().NoError(err)
+	s.Assert().True(used)
+
+	used, err = conn.BackupCodes().Use(accountId, "hash1")
+	s.Require().NoError(err)
+	s.Assert().False(used, "backup code must be single-use")
+
+	used, err = conn.BackupCodes().Use(models.AccountId(222), "hash2")
+	s.Require().NoError(err)
+	s.Assert().False(used)
+
+	err = conn.BackupCodes().Replace(accountId, []models.BackupCodeHash{"hash3"})
+	s.Require().NoError(err)
+
+	used, err = conn.BackupCodes().Use(accountId, "hash2")
+	s.Require().NoError(err)
+	s.Assert().False(used, "previous codes must be removed")
+
+	used, err = conn.BackupCodes().Use(accountId, "hash3")
+	s.Require().NoError(err)
+	s.Assert().True(used)
+}
+
+func (s *testSuite) TestSecondFactorChallengesSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	validUntil, err := conn.SecondFactorChallenges().Put("auth_hash", models.SecondFactorChallengeParams{
+		AccountId: 111,
+		Purpose:   models.SECOND_FACTOR_AUTHENTICATE,
+	}, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().WithinDuration(time.Now().Add(time.Hour), validUntil, time.Minute)
+
+	pendingToken := models.PendingApiToken{
+		Name:   "token",
+		Scopes: []soatoken.Scope{soatoken.SCOPE_POSTS_READ},
+		Ttl:    24 * time.Hour,
+	}
+	_, err = conn.SecondFactorChallenges().Put("token_hash", models.SecondFactorChallengeParams{
+		AccountId: 111,
+		Purpose:   models.SECOND_FACTOR_API_TOKEN,
+		ApiToken:  opt.Some(pendingToken),
+	}, time.Hour)
+	s.Require().NoError(err)
+
+	challenge, err := conn.SecondFactorChallenges().GetActive("auth_hash")
+	s.Require().NoError(err)
+	s.Assert().Equal(models.AccountId(111), challenge.AccountId)
+	s.Assert().Equal(models.SECOND_FACTOR_AUTHENTICATE, challenge.Purpose)
+	s.Assert().False(challenge.ApiToken.HasValue)
+
+	err = conn.SecondFactorChallenges().IncrementAttempts(challenge.Id)
+	s.Require().NoError(err)
+	err = conn.SecondFactorChallenges().MarkUsed(challenge.Id)
+	s.Require().NoError(err)
+
+	_, err = conn.SecondFactorChallenges().GetActive("auth_hash")
+	s.Require().ErrorAs(err, &errs.SecondFactorChallengeNotFound{})
+
+	challenge, err = conn.SecondFactorChallenges().GetActive("token_hash")
+	s.Require().NoError(err)
+	s.Assert().Equal(models.SECOND_FACTOR_API_TOKEN, challenge.Purpose)
+	s.Require().True(challenge.ApiToken.HasValue)
+	s.Assert().Equal(pendingToken, challenge.ApiToken.Value)
+	s.Assert().Equal(0, challenge.Attempts)
+
+	_, err = conn.SecondFactorChallenges().Put("expired_hash", models.SecondFactorChallengeParams{
+		AccountId: 111,
+		Purpose:   models.SECOND_FACTOR_AUTHENTICATE,
+	}, -time.Second)
+	s.Require().NoError(err)
+
+	_, err = conn.SecondFactorChallenges().GetActive("expired_hash")
+	s.Require().ErrorAs(err, &errs.SecondFactorChallengeNotFound{})
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type verificationCodesRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r verificationCodesRepo) Put(accountId models.AccountId, kind models.ContactKind, address string, codeHash models.VerificationCodeHash, ttl time.Duration) error {
+	sql := `
+	INSERT INTO verification_codes(account_id, contact_kind, address, code_hash, valid_until)
+	VALUES ($1, $2, $3, $4, NOW() + $5);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId, kind, address, codeHash, ttl)
+	return err
+}
+
+func (r verificationCodesRepo) GetActive(accountId models.AccountId, kind models.ContactKind) (models.VerificationCodeData, error) {
+	sql := `
+	SELECT id, account_id, contact_kind, address, code_hash, attempts, valid_until
+	FROM verification_codes
+	WHERE account_id = $1 AND contact_kind = $2 AND NOT is_used AND valid_until > NOW()
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1
+	FOR UPDATE;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, accountId, kind)
+
+	var (
+		data     models.VerificationCodeData
+		kindRaw  string
+		codeHash string
+	)
+	err := row.Scan(&data.Id, &data.AccountId, &kindRaw, &data.Address, &codeHash, &data.Attempts, &data.ValidUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.VerificationCodeData{}, errs.VerificationCodeNotFound{}
+		}
+
+		return models.VerificationCodeData{}, err
+	}
+	data.Kind = models.ContactKind(kindRaw)
+	data.CodeHash = models.VerificationCodeHash(codeHash)
+
+	return data, nil
+}
+
+func (r verificationCodesRepo) IncrementAttempts(id models.VerificationCodeId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE verification_codes
+		SET attempts = attempts + 1
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id)
+}
+
+func (r verificationCodesRepo) MarkUsed(id models.VerificationCodeId) error {
+	sql := `
+	WITH cte AS (
+		UPDATE verification_codes
+		SET is_used = TRUE
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM cte;
+	`
+
+	return r.updateOne(sql, id)
+}
+
+func (r verificationCodesRepo) InvalidateAll(accountId models.AccountId, kind models.ContactKind) error {
+	sql := `
+	UPDATE verification_codes
+	SET is_used = TRUE
+	WHERE account_id = $1 AND contact_kind = $2 AND NOT is_used;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId, kind)
+	return err
+}
+
+func (r verificationCodesRepo) CountIssuedWithin(accountId models.AccountId, kind models.ContactKind, period time.Duration) (int, error) {
+	lockSql := `
+	SELECT pg_advisory_xact_lock(hashtext('verification_codes'), $1);
+	`
+
+	_, err := r.scope.Exec(r.ctx, lockSql, accountId)
+	if err != nil {
+		return 0, err
+	}
+
+	sql := `
+	SELECT count(*)
+	FROM verification_codes
+	WHERE account_id = $1 AND contact_kind = $2 AND created_at > NOW() - $3::INTERVAL;
+	`
+
+	var cnt int
+	err = r.scope.QueryRow(r.ctx, sql, accountId, kind, period).Scan(&cnt)
+	return cnt, err
+}
+
+func (r verificationCodesRepo) updateOne(sql string, id models.VerificationCodeId) error {
+	row := r.scope.QueryRow(r.ctx, sql, id)
+
+	var cnt int
+	err := row.Scan(&cnt)
+	if err != nil {
+		return err
+	}
+
+	if cnt == 0 {
+		return errs.VerificationCodeNotFound{}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/accounts/internal/models"
+	"soa-socialnetwork/services/accounts/internal/storage/postgres/errs"
+	"time"
+)
+
+func (s *testSuite) TestVerificationCodesSimple() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(111)
+
+	err = conn.VerificationCodes().Put(accountId, models.CONTACT_EMAIL, "email@mail.com", "email_code_hash", time.Hour)
+	s.Require().NoError(err)
+	err = conn.VerificationCodes().Put(accountId, models.CONTACT_PHONE_NUMBER, "+333333333333", "phone_code_hash", time.Hour)
+	s.Require().NoError(err)
+
+	codeData, err := conn.VerificationCodes().GetActive(accountId, models.CONTACT_EMAIL)
+	s.Require().NoError(err)
+	s.Assert().Equal(models.CONTACT_EMAIL, codeData.Kind)
+	s.Assert().Equal("email@mail.com", codeData.Address)
+	s.Assert().Equal(models.VerificationCodeHash("email_code_hash"), codeData.CodeHash)
+
+	err = conn.VerificationCodes().IncrementAttempts(codeData.Id)
+	s.Require().NoError(err)
+
+	codeData, err = conn.VerificationCodes().GetActive(accountId, models.CONTACT_EMAIL)
+	s.Require().NoError(err)
+	s.Assert().Equal(1, codeData.Attempts)
+
+	err = conn.VerificationCodes().MarkUsed(codeData.Id)
+	s.Require().NoError(err)
+
+	_, err = conn.VerificationCodes().GetActive(accountId, models.CONTACT_EMAIL)
+	s.Require().ErrorAs(err, &errs.VerificationCodeNotFound{})
+
+	err = conn.VerificationCodes().InvalidateAll(accountId, models.CONTACT_PHONE_NUMBER)
+	s.Require().NoError(err)
+
+	_, err = conn.VerificationCodes().GetActive(accountId, models.CONTACT_PHONE_NUMBER)
+	s.Require().ErrorAs(err, &errs.VerificationCodeNotFound{})
+}
+
+func (s *testSuite) TestVerificationCodesCountIssued() {
+	ctx := context.Background()
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	accountId := models.AccountId(112)
+
+	cnt, err := conn.VerificationCodes().CountIssuedWithin(accountId, models.CONTACT_EMAIL, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal(0, cnt)
+
+	for range 3 {
+		err = conn.VerificationCodes().Put(accountId, models.CONTACT_EMAIL, "email@mail.com", "code_hash", time.Hour)
+		s.Require().NoError(err)
+	}
+
+	// used codes are counted as well
+	err = conn.VerificationCodes().InvalidateAll(accountId, models.CONTACT_EMAIL)
+	s.Require().NoError(err)
+
+	_, err = s.db.globalConn.Exec(ctx, `
+	UPDATE verification_codes
+	SET created_at = NOW() - INTERVAL '2 hours'
+	WHERE id = (SELECT min(id) FROM verification_codes WHERE account_id = $1);
+	`, accountId)
+	s.Require().NoError(err)
+
+	cnt, err = conn.VerificationCodes().CountIssuedWithin(accountId, models.CONTACT_EMAIL, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal(2, cnt)
+
+	cnt, err = conn.VerificationCodes().CountIssuedWithin(accountId, models.CONTACT_PHONE_NUMBER, time.Hour)
+	s.Require().NoError(err)
+	s.Assert().Equal(0, cnt)
+}
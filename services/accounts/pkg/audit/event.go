@@ -0,0 +1,47 @@
+package audit
+
+import "time"
+
+// Kafka topic of security audit events emitted by accounts service
+const AUDIT_EVENT_TOPIC = "audit_event"
+
+type EventType string
+
+const (
+	EVENT_LOGIN_SUCCEEDED    EventType = "login_succeeded"
+	EVENT_LOGIN_FAILED       EventType = "login_failed"
+	EVENT_API_TOKEN_CREATED  EventType = "api_token_created"
+	EVENT_API_TOKEN_REVOKED  EventType = "api_token_revoked"
+	EVENT_API_TOKEN_REJECTED EventType = "api_token_rejected"
+	EVENT_PROFILE_EDITED     EventType = "profile_edited"
+	EVENT_ACCOUNT_DELETED    EventType = "account_deleted"
+	// External identity of an OpenID Connect provider can be used to log in
+	EVENT_OIDC_IDENTITY_LINKED   EventType = "oidc_identity_linked"
+	EVENT_OIDC_IDENTITY_UNLINKED EventType = "oidc_identity_unlinked"
+)
+
+// How the user was identified when the event happened
+type IdentifierType string
+
+const (
+	IDENTIFIER_LOGIN         IdentifierType = "login"
+	IDENTIFIER_EMAIL         IdentifierType = "email"
+	IDENTIFIER_PHONE_NUMBER  IdentifierType = "phone_number"
+	IDENTIFIER_SECOND_FACTOR IdentifierType = "second_factor"
+	IDENTIFIER_JWT           IdentifierType = "jwt"
+	IDENTIFIER_API_TOKEN     IdentifierType = "api_token"
+	IDENTIFIER_OAUTH_CODE    IdentifierType = "oauth_code"
+	IDENTIFIER_OIDC          IdentifierType = "oidc"
+)
+
+type Event struct {
+	EventId        int64          `json:"event_id"`
+	Type           EventType      `json:"type"`
+	AccountId      int            `json:"account_id"`
+	IdentifierType IdentifierType `json:"identifier_type"`
+	// Address and user agent of the end client, empty for calls of
+	// other services
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	Timestamp time.Time `json:"timestamp"`
+}
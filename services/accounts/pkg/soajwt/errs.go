@@ -0,0 +1,7 @@
+package soajwt
+
+type SessionRevoked struct{}
+
+func (SessionRevoked) Error() string {
+	return "session is terminated"
+}
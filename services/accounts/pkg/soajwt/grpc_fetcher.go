@@ -0,0 +1,30 @@
+package soajwt
+
+import (
+	"context"
+	pb "soa-socialnetwork/services/accounts/proto"
+)
+
+// Fetches key set published by accounts service
+func NewGrpcJwksFetcher(client pb.AccountsServiceClient) JwksFetcher {
+	return func(ctx context.Context) ([]Jwk, error) {
+		resp, err := client.GetJwks(ctx, &pb.Empty{})
+		if err != nil {
+			return nil, err
+		}
+
+		jwks := make([]Jwk, 0, len(resp.Keys))
+		for _, key := range resp.Keys {
+			jwks = append(jwks, Jwk{
+				Kty: key.Kty,
+				Crv: key.Crv,
+				Kid: key.Kid,
+				X:   key.X,
+				Use: key.Use,
+				Alg: key.Alg,
+			})
+		}
+
+		return jwks, nil
+	}
+}
@@ -0,0 +1,51 @@
+package soajwt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Ed25519 public key in JWK format (RFC 8037)
+type Jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	X   string `json:"x"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+func NewEd25519Jwk(pubkey ed25519.PublicKey) Jwk {
+	return Jwk{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		Kid: KeyId(pubkey),
+		X:   base64.RawURLEncoding.EncodeToString(pubkey),
+		Use: "sig",
+		Alg: "EdDSA",
+	}
+}
+
+func (j *Jwk) Ed25519PublicKey() (ed25519.PublicKey, error) {
+	if j.Kty != "OKP" || j.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported key type %s/%s", j.Kty, j.Crv)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(j.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, errors.New("bad ed25519 public key length")
+	}
+
+	pubkey := ed25519.PublicKey(raw)
+	if j.Kid != KeyId(pubkey) {
+		return nil, errors.New("kid does not match public key")
+	}
+
+	return pubkey, nil
+}
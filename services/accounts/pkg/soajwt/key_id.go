@@ -0,0 +1,14 @@
+package soajwt
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// KeyId derives `kid` from public key itself, so issuer and verifiers agree
+// on identifiers without additional configuration
+func KeyId(pubkey ed25519.PublicKey) string {
+	hash := sha256.Sum256(pubkey)
+	return base64.RawURLEncoding.EncodeToString(hash[:12])
+}
@@ -0,0 +1,85 @@
+package soajwt
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier trusting several keys at once, e.g. current and previous ones
+// during key rotation. Keys can be replaced at runtime.
+type KeySetVerifier struct {
+	mu   sync.RWMutex
+	keys map[string]ed25519.PublicKey
+}
+
+func NewKeySetVerifier(pubkeys ...ed25519.PublicKey) *KeySetVerifier {
+	v := &KeySetVerifier{}
+	v.SetKeys(pubkeys...)
+	return v
+}
+
+func (v *KeySetVerifier) SetKeys(pubkeys ...ed25519.PublicKey) {
+	keys := make(map[string]ed25519.PublicKey, len(pubkeys))
+	for _, pubkey := range pubkeys {
+		keys[KeyId(pubkey)] = pubkey
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+}
+
+func (v *KeySetVerifier) Keys() []ed25519.PublicKey {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	keys := make([]ed25519.PublicKey, 0, len(v.keys))
+	for _, key := range v.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (v *KeySetVerifier) Verify(tokenString string) (Token, error) {
+	var token Token
+	parsedToken, err := jwt.ParseWithClaims(tokenString, &token, v.keyFunc, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+	if err != nil {
+		return Token{}, err
+	}
+
+	if !parsedToken.Valid {
+		return Token{}, errors.New("invalid token")
+	}
+
+	return token, nil
+}
+
+func (v *KeySetVerifier) keyFunc(t *jwt.Token) (any, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	kid, hasKid := t.Header["kid"]
+	if !hasKid {
+		// tokens issued before key ids were introduced
+		keySet := jwt.VerificationKeySet{}
+		for _, key := range v.keys {
+			keySet.Keys = append(keySet.Keys, key)
+		}
+		return keySet, nil
+	}
+
+	kidStr, ok := kid.(string)
+	if !ok {
+		return nil, errors.New("malformed kid")
+	}
+
+	key, ok := v.keys[kidStr]
+	if !ok {
+		return nil, errors.New("unknown kid")
+	}
+
+	return key, nil
+}
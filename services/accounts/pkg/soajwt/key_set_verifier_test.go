@@ -0,0 +1,146 @@
+package soajwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildValidJwtWithKid(token Token, priv ed25519.PrivateKey, kid string) string {
+	algoJson := fmt.Sprintf(`{"alg":"EdDSA","typ":"JWT","kid":"%s"}`, kid)
+	algo := base64.RawURLEncoding.EncodeToString([]byte(algoJson))
+
+	payloadBytes, err := json.Marshal(token)
+	if err != nil {
+		panic(err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(payloadBytes)
+
+	verified := algo + "." + payload
+	signRaw, err := priv.Sign(nil, []byte(verified), crypto.Hash(0))
+	if err != nil {
+		panic(err)
+	}
+	sign := base64.RawURLEncoding.EncodeToString(signRaw)
+	return algo + "." + payload + "." + sign
+}
+
+func newTestToken(accountId int) Token {
+	now := time.Now()
+	return Token{
+		Issuer:    "test-issuer",
+		Subject:   "test-subject",
+		Audience:  []string{"test-audience"},
+		ExpiresAt: now.Add(time.Hour),
+		NotBefore: now,
+		IssuedAt:  now,
+		JwtId:     "test-id",
+		AccountId: accountId,
+	}
+}
+
+func generateKey() (ed25519.PublicKey, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return pub, priv
+}
+
+func TestKeySetVerifierRotation(t *testing.T) {
+	prevPub, prevPriv := generateKey()
+	curPub, curPriv := generateKey()
+
+	verifier := NewKeySetVerifier(curPub, prevPub)
+
+	prevJwt := buildValidJwtWithKid(newTestToken(1), prevPriv, KeyId(prevPub))
+	token, err := verifier.Verify(prevJwt)
+	require.NoError(t, err, "token signed by previous key is not verified")
+	assert.Equal(t, 1, token.AccountId)
+
+	curJwt := buildValidJwtWithKid(newTestToken(2), curPriv, KeyId(curPub))
+	token, err = verifier.Verify(curJwt)
+	require.NoError(t, err, "token signed by current key is not verified")
+	assert.Equal(t, 2, token.AccountId)
+
+	verifier.SetKeys(curPub)
+
+	_, err = verifier.Verify(prevJwt)
+	require.Error(t, err, "token signed by removed key is verified")
+
+	_, err = verifier.Verify(curJwt)
+	require.NoError(t, err)
+}
+
+func TestKeySetVerifierWithoutKid(t *testing.T) {
+	prevPub, prevPriv := generateKey()
+	curPub, _ := generateKey()
+
+	verifier := NewKeySetVerifier(curPub, prevPub)
+
+	token, err := verifier.Verify(buildValidJwt(newTestToken(3), prevPriv))
+	require.NoError(t, err, "token without kid is not verified")
+	assert.Equal(t, 3, token.AccountId)
+}
+
+func TestKeySetVerifierUnknownKid(t *testing.T) {
+	curPub, _ := generateKey()
+	otherPub, otherPriv := generateKey()
+
+	verifier := NewKeySetVerifier(curPub)
+
+	_, err := verifier.Verify(buildValidJwtWithKid(newTestToken(4), otherPriv, KeyId(otherPub)))
+	require.Error(t, err, "token with unknown kid is verified")
+
+	// kid of trusted key must not let foreign signature pass
+	_, err = verifier.Verify(buildValidJwtWithKid(newTestToken(4), otherPriv, KeyId(curPub)))
+	require.Error(t, err, "token with forged kid is verified")
+}
+
+func TestJwkRoundTrip(t *testing.T) {
+	pub, _ := generateKey()
+
+	jwk := NewEd25519Jwk(pub)
+	assert.Equal(t, KeyId(pub), jwk.Kid)
+
+	parsed, err := jwk.Ed25519PublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, pub, parsed)
+
+	jwk.Kid = "wrong-kid"
+	_, err = jwk.Ed25519PublicKey()
+	require.Error(t, err, "jwk with mismatched kid is accepted")
+}
+
+func TestRefreshCallback(t *testing.T) {
+	oldPub, oldPriv := generateKey()
+	newPub, newPriv := generateKey()
+
+	verifier := NewKeySetVerifier(oldPub)
+
+	refresh := NewRefreshCallback(verifier, func(context.Context) ([]Jwk, error) {
+		return []Jwk{NewEd25519Jwk(newPub)}, nil
+	})
+	require.NoError(t, refresh(context.Background()))
+
+	_, err := verifier.Verify(buildValidJwtWithKid(newTestToken(5), newPriv, KeyId(newPub)))
+	require.NoError(t, err, "token signed by fetched key is not verified")
+
+	_, err = verifier.Verify(buildValidJwtWithKid(newTestToken(5), oldPriv, KeyId(oldPub)))
+	require.Error(t, err, "token signed by dropped key is verified")
+
+	emptyRefresh := NewRefreshCallback(verifier, func(context.Context) ([]Jwk, error) {
+		return nil, nil
+	})
+	require.Error(t, emptyRefresh(context.Background()))
+	assert.Len(t, verifier.Keys(), 1, "empty key set must not drop current keys")
+}
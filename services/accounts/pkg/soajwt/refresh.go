@@ -0,0 +1,40 @@
+package soajwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"log"
+	"soa-socialnetwork/services/common/backjob"
+)
+
+type JwksFetcher func(context.Context) ([]Jwk, error)
+
+// Job callback that keeps verifier keys in sync with published key set
+func NewRefreshCallback(verifier *KeySetVerifier, fetch JwksFetcher) backjob.JobCallback {
+	return func(ctx context.Context) error {
+		jwks, err := fetch(ctx)
+		if err != nil {
+			return err
+		}
+
+		pubkeys := make([]ed25519.PublicKey, 0, len(jwks))
+		for _, jwk := range jwks {
+			pubkey, err := jwk.Ed25519PublicKey()
+			if err != nil {
+				log.Printf("warning: skipping jwk %s: %v", jwk.Kid, err)
+				continue
+			}
+
+			pubkeys = append(pubkeys, pubkey)
+		}
+
+		// keep old keys rather than reject every token
+		if len(pubkeys) == 0 {
+			return errors.New("no valid keys in fetched key set")
+		}
+
+		verifier.SetKeys(pubkeys...)
+		return nil
+	}
+}
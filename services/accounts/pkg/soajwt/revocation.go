@@ -0,0 +1,85 @@
+package soajwt
+
+import (
+	"sync"
+	"time"
+)
+
+// Kafka topic of events emitted by accounts service when session is terminated
+const SESSION_REVOKED_TOPIC = "session_revoked"
+
+type SessionRevokedEvent struct {
+	SessionId string `json:"session_id"`
+	AccountId int    `json:"account_id"`
+	// Jwts of the session issued before termination are expired after it
+	RevokedUntil time.Time `json:"revoked_until"`
+}
+
+// Sessions terminated while their jwts may still be valid. Entries are
+// dropped once all such jwts have expired.
+type RevocationCache struct {
+	mu       sync.RWMutex
+	sessions map[string]time.Time
+	now      func() time.Time
+}
+
+func NewRevocationCache() *RevocationCache {
+	return &RevocationCache{
+		sessions: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (c *RevocationCache) Revoke(sessionId string, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for id, revokedUntil := range c.sessions {
+		if now.After(revokedUntil) {
+			delete(c.sessions, id)
+		}
+	}
+
+	if now.After(until) {
+		return
+	}
+
+	if revokedUntil, ok := c.sessions[sessionId]; !ok || revokedUntil.Before(until) {
+		c.sessions[sessionId] = until
+	}
+}
+
+func (c *RevocationCache) IsRevoked(sessionId string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	revokedUntil, ok := c.sessions[sessionId]
+	return ok && !c.now().After(revokedUntil)
+}
+
+// Verifier rejecting tokens of terminated sessions
+type RevocationVerifier struct {
+	verifier Verifier
+	cache    *RevocationCache
+}
+
+func NewRevocationVerifier(verifier Verifier, cache *RevocationCache) RevocationVerifier {
+	return RevocationVerifier{
+		verifier: verifier,
+		cache:    cache,
+	}
+}
+
+func (v RevocationVerifier) Verify(tokenString string) (Token, error) {
+	token, err := v.verifier.Verify(tokenString)
+	if err != nil {
+		return Token{}, err
+	}
+
+	if token.SessionId != "" && v.cache.IsRevoked(token.SessionId) {
+		return Token{}, SessionRevoked{}
+	}
+
+	return token, nil
+}
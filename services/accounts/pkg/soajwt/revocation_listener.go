@@ -0,0 +1,51 @@
+package soajwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"soa-socialnetwork/services/common/backjob"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const revocation_read_timeout = 500 * time.Millisecond
+
+// Job callback that applies session revocations published by accounts
+// service to cache. Every consumer reads the whole topic from the beginning,
+// so the topic is expected to have a single partition.
+func NewKafkaRevocationCallback(brokerAddr string, cache *RevocationCache) backjob.JobCallback {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{brokerAddr},
+		Topic:       SESSION_REVOKED_TOPIC,
+		StartOffset: kafka.FirstOffset,
+		MaxWait:     revocation_read_timeout,
+	})
+
+	return func(ctx context.Context) error {
+		for {
+			readCtx, cancel := context.WithTimeout(ctx, revocation_read_timeout)
+			msg, err := reader.ReadMessage(readCtx)
+			cancel()
+
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			var event SessionRevokedEvent
+			err = json.Unmarshal(msg.Value, &event)
+			if err != nil {
+				log.Printf("warning: skipping malformed session revocation: %v", err)
+				continue
+			}
+
+			cache.Revoke(event.SessionId, event.RevokedUntil)
+		}
+	}
+}
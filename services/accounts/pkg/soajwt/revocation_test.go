@@ -0,0 +1,56 @@
+package soajwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationCacheExpiration(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewRevocationCache()
+	cache.now = func() time.Time { return now }
+
+	cache.Revoke("session-1", now.Add(time.Minute))
+	cache.Revoke("session-2", now.Add(-time.Minute))
+	assert.True(t, cache.IsRevoked("session-1"))
+	assert.False(t, cache.IsRevoked("session-2"), "already expired revocation is kept")
+	assert.False(t, cache.IsRevoked("session-3"))
+
+	// earlier revocation must not shorten later one
+	cache.Revoke("session-1", now.Add(time.Second))
+	now = now.Add(30 * time.Second)
+	assert.True(t, cache.IsRevoked("session-1"))
+
+	now = now.Add(time.Minute)
+	assert.False(t, cache.IsRevoked("session-1"))
+
+	cache.Revoke("session-4", now.Add(time.Minute))
+	assert.Len(t, cache.sessions, 1, "expired revocations are not swept")
+}
+
+func TestRevocationVerifier(t *testing.T) {
+	pub, priv := generateKey()
+	cache := NewRevocationCache()
+	verifier := NewRevocationVerifier(NewKeySetVerifier(pub), cache)
+
+	token := newTestToken(5)
+	token.SessionId = "session-1"
+	jwt := buildValidJwtWithKid(token, priv, KeyId(pub))
+
+	verified, err := verifier.Verify(jwt)
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", verified.SessionId)
+
+	// tokens without session can not be revoked
+	legacyJwt := buildValidJwtWithKid(newTestToken(5), priv, KeyId(pub))
+
+	cache.Revoke("session-1", time.Now().Add(time.Minute))
+	_, err = verifier.Verify(jwt)
+	require.ErrorAs(t, err, &SessionRevoked{})
+
+	_, err = verifier.Verify(legacyJwt)
+	require.NoError(t, err)
+}
@@ -0,0 +1,24 @@
+package soajwt
+
+// Role of account, granted by administrators
+type Role string
+
+const (
+	ROLE_USER      Role = "user"
+	ROLE_MODERATOR Role = "moderator"
+	ROLE_ADMIN     Role = "admin"
+)
+
+// Moderators and administrators may delete content of other users
+func (r Role) CanModerate() bool {
+	return r == ROLE_MODERATOR || r == ROLE_ADMIN
+}
+
+// Tokens issued before roles were introduced have no role claim
+func (t *Token) GetRole() Role {
+	if t.Role == "" {
+		return ROLE_USER
+	}
+
+	return t.Role
+}
@@ -0,0 +1,16 @@
+package soajwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRole(t *testing.T) {
+	assert.Equal(t, ROLE_USER, (&Token{}).GetRole())
+	assert.Equal(t, ROLE_MODERATOR, (&Token{Role: ROLE_MODERATOR}).GetRole())
+
+	assert.False(t, ROLE_USER.CanModerate())
+	assert.True(t, ROLE_MODERATOR.CanModerate())
+	assert.True(t, ROLE_ADMIN.CanModerate())
+}
@@ -15,6 +15,10 @@ type Token struct {
 	IssuedAt  time.Time `json:"iat"`
 	JwtId     string    `json:"jti"`
 	AccountId int       `json:"accid"`
+	// Empty for tokens issued before sessions were introduced
+	SessionId string `json:"sid,omitempty"`
+	// Use GetRole, empty for tokens issued before roles were introduced
+	Role Role `json:"role,omitempty"`
 }
 
 func (t *Token) GetExpirationTime() (*jwt.NumericDate, error) {
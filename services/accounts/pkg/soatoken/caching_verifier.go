@@ -0,0 +1,170 @@
+package soatoken
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_RIGHTS_CACHE_CAPACITY = 10000
+
+// Source of token rights which is too expensive to query on every call
+type RightsFetcher interface {
+	// Rejected tokens are reported with Rights.Rejection and cached as well,
+	// returned error means that token state is unknown and is not cached
+	FetchRights(token string) (Rights, error)
+}
+
+type Rights struct {
+	// Zero if unknown, such rights cannot be invalidated by account
+	AccountId int32
+	Scopes    []Scope
+	// Rights are not cached beyond token expiration
+	ValidUntil time.Time
+	// Error returned for rejected token, e.g. InvalidToken
+	Rejection error
+}
+
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	Size   int
+}
+
+// Verifies tokens with rights from fetcher, keeping up to capacity least
+// recently used results for at most ttl. Owner of the rights source must call
+// InvalidateAccount on revocation, otherwise it takes effect with ttl delay.
+type CachingVerifier struct {
+	fetcher  RightsFetcher
+	ttl      time.Duration
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// most recently used entries go first
+	lru *list.List
+	// bumped on invalidation, so that results fetched before it are dropped
+	generation uint64
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type rightsCacheEntry struct {
+	token     string
+	rights    Rights
+	expiresAt time.Time
+}
+
+func NewCachingVerifier(fetcher RightsFetcher, ttl time.Duration, capacity int) *CachingVerifier {
+	return &CachingVerifier{
+		fetcher:  fetcher,
+		ttl:      ttl,
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (v *CachingVerifier) Verify(token string, reqs RightsRequirements) error {
+	rights, err := v.getRights(token)
+	if err != nil {
+		return err
+	}
+
+	if rights.Rejection != nil {
+		return rights.Rejection
+	}
+
+	return CheckScopes(rights.Scopes, reqs)
+}
+
+// Drops cached rights of all tokens of the account
+func (v *CachingVerifier) InvalidateAccount(accountId int32) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.generation++
+	for elem := v.lru.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*rightsCacheEntry)
+		if entry.rights.AccountId == accountId {
+			v.remove(elem)
+		}
+		elem = next
+	}
+}
+
+func (v *CachingVerifier) Stats() CacheStats {
+	v.mu.Lock()
+	size := v.lru.Len()
+	v.mu.Unlock()
+
+	return CacheStats{
+		Hits:   v.hits.Load(),
+		Misses: v.misses.Load(),
+		Size:   size,
+	}
+}
+
+func (v *CachingVerifier) getRights(token string) (Rights, error) {
+	now := time.Now()
+
+	v.mu.Lock()
+	if elem, ok := v.entries[token]; ok {
+		entry := elem.Value.(*rightsCacheEntry)
+		if now.Before(entry.expiresAt) {
+			v.lru.MoveToFront(elem)
+			v.mu.Unlock()
+
+			v.hits.Add(1)
+			return entry.rights, nil
+		}
+
+		v.remove(elem)
+	}
+	generation := v.generation
+	v.mu.Unlock()
+
+	v.misses.Add(1)
+	rights, err := v.fetcher.FetchRights(token)
+	if err != nil {
+		return Rights{}, err
+	}
+
+	// token must not outlive its own validity because of caching
+	expiresAt := now.Add(v.ttl)
+	if rights.Rejection == nil && rights.ValidUntil.Before(expiresAt) {
+		expiresAt = rights.ValidUntil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if generation == v.generation {
+		v.put(token, rights, expiresAt)
+	}
+
+	return rights, nil
+}
+
+func (v *CachingVerifier) put(token string, rights Rights, expiresAt time.Time) {
+	if elem, ok := v.entries[token]; ok {
+		v.remove(elem)
+	}
+
+	for v.lru.Len() >= v.capacity && v.lru.Len() > 0 {
+		v.remove(v.lru.Back())
+	}
+
+	v.entries[token] = v.lru.PushFront(&rightsCacheEntry{
+		token:     token,
+		rights:    rights,
+		expiresAt: expiresAt,
+	})
+}
+
+func (v *CachingVerifier) remove(elem *list.Element) {
+	entry := v.lru.Remove(elem).(*rightsCacheEntry)
+	delete(v.entries, entry.token)
+}
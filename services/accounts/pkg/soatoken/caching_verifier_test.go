@@ -0,0 +1,160 @@
+package soatoken
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRightsFetcher struct {
+	calls  int
+	rights map[string]Rights
+	err    error
+}
+
+func (f *fakeRightsFetcher) FetchRights(token string) (Rights, error) {
+	f.calls++
+	if f.err != nil {
+		return Rights{}, f.err
+	}
+
+	rights, ok := f.rights[token]
+	if !ok {
+		return Rights{Rejection: InvalidToken{}}, nil
+	}
+
+	return rights, nil
+}
+
+func validRights(accountId int32, scopes ...Scope) Rights {
+	return Rights{
+		AccountId:  accountId,
+		Scopes:     scopes,
+		ValidUntil: time.Now().Add(time.Hour),
+	}
+}
+
+func TestCachingVerifierHitsAndMisses(t *testing.T) {
+	fetcher := &fakeRightsFetcher{
+		rights: map[string]Rights{
+			"token": validRights(1, SCOPE_POSTS_READ),
+		},
+	}
+	verifier := NewCachingVerifier(fetcher, time.Minute, 10)
+
+	for range 5 {
+		require.NoError(t, verifier.Verify("token", RightsRequirements{Scope: SCOPE_POSTS_READ}))
+		require.ErrorAs(t, verifier.Verify("token", RightsRequirements{Scope: SCOPE_POSTS_WRITE}), &MissingScope{})
+		require.ErrorAs(t, verifier.Verify("unknown", RightsRequirements{}), &InvalidToken{})
+	}
+
+	assert.Equal(t, 2, fetcher.calls)
+	assert.Equal(t, CacheStats{Hits: 13, Misses: 2, Size: 2}, verifier.Stats())
+}
+
+func TestCachingVerifierFetchErrorNotCached(t *testing.T) {
+	fetcher := &fakeRightsFetcher{
+		rights: map[string]Rights{
+			"token": validRights(1),
+		},
+		err: errors.New("connection refused"),
+	}
+	verifier := NewCachingVerifier(fetcher, time.Minute, 10)
+
+	require.Error(t, verifier.Verify("token", RightsRequirements{}))
+
+	fetcher.err = nil
+	require.NoError(t, verifier.Verify("token", RightsRequirements{}))
+	assert.Equal(t, 2, fetcher.calls)
+}
+
+func TestCachingVerifierEvictsLeastRecentlyUsed(t *testing.T) {
+	fetcher := &fakeRightsFetcher{
+		rights: map[string]Rights{
+			"first":  validRights(1),
+			"second": validRights(2),
+			"third":  validRights(3),
+		},
+	}
+	verifier := NewCachingVerifier(fetcher, time.Minute, 2)
+
+	require.NoError(t, verifier.Verify("first", RightsRequirements{}))
+	require.NoError(t, verifier.Verify("second", RightsRequirements{}))
+	require.NoError(t, verifier.Verify("first", RightsRequirements{}))
+	require.NoError(t, verifier.Verify("third", RightsRequirements{}))
+	assert.Equal(t, 3, fetcher.calls)
+	assert.Equal(t, 2, verifier.Stats().Size)
+
+	require.NoError(t, verifier.Verify("first", RightsRequirements{}))
+	assert.Equal(t, 3, fetcher.calls, "recently used token must stay cached")
+
+	require.NoError(t, verifier.Verify("second", RightsRequirements{}))
+	assert.Equal(t, 4, fetcher.calls, "least recently used token must be evicted")
+}
+
+func TestCachingVerifierTtlCappedByValidUntil(t *testing.T) {
+	fetcher := &fakeRightsFetcher{
+		rights: map[string]Rights{
+			"token": {
+				Scopes:     []Scope{SCOPE_POSTS_READ},
+				ValidUntil: time.Now().Add(100 * time.Millisecond),
+			},
+		},
+	}
+	verifier := NewCachingVerifier(fetcher, time.Hour, 10)
+
+	require.NoError(t, verifier.Verify("token", RightsRequirements{}))
+	require.NoError(t, verifier.Verify("token", RightsRequirements{}))
+	assert.Equal(t, 1, fetcher.calls)
+
+	time.Sleep(200 * time.Millisecond)
+	verifier.Verify("token", RightsRequirements{})
+	assert.Equal(t, 2, fetcher.calls, "expired token must be refetched")
+}
+
+func TestCachingVerifierInvalidateAccount(t *testing.T) {
+	fetcher := &fakeRightsFetcher{
+		rights: map[string]Rights{
+			"first":  validRights(1),
+			"second": validRights(1),
+			"other":  validRights(2),
+		},
+	}
+	verifier := NewCachingVerifier(fetcher, time.Hour, 10)
+
+	for _, token := range []string{"first", "second", "other"} {
+		require.NoError(t, verifier.Verify(token, RightsRequirements{}))
+	}
+
+	revoked := fetcher.rights["first"]
+	revoked.Rejection = InvalidToken{}
+	fetcher.rights["first"] = revoked
+	verifier.InvalidateAccount(1)
+	assert.Equal(t, 1, verifier.Stats().Size)
+
+	require.ErrorAs(t, verifier.Verify("first", RightsRequirements{}), &InvalidToken{})
+	require.NoError(t, verifier.Verify("other", RightsRequirements{}))
+	assert.Equal(t, 4, fetcher.calls)
+}
+
+type invalidatingFetcher struct {
+	verifier *CachingVerifier
+}
+
+func (f *invalidatingFetcher) FetchRights(token string) (Rights, error) {
+	// revocation is committed while stale rights are being fetched
+	f.verifier.InvalidateAccount(1)
+	return validRights(1), nil
+}
+
+func TestCachingVerifierDropsRightsFetchedBeforeInvalidation(t *testing.T) {
+	fetcher := &invalidatingFetcher{}
+	verifier := NewCachingVerifier(fetcher, time.Hour, 10)
+	fetcher.verifier = verifier
+
+	require.NoError(t, verifier.Verify("token", RightsRequirements{}))
+	assert.Equal(t, 0, verifier.Stats().Size)
+}
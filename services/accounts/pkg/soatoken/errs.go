@@ -0,0 +1,17 @@
+package soatoken
+
+import "fmt"
+
+type InvalidToken struct{}
+
+type MissingScope struct {
+	Scope Scope
+}
+
+func (InvalidToken) Error() string {
+	return "invalid soa token"
+}
+
+func (e MissingScope) Error() string {
+	return fmt.Sprintf("api token has no %s scope", e.Scope)
+}
@@ -0,0 +1,69 @@
+package soatoken
+
+import (
+	"context"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const DEFAULT_REMOTE_VERIFIER_CACHE_TTL = 10 * time.Second
+
+type ApiTokenValidator interface {
+	ValidateApiToken(ctx context.Context, in *pb.ApiToken, opts ...grpc.CallOption) (*pb.ApiTokenValidity, error)
+}
+
+// Verifies tokens with accounts service ValidateApiToken. Results, both positive
+// and negative, are cached for a short time; revocations published by accounts
+// service drop them at once when applied with NewKafkaInvalidationCallback,
+// otherwise revocation takes effect with at most cache ttl delay.
+func NewRemoteVerifier(client ApiTokenValidator, ttl time.Duration) *CachingVerifier {
+	return NewCachingVerifier(remoteRightsFetcher{client: client}, ttl, DEFAULT_RIGHTS_CACHE_CAPACITY)
+}
+
+type remoteRightsFetcher struct {
+	client ApiTokenValidator
+}
+
+func (f remoteRightsFetcher) FetchRights(token string) (Rights, error) {
+	// accounts service accepts only tokens whose payload names the owner,
+	// malformed tokens are rejected and need no invalidation
+	var accountId int32
+	parsed, err := Parse(token)
+	if err == nil {
+		accountId = parsed.AccountId
+	}
+
+	resp, err := f.client.ValidateApiToken(context.Background(), &pb.ApiToken{
+		Token: token,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return Rights{AccountId: accountId, Rejection: InvalidToken{}}, nil
+		}
+
+		return Rights{}, err
+	}
+
+	valid := resp.GetValid()
+	if valid == nil {
+		return Rights{AccountId: accountId, Rejection: InvalidToken{}}, nil
+	}
+
+	// unknown scopes may come from newer accounts service, they cannot be
+	// required by this service anyway
+	scopes := make([]Scope, 0, len(valid.Scopes))
+	for _, raw := range valid.Scopes {
+		scopes = append(scopes, Scope(raw))
+	}
+
+	return Rights{
+		AccountId:  accountId,
+		Scopes:     scopes,
+		ValidUntil: valid.ValidUntil.AsTime(),
+	}, nil
+}
@@ -0,0 +1,147 @@
+package soatoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "soa-socialnetwork/services/accounts/proto"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type fakeAccountsClient struct {
+	calls  int
+	tokens map[string]*pb.ApiTokenValidity_Valid
+}
+
+func (c *fakeAccountsClient) ValidateApiToken(ctx context.Context, in *pb.ApiToken, opts ...grpc.CallOption) (*pb.ApiTokenValidity, error) {
+	c.calls++
+
+	valid, ok := c.tokens[in.Token]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "token not found")
+	}
+
+	return &pb.ApiTokenValidity{
+		Result: &pb.ApiTokenValidity_Valid_{
+			Valid: valid,
+		},
+	}, nil
+}
+
+func TestRemoteVerifierRights(t *testing.T) {
+	client := &fakeAccountsClient{
+		tokens: map[string]*pb.ApiTokenValidity_Valid{
+			"posts_reader": {
+				Scopes:     []string{"posts:read"},
+				ValidUntil: timestamppb.New(time.Now().Add(time.Hour)),
+			},
+		},
+	}
+	verifier := NewRemoteVerifier(client, time.Minute)
+
+	require.NoError(t, verifier.Verify("posts_reader", RightsRequirements{Scope: SCOPE_POSTS_READ}))
+	require.NoError(t, verifier.Verify("posts_reader", RightsRequirements{}))
+
+	err := verifier.Verify("posts_reader", RightsRequirements{Scope: SCOPE_POSTS_WRITE})
+	require.ErrorAs(t, err, &MissingScope{})
+	assert.Contains(t, err.Error(), "posts:write")
+
+	require.ErrorAs(t, verifier.Verify("unknown", RightsRequirements{}), &InvalidToken{})
+}
+
+func TestRemoteVerifierCache(t *testing.T) {
+	client := &fakeAccountsClient{
+		tokens: map[string]*pb.ApiTokenValidity_Valid{
+			"token": {
+				Scopes:     []string{"posts:read", "posts:write"},
+				ValidUntil: timestamppb.New(time.Now().Add(time.Hour)),
+			},
+		},
+	}
+	verifier := NewRemoteVerifier(client, time.Minute)
+
+	for range 10 {
+		require.NoError(t, verifier.Verify("token", RightsRequirements{Scope: SCOPE_POSTS_READ}))
+		require.Error(t, verifier.Verify("unknown", RightsRequirements{}))
+	}
+
+	assert.Equal(t, 2, client.calls, "results must be cached")
+}
+
+func TestRemoteVerifierCacheExpiration(t *testing.T) {
+	client := &fakeAccountsClient{
+		tokens: map[string]*pb.ApiTokenValidity_Valid{
+			"token": {
+				Scopes:     []string{"posts:read"},
+				ValidUntil: timestamppb.New(time.Now().Add(time.Hour)),
+			},
+		},
+	}
+	verifier := NewRemoteVerifier(client, 100*time.Millisecond)
+
+	require.NoError(t, verifier.Verify("token", RightsRequirements{}))
+
+	// revocation becomes visible after cache ttl
+	delete(client.tokens, "token")
+	require.NoError(t, verifier.Verify("token", RightsRequirements{}))
+
+	time.Sleep(200 * time.Millisecond)
+	require.ErrorAs(t, verifier.Verify("token", RightsRequirements{}), &InvalidToken{})
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestRemoteVerifierTokenExpiresBeforeTtl(t *testing.T) {
+	client := &fakeAccountsClient{
+		tokens: map[string]*pb.ApiTokenValidity_Valid{
+			"token": {
+				Scopes:     []string{"posts:read"},
+				ValidUntil: timestamppb.New(time.Now().Add(100 * time.Millisecond)),
+			},
+		},
+	}
+	verifier := NewRemoteVerifier(client, time.Hour)
+
+	require.NoError(t, verifier.Verify("token", RightsRequirements{}))
+
+	time.Sleep(200 * time.Millisecond)
+	verifier.Verify("token", RightsRequirements{})
+	assert.Equal(t, 2, client.calls, "expired token must be revalidated")
+}
+
+func TestRemoteVerifierInvalidateAccount(t *testing.T) {
+	profileId := uuid.New()
+	token := NewSoaToken(Payload{AccountId: 42, ProfileId: profileId})
+	otherToken := NewSoaToken(Payload{AccountId: 43, ProfileId: profileId})
+
+	client := &fakeAccountsClient{
+		tokens: map[string]*pb.ApiTokenValidity_Valid{
+			token: {
+				Scopes:     []string{"posts:read"},
+				ValidUntil: timestamppb.New(time.Now().Add(time.Hour)),
+			},
+			otherToken: {
+				Scopes:     []string{"posts:read"},
+				ValidUntil: timestamppb.New(time.Now().Add(time.Hour)),
+			},
+		},
+	}
+	verifier := NewRemoteVerifier(client, time.Minute)
+
+	require.NoError(t, verifier.Verify(token, RightsRequirements{}))
+	require.NoError(t, verifier.Verify(otherToken, RightsRequirements{}))
+
+	delete(client.tokens, token)
+	verifier.InvalidateAccount(42)
+
+	require.ErrorAs(t, verifier.Verify(token, RightsRequirements{}), &InvalidToken{})
+	require.NoError(t, verifier.Verify(otherToken, RightsRequirements{}))
+	assert.Equal(t, 3, client.calls, "rights of other accounts must stay cached")
+}
@@ -0,0 +1,58 @@
+package soatoken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"soa-socialnetwork/services/common/backjob"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka topic of events emitted by accounts service when api tokens of the
+// account are revoked or its status changes
+const API_TOKENS_REVOKED_TOPIC = "api_tokens_revoked"
+
+type ApiTokensRevokedEvent struct {
+	AccountId int `json:"account_id"`
+}
+
+const invalidation_read_timeout = 500 * time.Millisecond
+
+// Job callback that drops cached rights of accounts whose tokens are revoked.
+// Cache starts empty, so only events published after start are read.
+func NewKafkaInvalidationCallback(brokerAddr string, verifier *CachingVerifier) backjob.JobCallback {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{brokerAddr},
+		Topic:       API_TOKENS_REVOKED_TOPIC,
+		StartOffset: kafka.LastOffset,
+		MaxWait:     invalidation_read_timeout,
+	})
+
+	return func(ctx context.Context) error {
+		for {
+			readCtx, cancel := context.WithTimeout(ctx, invalidation_read_timeout)
+			msg, err := reader.ReadMessage(readCtx)
+			cancel()
+
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			var event ApiTokensRevokedEvent
+			err = json.Unmarshal(msg.Value, &event)
+			if err != nil {
+				log.Printf("warning: skipping malformed api tokens revocation: %v", err)
+				continue
+			}
+
+			verifier.InvalidateAccount(int32(event.AccountId))
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package soatoken
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Named permission of api token, methods of every service declare
+// which scope they require
+type Scope string
+
+const (
+	SCOPE_ACCOUNT_READ    Scope = "account:read"
+	SCOPE_ACCOUNT_MANAGE  Scope = "account:manage"
+	SCOPE_PROFILE_WRITE   Scope = "profile:write"
+	SCOPE_TOKENS_READ     Scope = "tokens:read"
+	SCOPE_TOKENS_MANAGE   Scope = "tokens:manage"
+	SCOPE_POSTS_READ      Scope = "posts:read"
+	SCOPE_POSTS_WRITE     Scope = "posts:write"
+	SCOPE_COMMENTS_READ   Scope = "comments:read"
+	SCOPE_COMMENTS_WRITE  Scope = "comments:write"
+	SCOPE_REACTIONS_WRITE Scope = "reactions:write"
+	SCOPE_STATS_READ      Scope = "stats:read"
+)
+
+var all_scopes = []Scope{
+	SCOPE_ACCOUNT_READ,
+	SCOPE_ACCOUNT_MANAGE,
+	SCOPE_PROFILE_WRITE,
+	SCOPE_TOKENS_READ,
+	SCOPE_TOKENS_MANAGE,
+	SCOPE_POSTS_READ,
+	SCOPE_POSTS_WRITE,
+	SCOPE_COMMENTS_READ,
+	SCOPE_COMMENTS_WRITE,
+	SCOPE_REACTIONS_WRITE,
+	SCOPE_STATS_READ,
+}
+
+func AllScopes() []Scope {
+	return append([]Scope(nil), all_scopes...)
+}
+
+func ParseScope(s string) (Scope, error) {
+	for _, scope := range all_scopes {
+		if string(scope) == s {
+			return scope, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown scope %q", s)
+}
+
+func ParseScopes(raw []string) ([]Scope, error) {
+	scopes := make([]Scope, 0, len(raw))
+	for _, s := range raw {
+		scope, err := ParseScope(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if !HasScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+func (s Scope) IsRead() bool {
+	return strings.HasSuffix(string(s), ":read")
+}
+
+// Scopes equivalent to read/write rights of tokens created before scopes
+// were introduced
+func ScopesFromRights(readAccess bool, writeAccess bool) []Scope {
+	scopes := make([]Scope, 0, len(all_scopes))
+	for _, scope := range all_scopes {
+		if (scope.IsRead() && readAccess) || (!scope.IsRead() && writeAccess) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// Coarse rights for clients which do not know about scopes
+func RightsFromScopes(scopes []Scope) (readAccess bool, writeAccess bool) {
+	for _, scope := range scopes {
+		if scope.IsRead() {
+			readAccess = true
+		} else {
+			writeAccess = true
+		}
+	}
+
+	return readAccess, writeAccess
+}
+
+func HasScope(scopes []Scope, scope Scope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ScopesToStrings(scopes []Scope) []string {
+	raw := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		raw = append(raw, string(scope))
+	}
+
+	return raw
+}
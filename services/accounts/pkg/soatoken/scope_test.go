@@ -0,0 +1,50 @@
+package soatoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"posts:write", "comments:read", "posts:write"})
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{SCOPE_POSTS_WRITE, SCOPE_COMMENTS_READ}, scopes)
+
+	_, err = ParseScopes([]string{"posts:write", "posts:delete"})
+	require.Error(t, err)
+}
+
+func TestScopesFromRights(t *testing.T) {
+	readScopes := ScopesFromRights(true, false)
+	assert.Contains(t, readScopes, SCOPE_POSTS_READ)
+	assert.Contains(t, readScopes, SCOPE_STATS_READ)
+	assert.NotContains(t, readScopes, SCOPE_POSTS_WRITE)
+
+	writeScopes := ScopesFromRights(false, true)
+	assert.Contains(t, writeScopes, SCOPE_POSTS_WRITE)
+	assert.Contains(t, writeScopes, SCOPE_TOKENS_MANAGE)
+	assert.NotContains(t, writeScopes, SCOPE_POSTS_READ)
+
+	assert.ElementsMatch(t, AllScopes(), ScopesFromRights(true, true))
+	assert.Empty(t, ScopesFromRights(false, false))
+}
+
+func TestRightsFromScopes(t *testing.T) {
+	read, write := RightsFromScopes([]Scope{SCOPE_COMMENTS_READ})
+	assert.True(t, read)
+	assert.False(t, write)
+
+	read, write = RightsFromScopes([]Scope{SCOPE_COMMENTS_WRITE})
+	assert.False(t, read)
+	assert.True(t, write)
+}
+
+func TestCheckScopes(t *testing.T) {
+	scopes := []Scope{SCOPE_POSTS_READ, SCOPE_POSTS_WRITE}
+
+	require.NoError(t, CheckScopes(scopes, RightsRequirements{}))
+	require.NoError(t, CheckScopes(scopes, RightsRequirements{Scope: SCOPE_POSTS_WRITE}))
+	require.ErrorAs(t, CheckScopes(scopes, RightsRequirements{Scope: SCOPE_COMMENTS_WRITE}), &MissingScope{})
+}
@@ -1,10 +1,19 @@
 package soatoken
 
+// Empty scope means that any valid token is accepted
 type RightsRequirements struct {
-	Read  bool
-	Write bool
+	Scope Scope
 }
 
 type Verifier interface {
 	Verify(token string, r RightsRequirements) error
 }
+
+// Returns MissingScope if requirements are not satisfied by token scopes
+func CheckScopes(scopes []Scope, reqs RightsRequirements) error {
+	if reqs.Scope != "" && !HasScope(scopes, reqs.Scope) {
+		return MissingScope{Scope: reqs.Scope}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package totp implements time-based one-time passwords (RFC 6238) with
+// parameters supported by common authenticator apps: HMAC-SHA1, 6 digits
+// and 30 second period.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DIGITS = 6
+const PERIOD = 30 * time.Second
+const SECRET_LENGTH = 20
+
+// Codes of adjacent steps are accepted to tolerate clock drift between
+// server and user device
+const ALLOWED_SKEW_STEPS = 1
+
+var secret_encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, SECRET_LENGTH)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Base32 form of secret which users enter into authenticator apps
+func EncodeSecret(secret []byte) string {
+	return secret_encoding.EncodeToString(secret)
+}
+
+func DecodeSecret(encoded string) ([]byte, error) {
+	return secret_encoding.DecodeString(strings.ToUpper(strings.ReplaceAll(encoded, " ", "")))
+}
+
+// URI in Key Uri Format, usually shown to user as QR code
+func KeyUri(issuer string, accountName string, secret []byte) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+	query := url.Values{}
+	query.Set("secret", EncodeSecret(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(DIGITS))
+	query.Set("period", fmt.Sprint(int(PERIOD.Seconds())))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// Number of periods since Unix epoch
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(PERIOD.Seconds())
+}
+
+func Generate(secret []byte, t time.Time) string {
+	return generateForStep(secret, Step(t))
+}
+
+// Returns step of matched code, so that caller may reject reuse of the code
+// by remembering the last accepted step
+func Validate(secret []byte, code string, t time.Time) (step int64, ok bool) {
+	if len(code) != DIGITS {
+		return 0, false
+	}
+
+	current := Step(t)
+	for delta := int64(-ALLOWED_SKEW_STEPS); delta <= ALLOWED_SKEW_STEPS; delta++ {
+		expected := generateForStep(secret, current+delta)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + delta, true
+		}
+	}
+
+	return 0, false
+}
+
+func generateForStep(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	binCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range DIGITS {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", DIGITS, binCode%mod)
+}
@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SHA1 test vectors from RFC 6238 appendix B, truncated to 6 digits
+func TestGenerateRfcVectors(t *testing.T) {
+	secret := []byte("12345678901234567890")
+
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		assert.Equal(t, v.code, Generate(secret, time.Unix(v.unix, 0)), "time %d", v.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	code := Generate(secret, now)
+
+	step, ok := Validate(secret, code, now)
+	require.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// previous and next periods are accepted for clock drift
+	step, ok = Validate(secret, code, now.Add(PERIOD))
+	require.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	_, ok = Validate(secret, code, now.Add(-PERIOD))
+	assert.True(t, ok)
+
+	_, ok = Validate(secret, code, now.Add(2*PERIOD))
+	assert.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestSecretEncoding(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, SECRET_LENGTH)
+
+	encoded := EncodeSecret(secret)
+	assert.NotContains(t, encoded, "=")
+
+	decoded, err := DecodeSecret(strings.ToLower(encoded))
+	require.NoError(t, err)
+	assert.Equal(t, secret, decoded)
+}
+
+func TestKeyUri(t *testing.T) {
+	uri := KeyUri("SOA Social Network", "alice", []byte("12345678901234567890"))
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/SOA%20Social%20Network:alice?"))
+	assert.Contains(t, uri, "secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	assert.Contains(t, uri, "issuer=SOA+Social+Network")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
@@ -0,0 +1,28 @@
+package envvar
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func TryBoolFromEnv(key string) (bool, error) {
+	s, err := TryStringFromEnv(key)
+	if err != nil {
+		return false, err
+	}
+
+	val, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("cannot unparse %s env variable: %v", key, err)
+	}
+
+	return val, nil
+}
+
+func MustBoolFromEnv(key string) bool {
+	val, err := TryBoolFromEnv(key)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 func TryEd25519PubKeyFromEnv(key string) (ed25519.PublicKey, error) {
@@ -56,3 +57,40 @@ func MustEd25519PrivKeyFromEnv(key string) ed25519.PrivateKey {
 
 	return val
 }
+
+// Comma separated list of hex-encoded keys, empty list if variable is not set
+func TryEd25519PubKeyListFromEnv(key string) ([]ed25519.PublicKey, error) {
+	listStr, err := TryStringFromEnv(key)
+	if err != nil {
+		return nil, nil
+	}
+
+	pubkeys := make([]ed25519.PublicKey, 0)
+	for _, pubkeyStr := range strings.Split(listStr, ",") {
+		pubkeyStr = strings.TrimSpace(pubkeyStr)
+		if pubkeyStr == "" {
+			continue
+		}
+
+		pubkey, err := hex.DecodeString(pubkeyStr)
+		if err != nil {
+			return nil, fmt.Errorf("hex decoding error while parsing ed25519 public key from %s (%s): %v", key, pubkeyStr, err)
+		}
+
+		if len(pubkey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("bad ed25519 public key length in %s (%s)", key, pubkeyStr)
+		}
+
+		pubkeys = append(pubkeys, ed25519.PublicKey(pubkey))
+	}
+
+	return pubkeys, nil
+}
+
+func MustEd25519PubKeyListFromEnv(key string) []ed25519.PublicKey {
+	val, err := TryEd25519PubKeyListFromEnv(key)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
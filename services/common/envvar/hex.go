@@ -0,0 +1,34 @@
+package envvar
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// Decoded value must be at least minLength bytes long, so that short secret
+// keys are not accepted
+func TryHexBytesFromEnv(key string, minLength int) ([]byte, error) {
+	hexStr, err := TryStringFromEnv(key)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, fmt.Errorf("hex decoding error while parsing %s: %v", key, err)
+	}
+
+	if len(val) < minLength {
+		return nil, fmt.Errorf("%s must be at least %d bytes long, got %d", key, minLength, len(val))
+	}
+
+	return val, nil
+}
+
+func MustHexBytesFromEnv(key string, minLength int) []byte {
+	val, err := TryHexBytesFromEnv(key, minLength)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"soa-socialnetwork/services/gateway/pkg/types"
+	"time"
+)
+
+type Suspension struct {
+	Reason      string    `json:"reason"`
+	SuspendedAt time.Time `json:"suspended_at"`
+	// Missing for permanent suspension
+	Until types.Optional[time.Time] `json:"until"`
+}
+
+type AccountStatus struct {
+	Role types.Role `json:"role"`
+	// Missing if account is not suspended
+	Suspension types.Optional[Suspension] `json:"suspension"`
+}
+
+type SetAccountRoleRequest struct {
+	Role types.Role `json:"role"`
+}
+
+// Suspension is permanent if until is missing
+type SuspendAccountRequest struct {
+	Reason string                    `json:"reason"`
+	Until  types.Optional[time.Time] `json:"until"`
+}
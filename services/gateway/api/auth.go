@@ -3,9 +3,15 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
 	"soa-socialnetwork/services/gateway/pkg/types"
+	"time"
 )
 
+const API_TOKEN_NAME_MAX_LENGTH = 64
+
 // Exactly one of {login,email,phone_number} must have value
 type AuthenticateRequestSchema struct {
 	Login       types.Optional[types.Login]       `json:"login"`
@@ -18,15 +24,31 @@ type AuthenticateRequest struct {
 	AuthenticateRequestSchema
 }
 
+// If second factor is set, other fields are empty
 type AuthenticateResponse struct {
-	Token string `json:"token"`
+	Token                  string                                `json:"token"`
+	RefreshToken           string                                `json:"refresh_token"`
+	RefreshTokenValidUntil time.Time                             `json:"refresh_token_valid_until"`
+	SecondFactor           types.Optional[SecondFactorChallenge] `json:"second_factor"`
+}
+
+// JSON Web Key Set of keys trusted for jwt verification
+type JwksResponse struct {
+	Keys []soajwt.Jwk `json:"keys"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
-// Ttl (time to live) must be positive
+// Ttl (time to live) must be positive, name is optional. If scopes
+// are set, read/write access flags are ignored
 type CreateApiTokenRequestSchema struct {
 	Auth        AuthenticateRequest `json:"auth"`
+	Name        string              `json:"name"`
 	ReadAccess  bool                `json:"read_access"`
 	WriteAccess bool                `json:"write_access"`
+	Scopes      []string            `json:"scopes"`
 	Ttl         types.Duration      `json:"ttl"`
 }
 
@@ -34,8 +56,83 @@ type CreateApiTokenRequest struct {
 	CreateApiTokenRequestSchema
 }
 
+// If second factor is set, other fields are empty
 type CreateApiTokenResponse struct {
-	Token string `json:"token"`
+	Token        string                                `json:"token"`
+	TokenId      int32                                 `json:"token_id"`
+	ValidUntil   time.Time                             `json:"valid_until"`
+	SecondFactor types.Optional[SecondFactorChallenge] `json:"second_factor"`
+}
+
+type ApiTokenInfo struct {
+	Id          int32                     `json:"id"`
+	Name        string                    `json:"name"`
+	ReadAccess  bool                      `json:"read_access"`
+	WriteAccess bool                      `json:"write_access"`
+	Scopes      []string                  `json:"scopes"`
+	Revoked     bool                      `json:"revoked"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	ValidUntil  time.Time                 `json:"valid_until"`
+	LastUsedAt  types.Optional[time.Time] `json:"last_used_at"`
+}
+
+type ListApiTokensResponse struct {
+	Tokens []ApiTokenInfo `json:"tokens"`
+}
+
+type RevokeAllApiTokensResponse struct {
+	RevokedCount int32 `json:"revoked_count"`
+}
+
+type AuthLockout struct {
+	Key          string                    `json:"key"`
+	FailedCount  int32                     `json:"failed_count"`
+	LastFailedAt time.Time                 `json:"last_failed_at"`
+	LockedUntil  types.Optional[time.Time] `json:"locked_until"`
+}
+
+type ListAuthLockoutsResponse struct {
+	Lockouts []AuthLockout `json:"lockouts"`
+}
+
+type AuditEvent struct {
+	EventId        int64     `json:"event_id"`
+	Type           string    `json:"type"`
+	IdentifierType string    `json:"identifier_type"`
+	ClientIp       string    `json:"client_ip"`
+	UserAgent      string    `json:"user_agent"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ListAuditEventsResponse struct {
+	Events        []AuditEvent `json:"events"`
+	NextPageToken string       `json:"next_page_token"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword types.Password `json:"old_password"`
+	NewPassword types.Password `json:"new_password"`
+}
+
+// Exactly one of {login,email,phone_number} must have value
+type UserIdSchema struct {
+	Login       types.Optional[types.Login]       `json:"login"`
+	Email       types.Optional[types.Email]       `json:"email"`
+	PhoneNumber types.Optional[types.PhoneNumber] `json:"phone_number"`
+}
+
+type RequestPasswordResetRequest struct {
+	UserIdSchema
+}
+
+type ResetPasswordRequestSchema struct {
+	UserIdSchema
+	Code        string         `json:"code"`
+	NewPassword types.Password `json:"new_password"`
+}
+
+type ResetPasswordRequest struct {
+	ResetPasswordRequestSchema
 }
 
 func (r *AuthenticateRequest) UnmarshalJSON(b []byte) error {
@@ -44,20 +141,63 @@ func (r *AuthenticateRequest) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	if !request.Login.HasValue && !request.PhoneNumber.HasValue && !request.Email.HasValue {
+	err := checkUserIdCount(request.Login.HasValue, request.Email.HasValue, request.PhoneNumber.HasValue)
+	if err != nil {
+		return err
+	}
+
+	r.AuthenticateRequestSchema = request
+
+	return nil
+}
+
+func (r *RequestPasswordResetRequest) UnmarshalJSON(b []byte) error {
+	var request UserIdSchema
+	if err := json.Unmarshal(b, &request); err != nil {
+		return err
+	}
+
+	err := checkUserIdCount(request.Login.HasValue, request.Email.HasValue, request.PhoneNumber.HasValue)
+	if err != nil {
+		return err
+	}
+
+	r.UserIdSchema = request
+	return nil
+}
+
+func (r *ResetPasswordRequest) UnmarshalJSON(b []byte) error {
+	var request ResetPasswordRequestSchema
+	if err := json.Unmarshal(b, &request); err != nil {
+		return err
+	}
+
+	err := checkUserIdCount(request.Login.HasValue, request.Email.HasValue, request.PhoneNumber.HasValue)
+	if err != nil {
+		return err
+	}
+
+	if request.Code == "" {
+		return errors.New("code is empty")
+	}
+
+	r.ResetPasswordRequestSchema = request
+	return nil
+}
+
+// Checks that exactly one user id has been passed
+func checkUserIdCount(hasLogin bool, hasEmail bool, hasPhoneNumber bool) error {
+	if !hasLogin && !hasEmail && !hasPhoneNumber {
 		return ErrorNoUserId{}
 	}
 
-	// checking that exactly one user id has been passed
-	if request.Login.HasValue && (request.Email.HasValue || request.PhoneNumber.HasValue) {
+	if hasLogin && (hasEmail || hasPhoneNumber) {
 		return ErrorTooMuchUserId{}
 	}
-	if request.Email.HasValue && request.PhoneNumber.HasValue {
+	if hasEmail && hasPhoneNumber {
 		return ErrorTooMuchUserId{}
 	}
 
-	r.AuthenticateRequestSchema = request
-
 	return nil
 }
 
@@ -71,6 +211,14 @@ func (r *CreateApiTokenRequest) UnmarshalJSON(b []byte) error {
 		return errors.New("ttl must be > 0")
 	}
 
+	if len(request.Name) > API_TOKEN_NAME_MAX_LENGTH {
+		return fmt.Errorf("name must be at most %d characters long", API_TOKEN_NAME_MAX_LENGTH)
+	}
+
+	if _, err := soatoken.ParseScopes(request.Scopes); err != nil {
+		return err
+	}
+
 	r.CreateApiTokenRequestSchema = request
 	return nil
 }
@@ -2,7 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"soa-socialnetwork/services/gateway/pkg/types"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -55,3 +57,120 @@ func TestAuthNoUserId(t *testing.T) {
 	_, err := unmarshal[AuthenticateRequest](rawJson)
 	require.ErrorAs(t, err, &ErrorNoUserId{})
 }
+
+func TestResetPasswordSimple(t *testing.T) {
+	rawJson := `
+{
+	"email": "some_email@yahoo.com",
+	"code": "123456",
+	"new_password": "new_passwd"
+}
+	`
+
+	req, err := unmarshal[ResetPasswordRequest](rawJson)
+	require.NoError(t, err, "valid json unmarshalling failed")
+
+	assert.True(t, req.Email.HasValue)
+	assert.Equal(t, "some_email@yahoo.com", string(req.Email.Value))
+	assert.Equal(t, "123456", req.Code)
+	assert.Equal(t, "new_passwd", string(req.NewPassword))
+}
+
+func TestResetPasswordTooMuchUserId(t *testing.T) {
+	rawJson := `
+{
+	"login": "some_login",
+	"phone_number": "+79250000000",
+	"code": "123456",
+	"new_password": "new_passwd"
+}
+	`
+
+	_, err := unmarshal[ResetPasswordRequest](rawJson)
+	require.ErrorAs(t, err, &ErrorTooMuchUserId{})
+}
+
+func TestRequestPasswordResetNoUserId(t *testing.T) {
+	_, err := unmarshal[RequestPasswordResetRequest](`{}`)
+	require.ErrorAs(t, err, &ErrorNoUserId{})
+}
+
+func TestSecondFactorSimple(t *testing.T) {
+	rawJson := `
+{
+	"challenge": "some_challenge",
+	"code": "123456"
+}
+	`
+
+	req, err := unmarshal[SecondFactorRequest](rawJson)
+	require.NoError(t, err, "valid json unmarshalling failed")
+
+	assert.Equal(t, "some_challenge", req.Challenge)
+	assert.Equal(t, "123456", req.Code)
+}
+
+func TestSecondFactorNoCode(t *testing.T) {
+	_, err := unmarshal[SecondFactorRequest](`{"challenge": "some_challenge"}`)
+	require.Error(t, err)
+
+	_, err = unmarshal[TotpCodeRequest](`{}`)
+	require.Error(t, err)
+}
+
+func TestAuthenticateResponseSecondFactor(t *testing.T) {
+	validUntil := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	b, err := json.Marshal(AuthenticateResponse{
+		SecondFactor: types.Optional[SecondFactorChallenge]{
+			Value: SecondFactorChallenge{
+				Challenge:  "some_challenge",
+				ValidUntil: validUntil,
+			},
+			HasValue: true,
+		},
+	})
+	require.NoError(t, err)
+
+	resp, err := unmarshal[map[string]any](string(b))
+	require.NoError(t, err)
+	assert.Equal(t, "", resp["token"])
+	assert.Equal(t, "some_challenge", resp["second_factor"].(map[string]any)["challenge"])
+
+	b, err = json.Marshal(AuthenticateResponse{Token: "token"})
+	require.NoError(t, err)
+
+	resp, err = unmarshal[map[string]any](string(b))
+	require.NoError(t, err)
+	assert.Nil(t, resp["second_factor"])
+}
+
+func TestCreateApiTokenScopes(t *testing.T) {
+	rawJson := `
+{
+	"auth": {
+		"login": "some_login",
+		"password": "some_passwd"
+	},
+	"scopes": ["posts:read", "comments:write"],
+	"ttl": "1h"
+}
+	`
+
+	req, err := unmarshal[CreateApiTokenRequest](rawJson)
+	require.NoError(t, err, "valid json unmarshalling failed")
+	assert.Equal(t, []string{"posts:read", "comments:write"}, req.Scopes)
+
+	rawJson = `
+{
+	"auth": {
+		"login": "some_login",
+		"password": "some_passwd"
+	},
+	"scopes": ["posts:delete"],
+	"ttl": "1h"
+}
+	`
+
+	_, err = unmarshal[CreateApiTokenRequest](rawJson)
+	require.ErrorContains(t, err, "unknown scope")
+}
@@ -14,6 +14,7 @@ type NewCommentResponse struct {
 type Comment struct {
 	Id             int32                 `json:"id"`
 	AuthorId       int32                 `json:"author_id"`
+	Author         *Author               `json:"author,omitempty"`
 	Content        string                `json:"content"`
 	ReplyCommentId types.Optional[int32] `json:"reply_comment_id"`
 }
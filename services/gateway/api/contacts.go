@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"soa-socialnetwork/services/gateway/pkg/types"
+)
+
+type ContactKind string
+
+const (
+	CONTACT_EMAIL        ContactKind = "email"
+	CONTACT_PHONE_NUMBER ContactKind = "phone_number"
+)
+
+type ContactsResponse struct {
+	Email               string `json:"email"`
+	EmailVerified       bool   `json:"email_verified"`
+	PhoneNumber         string `json:"phone_number"`
+	PhoneNumberVerified bool   `json:"phone_number_verified"`
+}
+
+type ChangeEmailRequest struct {
+	Email    types.Email    `json:"email"`
+	Password types.Password `json:"password"`
+}
+
+type ChangePhoneNumberRequest struct {
+	PhoneNumber types.PhoneNumber `json:"phone_number"`
+	Password    types.Password    `json:"password"`
+}
+
+type ConfirmContactRequestSchema struct {
+	Code string `json:"code"`
+}
+
+type ConfirmContactRequest struct {
+	ConfirmContactRequestSchema
+}
+
+func (r *ConfirmContactRequest) UnmarshalJSON(b []byte) error {
+	var request ConfirmContactRequestSchema
+	if err := json.Unmarshal(b, &request); err != nil {
+		return err
+	}
+
+	if request.Code == "" {
+		return errors.New("code is empty")
+	}
+
+	r.ConfirmContactRequestSchema = request
+	return nil
+}
@@ -0,0 +1,19 @@
+package api
+
+import "time"
+
+type DataExportStatus string
+
+const (
+	DATA_EXPORT_PENDING DataExportStatus = "pending"
+	DATA_EXPORT_READY   DataExportStatus = "ready"
+	DATA_EXPORT_FAILED  DataExportStatus = "failed"
+)
+
+type DataExport struct {
+	Id        string           `json:"id"`
+	Status    DataExportStatus `json:"status"`
+	CreatedAt time.Time        `json:"created_at"`
+	// Missing until the export is finished
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
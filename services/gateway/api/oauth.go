@@ -0,0 +1,69 @@
+package api
+
+import (
+	"soa-socialnetwork/services/gateway/pkg/types"
+	"time"
+)
+
+type OAuthClient struct {
+	ClientId     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectUris []string  `json:"redirect_uris"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RegisterOAuthClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectUris []string `json:"redirect_uris"`
+}
+
+type ListOAuthClientsResponse struct {
+	Clients []OAuthClient `json:"clients"`
+}
+
+// Consent of the caller, given with jwt only
+type AuthorizeOAuthClientRequest struct {
+	ClientId            string   `json:"client_id"`
+	RedirectUri         string   `json:"redirect_uri"`
+	Scopes              []string `json:"scopes"`
+	State               string   `json:"state"`
+	CodeChallenge       string   `json:"code_challenge"`
+	CodeChallengeMethod string   `json:"code_challenge_method"`
+}
+
+type AuthorizeOAuthClientResponse struct {
+	Code string `json:"code"`
+	// Where user agent is sent to deliver the code to the client
+	RedirectTo string `json:"redirect_to"`
+}
+
+// Only authorization_code grant type is supported
+type OAuthTokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	ClientId     string `json:"client_id"`
+	Code         string `json:"code"`
+	RedirectUri  string `json:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// Seconds until token expiration
+	ExpiresIn  int64     `json:"expires_in"`
+	ValidUntil time.Time `json:"valid_until"`
+	Scopes     []string  `json:"scopes"`
+	TokenId    int32     `json:"token_id"`
+}
+
+type AuthorizedApp struct {
+	ClientId     string                    `json:"client_id"`
+	Name         string                    `json:"name"`
+	Scopes       []string                  `json:"scopes"`
+	AuthorizedAt time.Time                 `json:"authorized_at"`
+	LastUsedAt   types.Optional[time.Time] `json:"last_used_at"`
+}
+
+type ListAuthorizedAppsResponse struct {
+	Apps []AuthorizedApp `json:"apps"`
+}
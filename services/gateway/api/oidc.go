@@ -0,0 +1,43 @@
+package api
+
+import (
+	"soa-socialnetwork/services/gateway/pkg/types"
+	"time"
+)
+
+type OidcProvider struct {
+	Name string `json:"name"`
+	// Unknown users get a new account on their first login
+	AutoProvision bool `json:"auto_provision"`
+}
+
+type ListOidcProvidersResponse struct {
+	Providers []OidcProvider `json:"providers"`
+}
+
+type BeginOidcLoginResponse struct {
+	// Login page of identity provider, where user agent is sent
+	AuthorizationUrl string `json:"authorization_url"`
+	// Kept by the client until the callback and sent with code and state
+	Binding string `json:"binding"`
+}
+
+// Code and state passed by identity provider to the redirect uri, binding
+// returned when the login began
+type CompleteOidcLoginRequest struct {
+	Code    string `json:"code"`
+	State   string `json:"state"`
+	Binding string `json:"binding"`
+}
+
+type OidcIdentity struct {
+	Provider    string                    `json:"provider"`
+	Subject     string                    `json:"subject"`
+	Email       string                    `json:"email,omitempty"`
+	LinkedAt    time.Time                 `json:"linked_at"`
+	LastLoginAt types.Optional[time.Time] `json:"last_login_at"`
+}
+
+type ListOidcIdentitiesResponse struct {
+	Identities []OidcIdentity `json:"identities"`
+}
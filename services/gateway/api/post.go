@@ -14,6 +14,7 @@ type NewPostResponse struct {
 type Post struct {
 	Id           int32                 `json:"id"`
 	AuthorId     int32                 `json:"author_id"`
+	Author       *Author               `json:"author,omitempty"`
 	Text         string                `json:"text"`
 	SourcePostId types.Optional[int32] `json:"source_post_id"`
 	Pinned       bool                  `json:"pinned"`
@@ -0,0 +1,18 @@
+package api
+
+import "soa-socialnetwork/services/gateway/pkg/types"
+
+type PrivacySettings struct {
+	Birthday    types.Visibility `json:"birthday"`
+	Bio         types.Visibility `json:"bio"`
+	Email       types.Visibility `json:"email"`
+	PhoneNumber types.Visibility `json:"phone_number"`
+}
+
+// Missing fields are not changed
+type EditPrivacySettingsRequest struct {
+	Birthday    types.Optional[types.Visibility] `json:"birthday"`
+	Bio         types.Optional[types.Visibility] `json:"bio"`
+	Email       types.Optional[types.Visibility] `json:"email"`
+	PhoneNumber types.Optional[types.Visibility] `json:"phone_number"`
+}
@@ -3,10 +3,33 @@ package api
 import "soa-socialnetwork/services/gateway/pkg/types"
 
 type GetProfileResponse struct {
-	Name     string `json:"name"`
-	Surname  string `json:"surname"`
-	Birthday string `json:"birthday"`
-	Bio      string `json:"bio"`
+	Name    string `json:"name"`
+	Surname string `json:"surname"`
+	// Private fields are omitted if hidden from the caller
+	Birthday    string `json:"birthday,omitempty"`
+	Bio         string `json:"bio,omitempty"`
+	Email       string `json:"email,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+
+	// Omitted if not uploaded
+	Avatar *ProfileImage `json:"avatar,omitempty"`
+	Cover  *ProfileImage `json:"cover,omitempty"`
+}
+
+// Short profile data shown next to posts and comments
+type Author struct {
+	ProfileId string        `json:"profile_id"`
+	Name      string        `json:"name"`
+	Surname   string        `json:"surname"`
+	Avatar    *ProfileImage `json:"avatar,omitempty"`
+}
+
+type ProfileImage struct {
+	Url          string `json:"url"`
+	ThumbnailUrl string `json:"thumbnail_url"`
 }
 
 type EditProfileRequest struct {
@@ -15,3 +38,24 @@ type EditProfileRequest struct {
 	Birthday types.Optional[types.Date]    `json:"birthday"`
 	Bio      types.Optional[types.Bio]     `json:"bio"`
 }
+
+type ProfileCard struct {
+	ProfileId string `json:"profile_id"`
+	Name      string `json:"name"`
+	Surname   string `json:"surname"`
+}
+
+type SearchProfilesResponse struct {
+	Profiles      []ProfileCard `json:"profiles"`
+	NextPageToken string        `json:"next_page_token"`
+}
+
+type ListFollowsResponse struct {
+	Profiles      []ProfileCard `json:"profiles"`
+	NextPageToken string        `json:"next_page_token"`
+}
+
+type ListBlockedResponse struct {
+	Profiles      []ProfileCard `json:"profiles"`
+	NextPageToken string        `json:"next_page_token"`
+}
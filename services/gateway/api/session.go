@@ -0,0 +1,25 @@
+package api
+
+import "time"
+
+type Session struct {
+	Id         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IpAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Session of the token used for the request
+	Current bool `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
+
+type TerminateAllSessionsRequest struct {
+	KeepCurrent bool `json:"keep_current"`
+}
+
+type TerminateAllSessionsResponse struct {
+	TerminatedCount int32 `json:"terminated_count"`
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Returned instead of tokens when account has two-factor authentication enabled
+type SecondFactorChallenge struct {
+	Challenge  string    `json:"challenge"`
+	ValidUntil time.Time `json:"valid_until"`
+}
+
+// Code is TOTP code or backup code
+type SecondFactorRequestSchema struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+type SecondFactorRequest struct {
+	SecondFactorRequestSchema
+}
+
+func (r *SecondFactorRequest) UnmarshalJSON(b []byte) error {
+	var request SecondFactorRequestSchema
+	if err := json.Unmarshal(b, &request); err != nil {
+		return err
+	}
+
+	if request.Challenge == "" {
+		return errors.New("challenge is empty")
+	}
+
+	if request.Code == "" {
+		return errors.New("code is empty")
+	}
+
+	r.SecondFactorRequestSchema = request
+	return nil
+}
+
+type EnrollTotpResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+}
+
+type TotpCodeRequestSchema struct {
+	Code string `json:"code"`
+}
+
+type TotpCodeRequest struct {
+	TotpCodeRequestSchema
+}
+
+func (r *TotpCodeRequest) UnmarshalJSON(b []byte) error {
+	var request TotpCodeRequestSchema
+	if err := json.Unmarshal(b, &request); err != nil {
+		return err
+	}
+
+	if request.Code == "" {
+		return errors.New("code is empty")
+	}
+
+	r.TotpCodeRequestSchema = request
+	return nil
+}
+
+type BackupCodesResponse struct {
+	Codes []string `json:"codes"`
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"strings"
 
 	"soa-socialnetwork/services/common/envvar"
 	"soa-socialnetwork/services/gateway/internal/server"
@@ -17,9 +18,28 @@ func extractConfig() service.Config {
 		PostsServicePort:    envvar.MustIntFromEnv("POSTS_SERVICE_PORT"),
 		StatsServiceHost:    envvar.MustStringFromEnv("STATS_SERVICE_HOST"),
 		StatsServicePort:    envvar.MustIntFromEnv("STATS_SERVICE_PORT"),
+		TrustedProxies:      extractTrustedProxies(),
 	}
 }
 
+// TRUSTED_PROXIES is an optional comma separated list of addresses or CIDRs
+func extractTrustedProxies() []string {
+	val, err := envvar.TryStringFromEnv("TRUSTED_PROXIES")
+	if err != nil {
+		return nil
+	}
+
+	proxies := make([]string, 0)
+	for _, proxy := range strings.Split(val, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
+
 func main() {
 	log.Println("Gateway Service")
 
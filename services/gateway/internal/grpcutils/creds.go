@@ -6,21 +6,32 @@ import (
 	"soa-socialnetwork/services/gateway/internal/query"
 )
 
+// Must match CLIENT_IP_METADATA_KEY of accounts service
+const CLIENT_IP_METADATA_KEY = "x-client-ip"
+
+// Must match USER_AGENT_METADATA_KEY of accounts service
+const USER_AGENT_METADATA_KEY = "x-user-agent"
+
 type tokenCredentials struct {
-	token string
-	kind  query.AuthTokenKind
+	token     string
+	kind      query.AuthTokenKind
+	clientIp  string
+	userAgent string
 }
 
 func NewCreds(qp *query.Params) tokenCredentials {
+	var creds tokenCredentials
 	if qp.AuthKind == query.AUTH_TOKEN_JWT {
-		return JwtCreds(qp.AuthToken)
+		creds = JwtCreds(qp.AuthToken)
 	}
 
 	if qp.AuthKind == query.AUTH_TOKEN_SOA {
-		return SoaCreds(qp.AuthToken)
+		creds = SoaCreds(qp.AuthToken)
 	}
 
-	return tokenCredentials{}
+	creds.clientIp = qp.ClientIp
+	creds.userAgent = qp.UserAgent
+	return creds
 }
 
 func JwtCreds(rawToken string) tokenCredentials {
@@ -38,24 +49,24 @@ func SoaCreds(rawToken string) tokenCredentials {
 }
 
 func (j tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	md := map[string]string{}
 	switch j.kind {
 	case query.AUTH_TOKEN_JWT:
-		{
-			return map[string]string{
-				"authorization": fmt.Sprintf("Bearer %s", j.token),
-			}, nil
-		}
+		md["authorization"] = fmt.Sprintf("Bearer %s", j.token)
 
 	case query.AUTH_TOKEN_SOA:
-		{
-			return map[string]string{
-				"authorization": fmt.Sprintf("SoaToken %s", j.token),
-			}, nil
-		}
-
-	default:
-		return nil, nil
+		md["authorization"] = fmt.Sprintf("SoaToken %s", j.token)
+	}
+
+	if j.clientIp != "" {
+		md[CLIENT_IP_METADATA_KEY] = j.clientIp
 	}
+
+	if j.userAgent != "" {
+		md[USER_AGENT_METADATA_KEY] = j.userAgent
+	}
+
+	return md, nil
 }
 
 func (j tokenCredentials) RequireTransportSecurity() bool {
@@ -14,7 +14,7 @@ import (
 var jwt_regexp = regexp.MustCompile(`^Bearer [\-A-Za-z0-9\+\/_]*={0,3}\.[\-A-Za-z0-9\+\/_]*={0,3}\.[\-A-Za-z0-9\+\/_]*={0,3}$`)
 var soatoken_regexp = regexp.MustCompile(`^SoaToken [\-A-Za-z0-9\+\/_]={0,3}`)
 
-func WithAuth(verifier soajwt.Verifier) gin.HandlerFunc {
+func WithAuth(jwtVerifier soajwt.Verifier, soaVerifier soatoken.Verifier, reqs soatoken.RightsRequirements) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		auth := ctx.Request.Header.Get("Authorization")
 		if strings.HasPrefix(auth, "Bearer") {
@@ -24,7 +24,7 @@ func WithAuth(verifier soajwt.Verifier) gin.HandlerFunc {
 			}
 
 			rawToken := auth[7:] // skip 'Bearer '
-			_, err := verifier.Verify(rawToken)
+			_, err := jwtVerifier.Verify(rawToken)
 			if err != nil {
 				ctx.AbortWithStatusJSON(http.StatusForbidden, fmt.Sprintf("bad jwt: %v", err))
 				return
@@ -46,6 +46,12 @@ func WithAuth(verifier soajwt.Verifier) gin.HandlerFunc {
 				return
 			}
 
+			err = soaVerifier.Verify(rawToken, reqs)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, fmt.Sprintf("bad soa token: %v", err))
+				return
+			}
+
 			params := ExtractParams(ctx)
 			params.AuthToken = rawToken
 			params.AuthKind = AUTH_TOKEN_SOA
@@ -55,3 +61,15 @@ func WithAuth(verifier soajwt.Verifier) gin.HandlerFunc {
 		}
 	}
 }
+
+// Same as WithAuth, but lets requests without Authorization header through as unauthorized
+func WithOptionalAuth(jwtVerifier soajwt.Verifier, soaVerifier soatoken.Verifier, reqs soatoken.RightsRequirements) gin.HandlerFunc {
+	withAuth := WithAuth(jwtVerifier, soaVerifier, reqs)
+	return func(ctx *gin.Context) {
+		if ctx.Request.Header.Get("Authorization") == "" {
+			return
+		}
+
+		withAuth(ctx)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,3 +29,64 @@ func WithPostId() gin.HandlerFunc {
 		params.PostId = int32(postId)
 	}
 }
+
+func WithCommentId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		commentIdStr := ctx.Param("comment_id")
+		commentId, err := strconv.Atoi(commentIdStr)
+		if err != nil {
+			ctx.AbortWithError(http.StatusBadRequest, errors.New("bad comment id"))
+			return
+		}
+		params.CommentId = int32(commentId)
+	}
+}
+
+func WithTokenId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		tokenIdStr := ctx.Param("token_id")
+		tokenId, err := strconv.Atoi(tokenIdStr)
+		if err != nil {
+			ctx.AbortWithError(http.StatusBadRequest, errors.New("bad token id"))
+			return
+		}
+		params.TokenId = int32(tokenId)
+	}
+}
+
+func WithSessionId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		params.SessionId = ctx.Param("session_id")
+	}
+}
+
+func WithExportId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		params.ExportId = ctx.Param("export_id")
+	}
+}
+
+func WithClientId() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		params.ClientId = ctx.Param("client_id")
+	}
+}
+
+func WithOidcProvider() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		params.OidcProvider = ctx.Param("provider")
+	}
+}
+
+func WithImageKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		params.ImageKey = strings.TrimPrefix(ctx.Param("key"), "/")
+	}
+}
@@ -13,8 +13,23 @@ const (
 type Params struct {
 	ProfileId string
 	PostId    int32
-	AuthToken string
-	AuthKind  AuthTokenKind
+	CommentId int32
+	TokenId   int32
+	SessionId string
+	ExportId  string
+	ClientId  string
+	// Name of external identity provider
+	OidcProvider string
+	// Key of image blob, the rest of the path
+	ImageKey string
+	// Search query and page token passed in query string
+	SearchQuery string
+	PageToken   string
+	AuthToken   string
+	AuthKind    AuthTokenKind
+	// Address and user agent of the end client, forwarded to services
+	ClientIp  string
+	UserAgent string
 }
 
 const QUERY_PARAMS_KEY = "SOAQUERYPARAMS"
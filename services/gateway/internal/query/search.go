@@ -0,0 +1,27 @@
+package query
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func WithSearchQuery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		searchQuery, ok := ctx.GetQuery("q")
+		if !ok {
+			ctx.AbortWithError(http.StatusBadRequest, errors.New("no search query"))
+			return
+		}
+		params.SearchQuery = searchQuery
+	}
+}
+
+func WithPageToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params := ExtractParams(ctx)
+		params.PageToken = ctx.Query("page_token")
+	}
+}
@@ -1,7 +1,11 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
 	"soa-socialnetwork/services/gateway/api"
 	"soa-socialnetwork/services/gateway/internal/httperr"
 	"soa-socialnetwork/services/gateway/internal/query"
@@ -21,6 +25,8 @@ type requestPerformer[TRequest any, TResponse any] func(*query.Params, *TRequest
 func createHandler[TRequest any, TResponse any](doRequest requestPerformer[TRequest, TResponse]) func(*gin.Context) {
 	return func(ctx *gin.Context) {
 		params := query.ExtractParams(ctx)
+		params.ClientIp = ctx.ClientIP()
+		params.UserAgent = ctx.Request.UserAgent()
 		var request TRequest
 		if err := ctx.BindJSON(&request); err != nil {
 			ctx.AbortWithError(http.StatusBadRequest, err)
@@ -37,12 +43,88 @@ func createHandler[TRequest any, TResponse any](doRequest requestPerformer[TRequ
 	}
 }
 
-func newHttpRouter(service *service.GatewayService) httpRouter {
-	router := gin.Default()
+// Uploads are passed to services in a single grpc message
+const MAX_UPLOAD_SIZE = 4 << 20
+
+type uploadPerformer[TResponse any] func(*query.Params, []byte) (TResponse, httperr.Err)
+
+// Creates handler of multipart/form-data request with a file in the form field
+func createUploadHandler[TResponse any](field string, doRequest uploadPerformer[TResponse]) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		params := query.ExtractParams(ctx)
+		params.ClientIp = ctx.ClientIP()
+		params.UserAgent = ctx.Request.UserAgent()
+
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MAX_UPLOAD_SIZE)
+		fileHeader, err := ctx.FormFile(field)
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			ctx.AbortWithError(http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		if err != nil {
+			ctx.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		data, err := func() ([]byte, error) {
+			file, err := fileHeader.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+
+			return io.ReadAll(file)
+		}()
+		if err != nil {
+			ctx.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		response, httpErr := doRequest(params, data)
+		if !httpErr.IsOk() {
+			ctx.AbortWithError(httpErr.StatusCode, httpErr.Err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// Client address keys the login lockout and is stored with sessions and
+// audit events, so forwarding headers are accepted only from trusted proxies
+func newEngine(trustedProxies []string) (*gin.Engine, error) {
+	engine := gin.Default()
+	err := engine.SetTrustedProxies(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine, nil
+}
+
+func newHttpRouter(service *service.GatewayService, trustedProxies []string) (httpRouter, error) {
+	router, err := newEngine(trustedProxies)
+	if err != nil {
+		return httpRouter{}, err
+	}
+
 	restApi := router.Group("/api/v1")
-	withAuth := query.WithAuth(service.JwtVerifier)
+	// scopes of api tokens are checked by services
+	withAuth := query.WithAuth(service.JwtVerifier, service.SoaVerifier, soatoken.RightsRequirements{})
+	withOptionalAuth := query.WithOptionalAuth(service.JwtVerifier, service.SoaVerifier, soatoken.RightsRequirements{})
+	// stats service does not check tokens, so its scope is checked here
+	withStatsAuth := query.WithOptionalAuth(service.JwtVerifier, service.SoaVerifier, soatoken.RightsRequirements{Scope: soatoken.SCOPE_STATS_READ})
 	withProfileId := query.WithProfileId()
 	withPostId := query.WithPostId()
+	withCommentId := query.WithCommentId()
+	withTokenId := query.WithTokenId()
+	withSessionId := query.WithSessionId()
+	withExportId := query.WithExportId()
+	withClientId := query.WithClientId()
+	withOidcProvider := query.WithOidcProvider()
+	withSearchQuery := query.WithSearchQuery()
+	withPageToken := query.WithPageToken()
+	withImageKey := query.WithImageKey()
 
 	{
 		profileGroup := restApi.Group("/profile")
@@ -52,14 +134,32 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 			},
 		))
 
+		restApi.GET("/profiles", withSearchQuery, withPageToken, createHandler(
+			func(qp *query.Params, r *empty) (api.SearchProfilesResponse, httperr.Err) {
+				return service.SearchProfiles(qp)
+			},
+		))
+
 		profileIdGroup := restApi.Group("/profile/:profile_id")
 		profileIdGroup.Use(withProfileId)
-		profileIdGroup.GET("", createHandler(
+		profileIdGroup.GET("", withOptionalAuth, createHandler(
 			func(qp *query.Params, r *empty) (api.GetProfileResponse, httperr.Err) {
 				return service.GetProfileInfo(qp)
 			},
 		))
 
+		profileIdGroup.GET("/privacy", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.PrivacySettings, httperr.Err) {
+				return service.GetPrivacySettings(qp)
+			},
+		))
+
+		profileIdGroup.PUT("/privacy", withAuth, createHandler(
+			func(qp *query.Params, r *api.EditPrivacySettingsRequest) (empty, httperr.Err) {
+				return empty{}, service.EditPrivacySettings(qp, r)
+			},
+		))
+
 		profileIdGroup.PUT("", withAuth, createHandler(
 			func(qp *query.Params, r *api.EditProfileRequest) (empty, httperr.Err) {
 				return empty{}, service.EditProfileInfo(qp, r)
@@ -71,6 +171,90 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 				return empty{}, service.DeleteProfile(qp)
 			},
 		))
+
+		profileIdGroup.PUT("/avatar", withAuth, createUploadHandler("image",
+			func(qp *query.Params, data []byte) (api.ProfileImage, httperr.Err) {
+				return service.UploadAvatar(qp, data)
+			},
+		))
+
+		profileIdGroup.DELETE("/avatar", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.DeleteAvatar(qp)
+			},
+		))
+
+		profileIdGroup.PUT("/cover", withAuth, createUploadHandler("image",
+			func(qp *query.Params, data []byte) (api.ProfileImage, httperr.Err) {
+				return service.UploadCover(qp, data)
+			},
+		))
+
+		profileIdGroup.DELETE("/cover", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.DeleteCover(qp)
+			},
+		))
+
+		restApi.GET("/images/*key", withImageKey, func(ctx *gin.Context) {
+			data, contentType, err := service.GetImage(query.ExtractParams(ctx))
+			if !err.IsOk() {
+				ctx.AbortWithError(err.StatusCode, err.Err)
+				return
+			}
+
+			// image keys are never reused
+			ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+			ctx.Data(http.StatusOK, contentType, data)
+		})
+
+		profileIdGroup.POST("/followers", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.Follow(qp)
+			},
+		))
+
+		profileIdGroup.DELETE("/followers", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.Unfollow(qp)
+			},
+		))
+
+		profileIdGroup.GET("/followers", withPageToken, createHandler(
+			func(qp *query.Params, r *empty) (api.ListFollowsResponse, httperr.Err) {
+				return service.ListFollowers(qp)
+			},
+		))
+
+		profileIdGroup.GET("/following", withPageToken, createHandler(
+			func(qp *query.Params, r *empty) (api.ListFollowsResponse, httperr.Err) {
+				return service.ListFollowing(qp)
+			},
+		))
+
+		profileIdGroup.GET("/friends", withPageToken, createHandler(
+			func(qp *query.Params, r *empty) (api.ListFollowsResponse, httperr.Err) {
+				return service.ListFriends(qp)
+			},
+		))
+
+		profileIdGroup.POST("/block", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.Block(qp)
+			},
+		))
+
+		profileIdGroup.DELETE("/block", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.Unblock(qp)
+			},
+		))
+
+		restApi.GET("/blocks", withAuth, withPageToken, createHandler(
+			func(qp *query.Params, r *empty) (api.ListBlockedResponse, httperr.Err) {
+				return service.ListBlocked(qp)
+			},
+		))
 	}
 
 	{
@@ -79,11 +263,256 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 				return service.Authenticate(qp, r)
 			},
 		))
+		restApi.POST("/auth/refresh", createHandler(
+			func(qp *query.Params, r *api.RefreshTokenRequest) (api.AuthenticateResponse, httperr.Err) {
+				return service.RefreshToken(qp, r)
+			},
+		))
+		restApi.POST("/auth/second_factor", createHandler(
+			func(qp *query.Params, r *api.SecondFactorRequest) (api.AuthenticateResponse, httperr.Err) {
+				return service.AuthenticateSecondFactor(qp, r)
+			},
+		))
+		restApi.POST("/auth/totp", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.EnrollTotpResponse, httperr.Err) {
+				return service.EnrollTotp(qp)
+			},
+		))
+		restApi.POST("/auth/totp/confirm", withAuth, createHandler(
+			func(qp *query.Params, r *api.TotpCodeRequest) (api.BackupCodesResponse, httperr.Err) {
+				return service.ConfirmTotp(qp, r)
+			},
+		))
+		restApi.POST("/auth/totp/disable", withAuth, createHandler(
+			func(qp *query.Params, r *api.TotpCodeRequest) (empty, httperr.Err) {
+				return empty{}, service.DisableTotp(qp, r)
+			},
+		))
+		restApi.POST("/auth/totp/backup_codes", withAuth, createHandler(
+			func(qp *query.Params, r *api.TotpCodeRequest) (api.BackupCodesResponse, httperr.Err) {
+				return service.RegenerateBackupCodes(qp, r)
+			},
+		))
+		restApi.PUT("/auth/password", withAuth, createHandler(
+			func(qp *query.Params, r *api.ChangePasswordRequest) (empty, httperr.Err) {
+				return empty{}, service.ChangePassword(qp, r)
+			},
+		))
+		restApi.POST("/auth/password/reset_request", createHandler(
+			func(qp *query.Params, r *api.RequestPasswordResetRequest) (empty, httperr.Err) {
+				return empty{}, service.RequestPasswordReset(qp, r)
+			},
+		))
+		restApi.POST("/auth/password/reset", createHandler(
+			func(qp *query.Params, r *api.ResetPasswordRequest) (empty, httperr.Err) {
+				return empty{}, service.ResetPassword(qp, r)
+			},
+		))
+		restApi.GET("/auth/lockouts", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.ListAuthLockoutsResponse, httperr.Err) {
+				return service.ListAuthLockouts(qp)
+			},
+		))
+		restApi.DELETE("/auth/lockouts", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.ClearAuthLockouts(qp)
+			},
+		))
+		restApi.GET("/auth/audit_log", withAuth, withPageToken, createHandler(
+			func(qp *query.Params, r *empty) (api.ListAuditEventsResponse, httperr.Err) {
+				return service.ListAuditEvents(qp)
+			},
+		))
+		restApi.GET("/auth/sessions", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.ListSessionsResponse, httperr.Err) {
+				return service.ListSessions(qp)
+			},
+		))
+		restApi.DELETE("/auth/sessions", withAuth, createHandler(
+			func(qp *query.Params, r *api.TerminateAllSessionsRequest) (api.TerminateAllSessionsResponse, httperr.Err) {
+				return service.TerminateAllSessions(qp, r)
+			},
+		))
+		restApi.DELETE("/auth/sessions/:session_id", withSessionId, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.TerminateSession(qp)
+			},
+		))
+		restApi.GET("/auth/apps", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.ListAuthorizedAppsResponse, httperr.Err) {
+				return service.ListAuthorizedApps(qp)
+			},
+		))
+		restApi.DELETE("/auth/apps/:client_id", withClientId, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.RevokeAuthorizedApp(qp)
+			},
+		))
+		restApi.GET("/auth/oidc/providers", createHandler(
+			func(qp *query.Params, r *empty) (api.ListOidcProvidersResponse, httperr.Err) {
+				return service.ListOidcProviders(qp)
+			},
+		))
+		restApi.POST("/auth/oidc/:provider/login", withOidcProvider, createHandler(
+			func(qp *query.Params, r *empty) (api.BeginOidcLoginResponse, httperr.Err) {
+				return service.BeginOidcLogin(qp)
+			},
+		))
+		restApi.POST("/auth/oidc/:provider/callback", withOidcProvider, createHandler(
+			func(qp *query.Params, r *api.CompleteOidcLoginRequest) (api.AuthenticateResponse, httperr.Err) {
+				return service.CompleteOidcLogin(qp, r)
+			},
+		))
+		restApi.POST("/auth/oidc/:provider/link", withOidcProvider, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.BeginOidcLoginResponse, httperr.Err) {
+				return service.BeginOidcLink(qp)
+			},
+		))
+		restApi.POST("/auth/oidc/:provider/link/callback", withOidcProvider, withAuth, createHandler(
+			func(qp *query.Params, r *api.CompleteOidcLoginRequest) (api.OidcIdentity, httperr.Err) {
+				return service.CompleteOidcLink(qp, r)
+			},
+		))
+		restApi.GET("/auth/oidc/identities", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.ListOidcIdentitiesResponse, httperr.Err) {
+				return service.ListOidcIdentities(qp)
+			},
+		))
+		restApi.DELETE("/auth/oidc/identities/:provider", withOidcProvider, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.UnlinkOidcIdentity(qp)
+			},
+		))
+		restApi.POST("/oauth/clients", withAuth, createHandler(
+			func(qp *query.Params, r *api.RegisterOAuthClientRequest) (api.OAuthClient, httperr.Err) {
+				return service.RegisterOAuthClient(qp, r)
+			},
+		))
+		restApi.GET("/oauth/clients", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.ListOAuthClientsResponse, httperr.Err) {
+				return service.ListOAuthClients(qp)
+			},
+		))
+		restApi.GET("/oauth/clients/:client_id", withClientId, createHandler(
+			func(qp *query.Params, r *empty) (api.OAuthClient, httperr.Err) {
+				return service.GetOAuthClient(qp)
+			},
+		))
+		restApi.DELETE("/oauth/clients/:client_id", withClientId, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.DeleteOAuthClient(qp)
+			},
+		))
+		restApi.POST("/oauth/authorize", withAuth, createHandler(
+			func(qp *query.Params, r *api.AuthorizeOAuthClientRequest) (api.AuthorizeOAuthClientResponse, httperr.Err) {
+				return service.AuthorizeOAuthClient(qp, r)
+			},
+		))
+		restApi.POST("/oauth/token", createHandler(
+			func(qp *query.Params, r *api.OAuthTokenRequest) (api.OAuthTokenResponse, httperr.Err) {
+				return service.ExchangeOAuthCode(qp, r)
+			},
+		))
+		restApi.POST("/exports", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.DataExport, httperr.Err) {
+				return service.RequestDataExport(qp)
+			},
+		))
+		restApi.GET("/exports/:export_id", withExportId, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.DataExport, httperr.Err) {
+				return service.GetDataExport(qp)
+			},
+		))
+		restApi.GET("/exports/:export_id/archive", withExportId, withAuth, func(ctx *gin.Context) {
+			qp := query.ExtractParams(ctx)
+			started := false
+			err := service.DownloadDataExport(qp, func(chunk []byte) error {
+				if !started {
+					ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="soa-export-%s.zip"`, qp.ExportId))
+					ctx.Header("Content-Type", "application/zip")
+					ctx.Status(http.StatusOK)
+					started = true
+				}
+
+				_, err := ctx.Writer.Write(chunk)
+				return err
+			})
+			if !err.IsOk() {
+				if started {
+					// status is already sent, client gets truncated archive
+					ctx.Error(err.Err)
+					ctx.Abort()
+					return
+				}
+
+				ctx.AbortWithError(err.StatusCode, err.Err)
+			}
+		})
+		restApi.GET("/auth/contacts", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.ContactsResponse, httperr.Err) {
+				return service.GetContacts(qp)
+			},
+		))
+		restApi.PUT("/auth/contacts/email", withAuth, createHandler(
+			func(qp *query.Params, r *api.ChangeEmailRequest) (empty, httperr.Err) {
+				return empty{}, service.ChangeContact(qp, api.CONTACT_EMAIL, string(r.Email), string(r.Password))
+			},
+		))
+		restApi.POST("/auth/contacts/email/verification", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.SendVerificationCode(qp, api.CONTACT_EMAIL)
+			},
+		))
+		restApi.POST("/auth/contacts/email/confirm", withAuth, createHandler(
+			func(qp *query.Params, r *api.ConfirmContactRequest) (empty, httperr.Err) {
+				return empty{}, service.ConfirmContact(qp, api.CONTACT_EMAIL, r)
+			},
+		))
+		restApi.PUT("/auth/contacts/phone_number", withAuth, createHandler(
+			func(qp *query.Params, r *api.ChangePhoneNumberRequest) (empty, httperr.Err) {
+				return empty{}, service.ChangeContact(qp, api.CONTACT_PHONE_NUMBER, string(r.PhoneNumber), string(r.Password))
+			},
+		))
+		restApi.POST("/auth/contacts/phone_number/verification", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.SendVerificationCode(qp, api.CONTACT_PHONE_NUMBER)
+			},
+		))
+		restApi.POST("/auth/contacts/phone_number/confirm", withAuth, createHandler(
+			func(qp *query.Params, r *api.ConfirmContactRequest) (empty, httperr.Err) {
+				return empty{}, service.ConfirmContact(qp, api.CONTACT_PHONE_NUMBER, r)
+			},
+		))
+		restApi.GET("/auth/jwks", createHandler(
+			func(qp *query.Params, r *empty) (api.JwksResponse, httperr.Err) {
+				return service.GetJwks(qp)
+			},
+		))
 		restApi.POST("/api_token", createHandler(
 			func(qp *query.Params, r *api.CreateApiTokenRequest) (api.CreateApiTokenResponse, httperr.Err) {
 				return service.CreateApiToken(qp, r)
 			},
 		))
+		restApi.POST("/api_token/second_factor", createHandler(
+			func(qp *query.Params, r *api.SecondFactorRequest) (api.CreateApiTokenResponse, httperr.Err) {
+				return service.CreateApiTokenSecondFactor(qp, r)
+			},
+		))
+		restApi.GET("/api_token", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.ListApiTokensResponse, httperr.Err) {
+				return service.ListApiTokens(qp)
+			},
+		))
+		restApi.DELETE("/api_token", withAuth, createHandler(
+			func(qp *query.Params, r *empty) (api.RevokeAllApiTokensResponse, httperr.Err) {
+				return service.RevokeAllApiTokens(qp)
+			},
+		))
+		restApi.DELETE("/api_token/:token_id", withTokenId, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.RevokeApiToken(qp)
+			},
+		))
 	}
 
 	{
@@ -100,7 +529,7 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 	}
 
 	{
-		restApi.GET("/profile/:profile_id/page/posts", withProfileId, createHandler(
+		restApi.GET("/profile/:profile_id/page/posts", withProfileId, withOptionalAuth, createHandler(
 			func(qp *query.Params, r *api.GetPostsRequest) (api.GetPostsResponse, httperr.Err) {
 				return service.GetPosts(qp, r)
 			},
@@ -116,7 +545,7 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 	{
 		postGroup := restApi.Group("/post/:post_id")
 		postGroup.Use(withPostId)
-		postGroup.GET("", createHandler(
+		postGroup.GET("", withOptionalAuth, createHandler(
 			func(qp *query.Params, r *empty) (api.Post, httperr.Err) {
 				return service.GetPost(qp)
 			},
@@ -139,11 +568,16 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 				return service.NewComment(qp, r)
 			},
 		))
-		restApi.GET("/post/:post_id/comments", withPostId, createHandler(
+		restApi.GET("/post/:post_id/comments", withPostId, withOptionalAuth, createHandler(
 			func(qp *query.Params, r *api.GetCommentsRequest) (api.GetCommentsResponse, httperr.Err) {
 				return service.GetComments(qp, r)
 			},
 		))
+		restApi.DELETE("/post/:post_id/comments/:comment_id", withPostId, withCommentId, withAuth, createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.DeleteComment(qp)
+			},
+		))
 
 		restApi.POST("/post/:post_id/views", withPostId, withAuth, createHandler(
 			func(qp *query.Params, r *empty) (empty, httperr.Err) {
@@ -158,13 +592,13 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 	}
 
 	{
-		restApi.GET("/post/:post_id/metric", withPostId, createHandler(
+		restApi.GET("/post/:post_id/metric", withPostId, withStatsAuth, createHandler(
 			func(qp *query.Params, r *api.GetPostMetricRequest) (api.GetPostMetricResponse, httperr.Err) {
 				return service.GetPostMetric(qp, r)
 			},
 		))
 
-		restApi.GET("/post/:post_id/metric_dynamics", withPostId, createHandler(
+		restApi.GET("/post/:post_id/metric_dynamics", withPostId, withStatsAuth, createHandler(
 			func(qp *query.Params, r *api.GetPostMetricDynamicsRequest) (api.GetPostMetricDynamicsResponse, httperr.Err) {
 				return service.GetPostMetricDynamics(qp, r)
 			},
@@ -172,18 +606,43 @@ func newHttpRouter(service *service.GatewayService) httpRouter {
 	}
 
 	{
-		restApi.GET("/top10/posts", createHandler(
+		restApi.GET("/top10/posts", withStatsAuth, createHandler(
 			func(qp *query.Params, r *api.GetTop10PostsRequest) (api.GetTop10PostsResponse, httperr.Err) {
 				return service.GetTop10Posts(qp, r)
 			},
 		))
 
-		restApi.GET("/top10/users", createHandler(
+		restApi.GET("/top10/users", withStatsAuth, createHandler(
 			func(qp *query.Params, r *api.GetTop10UsersRequest) (api.GetTop10UsersResponse, httperr.Err) {
 				return service.GetTop10Users(qp, r)
 			},
 		))
 	}
 
-	return httpRouter{router}
+	{
+		adminGroup := restApi.Group("/admin/accounts/:profile_id")
+		adminGroup.Use(withProfileId, withAuth)
+		adminGroup.GET("", createHandler(
+			func(qp *query.Params, r *empty) (api.AccountStatus, httperr.Err) {
+				return service.GetAccountStatus(qp)
+			},
+		))
+		adminGroup.PUT("/role", createHandler(
+			func(qp *query.Params, r *api.SetAccountRoleRequest) (empty, httperr.Err) {
+				return empty{}, service.SetAccountRole(qp, r)
+			},
+		))
+		adminGroup.POST("/suspension", createHandler(
+			func(qp *query.Params, r *api.SuspendAccountRequest) (empty, httperr.Err) {
+				return empty{}, service.SuspendAccount(qp, r)
+			},
+		))
+		adminGroup.DELETE("/suspension", createHandler(
+			func(qp *query.Params, r *empty) (empty, httperr.Err) {
+				return empty{}, service.UnsuspendAccount(qp)
+			},
+		))
+	}
+
+	return httpRouter{router}, nil
 }
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"soa-socialnetwork/services/gateway/internal/httperr"
+	"soa-socialnetwork/services/gateway/internal/query"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Serves a route which reports the client address passed to services
+func newClientIpEngine(t *testing.T, trustedProxies []string) *gin.Engine {
+	engine, err := newEngine(trustedProxies)
+	require.NoError(t, err)
+
+	engine.GET("/ip", createHandler(
+		func(qp *query.Params, r *empty) (string, httperr.Err) {
+			return qp.ClientIp, httperr.Ok()
+		},
+	))
+
+	return engine
+}
+
+func requestClientIp(t *testing.T, engine *gin.Engine, remoteAddr string, forwardedFor string) string {
+	req := httptest.NewRequest(http.MethodGet, "/ip", strings.NewReader("{}"))
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+// Client address keys the login lockout counter, so spoofed headers must not
+// give an attacker a fresh counter on every request
+func TestSpoofedForwardedForIsIgnored(t *testing.T) {
+	engine := newClientIpEngine(t, nil)
+
+	for _, spoofed := range []string{"", "1.2.3.4", "5.6.7.8", "1.2.3.4, 5.6.7.8"} {
+		assert.Equal(t, `"203.0.113.7"`, requestClientIp(t, engine, "203.0.113.7:41000", spoofed), spoofed)
+	}
+}
+
+func TestForwardedForOfTrustedProxy(t *testing.T) {
+	engine := newClientIpEngine(t, []string{"10.0.0.0/8"})
+
+	assert.Equal(t, `"198.51.100.1"`, requestClientIp(t, engine, "10.1.2.3:41000", "198.51.100.1"))
+	assert.Equal(t, `"203.0.113.7"`, requestClientIp(t, engine, "203.0.113.7:41000", "198.51.100.1"))
+}
+
+func TestInvalidTrustedProxy(t *testing.T) {
+	_, err := newEngine([]string{"not an address"})
+	assert.Error(t, err)
+}
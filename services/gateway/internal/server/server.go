@@ -11,12 +11,17 @@ type GatewayServer struct {
 }
 
 func (s *GatewayServer) Run(port int) error {
+	s.service.Start()
 	return s.router.Run(fmt.Sprintf(":%d", port))
 }
 
 func Create(cfg service.Config) (GatewayServer, error) {
 	service := service.NewGatewayService(cfg)
-	router := newHttpRouter(&service)
+	router, err := newHttpRouter(&service, cfg.TrustedProxies)
+	if err != nil {
+		return GatewayServer{}, err
+	}
+
 	return GatewayServer{
 		service: service,
 		router:  router,
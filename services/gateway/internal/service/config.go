@@ -26,4 +26,8 @@ type Config struct {
 
 	// Port of stats service
 	StatsServicePort int
+
+	// Addresses or CIDRs of reverse proxies whose X-Forwarded-For header is
+	// trusted; if empty, client address is always taken from the connection
+	TrustedProxies []string
 }
@@ -1,11 +1,186 @@
 package service
 
 import (
+	accountsPb "soa-socialnetwork/services/accounts/proto"
 	"soa-socialnetwork/services/gateway/api"
 	"soa-socialnetwork/services/gateway/pkg/types"
 	statsPb "soa-socialnetwork/services/stats/proto"
+	"time"
 )
 
+func authResponseFromProto(resp *accountsPb.AuthResponse) api.AuthenticateResponse {
+	if resp.SecondFactor != nil {
+		return api.AuthenticateResponse{
+			SecondFactor: secondFactorChallengeFromProto(resp.SecondFactor),
+		}
+	}
+
+	return api.AuthenticateResponse{
+		Token:                  resp.Token,
+		RefreshToken:           resp.RefreshToken,
+		RefreshTokenValidUntil: resp.RefreshTokenValidUntil.AsTime(),
+	}
+}
+
+func createApiTokenResponseFromProto(resp *accountsPb.CreateApiTokenResponse) api.CreateApiTokenResponse {
+	if resp.SecondFactor != nil {
+		return api.CreateApiTokenResponse{
+			SecondFactor: secondFactorChallengeFromProto(resp.SecondFactor),
+		}
+	}
+
+	return api.CreateApiTokenResponse{
+		Token:      resp.Token,
+		TokenId:    resp.TokenId,
+		ValidUntil: resp.ValidUntil.AsTime(),
+	}
+}
+
+func secondFactorChallengeFromProto(challenge *accountsPb.SecondFactorChallenge) types.Optional[api.SecondFactorChallenge] {
+	return types.Optional[api.SecondFactorChallenge]{
+		Value: api.SecondFactorChallenge{
+			Challenge:  challenge.Challenge,
+			ValidUntil: challenge.ValidUntil.AsTime(),
+		},
+		HasValue: true,
+	}
+}
+
+func userIdToProto(userId api.UserIdSchema) *accountsPb.UserId {
+	if userId.Login.HasValue {
+		return &accountsPb.UserId{
+			Id: &accountsPb.UserId_Login{
+				Login: string(userId.Login.Value),
+			},
+		}
+	}
+
+	if userId.Email.HasValue {
+		return &accountsPb.UserId{
+			Id: &accountsPb.UserId_Email{
+				Email: string(userId.Email.Value),
+			},
+		}
+	}
+
+	if userId.PhoneNumber.HasValue {
+		return &accountsPb.UserId{
+			Id: &accountsPb.UserId_PhoneNumber{
+				PhoneNumber: string(userId.PhoneNumber.Value),
+			},
+		}
+	}
+
+	panic("at least one user id must be provided")
+}
+
+func contactKindToProto(kind api.ContactKind) accountsPb.ContactKind {
+	switch kind {
+	case api.CONTACT_EMAIL:
+		return accountsPb.ContactKind_CONTACT_KIND_EMAIL
+
+	case api.CONTACT_PHONE_NUMBER:
+		return accountsPb.ContactKind_CONTACT_KIND_PHONE_NUMBER
+	}
+
+	return accountsPb.ContactKind_CONTACT_KIND_UNSPECIFIED
+}
+
+func apiTokenInfoFromProto(token *accountsPb.ApiTokenInfo) api.ApiTokenInfo {
+	var lastUsedAt types.Optional[time.Time]
+	if token.LastUsedAt != nil {
+		lastUsedAt = types.Optional[time.Time]{
+			Value:    token.LastUsedAt.AsTime(),
+			HasValue: true,
+		}
+	}
+
+	return api.ApiTokenInfo{
+		Id:          token.TokenId,
+		Name:        token.Name,
+		ReadAccess:  token.ReadAccess,
+		WriteAccess: token.WriteAccess,
+		Scopes:      token.Scopes,
+		Revoked:     token.Revoked,
+		CreatedAt:   token.CreatedAt.AsTime(),
+		ValidUntil:  token.ValidUntil.AsTime(),
+		LastUsedAt:  lastUsedAt,
+	}
+}
+
+func authLockoutFromProto(lockout *accountsPb.AuthLockout) api.AuthLockout {
+	var lockedUntil types.Optional[time.Time]
+	if lockout.LockedUntil != nil {
+		lockedUntil = types.Optional[time.Time]{
+			Value:    lockout.LockedUntil.AsTime(),
+			HasValue: true,
+		}
+	}
+
+	return api.AuthLockout{
+		Key:          lockout.Key,
+		FailedCount:  lockout.FailedCount,
+		LastFailedAt: lockout.LastFailedAt.AsTime(),
+		LockedUntil:  lockedUntil,
+	}
+}
+
+func auditEventFromProto(event *accountsPb.AuditEvent) api.AuditEvent {
+	return api.AuditEvent{
+		EventId:        event.EventId,
+		Type:           event.Type,
+		IdentifierType: event.IdentifierType,
+		ClientIp:       event.ClientIp,
+		UserAgent:      event.UserAgent,
+		CreatedAt:      event.CreatedAt.AsTime(),
+	}
+}
+
+func oauthClientFromProto(client *accountsPb.OAuthClient) api.OAuthClient {
+	return api.OAuthClient{
+		ClientId:     client.ClientId,
+		Name:         client.Name,
+		RedirectUris: client.RedirectUris,
+		CreatedAt:    client.CreatedAt.AsTime(),
+	}
+}
+
+func authorizedAppFromProto(app *accountsPb.AuthorizedApp) api.AuthorizedApp {
+	var lastUsedAt types.Optional[time.Time]
+	if app.LastUsedAt != nil {
+		lastUsedAt = types.Optional[time.Time]{
+			Value:    app.LastUsedAt.AsTime(),
+			HasValue: true,
+		}
+	}
+
+	return api.AuthorizedApp{
+		ClientId:     app.ClientId,
+		Name:         app.Name,
+		Scopes:       app.Scopes,
+		AuthorizedAt: app.AuthorizedAt.AsTime(),
+		LastUsedAt:   lastUsedAt,
+	}
+}
+
+func oidcIdentityFromProto(identity *accountsPb.OidcIdentity) api.OidcIdentity {
+	var lastLoginAt types.Optional[time.Time]
+	if identity.LastLoginAt != nil {
+		lastLoginAt = types.Optional[time.Time]{
+			Value:    identity.LastLoginAt.AsTime(),
+			HasValue: true,
+		}
+	}
+
+	return api.OidcIdentity{
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LinkedAt:    identity.LinkedAt.AsTime(),
+		LastLoginAt: lastLoginAt,
+	}
+}
+
 func metricToProto(metric types.Metric) statsPb.Metric {
 	switch metric {
 	case types.METRIC_VIEW_COUNT:
@@ -63,3 +238,174 @@ func userStatsFromProto(userStats *statsPb.UserStats, metric types.Metric) api.U
 
 	return result
 }
+
+func sessionFromProto(session *accountsPb.Session) api.Session {
+	return api.Session{
+		Id:         session.SessionId,
+		UserAgent:  session.UserAgent,
+		IpAddress:  session.IpAddress,
+		CreatedAt:  session.CreatedAt.AsTime(),
+		LastSeenAt: session.LastSeenAt.AsTime(),
+		Current:    session.Current,
+	}
+}
+
+// Returns nil for nil image
+func profileImageFromProto(image *accountsPb.ProfileImage) *api.ProfileImage {
+	if image == nil {
+		return nil
+	}
+
+	return &api.ProfileImage{
+		Url:          image.Url,
+		ThumbnailUrl: image.ThumbnailUrl,
+	}
+}
+
+func authorFromProto(profile *accountsPb.Profile) *api.Author {
+	return &api.Author{
+		ProfileId: profile.ProfileId,
+		Name:      profile.Name,
+		Surname:   profile.Surname,
+		Avatar:    profileImageFromProto(profile.Avatar),
+	}
+}
+
+// Missing visibility is left unchanged by accounts service
+func visibilityToProto(visibility types.Optional[types.Visibility]) accountsPb.Visibility {
+	if !visibility.HasValue {
+		return accountsPb.Visibility_VISIBILITY_UNSPECIFIED
+	}
+
+	switch visibility.Value {
+	case types.VISIBILITY_EVERYONE:
+		return accountsPb.Visibility_VISIBILITY_EVERYONE
+
+	case types.VISIBILITY_AUTHENTICATED:
+		return accountsPb.Visibility_VISIBILITY_AUTHENTICATED
+
+	case types.VISIBILITY_ONLY_ME:
+		return accountsPb.Visibility_VISIBILITY_ONLY_ME
+	}
+
+	panic("unknown visibility")
+}
+
+func visibilityFromProto(visibility accountsPb.Visibility) types.Visibility {
+	switch visibility {
+	case accountsPb.Visibility_VISIBILITY_EVERYONE:
+		return types.VISIBILITY_EVERYONE
+
+	case accountsPb.Visibility_VISIBILITY_AUTHENTICATED:
+		return types.VISIBILITY_AUTHENTICATED
+
+	case accountsPb.Visibility_VISIBILITY_ONLY_ME:
+		return types.VISIBILITY_ONLY_ME
+	}
+
+	panic("unknown visibility")
+}
+
+func privacySettingsFromProto(settings *accountsPb.PrivacySettings) api.PrivacySettings {
+	return api.PrivacySettings{
+		Birthday:    visibilityFromProto(settings.Birthday),
+		Bio:         visibilityFromProto(settings.Bio),
+		Email:       visibilityFromProto(settings.Email),
+		PhoneNumber: visibilityFromProto(settings.PhoneNumber),
+	}
+}
+
+func profileCardFromProto(card *accountsPb.ProfileCard) api.ProfileCard {
+	return api.ProfileCard{
+		ProfileId: card.ProfileId,
+		Name:      card.Name,
+		Surname:   card.Surname,
+	}
+}
+
+func dataExportFromProto(export *accountsPb.DataExport) api.DataExport {
+	result := api.DataExport{
+		Id:        export.ExportId,
+		Status:    dataExportStatusFromProto(export.Status),
+		CreatedAt: export.CreatedAt.AsTime(),
+	}
+	if export.FinishedAt != nil {
+		finishedAt := export.FinishedAt.AsTime()
+		result.FinishedAt = &finishedAt
+	}
+
+	return result
+}
+
+func dataExportStatusFromProto(status accountsPb.DataExportStatus) api.DataExportStatus {
+	switch status {
+	case accountsPb.DataExportStatus_DATA_EXPORT_STATUS_PENDING:
+		return api.DATA_EXPORT_PENDING
+
+	case accountsPb.DataExportStatus_DATA_EXPORT_STATUS_READY:
+		return api.DATA_EXPORT_READY
+
+	case accountsPb.DataExportStatus_DATA_EXPORT_STATUS_FAILED:
+		return api.DATA_EXPORT_FAILED
+	}
+
+	panic("unknown data export status")
+}
+
+// Missing role is rejected by accounts service
+func roleToProto(role types.Role) accountsPb.Role {
+	switch role {
+	case types.ROLE_USER:
+		return accountsPb.Role_ROLE_USER
+
+	case types.ROLE_MODERATOR:
+		return accountsPb.Role_ROLE_MODERATOR
+
+	case types.ROLE_ADMIN:
+		return accountsPb.Role_ROLE_ADMIN
+	}
+
+	return accountsPb.Role_ROLE_UNSPECIFIED
+}
+
+func roleFromProto(role accountsPb.Role) types.Role {
+	switch role {
+	case accountsPb.Role_ROLE_USER:
+		return types.ROLE_USER
+
+	case accountsPb.Role_ROLE_MODERATOR:
+		return types.ROLE_MODERATOR
+
+	case accountsPb.Role_ROLE_ADMIN:
+		return types.ROLE_ADMIN
+	}
+
+	panic("unknown role")
+}
+
+func accountStatusFromProto(status *accountsPb.AccountStatus) api.AccountStatus {
+	var suspension types.Optional[api.Suspension]
+	if status.Suspension != nil {
+		var until types.Optional[time.Time]
+		if status.Suspension.Until != nil {
+			until = types.Optional[time.Time]{
+				Value:    status.Suspension.Until.AsTime(),
+				HasValue: true,
+			}
+		}
+
+		suspension = types.Optional[api.Suspension]{
+			Value: api.Suspension{
+				Reason:      status.Suspension.Reason,
+				SuspendedAt: status.Suspension.SuspendedAt.AsTime(),
+				Until:       until,
+			},
+			HasValue: true,
+		}
+	}
+
+	return api.AccountStatus{
+		Role:       roleFromProto(status.Role),
+		Suspension: suspension,
+	}
+}
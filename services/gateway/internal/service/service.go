@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
 	accountsPb "soa-socialnetwork/services/accounts/proto"
+	"soa-socialnetwork/services/common/backjob"
 	"soa-socialnetwork/services/gateway/api"
 	"soa-socialnetwork/services/gateway/internal/grpcutils"
 	"soa-socialnetwork/services/gateway/internal/httperr"
@@ -13,137 +17,1308 @@ import (
 	"soa-socialnetwork/services/gateway/pkg/types"
 	postsPb "soa-socialnetwork/services/posts/proto"
 	statsPb "soa-socialnetwork/services/stats/proto"
+	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const JWKS_REFRESH_PERIOD = time.Minute
+const REVOCATIONS_REFRESH_PERIOD = time.Second
+
 type GatewayService struct {
-	JwtVerifier          soajwt.Verifier
+	JwtVerifier          soajwt.RevocationVerifier
+	SoaVerifier          *soatoken.CachingVerifier
 	AccountsGrpcAccessor GrpcAccessor[accountsPb.AccountsServiceClient]
 	PostsGrpcAccessor    GrpcAccessor[postsPb.PostsServiceClient]
 	StatsGrpcAccessor    GrpcAccessor[statsPb.StatsServiceClient]
+
+	jwksRefreshJob      backjob.TickerJob
+	revocationsJob      backjob.TickerJob
+	soaInvalidationsJob backjob.TickerJob
+}
+
+type GrpcAccessor[TStub any] struct {
+	Target  string
+	Factory grpcutils.StubCreator[TStub]
+}
+
+func (a *GrpcAccessor[TStub]) createStub(qp *query.Params) (TStub, error) {
+	return a.Factory.New(a.Target, qp)
+}
+
+func NewGatewayService(cfg Config) GatewayService {
+	jwtKeys := soajwt.NewKeySetVerifier(cfg.JwtPublicKey)
+	revokedSessions := soajwt.NewRevocationCache()
+
+	service := GatewayService{
+		JwtVerifier: soajwt.NewRevocationVerifier(jwtKeys, revokedSessions),
+		AccountsGrpcAccessor: GrpcAccessor[accountsPb.AccountsServiceClient]{
+			Target:  fmt.Sprintf("%s:%d", cfg.AccountsServiceHost, cfg.AccountsServicePort),
+			Factory: grpcutils.DefaultAccountsStubCreator{},
+		},
+		PostsGrpcAccessor: GrpcAccessor[postsPb.PostsServiceClient]{
+			Target:  fmt.Sprintf("%s:%d", cfg.PostsServiceHost, cfg.PostsServicePort),
+			Factory: grpcutils.DefaultPostsStubCreator{},
+		},
+		StatsGrpcAccessor: GrpcAccessor[statsPb.StatsServiceClient]{
+			Target:  fmt.Sprintf("%s:%d", cfg.StatsServiceHost, cfg.StatsServicePort),
+			Factory: grpcutils.DefaultStatsStubCreator{},
+		},
+	}
+
+	service.SoaVerifier = soatoken.NewRemoteVerifier(accountsTokenValidator{accessor: service.AccountsGrpcAccessor}, soatoken.DEFAULT_REMOTE_VERIFIER_CACHE_TTL)
+	service.jwksRefreshJob = backjob.NewTickerJob(JWKS_REFRESH_PERIOD, soajwt.NewRefreshCallback(jwtKeys, service.fetchJwks))
+	service.revocationsJob = backjob.NewTickerJob(REVOCATIONS_REFRESH_PERIOD, soajwt.NewKafkaRevocationCallback("stats-kafka:9092", revokedSessions))
+	service.soaInvalidationsJob = backjob.NewTickerJob(REVOCATIONS_REFRESH_PERIOD, soatoken.NewKafkaInvalidationCallback("stats-kafka:9092", service.SoaVerifier))
+	return service
+}
+
+func (s *GatewayService) Start() {
+	s.jwksRefreshJob.Run()
+	s.revocationsJob.Run()
+	s.soaInvalidationsJob.Run()
+}
+
+func (s *GatewayService) fetchJwks(ctx context.Context) ([]soajwt.Jwk, error) {
+	stub, err := s.createAccountsStub(&query.Params{})
+	if err != nil {
+		return nil, err
+	}
+
+	return soajwt.NewGrpcJwksFetcher(stub)(ctx)
+}
+
+type accountsTokenValidator struct {
+	accessor GrpcAccessor[accountsPb.AccountsServiceClient]
+}
+
+func (v accountsTokenValidator) ValidateApiToken(ctx context.Context, in *accountsPb.ApiToken, opts ...grpc.CallOption) (*accountsPb.ApiTokenValidity, error) {
+	stub, err := v.accessor.createStub(&query.Params{})
+	if err != nil {
+		return nil, err
+	}
+
+	return stub.ValidateApiToken(ctx, in, opts...)
+}
+
+func (s *GatewayService) createAccountsStub(qp *query.Params) (accountsPb.AccountsServiceClient, error) {
+	return s.AccountsGrpcAccessor.createStub(qp)
+}
+
+func (s *GatewayService) createPostsStub(qp *query.Params) (postsPb.PostsServiceClient, error) {
+	return s.PostsGrpcAccessor.createStub(qp)
+}
+
+func (s *GatewayService) createStatsStub(qp *query.Params) (statsPb.StatsServiceClient, error) {
+	return s.StatsGrpcAccessor.createStub(qp)
+}
+
+func (s *GatewayService) RegisterProfile(qp *query.Params, req *api.RegisterProfileRequest) (api.RegisterProfileResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.RegisterProfileResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.RegisterUser(context.Background(), &accountsPb.RegisterUserRequest{
+		Login:       string(req.Login),
+		Password:    string(req.Password),
+		Email:       string(req.Email),
+		PhoneNumber: string(req.PhoneNumber),
+		Name:        string(req.Name),
+		Surname:     string(req.Surname),
+	})
+	if err != nil {
+		return api.RegisterProfileResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.RegisterProfileResponse{
+		ProfileId: resp.ProfileId,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) GetProfileInfo(qp *query.Params) (api.GetProfileResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.GetProfileResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.GetProfile(context.Background(), &accountsPb.GetProfileRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return api.GetProfileResponse{}, httperr.FromGrpcError(err)
+	}
+
+	var birthday string
+	if resp.Birthday != nil {
+		birthday = resp.Birthday.AsTime().Format("2006-01-02")
+	}
+
+	return api.GetProfileResponse{
+		Name:        resp.Name,
+		Surname:     resp.Surname,
+		Birthday:    birthday,
+		Bio:         resp.Bio,
+		Email:       resp.Email,
+		PhoneNumber: resp.PhoneNumber,
+
+		FollowersCount: int(resp.FollowersCount),
+		FollowingCount: int(resp.FollowingCount),
+
+		Avatar: profileImageFromProto(resp.Avatar),
+		Cover:  profileImageFromProto(resp.Cover),
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) SearchProfiles(qp *query.Params) (api.SearchProfilesResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.SearchProfilesResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.SearchProfiles(context.Background(), &accountsPb.SearchProfilesRequest{
+		Query:     qp.SearchQuery,
+		PageToken: qp.PageToken,
+	})
+	if err != nil {
+		return api.SearchProfilesResponse{}, httperr.FromGrpcError(err)
+	}
+
+	profiles := make([]api.ProfileCard, len(resp.Profiles))
+	for i, card := range resp.Profiles {
+		profiles[i] = profileCardFromProto(card)
+	}
+
+	return api.SearchProfilesResponse{
+		Profiles:      profiles,
+		NextPageToken: resp.NextPageToken,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) UploadAvatar(qp *query.Params, data []byte) (api.ProfileImage, httperr.Err) {
+	return s.uploadProfileImage(qp, accountsPb.ProfileImageKind_PROFILE_IMAGE_KIND_AVATAR, data)
+}
+
+func (s *GatewayService) UploadCover(qp *query.Params, data []byte) (api.ProfileImage, httperr.Err) {
+	return s.uploadProfileImage(qp, accountsPb.ProfileImageKind_PROFILE_IMAGE_KIND_COVER, data)
+}
+
+func (s *GatewayService) DeleteAvatar(qp *query.Params) httperr.Err {
+	return s.deleteProfileImage(qp, accountsPb.ProfileImageKind_PROFILE_IMAGE_KIND_AVATAR)
+}
+
+func (s *GatewayService) DeleteCover(qp *query.Params) httperr.Err {
+	return s.deleteProfileImage(qp, accountsPb.ProfileImageKind_PROFILE_IMAGE_KIND_COVER)
+}
+
+func (s *GatewayService) uploadProfileImage(qp *query.Params, kind accountsPb.ProfileImageKind, data []byte) (api.ProfileImage, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ProfileImage{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.UploadProfileImage(context.Background(), &accountsPb.UploadProfileImageRequest{
+		ProfileId: qp.ProfileId,
+		Kind:      kind,
+		Data:      data,
+	})
+	if err != nil {
+		return api.ProfileImage{}, httperr.FromGrpcError(err)
+	}
+
+	return *profileImageFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) deleteProfileImage(qp *query.Params, kind accountsPb.ProfileImageKind) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.DeleteProfileImage(context.Background(), &accountsPb.DeleteProfileImageRequest{
+		ProfileId: qp.ProfileId,
+		Kind:      kind,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+// Returns image data and its content type
+func (s *GatewayService) GetImage(qp *query.Params) ([]byte, string, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return nil, "", httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.GetImage(context.Background(), &accountsPb.GetImageRequest{
+		Key: qp.ImageKey,
+	})
+	if err != nil {
+		return nil, "", httperr.FromGrpcError(err)
+	}
+
+	return resp.Data, resp.ContentType, httperr.Ok()
+}
+
+func (s *GatewayService) Follow(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.Follow(context.Background(), &accountsPb.FollowRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) Unfollow(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.Unfollow(context.Background(), &accountsPb.FollowRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) Block(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.Block(context.Background(), &accountsPb.BlockRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) Unblock(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.Unblock(context.Background(), &accountsPb.BlockRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) ListBlocked(qp *query.Params) (api.ListBlockedResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListBlockedResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListBlocked(context.Background(), &accountsPb.ListBlockedRequest{
+		PageToken: qp.PageToken,
+	})
+	if err != nil {
+		return api.ListBlockedResponse{}, httperr.FromGrpcError(err)
+	}
+
+	profiles := make([]api.ProfileCard, len(resp.Profiles))
+	for i, card := range resp.Profiles {
+		profiles[i] = profileCardFromProto(card)
+	}
+
+	return api.ListBlockedResponse{
+		Profiles:      profiles,
+		NextPageToken: resp.NextPageToken,
+	}, httperr.Ok()
 }
 
-type GrpcAccessor[TStub any] struct {
-	Target  string
-	Factory grpcutils.StubCreator[TStub]
+type listFollowsMethod func(accountsPb.AccountsServiceClient, context.Context, *accountsPb.ListFollowsRequest, ...grpc.CallOption) (*accountsPb.ListFollowsResponse, error)
+
+func (s *GatewayService) ListFollowers(qp *query.Params) (api.ListFollowsResponse, httperr.Err) {
+	return s.listFollows(qp, accountsPb.AccountsServiceClient.ListFollowers)
+}
+
+func (s *GatewayService) ListFollowing(qp *query.Params) (api.ListFollowsResponse, httperr.Err) {
+	return s.listFollows(qp, accountsPb.AccountsServiceClient.ListFollowing)
+}
+
+func (s *GatewayService) ListFriends(qp *query.Params) (api.ListFollowsResponse, httperr.Err) {
+	return s.listFollows(qp, accountsPb.AccountsServiceClient.ListFriends)
+}
+
+func (s *GatewayService) listFollows(qp *query.Params, list listFollowsMethod) (api.ListFollowsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListFollowsResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := list(stub, context.Background(), &accountsPb.ListFollowsRequest{
+		ProfileId: qp.ProfileId,
+		PageToken: qp.PageToken,
+	})
+	if err != nil {
+		return api.ListFollowsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	profiles := make([]api.ProfileCard, len(resp.Profiles))
+	for i, card := range resp.Profiles {
+		profiles[i] = profileCardFromProto(card)
+	}
+
+	return api.ListFollowsResponse{
+		Profiles:      profiles,
+		NextPageToken: resp.NextPageToken,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) EditProfileInfo(qp *query.Params, req *api.EditProfileRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	var pbBirthday *timestamppb.Timestamp = nil
+	if req.Birthday.HasValue {
+		pbBirthday = timestamppb.New(req.Birthday.Value.Time)
+	}
+
+	_, err = stub.EditProfile(context.Background(), &accountsPb.EditProfileRequest{
+		ProfileId: qp.ProfileId,
+		EditedProfileData: &accountsPb.Profile{
+			Name:     string(req.Name.Value),
+			Surname:  string(req.Surname.Value),
+			Birthday: pbBirthday,
+			Bio:      string(req.Bio.Value),
+		},
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) GetPrivacySettings(qp *query.Params) (api.PrivacySettings, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.PrivacySettings{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.GetPrivacySettings(context.Background(), &accountsPb.GetPrivacySettingsRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return api.PrivacySettings{}, httperr.FromGrpcError(err)
+	}
+
+	return privacySettingsFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) EditPrivacySettings(qp *query.Params, req *api.EditPrivacySettingsRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.EditPrivacySettings(context.Background(), &accountsPb.EditPrivacySettingsRequest{
+		ProfileId: qp.ProfileId,
+		Settings: &accountsPb.PrivacySettings{
+			Birthday:    visibilityToProto(req.Birthday),
+			Bio:         visibilityToProto(req.Bio),
+			Email:       visibilityToProto(req.Email),
+			PhoneNumber: visibilityToProto(req.PhoneNumber),
+		},
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) DeleteProfile(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.UnregisterUser(context.Background(), &accountsPb.UnregisterUserRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) GetAccountStatus(qp *query.Params) (api.AccountStatus, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.AccountStatus{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.GetAccountStatus(context.Background(), &accountsPb.GetAccountStatusRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return api.AccountStatus{}, httperr.FromGrpcError(err)
+	}
+
+	return accountStatusFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) SetAccountRole(qp *query.Params, req *api.SetAccountRoleRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.SetAccountRole(context.Background(), &accountsPb.SetAccountRoleRequest{
+		ProfileId: qp.ProfileId,
+		Role:      roleToProto(req.Role),
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) SuspendAccount(qp *query.Params, req *api.SuspendAccountRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	var until *timestamppb.Timestamp
+	if req.Until.HasValue {
+		until = timestamppb.New(req.Until.Value)
+	}
+
+	_, err = stub.SuspendAccount(context.Background(), &accountsPb.SuspendAccountRequest{
+		ProfileId: qp.ProfileId,
+		Reason:    req.Reason,
+		Until:     until,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) UnsuspendAccount(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.UnsuspendAccount(context.Background(), &accountsPb.UnsuspendAccountRequest{
+		ProfileId: qp.ProfileId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) buildAuthByPassword(req *api.AuthenticateRequest) (proto accountsPb.AuthByPassword) {
+	if req.Login.HasValue {
+		proto.UserId = &accountsPb.AuthByPassword_Login{
+			Login: string(req.Login.Value),
+		}
+	} else if req.Email.HasValue {
+		proto.UserId = &accountsPb.AuthByPassword_Email{
+			Email: string(req.Email.Value),
+		}
+	} else if req.PhoneNumber.HasValue {
+		proto.UserId = &accountsPb.AuthByPassword_PhoneNumber{
+			PhoneNumber: string(req.PhoneNumber.Value),
+		}
+	} else {
+		panic("at least one user id must be provided")
+	}
+
+	proto.Password = string(req.Password)
+	return
+}
+
+func (s *GatewayService) Authenticate(qp *query.Params, req *api.AuthenticateRequest) (api.AuthenticateResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.AuthenticateResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	protoRequest := s.buildAuthByPassword(req)
+	resp, err := stub.Authenticate(context.Background(), &protoRequest)
+
+	if err != nil {
+		return api.AuthenticateResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return authResponseFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) AuthenticateSecondFactor(qp *query.Params, req *api.SecondFactorRequest) (api.AuthenticateResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.AuthenticateResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.AuthenticateSecondFactor(context.Background(), &accountsPb.SecondFactorRequest{
+		Challenge: req.Challenge,
+		Code:      req.Code,
+	})
+	if err != nil {
+		return api.AuthenticateResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return authResponseFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) RefreshToken(qp *query.Params, req *api.RefreshTokenRequest) (api.AuthenticateResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.AuthenticateResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.RefreshToken(context.Background(), &accountsPb.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		return api.AuthenticateResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return authResponseFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) GetJwks(qp *query.Params) (api.JwksResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.JwksResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	jwks, err := soajwt.NewGrpcJwksFetcher(stub)(context.Background())
+	if err != nil {
+		return api.JwksResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.JwksResponse{
+		Keys: jwks,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) CreateApiToken(qp *query.Params, req *api.CreateApiTokenRequest) (api.CreateApiTokenResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.CreateApiTokenResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	protoAuthByPassword := s.buildAuthByPassword(&req.Auth)
+
+	resp, err := stub.CreateApiToken(context.Background(), &accountsPb.CreateApiTokenRequest{
+		Auth: &protoAuthByPassword,
+		Params: &accountsPb.AuthTokenParams{
+			Name:        req.Name,
+			ReadAccess:  req.ReadAccess,
+			WriteAccess: req.WriteAccess,
+			Scopes:      req.Scopes,
+			Ttl:         durationpb.New(req.Ttl.Duration),
+		},
+	})
+
+	if err != nil {
+		return api.CreateApiTokenResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return createApiTokenResponseFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) CreateApiTokenSecondFactor(qp *query.Params, req *api.SecondFactorRequest) (api.CreateApiTokenResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.CreateApiTokenResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.CreateApiTokenSecondFactor(context.Background(), &accountsPb.SecondFactorRequest{
+		Challenge: req.Challenge,
+		Code:      req.Code,
+	})
+	if err != nil {
+		return api.CreateApiTokenResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return createApiTokenResponseFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) ListApiTokens(qp *query.Params) (api.ListApiTokensResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListApiTokensResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListApiTokens(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ListApiTokensResponse{}, httperr.FromGrpcError(err)
+	}
+
+	tokens := make([]api.ApiTokenInfo, 0, len(resp.Tokens))
+	for _, token := range resp.Tokens {
+		tokens = append(tokens, apiTokenInfoFromProto(token))
+	}
+
+	return api.ListApiTokensResponse{
+		Tokens: tokens,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) RevokeApiToken(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.RevokeApiToken(context.Background(), &accountsPb.RevokeApiTokenRequest{
+		TokenId: qp.TokenId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) RevokeAllApiTokens(qp *query.Params) (api.RevokeAllApiTokensResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.RevokeAllApiTokensResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.RevokeAllApiTokens(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.RevokeAllApiTokensResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.RevokeAllApiTokensResponse{
+		RevokedCount: resp.RevokedCount,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) ChangePassword(qp *query.Params, req *api.ChangePasswordRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.ChangePassword(context.Background(), &accountsPb.ChangePasswordRequest{
+		OldPassword: string(req.OldPassword),
+		NewPassword: string(req.NewPassword),
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) RequestPasswordReset(qp *query.Params, req *api.RequestPasswordResetRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.RequestPasswordReset(context.Background(), &accountsPb.RequestPasswordResetRequest{
+		UserId: userIdToProto(req.UserIdSchema),
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) ResetPassword(qp *query.Params, req *api.ResetPasswordRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.ResetPassword(context.Background(), &accountsPb.ResetPasswordRequest{
+		UserId:      userIdToProto(req.UserIdSchema),
+		Code:        req.Code,
+		NewPassword: string(req.NewPassword),
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) EnrollTotp(qp *query.Params) (api.EnrollTotpResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.EnrollTotpResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.EnrollTotp(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.EnrollTotpResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.EnrollTotpResponse{
+		Secret:     resp.Secret,
+		OtpauthUri: resp.OtpauthUri,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) ConfirmTotp(qp *query.Params, req *api.TotpCodeRequest) (api.BackupCodesResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.BackupCodesResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ConfirmTotp(context.Background(), &accountsPb.TotpCodeRequest{
+		Code: req.Code,
+	})
+	if err != nil {
+		return api.BackupCodesResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.BackupCodesResponse{
+		Codes: resp.Codes,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) DisableTotp(qp *query.Params, req *api.TotpCodeRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.DisableTotp(context.Background(), &accountsPb.TotpCodeRequest{
+		Code: req.Code,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) RegenerateBackupCodes(qp *query.Params, req *api.TotpCodeRequest) (api.BackupCodesResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.BackupCodesResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.RegenerateBackupCodes(context.Background(), &accountsPb.TotpCodeRequest{
+		Code: req.Code,
+	})
+	if err != nil {
+		return api.BackupCodesResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.BackupCodesResponse{
+		Codes: resp.Codes,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) ListAuthLockouts(qp *query.Params) (api.ListAuthLockoutsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListAuthLockoutsResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListAuthLockouts(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ListAuthLockoutsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	lockouts := make([]api.AuthLockout, 0, len(resp.Lockouts))
+	for _, lockout := range resp.Lockouts {
+		lockouts = append(lockouts, authLockoutFromProto(lockout))
+	}
+
+	return api.ListAuthLockoutsResponse{
+		Lockouts: lockouts,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) ClearAuthLockouts(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.ClearAuthLockouts(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) ListAuditEvents(qp *query.Params) (api.ListAuditEventsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListAuditEventsResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListAuditEvents(context.Background(), &accountsPb.ListAuditEventsRequest{
+		PageToken: qp.PageToken,
+	})
+	if err != nil {
+		return api.ListAuditEventsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	events := make([]api.AuditEvent, 0, len(resp.Events))
+	for _, event := range resp.Events {
+		events = append(events, auditEventFromProto(event))
+	}
+
+	return api.ListAuditEventsResponse{
+		Events:        events,
+		NextPageToken: resp.NextPageToken,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) ListSessions(qp *query.Params) (api.ListSessionsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListSessionsResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListSessions(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ListSessionsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	sessions := make([]api.Session, 0, len(resp.Sessions))
+	for _, session := range resp.Sessions {
+		sessions = append(sessions, sessionFromProto(session))
+	}
+
+	return api.ListSessionsResponse{
+		Sessions: sessions,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) RequestDataExport(qp *query.Params) (api.DataExport, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.DataExport{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.RequestDataExport(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.DataExport{}, httperr.FromGrpcError(err)
+	}
+
+	return dataExportFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) GetDataExport(qp *query.Params) (api.DataExport, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.DataExport{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.GetDataExport(context.Background(), &accountsPb.GetDataExportRequest{
+		ExportId: qp.ExportId,
+	})
+	if err != nil {
+		return api.DataExport{}, httperr.FromGrpcError(err)
+	}
+
+	return dataExportFromProto(resp), httperr.Ok()
+}
+
+// Archive is received in chunks and passed to write as they come, so it is never held in memory as a whole.
+// Errors are reported before the first write whenever possible.
+func (s *GatewayService) DownloadDataExport(qp *query.Params, write func(chunk []byte) error) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	// stops the stream if the archive is not read to the end
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := stub.DownloadDataExport(ctx, &accountsPb.GetDataExportRequest{
+		ExportId: qp.ExportId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return httperr.Ok()
+		}
+		if err != nil {
+			return httperr.FromGrpcError(err)
+		}
+
+		err = write(resp.Data)
+		if err != nil {
+			return httperr.New(http.StatusInternalServerError, err)
+		}
+	}
+}
+
+func (s *GatewayService) TerminateSession(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.TerminateSession(context.Background(), &accountsPb.TerminateSessionRequest{
+		SessionId: qp.SessionId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
+func (s *GatewayService) TerminateAllSessions(qp *query.Params, req *api.TerminateAllSessionsRequest) (api.TerminateAllSessionsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.TerminateAllSessionsResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.TerminateAllSessions(context.Background(), &accountsPb.TerminateAllSessionsRequest{
+		KeepCurrent: req.KeepCurrent,
+	})
+	if err != nil {
+		return api.TerminateAllSessionsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.TerminateAllSessionsResponse{
+		TerminatedCount: resp.TerminatedCount,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) RegisterOAuthClient(qp *query.Params, req *api.RegisterOAuthClientRequest) (api.OAuthClient, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.OAuthClient{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.RegisterOAuthClient(context.Background(), &accountsPb.RegisterOAuthClientRequest{
+		Name:         req.Name,
+		RedirectUris: req.RedirectUris,
+	})
+	if err != nil {
+		return api.OAuthClient{}, httperr.FromGrpcError(err)
+	}
+
+	return oauthClientFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) ListOAuthClients(qp *query.Params) (api.ListOAuthClientsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListOAuthClientsResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListOAuthClients(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ListOAuthClientsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	clients := make([]api.OAuthClient, 0, len(resp.Clients))
+	for _, client := range resp.Clients {
+		clients = append(clients, oauthClientFromProto(client))
+	}
+
+	return api.ListOAuthClientsResponse{
+		Clients: clients,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) GetOAuthClient(qp *query.Params) (api.OAuthClient, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.OAuthClient{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.GetOAuthClient(context.Background(), &accountsPb.OAuthClientRequest{
+		ClientId: qp.ClientId,
+	})
+	if err != nil {
+		return api.OAuthClient{}, httperr.FromGrpcError(err)
+	}
+
+	return oauthClientFromProto(resp), httperr.Ok()
+}
+
+func (s *GatewayService) DeleteOAuthClient(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.DeleteOAuthClient(context.Background(), &accountsPb.OAuthClientRequest{
+		ClientId: qp.ClientId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
 }
 
-func (a *GrpcAccessor[TStub]) createStub(qp *query.Params) (TStub, error) {
-	return a.Factory.New(a.Target, qp)
+func (s *GatewayService) AuthorizeOAuthClient(qp *query.Params, req *api.AuthorizeOAuthClientRequest) (api.AuthorizeOAuthClientResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.AuthorizeOAuthClientResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.AuthorizeOAuthClient(context.Background(), &accountsPb.AuthorizeOAuthClientRequest{
+		ClientId:            req.ClientId,
+		RedirectUri:         req.RedirectUri,
+		Scopes:              req.Scopes,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	})
+	if err != nil {
+		return api.AuthorizeOAuthClientResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.AuthorizeOAuthClientResponse{
+		Code:       resp.Code,
+		RedirectTo: resp.RedirectTo,
+	}, httperr.Ok()
 }
 
-func NewGatewayService(cfg Config) GatewayService {
-	jwtVerifier := soajwt.NewEd25519Verifier(cfg.JwtPublicKey)
-	return GatewayService{
-		JwtVerifier: &jwtVerifier,
-		AccountsGrpcAccessor: GrpcAccessor[accountsPb.AccountsServiceClient]{
-			Target:  fmt.Sprintf("%s:%d", cfg.AccountsServiceHost, cfg.AccountsServicePort),
-			Factory: grpcutils.DefaultAccountsStubCreator{},
-		},
-		PostsGrpcAccessor: GrpcAccessor[postsPb.PostsServiceClient]{
-			Target:  fmt.Sprintf("%s:%d", cfg.PostsServiceHost, cfg.PostsServicePort),
-			Factory: grpcutils.DefaultPostsStubCreator{},
-		},
-		StatsGrpcAccessor: GrpcAccessor[statsPb.StatsServiceClient]{
-			Target:  fmt.Sprintf("%s:%d", cfg.StatsServiceHost, cfg.StatsServicePort),
-			Factory: grpcutils.DefaultStatsStubCreator{},
-		},
+const OAUTH_GRANT_TYPE_AUTHORIZATION_CODE = "authorization_code"
+
+func (s *GatewayService) ExchangeOAuthCode(qp *query.Params, req *api.OAuthTokenRequest) (api.OAuthTokenResponse, httperr.Err) {
+	if req.GrantType != OAUTH_GRANT_TYPE_AUTHORIZATION_CODE {
+		return api.OAuthTokenResponse{}, httperr.New(http.StatusBadRequest, fmt.Errorf("unsupported grant type %q", req.GrantType))
 	}
+
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.OAuthTokenResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ExchangeOAuthCode(context.Background(), &accountsPb.ExchangeOAuthCodeRequest{
+		ClientId:     req.ClientId,
+		Code:         req.Code,
+		RedirectUri:  req.RedirectUri,
+		CodeVerifier: req.CodeVerifier,
+	})
+	if err != nil {
+		return api.OAuthTokenResponse{}, httperr.FromGrpcError(err)
+	}
+
+	validUntil := resp.ValidUntil.AsTime()
+	return api.OAuthTokenResponse{
+		AccessToken: resp.AccessToken,
+		TokenType:   resp.TokenType,
+		ExpiresIn:   int64(time.Until(validUntil).Seconds()),
+		ValidUntil:  validUntil,
+		Scopes:      resp.Scopes,
+		TokenId:     resp.TokenId,
+	}, httperr.Ok()
 }
 
-func (s *GatewayService) createAccountsStub(qp *query.Params) (accountsPb.AccountsServiceClient, error) {
-	return s.AccountsGrpcAccessor.createStub(qp)
+func (s *GatewayService) ListAuthorizedApps(qp *query.Params) (api.ListAuthorizedAppsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListAuthorizedAppsResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListAuthorizedApps(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ListAuthorizedAppsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	apps := make([]api.AuthorizedApp, 0, len(resp.Apps))
+	for _, app := range resp.Apps {
+		apps = append(apps, authorizedAppFromProto(app))
+	}
+
+	return api.ListAuthorizedAppsResponse{
+		Apps: apps,
+	}, httperr.Ok()
 }
 
-func (s *GatewayService) createPostsStub(qp *query.Params) (postsPb.PostsServiceClient, error) {
-	return s.PostsGrpcAccessor.createStub(qp)
+func (s *GatewayService) RevokeAuthorizedApp(qp *query.Params) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.RevokeAuthorizedApp(context.Background(), &accountsPb.OAuthClientRequest{
+		ClientId: qp.ClientId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
 }
 
-func (s *GatewayService) createStatsStub(qp *query.Params) (statsPb.StatsServiceClient, error) {
-	return s.StatsGrpcAccessor.createStub(qp)
+func (s *GatewayService) ListOidcProviders(qp *query.Params) (api.ListOidcProvidersResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListOidcProvidersResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListOidcProviders(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ListOidcProvidersResponse{}, httperr.FromGrpcError(err)
+	}
+
+	providers := make([]api.OidcProvider, 0, len(resp.Providers))
+	for _, provider := range resp.Providers {
+		providers = append(providers, api.OidcProvider{
+			Name:          provider.Name,
+			AutoProvision: provider.AutoProvision,
+		})
+	}
+
+	return api.ListOidcProvidersResponse{
+		Providers: providers,
+	}, httperr.Ok()
 }
 
-func (s *GatewayService) RegisterProfile(qp *query.Params, req *api.RegisterProfileRequest) (api.RegisterProfileResponse, httperr.Err) {
+func (s *GatewayService) BeginOidcLogin(qp *query.Params) (api.BeginOidcLoginResponse, httperr.Err) {
 	stub, err := s.createAccountsStub(qp)
 	if err != nil {
-		return api.RegisterProfileResponse{}, httperr.New(http.StatusInternalServerError, err)
+		return api.BeginOidcLoginResponse{}, httperr.New(http.StatusInternalServerError, err)
 	}
 
-	resp, err := stub.RegisterUser(context.Background(), &accountsPb.RegisterUserRequest{
-		Login:       string(req.Login),
-		Password:    string(req.Password),
-		Email:       string(req.Email),
-		PhoneNumber: string(req.PhoneNumber),
-		Name:        string(req.Name),
-		Surname:     string(req.Surname),
+	resp, err := stub.BeginOidcLogin(context.Background(), &accountsPb.OidcProviderRequest{
+		Provider: qp.OidcProvider,
 	})
 	if err != nil {
-		return api.RegisterProfileResponse{}, httperr.FromGrpcError(err)
+		return api.BeginOidcLoginResponse{}, httperr.FromGrpcError(err)
 	}
 
-	return api.RegisterProfileResponse{
-		ProfileId: resp.ProfileId,
+	return api.BeginOidcLoginResponse{
+		AuthorizationUrl: resp.AuthorizationUrl,
+		Binding:          resp.Binding,
 	}, httperr.Ok()
 }
 
-func (s *GatewayService) GetProfileInfo(qp *query.Params) (api.GetProfileResponse, httperr.Err) {
+func (s *GatewayService) CompleteOidcLogin(qp *query.Params, req *api.CompleteOidcLoginRequest) (api.AuthenticateResponse, httperr.Err) {
 	stub, err := s.createAccountsStub(qp)
 	if err != nil {
-		return api.GetProfileResponse{}, httperr.New(http.StatusInternalServerError, err)
+		return api.AuthenticateResponse{}, httperr.New(http.StatusInternalServerError, err)
 	}
 
-	resp, err := stub.GetProfile(context.Background(), &accountsPb.GetProfileRequest{
-		ProfileId: qp.ProfileId,
+	resp, err := stub.CompleteOidcLogin(context.Background(), &accountsPb.CompleteOidcLoginRequest{
+		Provider: qp.OidcProvider,
+		Code:     req.Code,
+		State:    req.State,
+		Binding:  req.Binding,
 	})
 	if err != nil {
-		return api.GetProfileResponse{}, httperr.FromGrpcError(err)
+		return api.AuthenticateResponse{}, httperr.FromGrpcError(err)
 	}
 
-	return api.GetProfileResponse{
-		Name:     resp.Name,
-		Surname:  resp.Surname,
-		Birthday: resp.Birthday.AsTime().Format("2006-01-02"),
-		Bio:      resp.Bio,
-	}, httperr.Ok()
+	return authResponseFromProto(resp), httperr.Ok()
 }
 
-func (s *GatewayService) EditProfileInfo(qp *query.Params, req *api.EditProfileRequest) httperr.Err {
+func (s *GatewayService) BeginOidcLink(qp *query.Params) (api.BeginOidcLoginResponse, httperr.Err) {
 	stub, err := s.createAccountsStub(qp)
 	if err != nil {
-		return httperr.New(http.StatusInternalServerError, err)
+		return api.BeginOidcLoginResponse{}, httperr.New(http.StatusInternalServerError, err)
 	}
 
-	var pbBirthday *timestamppb.Timestamp = nil
-	if req.Birthday.HasValue {
-		pbBirthday = timestamppb.New(req.Birthday.Value.Time)
+	resp, err := stub.BeginOidcLink(context.Background(), &accountsPb.OidcProviderRequest{
+		Provider: qp.OidcProvider,
+	})
+	if err != nil {
+		return api.BeginOidcLoginResponse{}, httperr.FromGrpcError(err)
 	}
 
-	_, err = stub.EditProfile(context.Background(), &accountsPb.EditProfileRequest{
-		ProfileId: qp.ProfileId,
-		EditedProfileData: &accountsPb.Profile{
-			Name:     string(req.Name.Value),
-			Surname:  string(req.Surname.Value),
-			Birthday: pbBirthday,
-			Bio:      string(req.Bio.Value),
-		},
+	return api.BeginOidcLoginResponse{
+		AuthorizationUrl: resp.AuthorizationUrl,
+		Binding:          resp.Binding,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) CompleteOidcLink(qp *query.Params, req *api.CompleteOidcLoginRequest) (api.OidcIdentity, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.OidcIdentity{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.CompleteOidcLink(context.Background(), &accountsPb.CompleteOidcLoginRequest{
+		Provider: qp.OidcProvider,
+		Code:     req.Code,
+		State:    req.State,
+		Binding:  req.Binding,
 	})
 	if err != nil {
-		return httperr.FromGrpcError(err)
+		return api.OidcIdentity{}, httperr.FromGrpcError(err)
 	}
 
-	return httperr.Ok()
+	return oidcIdentityFromProto(resp), httperr.Ok()
 }
 
-func (s *GatewayService) DeleteProfile(qp *query.Params) httperr.Err {
+func (s *GatewayService) ListOidcIdentities(qp *query.Params) (api.ListOidcIdentitiesResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ListOidcIdentitiesResponse{}, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	resp, err := stub.ListOidcIdentities(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ListOidcIdentitiesResponse{}, httperr.FromGrpcError(err)
+	}
+
+	identities := make([]api.OidcIdentity, 0, len(resp.Identities))
+	for _, identity := range resp.Identities {
+		identities = append(identities, oidcIdentityFromProto(identity))
+	}
+
+	return api.ListOidcIdentitiesResponse{
+		Identities: identities,
+	}, httperr.Ok()
+}
+
+func (s *GatewayService) UnlinkOidcIdentity(qp *query.Params) httperr.Err {
 	stub, err := s.createAccountsStub(qp)
 	if err != nil {
 		return httperr.New(http.StatusInternalServerError, err)
 	}
 
-	_, err = stub.UnregisterUser(context.Background(), &accountsPb.UnregisterUserRequest{
-		ProfileId: qp.ProfileId,
+	_, err = stub.UnlinkOidcIdentity(context.Background(), &accountsPb.OidcProviderRequest{
+		Provider: qp.OidcProvider,
 	})
 	if err != nil {
 		return httperr.FromGrpcError(err)
@@ -152,69 +1327,74 @@ func (s *GatewayService) DeleteProfile(qp *query.Params) httperr.Err {
 	return httperr.Ok()
 }
 
-func (s *GatewayService) buildAuthByPassword(req *api.AuthenticateRequest) (proto accountsPb.AuthByPassword) {
-	if req.Login.HasValue {
-		proto.UserId = &accountsPb.AuthByPassword_Login{
-			Login: string(req.Login.Value),
-		}
-	} else if req.Email.HasValue {
-		proto.UserId = &accountsPb.AuthByPassword_Email{
-			Email: string(req.Email.Value),
-		}
-	} else if req.PhoneNumber.HasValue {
-		proto.UserId = &accountsPb.AuthByPassword_PhoneNumber{
-			PhoneNumber: string(req.PhoneNumber.Value),
-		}
-	} else {
-		panic("at least one user id must be provided")
+func (s *GatewayService) GetContacts(qp *query.Params) (api.ContactsResponse, httperr.Err) {
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return api.ContactsResponse{}, httperr.New(http.StatusInternalServerError, err)
 	}
 
-	proto.Password = string(req.Password)
-	return
+	resp, err := stub.GetContacts(context.Background(), &accountsPb.Empty{})
+	if err != nil {
+		return api.ContactsResponse{}, httperr.FromGrpcError(err)
+	}
+
+	return api.ContactsResponse{
+		Email:               resp.Email,
+		EmailVerified:       resp.EmailVerified,
+		PhoneNumber:         resp.PhoneNumber,
+		PhoneNumberVerified: resp.PhoneNumberVerified,
+	}, httperr.Ok()
 }
 
-func (s *GatewayService) Authenticate(qp *query.Params, req *api.AuthenticateRequest) (api.AuthenticateResponse, httperr.Err) {
+func (s *GatewayService) ChangeContact(qp *query.Params, kind api.ContactKind, value string, password string) httperr.Err {
 	stub, err := s.createAccountsStub(qp)
 	if err != nil {
-		return api.AuthenticateResponse{}, httperr.New(http.StatusInternalServerError, err)
+		return httperr.New(http.StatusInternalServerError, err)
 	}
 
-	protoRequest := s.buildAuthByPassword(req)
-	resp, err := stub.Authenticate(context.Background(), &protoRequest)
-
+	_, err = stub.ChangeContact(context.Background(), &accountsPb.ChangeContactRequest{
+		Kind:     contactKindToProto(kind),
+		Value:    value,
+		Password: password,
+	})
 	if err != nil {
-		return api.AuthenticateResponse{}, httperr.FromGrpcError(err)
+		return httperr.FromGrpcError(err)
 	}
 
-	return api.AuthenticateResponse{
-		Token: resp.Token,
-	}, httperr.Ok()
+	return httperr.Ok()
 }
 
-func (s *GatewayService) CreateApiToken(qp *query.Params, req *api.CreateApiTokenRequest) (api.CreateApiTokenResponse, httperr.Err) {
+func (s *GatewayService) SendVerificationCode(qp *query.Params, kind api.ContactKind) httperr.Err {
 	stub, err := s.createAccountsStub(qp)
 	if err != nil {
-		return api.CreateApiTokenResponse{}, httperr.New(http.StatusInternalServerError, err)
+		return httperr.New(http.StatusInternalServerError, err)
 	}
 
-	protoAuthByPassword := s.buildAuthByPassword(&req.Auth)
-
-	resp, err := stub.CreateApiToken(context.Background(), &accountsPb.CreateApiTokenRequest{
-		Auth: &protoAuthByPassword,
-		Params: &accountsPb.AuthTokenParams{
-			ReadAccess:  req.ReadAccess,
-			WriteAccess: req.WriteAccess,
-			Ttl:         durationpb.New(req.Ttl.Duration),
-		},
+	_, err = stub.SendVerificationCode(context.Background(), &accountsPb.SendVerificationCodeRequest{
+		Kind: contactKindToProto(kind),
 	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
 
+func (s *GatewayService) ConfirmContact(qp *query.Params, kind api.ContactKind, req *api.ConfirmContactRequest) httperr.Err {
+	stub, err := s.createAccountsStub(qp)
 	if err != nil {
-		return api.CreateApiTokenResponse{}, httperr.FromGrpcError(err)
+		return httperr.New(http.StatusInternalServerError, err)
 	}
 
-	return api.CreateApiTokenResponse{
-		Token: resp.Token,
-	}, httperr.Ok()
+	_, err = stub.ConfirmContact(context.Background(), &accountsPb.ConfirmContactRequest{
+		Kind: contactKindToProto(kind),
+		Code: req.Code,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
 }
 
 func (s *GatewayService) resolveProfileId(qp *query.Params, profileId string) (int32, httperr.Err) {
@@ -233,6 +1413,62 @@ func (s *GatewayService) resolveProfileId(qp *query.Params, profileId string) (i
 	return resp.AccountId, httperr.Ok()
 }
 
+// Fetches profiles of accounts with two batch calls. Accounts without
+// profile (e.g. being unregistered) are missing in the result.
+func (s *GatewayService) resolveAuthors(qp *query.Params, accountIds []int32) (map[int32]*api.Author, httperr.Err) {
+	if len(accountIds) == 0 {
+		return nil, httperr.Ok()
+	}
+
+	stub, err := s.createAccountsStub(qp)
+	if err != nil {
+		return nil, httperr.New(http.StatusInternalServerError, err)
+	}
+
+	ctx := context.Background()
+	resolved, err := stub.ResolveAccountIds(ctx, &accountsPb.ResolveAccountIdsRequest{
+		AccountIds: accountIds,
+	})
+	if err != nil {
+		return nil, httperr.FromGrpcError(err)
+	}
+
+	accountIdByProfileId := make(map[string]int32, len(resolved.ProfileIds))
+	profileIds := make([]string, 0, len(resolved.ProfileIds))
+	for accountId, profileId := range resolved.ProfileIds {
+		accountIdByProfileId[profileId] = accountId
+		profileIds = append(profileIds, profileId)
+	}
+
+	resp, err := stub.GetProfiles(ctx, &accountsPb.GetProfilesRequest{
+		ProfileIds: profileIds,
+	})
+	if err != nil {
+		return nil, httperr.FromGrpcError(err)
+	}
+
+	authors := make(map[int32]*api.Author, len(resp.Profiles))
+	for _, profile := range resp.Profiles {
+		authors[accountIdByProfileId[profile.ProfileId]] = authorFromProto(profile)
+	}
+
+	return authors, httperr.Ok()
+}
+
+// Returns unique account ids of authors of items
+func uniqueAuthorIds[T any](items []T, authorId func(T) int32) []int32 {
+	seen := make(map[int32]bool, len(items))
+	ids := make([]int32, 0, len(items))
+	for _, item := range items {
+		id := authorId(item)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 func (s *GatewayService) GetPageSettings(qp *query.Params) (api.GetPageSettingsResponse, httperr.Err) {
 	accountId, accErr := s.resolveProfileId(qp, qp.ProfileId)
 	if !accErr.IsOk() {
@@ -331,7 +1567,14 @@ func (s *GatewayService) GetPost(qp *query.Params) (api.Post, httperr.Err) {
 		return api.Post{}, httperr.FromGrpcError(err)
 	}
 
-	return postFromProto(resp), httperr.Ok()
+	authors, authorsErr := s.resolveAuthors(qp, []int32{resp.AuthorAccountId})
+	if !authorsErr.IsOk() {
+		return api.Post{}, authorsErr
+	}
+
+	post := postFromProto(resp)
+	post.Author = authors[post.AuthorId]
+	return post, httperr.Ok()
 }
 
 func (s *GatewayService) GetPosts(qp *query.Params, req *api.GetPostsRequest) (api.GetPostsResponse, httperr.Err) {
@@ -353,9 +1596,15 @@ func (s *GatewayService) GetPosts(qp *query.Params, req *api.GetPostsRequest) (a
 		return api.GetPostsResponse{}, httperr.FromGrpcError(err)
 	}
 
+	authors, authorsErr := s.resolveAuthors(qp, uniqueAuthorIds(resp.Posts, (*postsPb.Post).GetAuthorAccountId))
+	if !authorsErr.IsOk() {
+		return api.GetPostsResponse{}, authorsErr
+	}
+
 	posts := make([]api.Post, len(resp.Posts))
 	for i, p := range resp.Posts {
 		posts[i] = postFromProto(p)
+		posts[i].Author = authors[p.AuthorAccountId]
 	}
 
 	return api.GetPostsResponse{
@@ -398,6 +1647,22 @@ func (s *GatewayService) DeletePost(qp *query.Params) httperr.Err {
 	return httperr.Ok()
 }
 
+func (s *GatewayService) DeleteComment(qp *query.Params) httperr.Err {
+	stub, err := s.createPostsStub(qp)
+	if err != nil {
+		return httperr.New(http.StatusInternalServerError, err)
+	}
+
+	_, err = stub.DeleteComment(context.Background(), &postsPb.DeleteCommentRequest{
+		CommentId: qp.CommentId,
+	})
+	if err != nil {
+		return httperr.FromGrpcError(err)
+	}
+
+	return httperr.Ok()
+}
+
 func (s *GatewayService) NewComment(qp *query.Params, req *api.NewCommentRequest) (api.NewCommentResponse, httperr.Err) {
 	stub, err := s.createPostsStub(qp)
 	if err != nil {
@@ -441,9 +1706,15 @@ func (s *GatewayService) GetComments(qp *query.Params, req *api.GetCommentsReque
 		return api.GetCommentsResponse{}, httperr.FromGrpcError(err)
 	}
 
+	authors, authorsErr := s.resolveAuthors(qp, uniqueAuthorIds(resp.Comments, (*postsPb.Comment).GetAuthorAccountId))
+	if !authorsErr.IsOk() {
+		return api.GetCommentsResponse{}, authorsErr
+	}
+
 	comments := make([]api.Comment, len(resp.Comments))
 	for i, comment := range resp.Comments {
 		comments[i] = commentFromProto(comment)
+		comments[i].Author = authors[comment.AuthorAccountId]
 	}
 
 	return api.GetCommentsResponse{
@@ -567,20 +1838,32 @@ func (s *GatewayService) GetTop10Users(qp *query.Params, req *api.GetTop10UsersR
 
 	accountsStub, err := s.createAccountsStub(qp)
 	if err != nil {
-		return api.GetTop10UsersResponse{}, httperr.Ok()
+		return api.GetTop10UsersResponse{}, httperr.New(http.StatusInternalServerError, err)
 	}
 
-	users := make([]api.UserStats, len(resp.Users))
+	accountIds := make([]int32, len(resp.Users))
 	for i, userPb := range resp.Users {
-		resp, err := accountsStub.ResolveAccountId(ctx, &accountsPb.ResolveAccountIdRequest{
-			AccountId: userPb.UserId,
-		})
-		if err != nil {
-			return api.GetTop10UsersResponse{}, httperr.FromGrpcError(err)
+		accountIds[i] = userPb.UserId
+	}
+
+	resolved, err := accountsStub.ResolveAccountIds(ctx, &accountsPb.ResolveAccountIdsRequest{
+		AccountIds: accountIds,
+	})
+	if err != nil {
+		return api.GetTop10UsersResponse{}, httperr.FromGrpcError(err)
+	}
+
+	// Stats of just unregistered accounts may be not erased yet, they are skipped
+	users := make([]api.UserStats, 0, len(resp.Users))
+	for _, userPb := range resp.Users {
+		profileId, ok := resolved.ProfileIds[userPb.UserId]
+		if !ok {
+			continue
 		}
 
-		users[i] = userStatsFromProto(userPb, req.Metric)
-		users[i].Id = resp.ProfileId
+		user := userStatsFromProto(userPb, req.Metric)
+		user.Id = profileId
+		users = append(users, user)
 	}
 
 	return api.GetTop10UsersResponse{
@@ -24,7 +24,7 @@ func (o *Optional[T]) UnmarshalJSON(b []byte) error {
 
 func (o Optional[T]) MarshalJSON() ([]byte, error) {
 	if !o.HasValue {
-		json.Marshal(nil)
+		return json.Marshal(nil)
 	}
 	return json.Marshal(o.Value)
 }
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Role of account, granted by administrators
+type Role string
+
+const (
+	ROLE_USER      Role = "user"
+	ROLE_MODERATOR Role = "moderator"
+	ROLE_ADMIN     Role = "admin"
+)
+
+var error_unknown_role = fmt.Errorf("unknown role, must be one of %v",
+	[]Role{ROLE_USER, ROLE_MODERATOR, ROLE_ADMIN})
+
+func (r *Role) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	switch role := Role(s); role {
+	case ROLE_USER, ROLE_MODERATOR, ROLE_ADMIN:
+		*r = role
+		return nil
+	}
+
+	return error_unknown_role
+}
+
+func (r Role) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(r))
+}
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleValid(t *testing.T) {
+	for _, s := range []string{"user", "moderator", "admin"} {
+		role, err := unmarshalFromString[Role](s)
+		require.NoError(t, err, "cannot unmarshal valid role %s", s)
+		assert.Equal(t, s, string(role))
+	}
+}
+
+func TestRoleUnknown(t *testing.T) {
+	_, err := unmarshalFromString[Role]("owner")
+	require.Error(t, err, "unknown role is unmarshable")
+}
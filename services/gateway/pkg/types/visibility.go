@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Who can see a profile field besides its owner
+type Visibility string
+
+const (
+	VISIBILITY_EVERYONE      Visibility = "everyone"
+	VISIBILITY_AUTHENTICATED Visibility = "authenticated"
+	VISIBILITY_ONLY_ME       Visibility = "only_me"
+)
+
+var error_unknown_visibility = fmt.Errorf("unknown visibility, must be one of %v",
+	[]Visibility{VISIBILITY_EVERYONE, VISIBILITY_AUTHENTICATED, VISIBILITY_ONLY_ME})
+
+func (v *Visibility) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	switch visibility := Visibility(s); visibility {
+	case VISIBILITY_EVERYONE, VISIBILITY_AUTHENTICATED, VISIBILITY_ONLY_ME:
+		*v = visibility
+		return nil
+	}
+
+	return error_unknown_visibility
+}
+
+func (v Visibility) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(v))
+}
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisibilityValid(t *testing.T) {
+	for _, s := range []string{"everyone", "authenticated", "only_me"} {
+		visibility, err := unmarshalFromString[Visibility](s)
+		require.NoError(t, err, "cannot unmarshal valid visibility %s", s)
+		assert.Equal(t, s, string(visibility))
+	}
+}
+
+func TestVisibilityUnknown(t *testing.T) {
+	_, err := unmarshalFromString[Visibility]("friends")
+	require.Error(t, err, "unknown visibility is unmarshable")
+}
@@ -11,11 +11,13 @@ func main() {
 	port := envvar.MustIntFromEnv("POSTS_SERVICE_PORT")
 
 	s, err := server.Create(service.PostsServiceConfig{
-		DbHost:       envvar.MustStringFromEnv("DB_HOST"),
-		DbUser:       envvar.MustStringFromEnv("DB_USER"),
-		DbPassword:   envvar.MustStringFromEnv("DB_PASSWORD"),
-		DbPoolSize:   envvar.MustIntFromEnv("DB_POOL_SIZE"),
-		JwtPublicKey: envvar.MustEd25519PubKeyFromEnv("JWT_ED25519_PUBLIC_KEY"),
+		DbHost:              envvar.MustStringFromEnv("DB_HOST"),
+		DbUser:              envvar.MustStringFromEnv("DB_USER"),
+		DbPassword:          envvar.MustStringFromEnv("DB_PASSWORD"),
+		DbPoolSize:          envvar.MustIntFromEnv("DB_POOL_SIZE"),
+		JwtPublicKey:        envvar.MustEd25519PubKeyFromEnv("JWT_ED25519_PUBLIC_KEY"),
+		AccountsServiceHost: envvar.MustStringFromEnv("ACCOUNTS_SERVICE_HOST"),
+		AccountsServicePort: envvar.MustIntFromEnv("ACCOUNTS_SERVICE_PORT"),
 	})
 	if err != nil {
 		panic(err)
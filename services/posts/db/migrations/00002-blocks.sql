@@ -0,0 +1,11 @@
+-- Copy of block lists of accounts service, maintained by block and unblock events.
+-- Unblocked pairs are kept to discard block events delivered out of order.
+CREATE TABLE IF NOT EXISTS blocks (
+    blocker_account_id INTEGER NOT NULL,
+    blocked_account_id INTEGER NOT NULL,
+    is_blocked BOOLEAN NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (blocker_account_id, blocked_account_id)
+);
+
+CREATE INDEX IF NOT EXISTS blocks_blocked_idx ON blocks (blocked_account_id);
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+type Like struct {
+	PostId    PostId
+	AccountId AccountId
+	CreatedAt time.Time
+}
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"soa-socialnetwork/services/posts/internal/models"
+	"time"
+)
+
+// Local copy of block lists owned by accounts service
+type BlocksRepository interface {
+	// Change is ignored if a later one of the same pair is already applied,
+	// so events may be applied in any order
+	SetBlocked(blocker models.AccountId, blocked models.AccountId, isBlocked bool, changedAt time.Time) error
+	IsBlocked(blocker models.AccountId, blocked models.AccountId) (bool, error)
+
+	// Deletes blocks of account in both directions
+	DeleteByAccountId(models.AccountId) error
+}
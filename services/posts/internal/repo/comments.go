@@ -7,7 +7,16 @@ import (
 
 type CommentsRepository interface {
 	New(models.PostId, NewCommentData) (models.CommentId, error)
+	Get(models.CommentId) (models.Comment, error)
 	List(models.PostId, PagiToken) (CommentsList, error)
+	// Lists all comments written by account, the oldest first
+	ListByAuthor(models.AccountId) ([]models.Comment, error)
+	// Replies to the deleted comment are kept
+	Delete(models.CommentId) error
+
+	// Deletes comments written by account and comments under posts
+	// deleted by PostsRepository.DeleteByAccountId.
+	DeleteByAccountId(models.AccountId) error
 }
 
 type NewCommentData struct {
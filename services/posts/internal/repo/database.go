@@ -9,6 +9,7 @@ type RepositoryProvider interface {
 	Posts() PostsRepository
 	Comments() CommentsRepository
 	Metrics() MetricsRepository
+	Blocks() BlocksRepository
 	Outbox() OutboxRepository
 }
 
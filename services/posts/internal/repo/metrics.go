@@ -7,4 +7,10 @@ import (
 type MetricsRepository interface {
 	NewView(models.AccountId, models.PostId) error
 	NewLike(models.AccountId, models.PostId) error
+	// Lists all likes put by account, the oldest first
+	ListLikesByAccountId(models.AccountId) ([]models.Like, error)
+
+	// Deletes likes put by account and likes of posts
+	// deleted by PostsRepository.DeleteByAccountId.
+	DeleteLikesByAccountId(models.AccountId) error
 }
@@ -10,6 +10,7 @@ type PagesRepository interface {
 	GetByPageId(models.PageId) (models.Page, error)
 	GetByPostId(models.PostId) (models.Page, error)
 	Edit(models.PageId, EditedPageSettings) error
+	DeleteByAccountId(models.AccountId) error
 }
 
 type EditedPageSettings struct {
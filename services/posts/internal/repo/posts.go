@@ -11,6 +11,11 @@ type PostsRepository interface {
 	Get(models.PostId) (models.Post, error)
 	Edit(models.PostId, EditedPostData) error
 	Delete(models.PostId) error
+	// Lists all posts written by account, the oldest first
+	ListByAuthor(models.AccountId) ([]models.Post, error)
+
+	// Deletes posts written by account and posts on its page.
+	DeleteByAccountId(models.AccountId) error
 }
 
 type NewPostData struct {
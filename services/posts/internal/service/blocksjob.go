@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"soa-socialnetwork/services/common/backjob"
+	"soa-socialnetwork/services/posts/internal/models"
+	"soa-socialnetwork/services/posts/internal/repo"
+	statsModels "soa-socialnetwork/services/stats/pkg/models"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const blocks_read_timeout = 500 * time.Millisecond
+
+// Job callback that applies blocks and unblocks made in accounts service
+// to the local copy of block lists. Events of the two topics are not ordered
+// relative to each other, repository resolves it by event timestamps.
+func newBlocksCallback(db repo.Database) backjob.JobCallback {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{"stats-kafka:9092"},
+		GroupTopics: []string{"block", "unblock"},
+		GroupID:     "posts-service-blocks",
+		MaxWait:     blocks_read_timeout,
+	})
+
+	// message which applying failed, it is retried on the next call
+	var pending *kafka.Message
+
+	return func(ctx context.Context) error {
+		for {
+			if pending == nil {
+				readCtx, cancel := context.WithTimeout(ctx, blocks_read_timeout)
+				msg, err := reader.FetchMessage(readCtx)
+				cancel()
+
+				if errors.Is(err, context.DeadlineExceeded) {
+					return nil
+				}
+
+				if err != nil {
+					return err
+				}
+
+				pending = &msg
+			}
+
+			var event statsModels.BlockEvent
+			err := json.Unmarshal(pending.Value, &event)
+			if err != nil {
+				log.Printf("warning: skipping malformed %s event: %v", pending.Topic, err)
+			} else {
+				err = applyBlockEvent(ctx, db, event, pending.Topic == "block")
+				if err != nil {
+					return err
+				}
+			}
+
+			err = reader.CommitMessages(ctx, *pending)
+			if err != nil {
+				return err
+			}
+
+			pending = nil
+		}
+	}
+}
+
+func applyBlockEvent(ctx context.Context, db repo.Database, event statsModels.BlockEvent, isBlocked bool) error {
+	conn, err := db.OpenConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Blocks().SetBlocked(
+		models.AccountId(event.BlockerAccountId),
+		models.AccountId(event.BlockedAccountId),
+		isBlocked,
+		event.Timestamp,
+	)
+}
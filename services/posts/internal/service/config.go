@@ -3,9 +3,11 @@ package service
 import "crypto/ed25519"
 
 type PostsServiceConfig struct {
-	DbHost       string
-	DbUser       string
-	DbPassword   string
-	DbPoolSize   int
-	JwtPublicKey ed25519.PublicKey
+	DbHost              string
+	DbUser              string
+	DbPassword          string
+	DbPoolSize          int
+	JwtPublicKey        ed25519.PublicKey
+	AccountsServiceHost string
+	AccountsServicePort int
 }
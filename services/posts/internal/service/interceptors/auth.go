@@ -2,6 +2,7 @@ package interceptors
 
 import (
 	"context"
+	"errors"
 	"soa-socialnetwork/services/accounts/pkg/soajwt"
 	"soa-socialnetwork/services/accounts/pkg/soatoken"
 	"soa-socialnetwork/services/posts/internal/models"
@@ -17,8 +18,13 @@ type AccountIdCtxKey string
 
 const AUTHOR_ACCOUNT_ID_CTX_KEY AccountIdCtxKey = "account_id"
 
-func WithAuth(verifier soajwt.Verifier) grpc.UnaryServerInterceptor {
-	validateToken := func(t authToken) validationInfo {
+type RoleCtxKey string
+
+// Set along with account id, api tokens always have user role
+const CALLER_ROLE_CTX_KEY RoleCtxKey = "role"
+
+func WithAuth(jwtVerifier soajwt.Verifier, soaVerifier soatoken.Verifier) grpc.UnaryServerInterceptor {
+	validateToken := func(t authToken, reqs authRequirements) validationInfo {
 		switch t.kind {
 		case auth_kind_unknown:
 			{
@@ -27,7 +33,7 @@ func WithAuth(verifier soajwt.Verifier) grpc.UnaryServerInterceptor {
 
 		case auth_kind_jwt:
 			{
-				token, err := verifier.Verify(string(t.value))
+				token, err := jwtVerifier.Verify(string(t.value))
 				if err != nil {
 					return validationInfo{}
 				}
@@ -35,6 +41,7 @@ func WithAuth(verifier soajwt.Verifier) grpc.UnaryServerInterceptor {
 				return validationInfo{
 					valid:     true,
 					accountId: models.AccountId(token.AccountId),
+					role:      token.GetRole(),
 				}
 			}
 
@@ -45,9 +52,20 @@ func WithAuth(verifier soajwt.Verifier) grpc.UnaryServerInterceptor {
 					return validationInfo{}
 				}
 
+				err = soaVerifier.Verify(string(t.value), soatoken.RightsRequirements{
+					Scope: reqs.scope,
+				})
+				if missingScope := (soatoken.MissingScope{}); errors.As(err, &missingScope) {
+					return validationInfo{missingScope: missingScope.Scope}
+				}
+				if err != nil {
+					return validationInfo{}
+				}
+
 				return validationInfo{
 					valid:     true,
 					accountId: models.AccountId(token.AccountId),
+					role:      soajwt.ROLE_USER,
 				}
 			}
 		}
@@ -62,13 +80,19 @@ func WithAuth(verifier soajwt.Verifier) grpc.UnaryServerInterceptor {
 		}
 
 		authToken := fetchTokenFromMetadata(md)
-		tokenInfo := validateToken(authToken)
+		tokenInfo := validateToken(authToken, getAuthRequirements(info.FullMethod))
+
+		if tokenInfo.missingScope != "" {
+			return nil, status.Error(codes.PermissionDenied, soatoken.MissingScope{Scope: tokenInfo.missingScope}.Error())
+		}
 
 		if !tokenInfo.valid {
 			return handler(ctx, req)
 		}
 
-		return handler(context.WithValue(ctx, AUTHOR_ACCOUNT_ID_CTX_KEY, tokenInfo.accountId), req)
+		ctx = context.WithValue(ctx, AUTHOR_ACCOUNT_ID_CTX_KEY, tokenInfo.accountId)
+		ctx = context.WithValue(ctx, CALLER_ROLE_CTX_KEY, tokenInfo.role)
+		return handler(ctx, req)
 	}
 }
 
@@ -102,6 +126,9 @@ type authToken struct {
 type validationInfo struct {
 	valid     bool
 	accountId models.AccountId
+	role      soajwt.Role
+	// set if token is valid but lacks scope required by method
+	missingScope soatoken.Scope
 }
 
 func fetchTokenFromMetadata(md metadata.MD) authToken {
@@ -0,0 +1,62 @@
+package interceptors
+
+import (
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	pb "soa-socialnetwork/services/posts/proto"
+)
+
+// Scopes are checked only for api tokens, jwt grants all of them
+type authRequirements struct {
+	scope soatoken.Scope
+}
+
+var methods_auth_requirements = map[string]authRequirements{
+	pb.PostsService_GetPageSettings_FullMethodName: {
+		scope: soatoken.SCOPE_POSTS_READ,
+	},
+	pb.PostsService_EditPageSettings_FullMethodName: {
+		scope: soatoken.SCOPE_POSTS_WRITE,
+	},
+	pb.PostsService_NewPost_FullMethodName: {
+		scope: soatoken.SCOPE_POSTS_WRITE,
+	},
+	pb.PostsService_GetPost_FullMethodName: {
+		scope: soatoken.SCOPE_POSTS_READ,
+	},
+	pb.PostsService_GetPosts_FullMethodName: {
+		scope: soatoken.SCOPE_POSTS_READ,
+	},
+	pb.PostsService_EditPost_FullMethodName: {
+		scope: soatoken.SCOPE_POSTS_WRITE,
+	},
+	pb.PostsService_DeletePost_FullMethodName: {
+		scope: soatoken.SCOPE_POSTS_WRITE,
+	},
+	pb.PostsService_NewComment_FullMethodName: {
+		scope: soatoken.SCOPE_COMMENTS_WRITE,
+	},
+	pb.PostsService_DeleteComment_FullMethodName: {
+		scope: soatoken.SCOPE_COMMENTS_WRITE,
+	},
+	pb.PostsService_GetComments_FullMethodName: {
+		scope: soatoken.SCOPE_COMMENTS_READ,
+	},
+	pb.PostsService_NewView_FullMethodName: {
+		scope: soatoken.SCOPE_REACTIONS_WRITE,
+	},
+	pb.PostsService_NewLike_FullMethodName: {
+		scope: soatoken.SCOPE_REACTIONS_WRITE,
+	},
+	pb.PostsService_ExportAccountData_FullMethodName: {
+		scope: soatoken.SCOPE_ACCOUNT_READ,
+	},
+}
+
+func getAuthRequirements(fullMethodName string) authRequirements {
+	reqs, ok := methods_auth_requirements[fullMethodName]
+	if !ok {
+		return authRequirements{}
+	}
+
+	return reqs
+}
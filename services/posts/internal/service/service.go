@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"soa-socialnetwork/services/accounts/pkg/soajwt"
+	"soa-socialnetwork/services/accounts/pkg/soatoken"
+	accountsPb "soa-socialnetwork/services/accounts/proto"
 	"soa-socialnetwork/services/common/backjob"
 	opt "soa-socialnetwork/services/common/option"
 	"soa-socialnetwork/services/posts/internal/models"
@@ -14,17 +17,32 @@ import (
 	statsModels "soa-socialnetwork/services/stats/pkg/models"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const JWKS_REFRESH_PERIOD = time.Minute
+const REVOCATIONS_REFRESH_PERIOD = time.Second
+
 type PostsService struct {
 	pb.UnimplementedPostsServiceServer
 
-	Db          repo.Database
-	JwtVerifier soajwt.Verifier
-
-	outboxJob backjob.TickerJob
+	Db             repo.Database
+	JwtVerifier    soajwt.RevocationVerifier
+	SoaVerifier    *soatoken.CachingVerifier
+	AccountsClient accountsPb.AccountsServiceClient
+
+	jwtKeys             *soajwt.KeySetVerifier
+	revokedSessions     *soajwt.RevocationCache
+	outboxJob           backjob.TickerJob
+	jwksRefreshJob      backjob.TickerJob
+	revocationsJob      backjob.TickerJob
+	soaInvalidationsJob backjob.TickerJob
+	unregistrationJob   backjob.TickerJob
+	blocksJob           backjob.TickerJob
 }
 
 func New(cfg PostsServiceConfig) (PostsService, error) {
@@ -39,11 +57,26 @@ func New(cfg PostsServiceConfig) (PostsService, error) {
 		return PostsService{}, err
 	}
 
-	jwtVerifier := soajwt.NewEd25519Verifier(cfg.JwtPublicKey)
+	accountsConn, err := grpc.NewClient(
+		fmt.Sprintf("%s:%d", cfg.AccountsServiceHost, cfg.AccountsServicePort),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return PostsService{}, err
+	}
+
+	accountsClient := accountsPb.NewAccountsServiceClient(accountsConn)
+	jwtKeys := soajwt.NewKeySetVerifier(cfg.JwtPublicKey)
+	revokedSessions := soajwt.NewRevocationCache()
 
 	return PostsService{
-		Db:          &db,
-		JwtVerifier: &jwtVerifier,
+		Db:             &db,
+		JwtVerifier:    soajwt.NewRevocationVerifier(jwtKeys, revokedSessions),
+		SoaVerifier:    soatoken.NewRemoteVerifier(accountsClient, soatoken.DEFAULT_REMOTE_VERIFIER_CACHE_TTL),
+		AccountsClient: accountsClient,
+
+		jwtKeys:         jwtKeys,
+		revokedSessions: revokedSessions,
 	}, nil
 }
 
@@ -51,6 +84,26 @@ func (s *PostsService) Start() {
 	outboxJobCallback := newCheckOutboxCallback(s.Db, 100)
 	s.outboxJob = backjob.NewTickerJob(3*time.Second, outboxJobCallback)
 	s.outboxJob.Run()
+
+	jwksRefreshCallback := soajwt.NewRefreshCallback(s.jwtKeys, soajwt.NewGrpcJwksFetcher(s.AccountsClient))
+	s.jwksRefreshJob = backjob.NewTickerJob(JWKS_REFRESH_PERIOD, jwksRefreshCallback)
+	s.jwksRefreshJob.Run()
+
+	revocationsCallback := soajwt.NewKafkaRevocationCallback("stats-kafka:9092", s.revokedSessions)
+	s.revocationsJob = backjob.NewTickerJob(REVOCATIONS_REFRESH_PERIOD, revocationsCallback)
+	s.revocationsJob.Run()
+
+	soaInvalidationsCallback := soatoken.NewKafkaInvalidationCallback("stats-kafka:9092", s.SoaVerifier)
+	s.soaInvalidationsJob = backjob.NewTickerJob(REVOCATIONS_REFRESH_PERIOD, soaInvalidationsCallback)
+	s.soaInvalidationsJob.Run()
+
+	unregistrationCallback := newUnregistrationCallback(s.Db)
+	s.unregistrationJob = backjob.NewTickerJob(time.Second, unregistrationCallback)
+	s.unregistrationJob.Run()
+
+	blocksCallback := newBlocksCallback(s.Db)
+	s.blocksJob = backjob.NewTickerJob(time.Second, blocksCallback)
+	s.blocksJob.Run()
 }
 
 func (s *PostsService) EditPageSettings(ctx context.Context, req *pb.EditPageSettingsRequest) (*pb.Empty, error) {
@@ -128,6 +181,11 @@ func (s *PostsService) NewPost(ctx context.Context, req *pb.NewPostRequest) (*pb
 		}
 
 		if authorId != models.AccountId(req.PageAccountId) {
+			err = checkNotBlocked(conn, pageData.AccountId, authorId)
+			if err != nil {
+				return models.Page{}, err
+			}
+
 			if !pageData.AnyoneCanPost {
 				return models.Page{}, status.Error(codes.PermissionDenied, "page owner prohibited posting")
 			}
@@ -211,7 +269,12 @@ func (s *PostsService) NewComment(ctx context.Context, req *pb.NewCommentRequest
 			return status.Error(codes.PermissionDenied, "comments prohibited")
 		}
 
-		return nil
+		post, err := conn.Posts().Get(models.PostId(req.PostId))
+		if err != nil {
+			return err
+		}
+
+		return checkNotBlocked(conn, post.AuthorAccountId, authorId)
 	}()
 
 	if commentsEnabledErr != nil {
@@ -272,17 +335,14 @@ func (s *PostsService) GetComments(ctx context.Context, req *pb.GetCommentsReque
 	}
 	defer conn.Close()
 
-	authorizedId := ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY)
-	if authorizedId == nil {
-		pageData, err := conn.Pages().GetByPostId(models.PostId(req.PostId))
-
-		if err != nil {
-			return nil, err
-		}
+	pageData, err := conn.Pages().GetByPostId(models.PostId(req.PostId))
+	if err != nil {
+		return nil, err
+	}
 
-		if !pageData.VisibleForUnauthorized {
-			return nil, status.Error(codes.PermissionDenied, "denied for unauthorized")
-		}
+	err = checkPageReadable(conn, pageData, ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY))
+	if err != nil {
+		return nil, err
 	}
 
 	commentsList, err := conn.Comments().List(models.PostId(req.PostId), repo.PagiToken(req.PageToken))
@@ -292,12 +352,7 @@ func (s *PostsService) GetComments(ctx context.Context, req *pb.GetCommentsReque
 
 	comments := make([]*pb.Comment, len(commentsList.Comments))
 	for i, comment := range commentsList.Comments {
-		comments[i] = &pb.Comment{
-			Id:              int32(comment.Id),
-			AuthorAccountId: int32(comment.AuthorId),
-			Content:         string(comment.Content),
-			ReplyCommentId:  (*int32)(comment.ReplyId.ToPointer()),
-		}
+		comments[i] = commentToProto(comment)
 	}
 
 	return &pb.GetCommentsResponse{
@@ -318,9 +373,9 @@ func (s *PostsService) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb
 		return nil, err
 	}
 
-	authorizedId := ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY)
-	if authorizedId == nil && !pageData.VisibleForUnauthorized {
-		return nil, status.Error(codes.PermissionDenied, "denied for unauthorized")
+	err = checkPageReadable(conn, pageData, ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY))
+	if err != nil {
+		return nil, err
 	}
 
 	post, err := conn.Posts().Get(models.PostId(req.PostId))
@@ -329,14 +384,7 @@ func (s *PostsService) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb
 		return nil, err
 	}
 
-	return &pb.Post{
-		Id:              int32(post.Id),
-		AuthorAccountId: int32(post.AuthorAccountId),
-		Text:            string(post.Content.Text),
-		SourcePostId:    (*int32)(post.Content.SourcePostId.ToPointer()),
-		Pinned:          post.Pinned,
-		ViewsCount:      post.ViewsCount,
-	}, nil
+	return postToProto(post), nil
 }
 
 func (s *PostsService) GetPosts(ctx context.Context, req *pb.GetPostsRequest) (*pb.GetPostsResponse, error) {
@@ -351,9 +399,9 @@ func (s *PostsService) GetPosts(ctx context.Context, req *pb.GetPostsRequest) (*
 		return nil, err
 	}
 
-	authorizedId := ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY)
-	if authorizedId == nil && !pageData.VisibleForUnauthorized {
-		return nil, status.Error(codes.PermissionDenied, "denied for unauthorized")
+	err = checkPageReadable(conn, pageData, ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY))
+	if err != nil {
+		return nil, err
 	}
 
 	postsList, err := conn.Posts().List(pageData.Id, repo.PagiToken(req.PageToken))
@@ -363,14 +411,7 @@ func (s *PostsService) GetPosts(ctx context.Context, req *pb.GetPostsRequest) (*
 
 	posts := make([]*pb.Post, len(postsList.Posts))
 	for i, post := range postsList.Posts {
-		posts[i] = &pb.Post{
-			Id:              int32(post.Id),
-			AuthorAccountId: int32(post.AuthorAccountId),
-			Text:            string(post.Content.Text),
-			SourcePostId:    (*int32)(post.Content.SourcePostId.ToPointer()),
-			Pinned:          post.Pinned,
-			ViewsCount:      post.ViewsCount,
-		}
+		posts[i] = postToProto(post)
 	}
 
 	return &pb.GetPostsResponse{
@@ -434,7 +475,7 @@ func (s *PostsService) DeletePost(ctx context.Context, req *pb.DeletePostRequest
 		return nil, err
 	}
 
-	if post.AuthorAccountId != authorizedId {
+	if post.AuthorAccountId != authorizedId && !callerRole(ctx).CanModerate() {
 		page, err := conn.Pages().GetByPageId(models.PageId(post.PageId))
 
 		if err != nil {
@@ -454,6 +495,43 @@ func (s *PostsService) DeletePost(ctx context.Context, req *pb.DeletePostRequest
 	return &pb.Empty{}, err
 }
 
+func (s *PostsService) DeleteComment(ctx context.Context, req *pb.DeleteCommentRequest) (*pb.Empty, error) {
+	authorizedIdVal := ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY)
+	if authorizedIdVal == nil {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	authorizedId := authorizedIdVal.(models.AccountId)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	comment, err := conn.Comments().Get(models.CommentId(req.CommentId))
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorId != authorizedId && !callerRole(ctx).CanModerate() {
+		post, err := conn.Posts().Get(comment.PostId)
+		if err != nil {
+			return nil, err
+		}
+
+		if post.AuthorAccountId != authorizedId {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+	}
+
+	err = conn.Comments().Delete(comment.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Empty{}, nil
+}
+
 func (s *PostsService) NewView(ctx context.Context, req *pb.NewViewRequest) (*pb.Empty, error) {
 	authorizedIdVal := ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY)
 	if authorizedIdVal == nil {
@@ -513,6 +591,18 @@ func (s *PostsService) NewLike(ctx context.Context, req *pb.NewLikeRequest) (*pb
 	}
 	defer tx.Close()
 
+	post, err := tx.Posts().Get(models.PostId(req.PostId))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = checkNotBlocked(tx, post.AuthorAccountId, authorizedId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	err = tx.Metrics().NewLike(authorizedId, models.PostId(req.PostId))
 	if err != nil {
 		tx.Rollback()
@@ -545,3 +635,113 @@ func (s *PostsService) NewLike(ctx context.Context, req *pb.NewLikeRequest) (*pb
 
 	return &pb.Empty{}, nil
 }
+
+func (s *PostsService) ExportAccountData(ctx context.Context, req *pb.Empty) (*pb.ExportAccountDataResponse, error) {
+	authorizedId := ctx.Value(interceptors.AUTHOR_ACCOUNT_ID_CTX_KEY)
+	if authorizedId == nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	accountId := authorizedId.(models.AccountId)
+
+	conn, err := s.Db.OpenConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	posts, err := conn.Posts().ListByAuthor(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := conn.Comments().ListByAuthor(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	likes, err := conn.Metrics().ListLikesByAccountId(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ExportAccountDataResponse{
+		Posts:    make([]*pb.Post, len(posts)),
+		Comments: make([]*pb.Comment, len(comments)),
+		Likes:    make([]*pb.Like, len(likes)),
+	}
+	for i, post := range posts {
+		resp.Posts[i] = postToProto(post)
+	}
+	for i, comment := range comments {
+		resp.Comments[i] = commentToProto(comment)
+	}
+	for i, like := range likes {
+		resp.Likes[i] = &pb.Like{
+			PostId:    int32(like.PostId),
+			CreatedAt: timestamppb.New(like.CreatedAt),
+		}
+	}
+
+	return resp, nil
+}
+
+func postToProto(post models.Post) *pb.Post {
+	return &pb.Post{
+		Id:              int32(post.Id),
+		AuthorAccountId: int32(post.AuthorAccountId),
+		Text:            string(post.Content.Text),
+		SourcePostId:    (*int32)(post.Content.SourcePostId.ToPointer()),
+		Pinned:          post.Pinned,
+		ViewsCount:      post.ViewsCount,
+		CreatedAt:       timestamppb.New(post.CreatedAt),
+	}
+}
+
+func commentToProto(comment models.Comment) *pb.Comment {
+	return &pb.Comment{
+		Id:              int32(comment.Id),
+		AuthorAccountId: int32(comment.AuthorId),
+		Content:         string(comment.Content),
+		ReplyCommentId:  (*int32)(comment.ReplyId.ToPointer()),
+		PostId:          int32(comment.PostId),
+		CreatedAt:       timestamppb.New(comment.CreatedAt),
+	}
+}
+
+// User role if the caller is not authenticated
+func callerRole(ctx context.Context) soajwt.Role {
+	role, ok := ctx.Value(interceptors.CALLER_ROLE_CTX_KEY).(soajwt.Role)
+	if !ok {
+		return soajwt.ROLE_USER
+	}
+
+	return role
+}
+
+// Denies unauthorized access to pages hidden from unauthorized
+// and access of accounts blocked by the page owner.
+// authorizedId is nil for unauthorized requests.
+func checkPageReadable(provider repo.RepositoryProvider, page models.Page, authorizedId any) error {
+	if authorizedId == nil {
+		if !page.VisibleForUnauthorized {
+			return status.Error(codes.PermissionDenied, "denied for unauthorized")
+		}
+
+		return nil
+	}
+
+	return checkNotBlocked(provider, page.AccountId, authorizedId.(models.AccountId))
+}
+
+func checkNotBlocked(provider repo.RepositoryProvider, blocker models.AccountId, accountId models.AccountId) error {
+	isBlocked, err := provider.Blocks().IsBlocked(blocker, accountId)
+	if err != nil {
+		return err
+	}
+
+	if isBlocked {
+		return status.Error(codes.PermissionDenied, "blocked by owner")
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"soa-socialnetwork/services/common/backjob"
+	"soa-socialnetwork/services/posts/internal/models"
+	"soa-socialnetwork/services/posts/internal/repo"
+	statsModels "soa-socialnetwork/services/stats/pkg/models"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const unregistration_read_timeout = 500 * time.Millisecond
+
+// Job callback that erases page, posts, comments, likes and blocks of unregistered
+// accounts. Message is committed only after its account data is erased,
+// erasure is idempotent, so redelivered events are harmless.
+func newUnregistrationCallback(db repo.Database) backjob.JobCallback {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{"stats-kafka:9092"},
+		Topic:   "unregistration",
+		GroupID: "posts-service-unregistration",
+		MaxWait: unregistration_read_timeout,
+	})
+
+	// message which erasure failed, it is retried on the next call
+	var pending *kafka.Message
+
+	return func(ctx context.Context) error {
+		for {
+			if pending == nil {
+				readCtx, cancel := context.WithTimeout(ctx, unregistration_read_timeout)
+				msg, err := reader.FetchMessage(readCtx)
+				cancel()
+
+				if errors.Is(err, context.DeadlineExceeded) {
+					return nil
+				}
+
+				if err != nil {
+					return err
+				}
+
+				pending = &msg
+			}
+
+			var event statsModels.UnregistrationEvent
+			err := json.Unmarshal(pending.Value, &event)
+			if err != nil {
+				log.Printf("warning: skipping malformed unregistration event: %v", err)
+			} else {
+				err = eraseAccountData(ctx, db, models.AccountId(event.AccountId))
+				if err != nil {
+					return err
+				}
+			}
+
+			err = reader.CommitMessages(ctx, *pending)
+			if err != nil {
+				return err
+			}
+
+			pending = nil
+		}
+	}
+}
+
+func eraseAccountData(ctx context.Context, db repo.Database, accountId models.AccountId) error {
+	tx, err := db.BeginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Close()
+
+	// comments and likes are looked up by posts and page, so they go first
+	err = tx.Comments().DeleteByAccountId(accountId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Metrics().DeleteLikesByAccountId(accountId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Posts().DeleteByAccountId(accountId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Pages().DeleteByAccountId(accountId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Blocks().DeleteByAccountId(accountId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/posts/internal/models"
+	"time"
+)
+
+type blocksRepo struct {
+	ctx   context.Context
+	scope pgxScope
+}
+
+func (r blocksRepo) SetBlocked(blocker models.AccountId, blocked models.AccountId, isBlocked bool, changedAt time.Time) error {
+	sql := `
+	INSERT INTO blocks(blocker_account_id, blocked_account_id, is_blocked, changed_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT(blocker_account_id, blocked_account_id) DO UPDATE
+	SET
+		is_blocked = EXCLUDED.is_blocked,
+		changed_at = EXCLUDED.changed_at
+	WHERE blocks.changed_at < EXCLUDED.changed_at;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, blocker, blocked, isBlocked, changedAt)
+	return err
+}
+
+func (r blocksRepo) IsBlocked(blocker models.AccountId, blocked models.AccountId) (bool, error) {
+	sql := `
+	SELECT EXISTS(
+		SELECT 1
+		FROM blocks
+		WHERE blocker_account_id = $1 AND blocked_account_id = $2 AND is_blocked
+	);
+	`
+
+	var isBlocked bool
+	err := r.scope.QueryRow(r.ctx, sql, blocker, blocked).Scan(&isBlocked)
+	if err != nil {
+		return false, err
+	}
+
+	return isBlocked, nil
+}
+
+func (r blocksRepo) DeleteByAccountId(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM blocks
+	WHERE blocker_account_id = $1 OR blocked_account_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
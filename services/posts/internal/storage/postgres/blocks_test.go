@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/posts/internal/models"
+	"time"
+)
+
+func (s *testSuite) TestBlocks() {
+	ctx := context.Background()
+	blocker := models.AccountId(301)
+	blocked := models.AccountId(302)
+
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	isBlocked := func(blocker models.AccountId, blocked models.AccountId) bool {
+		isBlocked, err := conn.Blocks().IsBlocked(blocker, blocked)
+		s.Require().NoError(err)
+		return isBlocked
+	}
+
+	s.Assert().False(isBlocked(blocker, blocked))
+
+	blockedAt := time.Now()
+	err = conn.Blocks().SetBlocked(blocker, blocked, true, blockedAt)
+	s.Require().NoError(err)
+	s.Assert().True(isBlocked(blocker, blocked))
+	s.Assert().False(isBlocked(blocked, blocker))
+
+	err = conn.Blocks().SetBlocked(blocker, blocked, false, blockedAt.Add(time.Second))
+	s.Require().NoError(err)
+	s.Assert().False(isBlocked(blocker, blocked))
+
+	// block delivered after the later unblock is discarded
+	err = conn.Blocks().SetBlocked(blocker, blocked, true, blockedAt)
+	s.Require().NoError(err)
+	s.Assert().False(isBlocked(blocker, blocked))
+
+	err = conn.Blocks().SetBlocked(blocker, blocked, true, blockedAt.Add(2*time.Second))
+	s.Require().NoError(err)
+	s.Assert().True(isBlocked(blocker, blocked))
+
+	err = conn.Blocks().DeleteByAccountId(blocked)
+	s.Require().NoError(err)
+	s.Assert().False(isBlocked(blocker, blocked))
+}
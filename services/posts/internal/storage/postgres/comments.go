@@ -2,13 +2,17 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	opt "soa-socialnetwork/services/common/option"
 	"soa-socialnetwork/services/posts/internal/models"
 	"soa-socialnetwork/services/posts/internal/repo"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type commentsRepo struct {
@@ -35,6 +39,33 @@ func (r commentsRepo) New(postId models.PostId, data repo.NewCommentData) (model
 	return id, nil
 }
 
+func (r commentsRepo) Get(commentId models.CommentId) (models.Comment, error) {
+	sql := `
+	SELECT post_id, author_account_id, text_content, reply_comment_id, created_at
+	FROM comments
+	WHERE id = $1;
+	`
+
+	var comment models.Comment
+	var pgReplyCommentId pgtype.Int4
+
+	row := r.scope.QueryRow(r.ctx, sql, commentId)
+	err := row.Scan(&comment.PostId, &comment.AuthorId, &comment.Content, &pgReplyCommentId, &comment.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Comment{}, status.Error(codes.NotFound, "comment not found")
+	}
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	comment.Id = commentId
+	if pgReplyCommentId.Valid {
+		comment.ReplyId = opt.Some(models.CommentId(pgReplyCommentId.Int32))
+	}
+
+	return comment, nil
+}
+
 const COMMENTS_PAGE_SIZE = 10
 
 type commentsPagiToken struct {
@@ -115,3 +146,76 @@ func (r commentsRepo) List(postId models.PostId, encodedPagiToken repo.PagiToken
 		NextPagiToken: nextPagiToken,
 	}, nil
 }
+
+func (r commentsRepo) ListByAuthor(accountId models.AccountId) ([]models.Comment, error) {
+	sql := `
+	SELECT id, post_id, text_content, reply_comment_id, created_at
+	FROM comments
+	WHERE author_account_id = $1
+	ORDER BY id;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var pgReplyCommentId pgtype.Int4
+		var comment models.Comment
+
+		err := rows.Scan(&comment.Id, &comment.PostId, &comment.Content, &pgReplyCommentId, &comment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		comment.AuthorId = accountId
+		if pgReplyCommentId.Valid {
+			comment.ReplyId = opt.Some(models.CommentId(pgReplyCommentId.Int32))
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+func (r commentsRepo) Delete(commentId models.CommentId) error {
+	sql := `
+	WITH affected_rows AS (
+		DELETE FROM comments
+		WHERE id = $1
+		RETURNING 1
+	)
+	SELECT count(*) FROM affected_rows;
+	`
+
+	row := r.scope.QueryRow(r.ctx, sql, commentId)
+	var countAffected int
+	if err := row.Scan(&countAffected); err != nil {
+		return err
+	}
+
+	if countAffected == 0 {
+		return status.Error(codes.NotFound, "comment not found")
+	}
+
+	return nil
+}
+
+func (r commentsRepo) DeleteByAccountId(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM comments
+	WHERE author_account_id = $1 OR post_id IN (
+		SELECT p.id
+		FROM posts p
+		LEFT JOIN pages pg ON pg.id = p.page_id
+		WHERE p.author_account_id = $1 OR pg.account_id = $1
+	);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/posts/internal/models"
+	"soa-socialnetwork/services/posts/internal/repo"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *testSuite) TestGetAndDeleteComment() {
+	ctx := context.Background()
+	accountId := models.AccountId(401)
+
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	page, err := conn.Pages().GetByAccountId(accountId)
+	s.Require().NoError(err)
+
+	postId, err := conn.Posts().New(page.Id, repo.NewPostData{
+		AuthorId: accountId,
+		Content: models.PostContent{
+			Text: "post",
+		},
+	})
+	s.Require().NoError(err)
+
+	commentId, err := conn.Comments().New(postId, repo.NewCommentData{
+		AuthorId: accountId,
+		Content:  "comment",
+	})
+	s.Require().NoError(err)
+
+	comment, err := conn.Comments().Get(commentId)
+	s.Require().NoError(err)
+	s.Assert().Equal(postId, comment.PostId)
+	s.Assert().Equal(accountId, comment.AuthorId)
+	s.Assert().Equal(models.Text("comment"), comment.Content)
+
+	s.Require().NoError(conn.Comments().Delete(commentId))
+
+	_, err = conn.Comments().Get(commentId)
+	s.Assert().Equal(codes.NotFound, status.Code(err))
+
+	err = conn.Comments().Delete(commentId)
+	s.Assert().Equal(codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/posts/internal/models"
+	"soa-socialnetwork/services/posts/internal/repo"
+)
+
+func (s *testSuite) TestDeleteByAccountId() {
+	ctx := context.Background()
+	erasedId := models.AccountId(201)
+	otherId := models.AccountId(202)
+
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	erasedPage, err := conn.Pages().GetByAccountId(erasedId)
+	s.Require().NoError(err)
+
+	otherPage, err := conn.Pages().GetByAccountId(otherId)
+	s.Require().NoError(err)
+
+	newPost := func(pageId models.PageId, authorId models.AccountId) models.PostId {
+		postId, err := conn.Posts().New(pageId, repo.NewPostData{
+			AuthorId: authorId,
+			Content: models.PostContent{
+				Text: "test post",
+			},
+		})
+		s.Require().NoError(err)
+		return postId
+	}
+
+	newComment := func(postId models.PostId, authorId models.AccountId) {
+		_, err := conn.Comments().New(postId, repo.NewCommentData{
+			AuthorId: authorId,
+			Content:  "test comment",
+		})
+		s.Require().NoError(err)
+	}
+
+	postOnErasedPage := newPost(erasedPage.Id, otherId)
+	postByErased := newPost(otherPage.Id, erasedId)
+	otherPost := newPost(otherPage.Id, otherId)
+
+	newComment(postOnErasedPage, otherId)
+	newComment(postByErased, otherId)
+	newComment(otherPost, erasedId)
+	newComment(otherPost, otherId)
+
+	s.Require().NoError(conn.Metrics().NewLike(otherId, postOnErasedPage))
+	s.Require().NoError(conn.Metrics().NewLike(otherId, postByErased))
+	s.Require().NoError(conn.Metrics().NewLike(erasedId, otherPost))
+	s.Require().NoError(conn.Metrics().NewLike(otherId, otherPost))
+
+	// second run checks that erasure is idempotent
+	for range 2 {
+		s.Require().NoError(conn.Comments().DeleteByAccountId(erasedId))
+		s.Require().NoError(conn.Metrics().DeleteLikesByAccountId(erasedId))
+		s.Require().NoError(conn.Posts().DeleteByAccountId(erasedId))
+		s.Require().NoError(conn.Pages().DeleteByAccountId(erasedId))
+	}
+
+	_, err = conn.Posts().Get(postOnErasedPage)
+	s.Require().Error(err)
+
+	_, err = conn.Posts().Get(postByErased)
+	s.Require().Error(err)
+
+	_, err = conn.Posts().Get(otherPost)
+	s.Require().NoError(err)
+
+	comments, err := conn.Comments().List(otherPost, "")
+	s.Require().NoError(err)
+	s.Require().Len(comments.Comments, 1)
+	s.Assert().Equal(otherId, comments.Comments[0].AuthorId)
+
+	countRows := func(sql string) int {
+		var cnt int
+		err := s.db.globalConn.QueryRow(ctx, sql).Scan(&cnt)
+		s.Require().NoError(err)
+		return cnt
+	}
+
+	s.Assert().Equal(0, countRows(`SELECT count(*) FROM pages WHERE account_id = 201;`))
+	s.Assert().Equal(1, countRows(`SELECT count(*) FROM posts;`))
+	s.Assert().Equal(1, countRows(`SELECT count(*) FROM comments;`))
+	s.Assert().Equal(1, countRows(`SELECT count(*) FROM likes;`))
+}
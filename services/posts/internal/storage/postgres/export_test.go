@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"soa-socialnetwork/services/posts/internal/models"
+	"soa-socialnetwork/services/posts/internal/repo"
+)
+
+func (s *testSuite) TestListByAuthor() {
+	ctx := context.Background()
+	exportedId := models.AccountId(301)
+	otherId := models.AccountId(302)
+
+	conn, err := s.db.OpenConnection(ctx)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	otherPage, err := conn.Pages().GetByAccountId(otherId)
+	s.Require().NoError(err)
+
+	newPost := func(authorId models.AccountId, text models.Text) models.PostId {
+		postId, err := conn.Posts().New(otherPage.Id, repo.NewPostData{
+			AuthorId: authorId,
+			Content: models.PostContent{
+				Text: text,
+			},
+		})
+		s.Require().NoError(err)
+		return postId
+	}
+
+	firstPost := newPost(exportedId, "first")
+	secondPost := newPost(exportedId, "second")
+	otherPost := newPost(otherId, "other")
+
+	_, err = conn.Comments().New(otherPost, repo.NewCommentData{
+		AuthorId: exportedId,
+		Content:  "exported comment",
+	})
+	s.Require().NoError(err)
+
+	_, err = conn.Comments().New(firstPost, repo.NewCommentData{
+		AuthorId: otherId,
+		Content:  "other comment",
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(conn.Metrics().NewLike(exportedId, otherPost))
+	s.Require().NoError(conn.Metrics().NewLike(otherId, firstPost))
+
+	posts, err := conn.Posts().ListByAuthor(exportedId)
+	s.Require().NoError(err)
+	s.Require().Len(posts, 2)
+	s.Assert().Equal(firstPost, posts[0].Id)
+	s.Assert().Equal(models.Text("first"), posts[0].Content.Text)
+	s.Assert().Equal(secondPost, posts[1].Id)
+	s.Assert().Equal(exportedId, posts[1].AuthorAccountId)
+
+	comments, err := conn.Comments().ListByAuthor(exportedId)
+	s.Require().NoError(err)
+	s.Require().Len(comments, 1)
+	s.Assert().Equal(otherPost, comments[0].PostId)
+	s.Assert().Equal(models.Text("exported comment"), comments[0].Content)
+
+	likes, err := conn.Metrics().ListLikesByAccountId(exportedId)
+	s.Require().NoError(err)
+	s.Require().Len(likes, 1)
+	s.Assert().Equal(otherPost, likes[0].PostId)
+
+	posts, err = conn.Posts().ListByAuthor(models.AccountId(303))
+	s.Require().NoError(err)
+	s.Assert().Empty(posts)
+}
@@ -56,3 +56,46 @@ func (r metricsRepo) NewLike(accountId models.AccountId, postId models.PostId) e
 
 	return nil
 }
+
+func (r metricsRepo) ListLikesByAccountId(accountId models.AccountId) ([]models.Like, error) {
+	sql := `
+	SELECT post_id, created_at
+	FROM likes
+	WHERE author_account_id = $1
+	ORDER BY created_at, post_id;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var likes []models.Like
+	for rows.Next() {
+		like := models.Like{AccountId: accountId}
+		err := rows.Scan(&like.PostId, &like.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		likes = append(likes, like)
+	}
+
+	return likes, rows.Err()
+}
+
+func (r metricsRepo) DeleteLikesByAccountId(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM likes
+	WHERE author_account_id = $1 OR post_id IN (
+		SELECT p.id
+		FROM posts p
+		LEFT JOIN pages pg ON pg.id = p.page_id
+		WHERE p.author_account_id = $1 OR pg.account_id = $1
+	);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
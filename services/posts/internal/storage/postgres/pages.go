@@ -107,3 +107,13 @@ func (r pagesRepo) Edit(pageId models.PageId, edited repo.EditedPageSettings) er
 
 	return nil
 }
+
+func (r pagesRepo) DeleteByAccountId(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM pages
+	WHERE account_id = $1;
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
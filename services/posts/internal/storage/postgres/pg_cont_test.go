@@ -90,6 +90,7 @@ func (d *testPgContainerDatabase) cleanup(t *testing.T) {
 		TRUNCATE TABLE posts;
 		TRUNCATE TABLE comments;
 		TRUNCATE TABLE likes;
+		TRUNCATE TABLE blocks;
 		TRUNCATE TABLE outbox;
 	`)
 
@@ -161,6 +162,13 @@ func (p *testRepoProvider) Metrics() repo.MetricsRepository {
 	}
 }
 
+func (p *testRepoProvider) Blocks() repo.BlocksRepository {
+	return blocksRepo{
+		ctx:   context.Background(),
+		scope: p.scope,
+	}
+}
+
 func (p *testRepoProvider) Outbox() repo.OutboxRepository {
 	return outboxRepo{
 		ctx:   context.Background(),
@@ -209,3 +209,48 @@ func (r postsRepo) Delete(postId models.PostId) error {
 
 	return nil
 }
+
+func (r postsRepo) ListByAuthor(accountId models.AccountId) ([]models.Post, error) {
+	sql := `
+	SELECT id, page_id, text_content, source_post_id, pinned, views_count, created_at
+	FROM posts
+	WHERE author_account_id = $1
+	ORDER BY created_at, id;
+	`
+
+	rows, err := r.scope.Query(r.ctx, sql, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var post models.Post
+		var pgSourcePostId pgtype.Int4
+		err := rows.Scan(&post.Id, &post.PageId, &post.Content.Text, &pgSourcePostId, &post.Pinned, &post.ViewsCount, &post.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		post.AuthorAccountId = accountId
+		post.Content.SourcePostId = opt.Option[models.PostId]{Value: models.PostId(pgSourcePostId.Int32), HasValue: pgSourcePostId.Valid}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+func (r postsRepo) DeleteByAccountId(accountId models.AccountId) error {
+	sql := `
+	DELETE FROM posts
+	WHERE author_account_id = $1 OR page_id IN (
+		SELECT id
+		FROM pages
+		WHERE account_id = $1
+	);
+	`
+
+	_, err := r.scope.Exec(r.ctx, sql, accountId)
+	return err
+}
@@ -34,6 +34,12 @@ func (p *repoProvider) Metrics() repo.MetricsRepository {
 		scope: p.scope,
 	}
 }
+func (p *repoProvider) Blocks() repo.BlocksRepository {
+	return blocksRepo{
+		ctx:   p.ctx,
+		scope: p.scope,
+	}
+}
 func (p *repoProvider) Outbox() repo.OutboxRepository {
 	return outboxRepo{
 		ctx:   p.ctx,
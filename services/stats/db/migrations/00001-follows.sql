@@ -0,0 +1,10 @@
+-- sign is 1 for follow and -1 for unfollow, so sum(sign) is the follow balance
+CREATE TABLE IF NOT EXISTS follows(
+    follower_account_id Int32,
+    followee_account_id Int32,
+    sign Int8,
+    event_time DateTime
+)
+ENGINE = MergeTree
+ORDER BY (followee_account_id, event_time)
+PARTITION BY toYYYYMM(event_time);
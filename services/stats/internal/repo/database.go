@@ -8,5 +8,7 @@ type Database interface {
 	PostsComments(context.Context) PostsCommentsRepo
 	Registrations(context.Context) RegistrationsRepo
 	Posts(context.Context) PostsRepo
+	Follows(context.Context) FollowsRepo
 	Aggregation(context.Context) AggregationRepo
+	Erasure(context.Context) ErasureRepo
 }
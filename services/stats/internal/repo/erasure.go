@@ -0,0 +1,11 @@
+package repo
+
+import "soa-socialnetwork/services/stats/pkg/models"
+
+type ErasureRepo interface {
+	// Removes registrations, follows and posts of accounts together with
+	// all events on these posts. Views, likes and comments left by accounts on other
+	// posts are kept anonymized, so aggregated metrics of other posts and
+	// users stay consistent.
+	EraseAccounts(...models.AccountId) error
+}
@@ -0,0 +1,8 @@
+package repo
+
+import "soa-socialnetwork/services/stats/pkg/models"
+
+type FollowsRepo interface {
+	PutFollows(...models.FollowEvent) error
+	PutUnfollows(...models.FollowEvent) error
+}
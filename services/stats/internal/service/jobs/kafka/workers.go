@@ -100,6 +100,63 @@ func NewWorkers(connCfg kafka.ConnectionConfig, db repo.Database) (Workers, erro
 	}
 	registerWorker(&registration)
 
+	unregistration, err := newTopicWorker(
+		connCfg,
+		kafka.ConsumerConfig{
+			Topic:   "unregistration",
+			GroupId: "stats-service-unregistration",
+		},
+		func(ctx context.Context, batch messageBatch[models.UnregistrationEvent]) error {
+			accountIds := make([]models.AccountId, len(batch))
+			for i := range batch {
+				accountIds[i] = batch[i].Value.AccountId
+			}
+			return db.Erasure(ctx).EraseAccounts(accountIds...)
+		},
+	)
+	if err != nil {
+		return Workers{}, err
+	}
+	registerWorker(&unregistration)
+
+	follow, err := newTopicWorker(
+		connCfg,
+		kafka.ConsumerConfig{
+			Topic:   "follow",
+			GroupId: "stats-service-follow",
+		},
+		func(ctx context.Context, batch messageBatch[models.FollowEvent]) error {
+			events := make([]models.FollowEvent, len(batch))
+			for i := range batch {
+				events[i] = batch[i].Value
+			}
+			return db.Follows(ctx).PutFollows(events...)
+		},
+	)
+	if err != nil {
+		return Workers{}, err
+	}
+	registerWorker(&follow)
+
+	unfollow, err := newTopicWorker(
+		connCfg,
+		kafka.ConsumerConfig{
+			Topic:   "unfollow",
+			GroupId: "stats-service-unfollow",
+		},
+		func(ctx context.Context, batch messageBatch[models.FollowEvent]) error {
+			events := make([]models.FollowEvent, len(batch))
+			for i := range batch {
+				events[i] = batch[i].Value
+			}
+			return db.Follows(ctx).PutUnfollows(events...)
+		},
+	)
+	if err != nil {
+		return Workers{}, err
+	}
+	registerWorker(&unfollow)
+
 	posts, err := newTopicWorker(
 		connCfg,
 		kafka.ConsumerConfig{
@@ -104,6 +104,14 @@ func (d *clickhouseTestDb) Posts() repo.PostsRepo {
 	return d.underlyingDb.Posts(context.Background())
 }
 
+func (d *clickhouseTestDb) Follows() repo.FollowsRepo {
+	return d.underlyingDb.Follows(context.Background())
+}
+
 func (d *clickhouseTestDb) Aggregation() repo.AggregationRepo {
 	return d.underlyingDb.Aggregation(context.Background())
 }
+
+func (d *clickhouseTestDb) Erasure() repo.ErasureRepo {
+	return d.underlyingDb.Erasure(context.Background())
+}
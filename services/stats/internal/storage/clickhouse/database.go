@@ -84,9 +84,23 @@ func (d *Database) Posts(ctx context.Context) repo.PostsRepo {
 	}
 }
 
+func (d *Database) Follows(ctx context.Context) repo.FollowsRepo {
+	return &followsRepo{
+		ctx:  ctx,
+		conn: d.connection,
+	}
+}
+
 func (d *Database) Aggregation(ctx context.Context) repo.AggregationRepo {
 	return &aggregationRepo{
 		ctx:  ctx,
 		conn: d.connection,
 	}
 }
+
+func (d *Database) Erasure(ctx context.Context) repo.ErasureRepo {
+	return &erasureRepo{
+		ctx:  ctx,
+		conn: d.connection,
+	}
+}
@@ -0,0 +1,55 @@
+package clickhouse
+
+import (
+	"context"
+	"soa-socialnetwork/services/stats/pkg/models"
+
+	ch "github.com/ClickHouse/clickhouse-go/v2"
+	chDriver "github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+type erasureRepo struct {
+	ctx  context.Context
+	conn chDriver.Conn
+}
+
+func (r *erasureRepo) EraseAccounts(accountIds ...models.AccountId) error {
+	if len(accountIds) == 0 {
+		return nil
+	}
+
+	ids := make([]int32, len(accountIds))
+	for i, id := range accountIds {
+		ids[i] = int32(id)
+	}
+
+	// mutations are asynchronous by default, but posts of accounts
+	// must be still present while events on them are being deleted
+	ctx := ch.Context(r.ctx, ch.WithSettings(ch.Settings{
+		"mutations_sync": 2,
+	}))
+
+	mutations := []string{
+		`ALTER TABLE agg_post_metrics DELETE WHERE post_id IN (SELECT post_id FROM posts WHERE has(?, author_id));`,
+		`ALTER TABLE posts_views DELETE WHERE post_id IN (SELECT post_id FROM posts WHERE has(?, author_id));`,
+		`ALTER TABLE posts_likes DELETE WHERE post_id IN (SELECT post_id FROM posts WHERE has(?, author_id));`,
+		`ALTER TABLE posts_comments DELETE WHERE post_id IN (SELECT post_id FROM posts WHERE has(?, author_id));`,
+		`ALTER TABLE posts_views UPDATE viewer_account_id = 0 WHERE has(?, viewer_account_id);`,
+		`ALTER TABLE posts_likes UPDATE liker_account_id = 0 WHERE has(?, liker_account_id);`,
+		`ALTER TABLE posts_comments UPDATE author_account_id = 0 WHERE has(?, author_account_id);`,
+		`ALTER TABLE agg_user_metrics DELETE WHERE has(?, account_id);`,
+		`ALTER TABLE posts DELETE WHERE has(?, author_id);`,
+		`ALTER TABLE registrations DELETE WHERE has(?, account_id);`,
+		`ALTER TABLE follows DELETE WHERE has(?, follower_account_id);`,
+		`ALTER TABLE follows DELETE WHERE has(?, followee_account_id);`,
+	}
+
+	for _, sql := range mutations {
+		err := r.conn.Exec(ctx, sql, ids)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
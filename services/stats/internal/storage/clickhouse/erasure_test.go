@@ -0,0 +1,83 @@
+package clickhouse
+
+import (
+	"context"
+	"soa-socialnetwork/services/stats/pkg/models"
+	"time"
+)
+
+func (s *testSuite) TestEraseAccounts() {
+	erasedId := models.AccountId(1)
+	otherId := models.AccountId(2)
+
+	for _, id := range []models.AccountId{erasedId, otherId} {
+		err := s.db.Registrations().Put(models.RegistrationEvent{
+			AccountId: id,
+			ProfileId: "profile",
+			Timestamp: time.Now(),
+		})
+		s.Require().NoError(err, "cannot put registration")
+	}
+
+	erasedPost := models.PostId(10)
+	otherPost := models.PostId(20)
+
+	err := s.db.Posts().Put(
+		models.PostEvent{PostId: erasedPost, AuthorId: erasedId, Timestamp: time.Now()},
+		models.PostEvent{PostId: otherPost, AuthorId: otherId, Timestamp: time.Now()},
+	)
+	s.Require().NoError(err, "cannot put posts")
+
+	err = s.db.PostsViews().Put(
+		models.PostViewEvent{PostId: erasedPost, ViewerAccountId: otherId, Timestamp: time.Now()},
+		models.PostViewEvent{PostId: otherPost, ViewerAccountId: erasedId, Timestamp: time.Now()},
+	)
+	s.Require().NoError(err, "cannot put views")
+
+	err = s.db.PostsLikes().Put(
+		models.PostLikeEvent{PostId: erasedPost, LikerAccountId: otherId, Timestamp: time.Now()},
+		models.PostLikeEvent{PostId: otherPost, LikerAccountId: erasedId, Timestamp: time.Now()},
+	)
+	s.Require().NoError(err, "cannot put likes")
+
+	err = s.db.PostsComments().Put(
+		models.PostCommentEvent{CommentId: 1, PostId: erasedPost, AuthorAccountId: otherId, Timestamp: time.Now()},
+		models.PostCommentEvent{CommentId: 2, PostId: otherPost, AuthorAccountId: erasedId, Timestamp: time.Now()},
+	)
+	s.Require().NoError(err, "cannot put comments")
+
+	// second run checks that erasure is idempotent
+	for range 2 {
+		err = s.db.Erasure().EraseAccounts(erasedId)
+		s.Require().NoError(err, "cannot erase account")
+	}
+
+	viewCount, err := s.db.PostsViews().GetCountForPost(erasedPost)
+	s.Require().NoError(err)
+	s.Assert().EqualValues(0, viewCount)
+
+	likeCount, err := s.db.PostsLikes().GetCountForPost(otherPost)
+	s.Require().NoError(err)
+	s.Assert().EqualValues(1, likeCount)
+
+	countRows := func(sql string) uint64 {
+		var cnt uint64
+		err := s.db.underlyingDb.connection.QueryRow(context.Background(), sql).Scan(&cnt)
+		s.Require().NoError(err)
+		return cnt
+	}
+
+	s.Assert().EqualValues(0, countRows(`SELECT count() FROM registrations WHERE account_id = 1;`))
+	s.Assert().EqualValues(0, countRows(`SELECT count() FROM posts WHERE author_id = 1;`))
+	s.Assert().EqualValues(0, countRows(`SELECT count() FROM posts_views WHERE viewer_account_id = 1;`))
+	s.Assert().EqualValues(0, countRows(`SELECT count() FROM posts_likes WHERE liker_account_id = 1 OR post_id = 10;`))
+	s.Assert().EqualValues(0, countRows(`SELECT count() FROM posts_comments WHERE author_account_id = 1 OR post_id = 10;`))
+	s.Assert().EqualValues(0, countRows(`SELECT count() FROM agg_post_metrics WHERE post_id = 10;`))
+	s.Assert().EqualValues(0, countRows(`SELECT count() FROM agg_user_metrics WHERE account_id = 1;`))
+
+	top10Users, err := s.db.Aggregation().GetTop10UsersByMetric(models.METRIC_VIEW_COUNT)
+	s.Require().NoError(err)
+	s.Require().Len(top10Users, 1)
+	s.Assert().EqualValues(otherId, top10Users[0].AccountId)
+	s.Assert().EqualValues(1, top10Users[0].MetricValue)
+}
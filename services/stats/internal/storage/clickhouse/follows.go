@@ -0,0 +1,42 @@
+package clickhouse
+
+import (
+	"context"
+	"soa-socialnetwork/services/stats/pkg/models"
+
+	chDriver "github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+type followsRepo struct {
+	ctx  context.Context
+	conn chDriver.Conn
+}
+
+func (r *followsRepo) PutFollows(events ...models.FollowEvent) error {
+	return r.put(1, events)
+}
+
+func (r *followsRepo) PutUnfollows(events ...models.FollowEvent) error {
+	return r.put(-1, events)
+}
+
+func (r *followsRepo) put(sign int8, events []models.FollowEvent) error {
+	sql := `
+	INSERT INTO follows(follower_account_id, followee_account_id, sign, event_time)
+	`
+
+	batch, err := r.conn.PrepareBatch(r.ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer batch.Close()
+
+	for _, event := range events {
+		err := batch.Append(event.FollowerAccountId, event.FolloweeAccountId, sign, event.Timestamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	return batch.Send()
+}
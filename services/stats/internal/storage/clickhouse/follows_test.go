@@ -0,0 +1,28 @@
+package clickhouse
+
+import (
+	"context"
+	"soa-socialnetwork/services/stats/pkg/models"
+	"time"
+)
+
+func (s *testSuite) TestFollowsSimple() {
+	event := models.FollowEvent{
+		FollowerAccountId: 1,
+		FolloweeAccountId: 2,
+		Timestamp:         time.Now(),
+	}
+
+	err := s.db.Follows().PutFollows(event, event)
+	s.Require().NoError(err)
+
+	err = s.db.Follows().PutUnfollows(event)
+	s.Require().NoError(err)
+
+	var balance int64
+	err = s.db.underlyingDb.connection.QueryRow(context.Background(), `
+	SELECT sum(sign) FROM follows WHERE followee_account_id = 2;
+	`).Scan(&balance)
+	s.Require().NoError(err)
+	s.Assert().EqualValues(1, balance)
+}
@@ -27,8 +27,27 @@ type RegistrationEvent struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+type UnregistrationEvent struct {
+	AccountId AccountId `json:"account_id"`
+	ProfileId string    `json:"profile_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 type PostEvent struct {
 	PostId    PostId    `json:"post_id"`
 	AuthorId  AccountId `json:"author_id"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+type FollowEvent struct {
+	FollowerAccountId AccountId `json:"follower_account_id"`
+	FolloweeAccountId AccountId `json:"followee_account_id"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// Emitted on both block and unblock, consumed by posts service
+type BlockEvent struct {
+	BlockerAccountId AccountId `json:"blocker_account_id"`
+	BlockedAccountId AccountId `json:"blocked_account_id"`
+	Timestamp        time.Time `json:"timestamp"`
+}
@@ -1,8 +1,21 @@
 package e2e
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"soa-socialnetwork/services/accounts/pkg/totp"
+	"strings"
 	"testing"
 	"time"
 
@@ -55,6 +68,334 @@ func createApiTokenOk(t *testing.T, createTokenRequest map[string]any) string {
 	return responseBodyToMap(t, resp)["token"].(string)
 }
 
+func tryRefreshToken(t *testing.T, refreshToken string) *http.Response {
+	return makeRequest(t, http.MethodPost, "/auth/refresh", map[string]any{
+		"refresh_token": refreshToken,
+	}, "")
+}
+
+func refreshTokenOk(t *testing.T, refreshToken string) map[string]any {
+	resp := tryRefreshToken(t, refreshToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func listApiTokensOk(t *testing.T, auth string) []any {
+	resp := makeRequest(t, http.MethodGet, "/api_token", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)["tokens"].([]any)
+}
+
+func tryRevokeApiToken(t *testing.T, tokenId int, auth string) *http.Response {
+	return makeRequest(t, http.MethodDelete, fmt.Sprintf("/api_token/%d", tokenId), nil, auth)
+}
+
+func tryChangePassword(t *testing.T, changeRequest map[string]any, auth string) *http.Response {
+	return makeRequest(t, http.MethodPut, "/auth/password", changeRequest, auth)
+}
+
+func requestPasswordResetOk(t *testing.T, resetRequest map[string]any) {
+	resp := makeRequest(t, http.MethodPost, "/auth/password/reset_request", resetRequest, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func tryResetPassword(t *testing.T, resetRequest map[string]any) *http.Response {
+	return makeRequest(t, http.MethodPost, "/auth/password/reset", resetRequest, "")
+}
+
+func getContactsOk(t *testing.T, auth string) map[string]any {
+	resp := makeRequest(t, http.MethodGet, "/auth/contacts", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func sendVerificationCodeOk(t *testing.T, contact string, auth string) {
+	resp := makeRequest(t, http.MethodPost, fmt.Sprintf("/auth/contacts/%s/verification", contact), nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func tryConfirmContact(t *testing.T, contact string, code string, auth string) *http.Response {
+	return makeRequest(t, http.MethodPost, fmt.Sprintf("/auth/contacts/%s/confirm", contact), map[string]any{
+		"code": code,
+	}, auth)
+}
+
+func verifyContactOk(t *testing.T, contact string, address string, auth string) {
+	sendVerificationCodeOk(t, contact, auth)
+	resp := tryConfirmContact(t, contact, lastNotificationCode(t, address), auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func listAuthLockoutsOk(t *testing.T, auth string) []any {
+	resp := makeRequest(t, http.MethodGet, "/auth/lockouts", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)["lockouts"].([]any)
+}
+
+func listAuditEventsOk(t *testing.T, auth string) []any {
+	resp := makeRequest(t, http.MethodGet, "/auth/audit_log", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)["events"].([]any)
+}
+
+func listSessionsOk(t *testing.T, auth string) []any {
+	resp := makeRequest(t, http.MethodGet, "/auth/sessions", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)["sessions"].([]any)
+}
+
+func tryTerminateSession(t *testing.T, sessionId string, auth string) *http.Response {
+	return makeRequest(t, http.MethodDelete, fmt.Sprintf("/auth/sessions/%s", sessionId), nil, auth)
+}
+
+func tryTerminateAllSessions(t *testing.T, terminateRequest map[string]any, auth string) *http.Response {
+	return makeRequest(t, http.MethodDelete, "/auth/sessions", terminateRequest, auth)
+}
+
+func trySearchProfiles(t *testing.T, searchQuery string, pageToken string) *http.Response {
+	values := url.Values{}
+	values.Set("q", searchQuery)
+	values.Set("page_token", pageToken)
+	return makeRequest(t, http.MethodGet, "/profiles?"+values.Encode(), nil, "")
+}
+
+func searchProfilesOk(t *testing.T, searchQuery string, pageToken string) map[string]any {
+	resp := trySearchProfiles(t, searchQuery, pageToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func tryFollow(t *testing.T, profileId string, auth string) *http.Response {
+	return makeRequest(t, http.MethodPost, fmt.Sprintf("/profile/%s/followers", profileId), nil, auth)
+}
+
+func followOk(t *testing.T, profileId string, auth string) {
+	resp := tryFollow(t, profileId, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func unfollowOk(t *testing.T, profileId string, auth string) {
+	resp := makeRequest(t, http.MethodDelete, fmt.Sprintf("/profile/%s/followers", profileId), nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// Lists profiles of relation ("followers", "following" or "friends") of profile.
+func listFollowsOk(t *testing.T, profileId string, relation string) []string {
+	resp := makeRequest(t, http.MethodGet, fmt.Sprintf("/profile/%s/%s", profileId, relation), nil, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	profiles := responseBodyToMap(t, resp)["profiles"].([]any)
+	ids := make([]string, len(profiles))
+	for i, p := range profiles {
+		ids[i] = p.(map[string]any)["profile_id"].(string)
+	}
+	return ids
+}
+
+func tryBlock(t *testing.T, profileId string, auth string) *http.Response {
+	return makeRequest(t, http.MethodPost, fmt.Sprintf("/profile/%s/block", profileId), nil, auth)
+}
+
+func blockOk(t *testing.T, profileId string, auth string) {
+	resp := tryBlock(t, profileId, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func unblockOk(t *testing.T, profileId string, auth string) {
+	resp := makeRequest(t, http.MethodDelete, fmt.Sprintf("/profile/%s/block", profileId), nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func listBlockedOk(t *testing.T, auth string) []string {
+	resp := makeRequest(t, http.MethodGet, "/blocks", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	profiles := responseBodyToMap(t, resp)["profiles"].([]any)
+	ids := make([]string, len(profiles))
+	for i, p := range profiles {
+		ids[i] = p.(map[string]any)["profile_id"].(string)
+	}
+	return ids
+}
+
+func tryUploadProfileImage(t *testing.T, profileId string, kind string, data []byte, auth string) *http.Response {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", "image")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/profile/%s/%s", gatewayApiUrl(), profileId, kind), &body)
+	require.NoError(t, err, "error while creating request")
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	req.Header.Add("Authorization", auth)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "error while sending upload request")
+	return resp
+}
+
+func uploadProfileImageOk(t *testing.T, profileId string, kind string, data []byte, auth string) map[string]any {
+	resp := tryUploadProfileImage(t, profileId, kind, data, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+// Image urls are relative to the gateway host
+func tryGetImage(t *testing.T, imageUrl string) *http.Response {
+	resp, err := http.Get(gatewayApiUrl() + strings.TrimPrefix(imageUrl, "/api/v1"))
+	require.NoError(t, err, "error while getting image %s", imageUrl)
+	return resp
+}
+
+func makePng(t *testing.T, width int, height int) []byte {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func tryEditPrivacySettings(t *testing.T, profileId string, editRequest map[string]any, auth string) *http.Response {
+	return makeRequest(t, http.MethodPut, fmt.Sprintf("/profile/%s/privacy", profileId), editRequest, auth)
+}
+
+func getPrivacySettingsOk(t *testing.T, profileId string, auth string) map[string]any {
+	resp := makeRequest(t, http.MethodGet, fmt.Sprintf("/profile/%s/privacy", profileId), nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func getProfileInfoAsOk(t *testing.T, profileId string, auth string) map[string]any {
+	resp := makeRequest(t, http.MethodGet, fmt.Sprintf("/profile/%s", profileId), nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func requestDataExportOk(t *testing.T, auth string) map[string]any {
+	resp := makeRequest(t, http.MethodPost, "/exports", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func tryGetDataExport(t *testing.T, exportId string, auth string) *http.Response {
+	return makeRequest(t, http.MethodGet, fmt.Sprintf("/exports/%s", exportId), nil, auth)
+}
+
+func tryDownloadDataExport(t *testing.T, exportId string, auth string) *http.Response {
+	return makeRequest(t, http.MethodGet, fmt.Sprintf("/exports/%s/archive", exportId), nil, auth)
+}
+
+// Returns json files of the archive by name
+func readExportArchive(t *testing.T, data []byte) map[string]any {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string]any)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+
+		var v any
+		require.NoError(t, json.Unmarshal(content, &v), "file %s", f.Name)
+		files[f.Name] = v
+	}
+	return files
+}
+
+func passSecondFactor(t *testing.T, resourcePath string, challenge string, code string) *http.Response {
+	return makeRequest(t, http.MethodPost, resourcePath, map[string]any{
+		"challenge": challenge,
+		"code":      code,
+	}, "")
+}
+
+func tryGetAccountStatus(t *testing.T, profileId string, auth string) *http.Response {
+	return makeRequest(t, http.MethodGet, fmt.Sprintf("/admin/accounts/%s", profileId), nil, auth)
+}
+
+func getAccountStatusOk(t *testing.T, profileId string, auth string) map[string]any {
+	resp := tryGetAccountStatus(t, profileId, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func trySetAccountRole(t *testing.T, profileId string, role string, auth string) *http.Response {
+	return makeRequest(t, http.MethodPut, fmt.Sprintf("/admin/accounts/%s/role", profileId), map[string]any{"role": role}, auth)
+}
+
+func setAccountRoleOk(t *testing.T, profileId string, role string, auth string) {
+	resp := trySetAccountRole(t, profileId, role, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func trySuspendAccount(t *testing.T, profileId string, suspendRequest map[string]any, auth string) *http.Response {
+	return makeRequest(t, http.MethodPost, fmt.Sprintf("/admin/accounts/%s/suspension", profileId), suspendRequest, auth)
+}
+
+func unsuspendAccountOk(t *testing.T, profileId string, auth string) {
+	resp := makeRequest(t, http.MethodDelete, fmt.Sprintf("/admin/accounts/%s/suspension", profileId), nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func registerOAuthClientOk(t *testing.T, registerRequest map[string]any, auth string) map[string]any {
+	resp := makeRequest(t, http.MethodPost, "/oauth/clients", registerRequest, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)
+}
+
+func tryAuthorizeOAuthClient(t *testing.T, authorizeRequest map[string]any, auth string) *http.Response {
+	return makeRequest(t, http.MethodPost, "/oauth/authorize", authorizeRequest, auth)
+}
+
+func tryExchangeOAuthCode(t *testing.T, tokenRequest map[string]any) *http.Response {
+	return makeRequest(t, http.MethodPost, "/oauth/token", tokenRequest, "")
+}
+
+func listAuthorizedAppsOk(t *testing.T, auth string) []any {
+	resp := makeRequest(t, http.MethodGet, "/auth/apps", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)["apps"].([]any)
+}
+
+func tryRevokeAuthorizedApp(t *testing.T, clientId string, auth string) *http.Response {
+	return makeRequest(t, http.MethodDelete, fmt.Sprintf("/auth/apps/%s", clientId), nil, auth)
+}
+
+// PKCE S256 challenge of the verifier
+func oauthCodeChallenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func listOidcProvidersOk(t *testing.T) []any {
+	resp := makeRequest(t, http.MethodGet, "/auth/oidc/providers", nil, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)["providers"].([]any)
+}
+
+func tryBeginOidcLogin(t *testing.T, provider string) *http.Response {
+	return makeRequest(t, http.MethodPost, fmt.Sprintf("/auth/oidc/%s/login", provider), nil, "")
+}
+
+func tryCompleteOidcLogin(t *testing.T, provider string, callback map[string]any) *http.Response {
+	return makeRequest(t, http.MethodPost, fmt.Sprintf("/auth/oidc/%s/callback", provider), callback, "")
+}
+
+func listOidcIdentitiesOk(t *testing.T, auth string) []any {
+	resp := makeRequest(t, http.MethodGet, "/auth/oidc/identities", nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return responseBodyToMap(t, resp)["identities"].([]any)
+}
+
+func tryUnlinkOidcIdentity(t *testing.T, provider string, auth string) *http.Response {
+	return makeRequest(t, http.MethodDelete, fmt.Sprintf("/auth/oidc/identities/%s", provider), nil, auth)
+}
+
 func TestRegister(t *testing.T) {
 	id := registerUserOk(t, map[string]any{
 		"login":        "register_test",
@@ -279,3 +620,1204 @@ func TestApiTokenTimeout(t *testing.T) {
 	}, soaTokenAuth(token))
 	require.Equal(t, http.StatusForbidden, resp.StatusCode)
 }
+
+func TestRefreshTokenRotation(t *testing.T) {
+	id := registerUserOk(t, map[string]any{
+		"login":        "refresh_token_rotation",
+		"password":     "testpasswd",
+		"email":        "refresh_token_rotation@yahoo.com",
+		"phone_number": "+79250000028",
+		"name":         "Test",
+		"surname":      "RefreshTokenRotation",
+	})
+
+	resp := tryAuthenticate(t, map[string]any{
+		"login":    "refresh_token_rotation",
+		"password": "testpasswd",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	refreshToken := responseBodyToMap(t, resp)["refresh_token"].(string)
+
+	refreshed := refreshTokenOk(t, refreshToken)
+	assert.NotEqual(t, refreshToken, refreshed["refresh_token"].(string))
+
+	editProfileOk(t, id, map[string]any{
+		"bio": "new bio",
+	}, jwtAuth(refreshed["token"].(string)))
+
+	refreshTokenOk(t, refreshed["refresh_token"].(string))
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	registerUserOk(t, map[string]any{
+		"login":        "refresh_token_reuse",
+		"password":     "testpasswd",
+		"email":        "refresh_token_reuse@yahoo.com",
+		"phone_number": "+79250000029",
+		"name":         "Test",
+		"surname":      "RefreshTokenReuse",
+	})
+
+	resp := tryAuthenticate(t, map[string]any{
+		"login":    "refresh_token_reuse",
+		"password": "testpasswd",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	refreshToken := responseBodyToMap(t, resp)["refresh_token"].(string)
+
+	refreshed := refreshTokenOk(t, refreshToken)
+
+	// replaying rotated token revokes the whole family
+	resp = tryRefreshToken(t, refreshToken)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryRefreshToken(t, refreshed["refresh_token"].(string))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestApiTokenRevoke(t *testing.T) {
+	id := registerUserOk(t, map[string]any{
+		"login":        "api_token_revoke",
+		"password":     "testpasswd",
+		"email":        "api_token_revoke@yahoo.com",
+		"phone_number": "+79250000030",
+		"name":         "Test",
+		"surname":      "ApiTokenRevoke",
+	})
+
+	resp := tryCreateApiToken(t, map[string]any{
+		"auth": map[string]any{
+			"login":    "api_token_revoke",
+			"password": "testpasswd",
+		},
+		"name":         "ci",
+		"read_access":  true,
+		"write_access": true,
+		"ttl":          "1h",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	created := responseBodyToMap(t, resp)
+	token := created["token"].(string)
+	tokenId := int(created["token_id"].(float64))
+
+	editProfileOk(t, id, map[string]any{
+		"bio": "new bio",
+	}, soaTokenAuth(token))
+
+	jwt := authenticateOk(t, map[string]any{
+		"login":    "api_token_revoke",
+		"password": "testpasswd",
+	})
+
+	tokens := listApiTokensOk(t, jwtAuth(jwt))
+	require.Len(t, tokens, 1)
+	tokenInfo := tokens[0].(map[string]any)
+	assert.Equal(t, "ci", tokenInfo["name"].(string))
+	assert.Equal(t, tokenId, int(tokenInfo["id"].(float64)))
+	assert.NotNil(t, tokenInfo["last_used_at"])
+
+	resp = tryRevokeApiToken(t, tokenId, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = tryEditProfile(t, id, map[string]any{
+		"bio": "new bio",
+	}, soaTokenAuth(token))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestChangePassword(t *testing.T) {
+	registerUserOk(t, map[string]any{
+		"login":        "change_password",
+		"password":     "testpasswd",
+		"email":        "change_password@yahoo.com",
+		"phone_number": "+79250000032",
+		"name":         "Change",
+		"surname":      "Password",
+	})
+
+	resp := tryAuthenticate(t, map[string]any{
+		"login":    "change_password",
+		"password": "testpasswd",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	authResponse := responseBodyToMap(t, resp)
+	jwt := authResponse["token"].(string)
+	refreshToken := authResponse["refresh_token"].(string)
+
+	resp = tryChangePassword(t, map[string]any{
+		"old_password": "wrongpasswd",
+		"new_password": "newtestpasswd",
+	}, jwtAuth(jwt))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryChangePassword(t, map[string]any{
+		"old_password": "testpasswd",
+		"new_password": "newtestpasswd",
+	}, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = tryRefreshToken(t, refreshToken)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode, "sessions must be revoked after password change")
+
+	resp = tryAuthenticate(t, map[string]any{
+		"login":    "change_password",
+		"password": "testpasswd",
+	})
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	authenticateOk(t, map[string]any{
+		"login":    "change_password",
+		"password": "newtestpasswd",
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	registerUserOk(t, map[string]any{
+		"login":        "password_reset",
+		"password":     "testpasswd",
+		"email":        "password_reset@yahoo.com",
+		"phone_number": "+79250000033",
+		"name":         "Password",
+		"surname":      "Reset",
+	})
+
+	// codes are not sent to unverified contacts
+	requestPasswordResetOk(t, map[string]any{
+		"login": "password_reset",
+	})
+	assert.NoFileExists(t, filepath.Join(accounts_notifications_dir, "password_reset@yahoo.com"))
+
+	jwt := authenticateOk(t, map[string]any{
+		"login":    "password_reset",
+		"password": "testpasswd",
+	})
+	verifyContactOk(t, "email", "password_reset@yahoo.com", jwtAuth(jwt))
+
+	requestPasswordResetOk(t, map[string]any{
+		"login": "password_reset",
+	})
+	code := lastNotificationCode(t, "password_reset@yahoo.com")
+	require.NotEmpty(t, code)
+
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "111111"
+	}
+	resp := tryResetPassword(t, map[string]any{
+		"login":        "password_reset",
+		"code":         wrongCode,
+		"new_password": "newtestpasswd",
+	})
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resetRequest := map[string]any{
+		"email":        "password_reset@yahoo.com",
+		"code":         code,
+		"new_password": "newtestpasswd",
+	}
+	resp = tryResetPassword(t, resetRequest)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = tryResetPassword(t, resetRequest)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode, "reset code must be single-use")
+
+	authenticateOk(t, map[string]any{
+		"login":    "password_reset",
+		"password": "newtestpasswd",
+	})
+
+	// unknown users are not disclosed
+	requestPasswordResetOk(t, map[string]any{
+		"login": "password_reset_unknown",
+	})
+}
+
+func TestContactsVerification(t *testing.T) {
+	registerUserOk(t, map[string]any{
+		"login":        "contacts_verification",
+		"password":     "testpasswd",
+		"email":        "contacts_verification@yahoo.com",
+		"phone_number": "+79250000034",
+		"name":         "Contacts",
+		"surname":      "Verification",
+	})
+
+	jwt := authenticateOk(t, map[string]any{
+		"login":    "contacts_verification",
+		"password": "testpasswd",
+	})
+
+	contacts := getContactsOk(t, jwtAuth(jwt))
+	assert.False(t, contacts["email_verified"].(bool))
+	assert.False(t, contacts["phone_number_verified"].(bool))
+
+	sendVerificationCodeOk(t, "email", jwtAuth(jwt))
+	code := lastNotificationCode(t, "contacts_verification@yahoo.com")
+
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "111111"
+	}
+	resp := tryConfirmContact(t, "email", wrongCode, jwtAuth(jwt))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryConfirmContact(t, "email", code, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sendVerificationCodeOk(t, "phone_number", jwtAuth(jwt))
+	resp = tryConfirmContact(t, "phone_number", lastNotificationCode(t, "+79250000034"), jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	contacts = getContactsOk(t, jwtAuth(jwt))
+	assert.True(t, contacts["email_verified"].(bool))
+	assert.True(t, contacts["phone_number_verified"].(bool))
+
+	// contact change requires the current password
+	resp = makeRequest(t, http.MethodPut, "/auth/contacts/email", map[string]any{
+		"email":    "contacts_verification_new@yahoo.com",
+		"password": "wrongpasswd",
+	}, jwtAuth(jwt))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// changed contact must be verified again
+	resp = makeRequest(t, http.MethodPut, "/auth/contacts/email", map[string]any{
+		"email":    "contacts_verification_new@yahoo.com",
+		"password": "testpasswd",
+	}, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	contacts = getContactsOk(t, jwtAuth(jwt))
+	assert.Equal(t, "contacts_verification_new@yahoo.com", contacts["email"].(string))
+	assert.False(t, contacts["email_verified"].(bool))
+
+	resp = tryConfirmContact(t, "email", lastNotificationCode(t, "contacts_verification_new@yahoo.com"), jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	contacts = getContactsOk(t, jwtAuth(jwt))
+	assert.True(t, contacts["email_verified"].(bool))
+}
+
+func TestAuthLockout(t *testing.T) {
+	registerUserOk(t, map[string]any{
+		"login":        "auth_lockout",
+		"password":     "testpasswd",
+		"email":        "auth_lockout@yahoo.com",
+		"phone_number": "+79250000035",
+		"name":         "Auth",
+		"surname":      "Lockout",
+	})
+
+	jwt := authenticateOk(t, map[string]any{
+		"login":    "auth_lockout",
+		"password": "testpasswd",
+	})
+	verifyContactOk(t, "email", "auth_lockout@yahoo.com", jwtAuth(jwt))
+
+	// unknown login and wrong password are indistinguishable
+	resp := tryAuthenticate(t, map[string]any{
+		"login":    "auth_lockout_unknown",
+		"password": "testpasswd",
+	})
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	for range 5 {
+		resp = tryAuthenticate(t, map[string]any{
+			"login":    "auth_lockout",
+			"password": "wrongpasswd",
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	}
+
+	resp = tryAuthenticate(t, map[string]any{
+		"login":    "auth_lockout",
+		"password": "testpasswd",
+	})
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "correct password must not be checked while locked out")
+
+	resp = tryCreateApiToken(t, map[string]any{
+		"auth": map[string]any{
+			"login":    "auth_lockout",
+			"password": "testpasswd",
+		},
+		"read_access":  true,
+		"write_access": false,
+		"ttl":          "1h",
+	})
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	lockouts := listAuthLockoutsOk(t, jwtAuth(jwt))
+	require.Len(t, lockouts, 1)
+	lockout := lockouts[0].(map[string]any)
+	assert.Equal(t, "login:auth_lockout", lockout["key"].(string))
+	assert.EqualValues(t, 5, lockout["failed_count"].(float64))
+	assert.NotNil(t, lockout["locked_until"])
+
+	// successful reset proves ownership and lifts lockout
+	requestPasswordResetOk(t, map[string]any{
+		"login": "auth_lockout",
+	})
+	resp = tryResetPassword(t, map[string]any{
+		"login":        "auth_lockout",
+		"code":         lastNotificationCode(t, "auth_lockout@yahoo.com"),
+		"new_password": "newtestpasswd",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	jwt = authenticateOk(t, map[string]any{
+		"login":    "auth_lockout",
+		"password": "newtestpasswd",
+	})
+	assert.Empty(t, listAuthLockoutsOk(t, jwtAuth(jwt)))
+}
+
+func TestTwoFactor(t *testing.T) {
+	registerUserOk(t, map[string]any{
+		"login":        "two_factor",
+		"password":     "testpasswd",
+		"email":        "two_factor@yahoo.com",
+		"phone_number": "+79250000036",
+		"name":         "Two",
+		"surname":      "Factor",
+	})
+
+	authRequest := map[string]any{
+		"login":    "two_factor",
+		"password": "testpasswd",
+	}
+	jwt := authenticateOk(t, authRequest)
+
+	resp := makeRequest(t, http.MethodPost, "/auth/totp", nil, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	enrollment := responseBodyToMap(t, resp)
+	assert.Contains(t, enrollment["otpauth_uri"].(string), "otpauth://totp/")
+
+	secret, err := totp.DecodeSecret(enrollment["secret"].(string))
+	require.NoError(t, err)
+
+	resp = makeRequest(t, http.MethodPost, "/auth/totp/confirm", map[string]any{
+		"code": totp.Generate(secret, time.Now()),
+	}, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	backupCodes := responseBodyToMap(t, resp)["codes"].([]any)
+	require.NotEmpty(t, backupCodes)
+
+	// password alone is not enough anymore
+	resp = tryAuthenticate(t, authRequest)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	authResponse := responseBodyToMap(t, resp)
+	assert.Empty(t, authResponse["token"].(string))
+	challenge := authResponse["second_factor"].(map[string]any)["challenge"].(string)
+
+	resp = passSecondFactor(t, "/auth/second_factor", challenge, "wrong-code")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// totp code of confirmation step is already used, so backup code is passed
+	resp = passSecondFactor(t, "/auth/second_factor", challenge, backupCodes[0].(string))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	jwt = responseBodyToMap(t, resp)["token"].(string)
+	require.NotEmpty(t, jwt)
+
+	resp = passSecondFactor(t, "/auth/second_factor", challenge, backupCodes[1].(string))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode, "challenge must be single-use")
+
+	resp = tryCreateApiToken(t, map[string]any{
+		"auth":         authRequest,
+		"read_access":  true,
+		"write_access": false,
+		"ttl":          "1h",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	challenge = responseBodyToMap(t, resp)["second_factor"].(map[string]any)["challenge"].(string)
+
+	resp = passSecondFactor(t, "/api_token/second_factor", challenge, backupCodes[0].(string))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode, "backup code must be single-use")
+
+	resp = passSecondFactor(t, "/api_token/second_factor", challenge, backupCodes[1].(string))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, responseBodyToMap(t, resp)["token"].(string))
+
+	resp = makeRequest(t, http.MethodPost, "/auth/totp/disable", map[string]any{
+		"code": backupCodes[2].(string),
+	}, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	authenticateOk(t, authRequest)
+}
+
+func TestSessions(t *testing.T) {
+	id := registerUserOk(t, map[string]any{
+		"login":        "sessions",
+		"password":     "testpasswd",
+		"email":        "sessions@yahoo.com",
+		"phone_number": "+79250000038",
+		"name":         "Test",
+		"surname":      "Sessions",
+	})
+
+	login := func() map[string]any {
+		resp := tryAuthenticate(t, map[string]any{
+			"login":    "sessions",
+			"password": "testpasswd",
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return responseBodyToMap(t, resp)
+	}
+
+	first := login()
+	second := login()
+	third := login()
+
+	sessions := listSessionsOk(t, jwtAuth(first["token"].(string)))
+	require.Len(t, sessions, 3)
+
+	var firstSessionId string
+	for _, s := range sessions {
+		session := s.(map[string]any)
+		if session["current"].(bool) {
+			require.Empty(t, firstSessionId)
+			firstSessionId = session["id"].(string)
+		}
+	}
+	require.NotEmpty(t, firstSessionId)
+
+	resp := tryTerminateSession(t, firstSessionId, jwtAuth(second["token"].(string)))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = tryTerminateSession(t, firstSessionId, jwtAuth(second["token"].(string)))
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = tryRefreshToken(t, first["refresh_token"].(string))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// revocation reaches other services asynchronously
+	require.Eventually(t, func() bool {
+		resp := tryEditProfile(t, id, map[string]any{"bio": "terminated"}, jwtAuth(first["token"].(string)))
+		return resp.StatusCode == http.StatusForbidden
+	}, 10*time.Second, 200*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		resp := tryCreatePost(t, id, map[string]any{"text": "terminated"}, jwtAuth(first["token"].(string)))
+		return resp.StatusCode == http.StatusForbidden
+	}, 10*time.Second, 200*time.Millisecond)
+
+	resp = tryTerminateAllSessions(t, map[string]any{
+		"keep_current": true,
+	}, jwtAuth(second["token"].(string)))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, int(responseBodyToMap(t, resp)["terminated_count"].(float64)))
+
+	resp = tryRefreshToken(t, third["refresh_token"].(string))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	sessions = listSessionsOk(t, jwtAuth(second["token"].(string)))
+	require.Len(t, sessions, 1)
+	assert.True(t, sessions[0].(map[string]any)["current"].(bool))
+
+	refreshTokenOk(t, second["refresh_token"].(string))
+}
+
+func TestDeleteErasesAccountData(t *testing.T) {
+	erasedId := registerUserOk(t, map[string]any{
+		"login":        "delete_erases_data",
+		"password":     "testpasswd",
+		"email":        "delete_erases_data@yahoo.com",
+		"phone_number": "+79250000039",
+		"name":         "Test",
+		"surname":      "DeleteErasesData",
+	})
+	erasedToken := authenticateOk(t, map[string]any{
+		"login":    "delete_erases_data",
+		"password": "testpasswd",
+	})
+
+	otherId := registerUserOk(t, map[string]any{
+		"login":        "delete_erases_data_other",
+		"password":     "testpasswd",
+		"email":        "delete_erases_data_other@yahoo.com",
+		"phone_number": "+79250000040",
+		"name":         "Test",
+		"surname":      "DeleteErasesDataOther",
+	})
+	otherToken := authenticateOk(t, map[string]any{
+		"login":    "delete_erases_data_other",
+		"password": "testpasswd",
+	})
+
+	erasedPostId := createPostOk(t, erasedId, map[string]any{"text": "post to be erased"}, jwtAuth(erasedToken))
+	otherPostId := createPostOk(t, otherId, map[string]any{"text": "post to be kept"}, jwtAuth(otherToken))
+
+	newViewOk(t, erasedPostId, jwtAuth(otherToken))
+	newLikeOk(t, erasedPostId, jwtAuth(otherToken))
+	newCommentOk(t, erasedPostId, map[string]any{"content": "comment under erased post"}, jwtAuth(otherToken))
+
+	newViewOk(t, otherPostId, jwtAuth(erasedToken))
+	newLikeOk(t, otherPostId, jwtAuth(erasedToken))
+	newCommentOk(t, otherPostId, map[string]any{"content": "comment of erased user"}, jwtAuth(erasedToken))
+	newCommentOk(t, otherPostId, map[string]any{"content": "comment to be kept"}, jwtAuth(otherToken))
+
+	require.Eventually(t, func() bool {
+		return getMetricOk(t, erasedPostId, "like_count") == 1
+	}, 20*time.Second, 500*time.Millisecond)
+
+	deleteProfileOk(t, erasedId, jwtAuth(erasedToken))
+
+	resp := tryGetProfileInfo(t, erasedId)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		return tryGetPost(t, erasedPostId, jwtAuth(otherToken)).StatusCode == http.StatusNotFound
+	}, 20*time.Second, 500*time.Millisecond)
+
+	comments := getCommentsOk(t, otherPostId, map[string]any{}, jwtAuth(otherToken))["comments"].([]any)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "comment to be kept", comments[0].(map[string]any)["content"].(string))
+
+	require.Eventually(t, func() bool {
+		return getMetricOk(t, erasedPostId, "view_count") == 0 &&
+			getMetricOk(t, erasedPostId, "like_count") == 0 &&
+			getMetricOk(t, erasedPostId, "comment_count") == 0
+	}, 20*time.Second, 500*time.Millisecond)
+
+	// actions of erased user on other posts are kept anonymized
+	assert.Equal(t, 1, getMetricOk(t, otherPostId, "view_count"))
+	assert.Equal(t, 1, getMetricOk(t, otherPostId, "like_count"))
+	assert.Equal(t, 2, getMetricOk(t, otherPostId, "comment_count"))
+}
+
+func TestSearchProfiles(t *testing.T) {
+	ids := make([]string, 3)
+	for i, suffix := range []string{"Alpha", "Beta", "Gamma"} {
+		ids[i] = registerUserOk(t, map[string]any{
+			"login":        fmt.Sprintf("search_profiles_%d", i),
+			"password":     "testpasswd",
+			"email":        fmt.Sprintf("search_profiles_%d@yahoo.com", i),
+			"phone_number": fmt.Sprintf("+7925000004%d", i+1),
+			"name":         "Test",
+			"surname":      "Zyxwsearch" + suffix,
+		})
+	}
+
+	checkFound := func(result map[string]any, expectedIds []string) {
+		profiles := result["profiles"].([]any)
+		foundIds := make([]string, len(profiles))
+		for i, p := range profiles {
+			foundIds[i] = p.(map[string]any)["profile_id"].(string)
+		}
+		assert.ElementsMatch(t, expectedIds, foundIds)
+		assert.Empty(t, result["next_page_token"].(string))
+	}
+
+	checkFound(searchProfilesOk(t, "zyxwsearch", ""), ids)
+	checkFound(searchProfilesOk(t, "ZYXWSEARCHBETA", ""), ids[1:2])
+	checkFound(searchProfilesOk(t, "search_profiles_2", ""), ids[2:3])
+
+	// typo is tolerated
+	result := searchProfilesOk(t, "zyxwserchgamma", "")
+	profiles := result["profiles"].([]any)
+	require.NotEmpty(t, profiles)
+	card := profiles[0].(map[string]any)
+	assert.Equal(t, ids[2], card["profile_id"].(string))
+	assert.NotContains(t, card, "login")
+	assert.Equal(t, "Test", card["name"].(string))
+	assert.Equal(t, "ZyxwsearchGamma", card["surname"].(string))
+
+	resp := trySearchProfiles(t, " ", "")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = trySearchProfiles(t, "zyxwsearch", "broken token")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = makeRequest(t, http.MethodGet, "/profiles", nil, "")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestFollows(t *testing.T) {
+	ids := make([]string, 3)
+	auths := make([]string, 3)
+	for i := range 3 {
+		login := fmt.Sprintf("follows_%d", i)
+		ids[i] = registerUserOk(t, map[string]any{
+			"login":        login,
+			"password":     "testpasswd",
+			"email":        fmt.Sprintf("follows_%d@yahoo.com", i),
+			"phone_number": fmt.Sprintf("+7925000004%d", i+4),
+			"name":         "Test",
+			"surname":      "Follows",
+		})
+		auths[i] = jwtAuth(authenticateOk(t, map[string]any{
+			"login":    login,
+			"password": "testpasswd",
+		}))
+	}
+
+	followOk(t, ids[1], auths[0])
+	followOk(t, ids[1], auths[0])
+	followOk(t, ids[0], auths[1])
+	followOk(t, ids[1], auths[2])
+
+	assert.ElementsMatch(t, []string{ids[0], ids[2]}, listFollowsOk(t, ids[1], "followers"))
+	assert.ElementsMatch(t, []string{ids[0]}, listFollowsOk(t, ids[1], "following"))
+	assert.ElementsMatch(t, []string{ids[0]}, listFollowsOk(t, ids[1], "friends"))
+	assert.Empty(t, listFollowsOk(t, ids[2], "friends"))
+
+	profileInfo := getProfileInfoOk(t, ids[1])
+	assert.EqualValues(t, 2, profileInfo["followers_count"])
+	assert.EqualValues(t, 1, profileInfo["following_count"])
+
+	unfollowOk(t, ids[1], auths[0])
+	assert.ElementsMatch(t, []string{ids[2]}, listFollowsOk(t, ids[1], "followers"))
+	assert.Empty(t, listFollowsOk(t, ids[1], "friends"))
+
+	resp := tryFollow(t, ids[0], auths[0])
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = tryFollow(t, ids[0], "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestProfileImages(t *testing.T) {
+	profileId := registerUserOk(t, map[string]any{
+		"login":        "images",
+		"password":     "testpasswd",
+		"email":        "images@yahoo.com",
+		"phone_number": "+79250000049",
+		"name":         "Test",
+		"surname":      "Images",
+	})
+	auth := jwtAuth(authenticateOk(t, map[string]any{
+		"login":    "images",
+		"password": "testpasswd",
+	}))
+
+	uploaded := uploadProfileImageOk(t, profileId, "avatar", makePng(t, 256, 256), auth)
+
+	avatar := getProfileInfoOk(t, profileId)["avatar"].(map[string]any)
+	assert.Equal(t, uploaded["url"], avatar["url"])
+	assert.Equal(t, uploaded["thumbnail_url"], avatar["thumbnail_url"])
+
+	resp := tryGetImage(t, avatar["thumbnail_url"].(string))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	thumbnail, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 128, thumbnail.Width)
+	assert.Equal(t, 128, thumbnail.Height)
+
+	resp = tryUploadProfileImage(t, profileId, "avatar", []byte("not an image"), auth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = tryUploadProfileImage(t, profileId, "cover", makePng(t, 100, 100), auth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = tryUploadProfileImage(t, profileId, "avatar", makePng(t, 256, 256), "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = makeRequest(t, http.MethodDelete, fmt.Sprintf("/profile/%s/avatar", profileId), nil, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, getProfileInfoOk(t, profileId), "avatar")
+
+	resp = tryGetImage(t, avatar["url"].(string))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestProfilePrivacy(t *testing.T) {
+	ids := make([]string, 2)
+	auths := make([]string, 2)
+	for i := range 2 {
+		login := fmt.Sprintf("privacy_%d", i)
+		ids[i] = registerUserOk(t, map[string]any{
+			"login":        login,
+			"password":     "testpasswd",
+			"email":        fmt.Sprintf("privacy_%d@yahoo.com", i),
+			"phone_number": fmt.Sprintf("+792500000%d", 50+i),
+			"name":         "Test",
+			"surname":      "Privacy",
+		})
+		auths[i] = jwtAuth(authenticateOk(t, map[string]any{
+			"login":    login,
+			"password": "testpasswd",
+		}))
+	}
+	ownerId, ownerAuth, strangerAuth := ids[0], auths[0], auths[1]
+
+	editProfileOk(t, ownerId, map[string]any{"bio": "private bio"}, ownerAuth)
+
+	assert.Equal(t, map[string]any{
+		"birthday":     "everyone",
+		"bio":          "everyone",
+		"email":        "only_me",
+		"phone_number": "only_me",
+	}, getPrivacySettingsOk(t, ownerId, ownerAuth))
+
+	profile := getProfileInfoOk(t, ownerId)
+	assert.Equal(t, "private bio", profile["bio"])
+	assert.NotContains(t, profile, "email")
+
+	resp := tryEditPrivacySettings(t, ownerId, map[string]any{
+		"bio":   "authenticated",
+		"email": "everyone",
+	}, ownerAuth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	profile = getProfileInfoOk(t, ownerId)
+	assert.NotContains(t, profile, "bio")
+	assert.Equal(t, "privacy_0@yahoo.com", profile["email"])
+	assert.NotContains(t, profile, "phone_number")
+
+	profile = getProfileInfoAsOk(t, ownerId, strangerAuth)
+	assert.Equal(t, "private bio", profile["bio"])
+	assert.NotContains(t, profile, "phone_number")
+
+	profile = getProfileInfoAsOk(t, ownerId, ownerAuth)
+	assert.Equal(t, "+79250000050", profile["phone_number"])
+
+	resp = tryEditPrivacySettings(t, ownerId, map[string]any{"bio": "friends"}, ownerAuth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = tryEditPrivacySettings(t, ownerId, map[string]any{"bio": "everyone"}, strangerAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestDataExport(t *testing.T) {
+	profileId := registerUserOk(t, map[string]any{
+		"login":        "data_export",
+		"password":     "testpasswd",
+		"email":        "data_export@yahoo.com",
+		"phone_number": "+79250000052",
+		"name":         "Test",
+		"surname":      "Export",
+	})
+	auth := jwtAuth(authenticateOk(t, map[string]any{
+		"login":    "data_export",
+		"password": "testpasswd",
+	}))
+
+	postId := createPostOk(t, profileId, map[string]any{"text": "exported post"}, auth)
+	newCommentOk(t, postId, map[string]any{"content": "exported comment"}, auth)
+	newLikeOk(t, postId, auth)
+
+	export := requestDataExportOk(t, auth)
+	exportId := export["id"].(string)
+
+	resp := tryGetDataExport(t, exportId, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		resp := tryGetDataExport(t, exportId, auth)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		export = responseBodyToMap(t, resp)
+		return export["status"] != "pending"
+	}, 30*time.Second, 500*time.Millisecond)
+	require.Equal(t, "ready", export["status"])
+	assert.Contains(t, export, "finished_at")
+
+	resp = tryDownloadDataExport(t, exportId, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	files := readExportArchive(t, data)
+	account := files["account.json"].(map[string]any)
+	assert.Equal(t, profileId, account["profile_id"])
+	assert.Equal(t, "data_export", account["login"])
+	assert.Equal(t, "data_export@yahoo.com", account["email"])
+
+	posts := files["posts.json"].([]any)
+	require.Len(t, posts, 1)
+	assert.Equal(t, "exported post", posts[0].(map[string]any)["text"])
+
+	comments := files["comments.json"].([]any)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "exported comment", comments[0].(map[string]any)["content"])
+	assert.Equal(t, float64(postId), comments[0].(map[string]any)["post_id"])
+
+	likes := files["likes.json"].([]any)
+	require.Len(t, likes, 1)
+	assert.Equal(t, float64(postId), likes[0].(map[string]any)["post_id"])
+
+	postStats := files["post_stats.json"].([]any)
+	require.Len(t, postStats, 1)
+	assert.Equal(t, float64(postId), postStats[0].(map[string]any)["post_id"])
+
+	registerUserOk(t, map[string]any{
+		"login":        "data_export_stranger",
+		"password":     "testpasswd",
+		"email":        "data_export_stranger@yahoo.com",
+		"phone_number": "+79250000053",
+		"name":         "Test",
+		"surname":      "Stranger",
+	})
+	strangerAuth := jwtAuth(authenticateOk(t, map[string]any{
+		"login":    "data_export_stranger",
+		"password": "testpasswd",
+	}))
+	resp = tryDownloadDataExport(t, exportId, strangerAuth)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = tryGetDataExport(t, "not-an-id", auth)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAdministration(t *testing.T) {
+	// login of admin is listed in ADMIN_LOGINS of test.env
+	logins := []string{"e2e_admin", "e2e_moderator", "e2e_suspended"}
+	ids := make([]string, len(logins))
+	auths := make([]string, len(logins))
+	for i, login := range logins {
+		ids[i] = registerUserOk(t, map[string]any{
+			"login":        login,
+			"password":     "testpasswd",
+			"email":        fmt.Sprintf("%s@yahoo.com", login),
+			"phone_number": fmt.Sprintf("+792500000%d", 54+i),
+			"name":         "Test",
+			"surname":      "Administration",
+		})
+		auths[i] = jwtAuth(authenticateOk(t, map[string]any{
+			"login":    login,
+			"password": "testpasswd",
+		}))
+	}
+	adminId, moderatorId, userId := ids[0], ids[1], ids[2]
+	adminAuth, moderatorAuth, userAuth := auths[0], auths[1], auths[2]
+
+	assert.Equal(t, "admin", getAccountStatusOk(t, adminId, adminAuth)["role"])
+
+	resp := tryGetAccountStatus(t, adminId, userAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = trySetAccountRole(t, userId, "admin", userAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = trySetAccountRole(t, adminId, "user", adminAuth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = trySetAccountRole(t, moderatorId, "owner", adminAuth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// moderation by posts service
+	postId := createPostOk(t, userId, map[string]any{"text": "moderated post"}, userAuth)
+	commentId := newCommentOk(t, postId, map[string]any{"content": "moderated comment"}, userAuth)
+
+	resp = tryDeleteComment(t, postId, commentId, moderatorAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	setAccountRoleOk(t, moderatorId, "moderator", adminAuth)
+	status := getAccountStatusOk(t, moderatorId, adminAuth)
+	assert.Equal(t, "moderator", status["role"])
+	assert.Nil(t, status["suspension"])
+
+	// role is put into the next issued jwt
+	moderatorAuth = jwtAuth(authenticateOk(t, map[string]any{
+		"login":    "e2e_moderator",
+		"password": "testpasswd",
+	}))
+
+	deleteCommentOk(t, postId, commentId, moderatorAuth)
+	resp = tryDeleteComment(t, postId, commentId, moderatorAuth)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	deletePostOk(t, postId, moderatorAuth)
+	resp = tryGetPost(t, postId, userAuth)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// demotion terminates sessions, so old jwts can not moderate anymore
+	postId = createPostOk(t, userId, map[string]any{"text": "post after demotion"}, userAuth)
+	setAccountRoleOk(t, moderatorId, "user", adminAuth)
+	require.Eventually(t, func() bool {
+		return tryDeletePost(t, postId, moderatorAuth).StatusCode == http.StatusForbidden
+	}, 10*time.Second, 200*time.Millisecond)
+
+	getPostOk(t, postId, userAuth)
+	setAccountRoleOk(t, moderatorId, "moderator", adminAuth)
+	moderatorAuth = jwtAuth(authenticateOk(t, map[string]any{
+		"login":    "e2e_moderator",
+		"password": "testpasswd",
+	}))
+
+	// suspension
+	apiToken := createApiTokenOk(t, map[string]any{
+		"auth": map[string]any{
+			"login":    "e2e_suspended",
+			"password": "testpasswd",
+		},
+		"scopes": []string{"posts:write"},
+		"ttl":    "1h",
+	})
+
+	resp = trySuspendAccount(t, userId, map[string]any{"reason": "spam"}, moderatorAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = trySuspendAccount(t, userId, map[string]any{}, adminAuth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = trySuspendAccount(t, userId, map[string]any{
+		"reason": "spam",
+		"until":  time.Now().Add(-time.Hour).Format(time.RFC3339),
+	}, adminAuth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = trySuspendAccount(t, adminId, map[string]any{"reason": "spam"}, adminAuth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	resp = trySuspendAccount(t, userId, map[string]any{
+		"reason": "spam",
+		"until":  until.Format(time.RFC3339),
+	}, adminAuth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	status = getAccountStatusOk(t, userId, adminAuth)
+	assert.Equal(t, "user", status["role"])
+	suspension := status["suspension"].(map[string]any)
+	assert.Equal(t, "spam", suspension["reason"])
+	assert.Equal(t, until.Format(time.RFC3339), suspension["until"])
+
+	resp = tryAuthenticate(t, map[string]any{
+		"login":    "e2e_suspended",
+		"password": "testpasswd",
+	})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryCreatePost(t, userId, map[string]any{"text": "suspended post"}, soaTokenAuth(apiToken))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	unsuspendAccountOk(t, userId, adminAuth)
+	assert.Nil(t, getAccountStatusOk(t, userId, adminAuth)["suspension"])
+
+	authenticateOk(t, map[string]any{
+		"login":    "e2e_suspended",
+		"password": "testpasswd",
+	})
+	// rejection of api token is cached by gateway for a while
+	require.Eventually(t, func() bool {
+		resp := tryCreatePost(t, userId, map[string]any{"text": "restored post"}, soaTokenAuth(apiToken))
+		return resp.StatusCode == http.StatusOK
+	}, 30*time.Second, time.Second)
+}
+
+func TestAuditLog(t *testing.T) {
+	id := registerUserOk(t, map[string]any{
+		"login":        "audit_log",
+		"password":     "testpasswd",
+		"email":        "audit_log@yahoo.com",
+		"phone_number": "+79250000057",
+		"name":         "Test",
+		"surname":      "AuditLog",
+	})
+
+	resp := tryAuthenticate(t, map[string]any{
+		"email":    "audit_log@yahoo.com",
+		"password": "wrongpasswd",
+	})
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	jwt := authenticateOk(t, map[string]any{
+		"login":    "audit_log",
+		"password": "testpasswd",
+	})
+
+	resp = tryCreateApiToken(t, map[string]any{
+		"auth": map[string]any{
+			"phone_number": "+79250000057",
+			"password":     "testpasswd",
+		},
+		"name":         "ci",
+		"read_access":  true,
+		"write_access": true,
+		"ttl":          "1h",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	created := responseBodyToMap(t, resp)
+	token := created["token"].(string)
+	tokenId := int(created["token_id"].(float64))
+
+	editProfileOk(t, id, map[string]any{
+		"bio": "new bio",
+	}, soaTokenAuth(token))
+
+	resp = tryRevokeApiToken(t, tokenId, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	expected := []struct {
+		eventType      string
+		identifierType string
+	}{
+		{"api_token_revoked", "jwt"},
+		{"profile_edited", "api_token"},
+		{"api_token_created", "phone_number"},
+		{"login_succeeded", "login"},
+		{"login_failed", "email"},
+	}
+
+	events := listAuditEventsOk(t, jwtAuth(jwt))
+	require.Len(t, events, len(expected))
+	for i, e := range events {
+		event := e.(map[string]any)
+		assert.Equal(t, expected[i].eventType, event["type"].(string))
+		assert.Equal(t, expected[i].identifierType, event["identifier_type"].(string))
+		assert.NotEmpty(t, event["client_ip"].(string))
+		assert.NotEmpty(t, event["created_at"].(string))
+	}
+}
+
+func TestOAuth(t *testing.T) {
+	id := registerUserOk(t, map[string]any{
+		"login":        "oauth",
+		"password":     "testpasswd",
+		"email":        "oauth@yahoo.com",
+		"phone_number": "+79250000058",
+		"name":         "Test",
+		"surname":      "OAuth",
+	})
+
+	jwt := authenticateOk(t, map[string]any{
+		"login":    "oauth",
+		"password": "testpasswd",
+	})
+
+	redirectUri := "https://app.example.com/callback"
+	client := registerOAuthClientOk(t, map[string]any{
+		"name":          "Example app",
+		"redirect_uris": []string{redirectUri},
+	}, jwtAuth(jwt))
+	clientId := client["client_id"].(string)
+
+	resp := makeRequest(t, http.MethodGet, fmt.Sprintf("/oauth/clients/%s", clientId), nil, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Example app", responseBodyToMap(t, resp)["name"].(string))
+
+	verifier := strings.Repeat("verifier", 6)
+	authorizeRequest := map[string]any{
+		"client_id":             clientId,
+		"redirect_uri":          redirectUri,
+		"scopes":                []string{"profile:write"},
+		"state":                 "xyz",
+		"code_challenge":        oauthCodeChallenge(verifier),
+		"code_challenge_method": "S256",
+	}
+
+	resp = tryAuthorizeOAuthClient(t, map[string]any{
+		"client_id":             clientId,
+		"redirect_uri":          "https://evil.example.com/callback",
+		"scopes":                []string{"profile:write"},
+		"code_challenge":        oauthCodeChallenge(verifier),
+		"code_challenge_method": "S256",
+	}, jwtAuth(jwt))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = tryAuthorizeOAuthClient(t, authorizeRequest, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	authorized := responseBodyToMap(t, resp)
+	code := authorized["code"].(string)
+
+	redirectTo, err := url.Parse(authorized["redirect_to"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, code, redirectTo.Query().Get("code"))
+	assert.Equal(t, "xyz", redirectTo.Query().Get("state"))
+
+	tokenRequest := map[string]any{
+		"grant_type":    "authorization_code",
+		"client_id":     clientId,
+		"code":          code,
+		"redirect_uri":  redirectUri,
+		"code_verifier": verifier,
+	}
+
+	resp = tryExchangeOAuthCode(t, tokenRequest)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := responseBodyToMap(t, resp)["access_token"].(string)
+
+	editProfileOk(t, id, map[string]any{
+		"bio": "edited by app",
+	}, soaTokenAuth(token))
+
+	apps := listAuthorizedAppsOk(t, jwtAuth(jwt))
+	require.Len(t, apps, 1)
+	app := apps[0].(map[string]any)
+	assert.Equal(t, clientId, app["client_id"].(string))
+	assert.Equal(t, "Example app", app["name"].(string))
+	assert.Equal(t, []any{"profile:write"}, app["scopes"])
+
+	// replayed code revokes tokens issued by it
+	resp = tryExchangeOAuthCode(t, tokenRequest)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = tryEditProfile(t, id, map[string]any{
+		"bio": "replayed",
+	}, soaTokenAuth(token))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Empty(t, listAuthorizedAppsOk(t, jwtAuth(jwt)))
+
+	// wrong verifier burns the code
+	resp = tryAuthorizeOAuthClient(t, authorizeRequest, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	tokenRequest["code"] = responseBodyToMap(t, resp)["code"].(string)
+	tokenRequest["code_verifier"] = strings.Repeat("wrong", 10)
+
+	resp = tryExchangeOAuthCode(t, tokenRequest)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	tokenRequest["code_verifier"] = verifier
+	resp = tryExchangeOAuthCode(t, tokenRequest)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = tryAuthorizeOAuthClient(t, authorizeRequest, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	tokenRequest["code"] = responseBodyToMap(t, resp)["code"].(string)
+
+	resp = tryExchangeOAuthCode(t, tokenRequest)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token = responseBodyToMap(t, resp)["access_token"].(string)
+
+	// applications can not authorize other ones
+	resp = tryAuthorizeOAuthClient(t, authorizeRequest, soaTokenAuth(token))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryRevokeAuthorizedApp(t, clientId, jwtAuth(jwt))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = tryEditProfile(t, id, map[string]any{
+		"bio": "revoked",
+	}, soaTokenAuth(token))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryRevokeAuthorizedApp(t, clientId, jwtAuth(jwt))
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// Compose stack has no identity providers, logins with them are covered by
+// accounts tests against a mock provider
+func TestOidcWithoutProviders(t *testing.T) {
+	registerUserOk(t, map[string]any{
+		"login":        "oidc",
+		"password":     "testpasswd",
+		"email":        "oidc@yahoo.com",
+		"phone_number": "+79250000059",
+		"name":         "Test",
+		"surname":      "Oidc",
+	})
+
+	jwt := authenticateOk(t, map[string]any{
+		"login":    "oidc",
+		"password": "testpasswd",
+	})
+
+	require.Empty(t, listOidcProvidersOk(t))
+
+	resp := tryBeginOidcLogin(t, "corp")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = tryCompleteOidcLogin(t, "corp", map[string]any{
+		"code":  "code",
+		"state": "state",
+	})
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	require.Empty(t, listOidcIdentitiesOk(t, jwtAuth(jwt)))
+
+	resp = tryUnlinkOidcIdentity(t, "corp", jwtAuth(jwt))
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
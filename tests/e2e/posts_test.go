@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -115,6 +116,15 @@ func getCommentsOk(t *testing.T, postId int, getCommentsRequest map[string]any,
 	return responseBodyToMap(t, resp)
 }
 
+func tryDeleteComment(t *testing.T, postId int, commentId int, auth string) *http.Response {
+	return makeRequest(t, http.MethodDelete, fmt.Sprintf("/post/%d/comments/%d", postId, commentId), nil, auth)
+}
+
+func deleteCommentOk(t *testing.T, postId int, commentId int, auth string) {
+	resp := tryDeleteComment(t, postId, commentId, auth)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
 func TestEditPageSettings(t *testing.T) {
 	id := registerUserOk(t, map[string]any{
 		"login":        "edit_page_settings",
@@ -186,6 +196,11 @@ func TestCreatePost(t *testing.T) {
 
 	postResponse := getPostOk(t, postId, jwtAuth(token))
 	assert.Equal(t, post["text"].(string), postResponse["text"].(string))
+
+	author := postResponse["author"].(map[string]any)
+	assert.Equal(t, id, author["profile_id"])
+	assert.Equal(t, "Create", author["name"])
+	assert.Equal(t, "Post", author["surname"])
 }
 
 func TestCreatePostRepost(t *testing.T) {
@@ -229,6 +244,103 @@ func TestCreatePostRepost(t *testing.T) {
 	assert.Equal(t, postId, int(postResponse["source_post_id"].(float64)))
 }
 
+func TestCreatePostSoaToken(t *testing.T) {
+	id := registerUserOk(t, map[string]any{
+		"login":        "create_post_soa",
+		"password":     "testpasswd",
+		"email":        "create_post_soa@yahoo.com",
+		"phone_number": "+79250000031",
+		"name":         "Create",
+		"surname":      "PostSoa",
+	})
+
+	createToken := func(writeAccess bool) string {
+		resp := tryCreateApiToken(t, map[string]any{
+			"auth": map[string]any{
+				"login":    "create_post_soa",
+				"password": "testpasswd",
+			},
+			"read_access":  true,
+			"write_access": writeAccess,
+			"ttl":          "1h",
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return responseBodyToMap(t, resp)["token"].(string)
+	}
+
+	post := map[string]any{
+		"text": "new test post!",
+	}
+
+	readOnlyToken := createToken(false)
+	resp := tryCreatePost(t, id, post, soaTokenAuth(readOnlyToken))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	token := createToken(true)
+	postId := createPostOk(t, id, post, soaTokenAuth(token))
+
+	postResponse := getPostOk(t, postId, soaTokenAuth(readOnlyToken))
+	assert.Equal(t, post["text"].(string), postResponse["text"].(string))
+
+	// tampered token must be rejected
+	forgedToken := token[:len(token)-1] + "A"
+	if forgedToken == token {
+		forgedToken = token[:len(token)-1] + "B"
+	}
+	resp = tryCreatePost(t, id, post, soaTokenAuth(forgedToken))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestCreatePostSoaTokenScopes(t *testing.T) {
+	id := registerUserOk(t, map[string]any{
+		"login":        "create_post_scopes",
+		"password":     "testpasswd",
+		"email":        "create_post_scopes@yahoo.com",
+		"phone_number": "+79250000037",
+		"name":         "Create",
+		"surname":      "PostScopes",
+	})
+
+	createToken := func(scopes ...string) string {
+		return createApiTokenOk(t, map[string]any{
+			"auth": map[string]any{
+				"login":    "create_post_scopes",
+				"password": "testpasswd",
+			},
+			"scopes": scopes,
+			"ttl":    "1h",
+		})
+	}
+
+	post := map[string]any{
+		"text": "new test post!",
+	}
+
+	readToken := createToken("posts:read")
+	resp := tryCreatePost(t, id, post, soaTokenAuth(readToken))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	writeToken := createToken("posts:write")
+	postId := createPostOk(t, id, post, soaTokenAuth(writeToken))
+
+	getPostOk(t, postId, soaTokenAuth(readToken))
+
+	resp = tryEditProfile(t, id, map[string]any{
+		"bio": "new bio",
+	}, soaTokenAuth(writeToken))
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryCreateApiToken(t, map[string]any{
+		"auth": map[string]any{
+			"login":    "create_post_scopes",
+			"password": "testpasswd",
+		},
+		"scopes": []string{"posts:delete"},
+		"ttl":    "1h",
+	})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestGetPosts(t *testing.T) {
 	id := registerUserOk(t, map[string]any{
 		"login":        "get_posts",
@@ -449,5 +561,81 @@ func TestGetComments(t *testing.T) {
 	for i := range len(publishedComments) {
 		assert.Equal(t, publishedComments[i]["id"].(int), int(receivedComments[i]["id"].(float64)))
 		assert.Equal(t, publishedComments[i]["content"].(string), receivedComments[i]["content"].(string))
+		assert.Equal(t, id, receivedComments[i]["author"].(map[string]any)["profile_id"])
 	}
 }
+
+func TestBlocks(t *testing.T) {
+	ownerId := registerUserOk(t, map[string]any{
+		"login":        "blocks_owner",
+		"password":     "testpasswd",
+		"email":        "blocks_owner@yahoo.com",
+		"phone_number": "+79250000047",
+		"name":         "Blocks",
+		"surname":      "Owner",
+	})
+	ownerAuth := jwtAuth(authenticateOk(t, map[string]any{
+		"login":    "blocks_owner",
+		"password": "testpasswd",
+	}))
+
+	blockedId := registerUserOk(t, map[string]any{
+		"login":        "blocks_blocked",
+		"password":     "testpasswd",
+		"email":        "blocks_blocked@yahoo.com",
+		"phone_number": "+79250000048",
+		"name":         "Blocks",
+		"surname":      "Blocked",
+	})
+	blockedAuth := jwtAuth(authenticateOk(t, map[string]any{
+		"login":    "blocks_blocked",
+		"password": "testpasswd",
+	}))
+
+	editPageSettingsOk(t, ownerId, map[string]any{
+		"comments_enabled": true,
+		"anyone_can_post":  true,
+	}, ownerAuth)
+
+	postId := createPostOk(t, ownerId, map[string]any{"text": "post of blocker"}, ownerAuth)
+	createPostOk(t, ownerId, map[string]any{"text": "post before block"}, blockedAuth)
+
+	blockOk(t, blockedId, ownerAuth)
+	blockOk(t, blockedId, ownerAuth)
+	assert.Equal(t, []string{blockedId}, listBlockedOk(t, ownerAuth))
+	assert.Empty(t, listBlockedOk(t, blockedAuth))
+
+	// block reaches posts service asynchronously
+	require.Eventually(t, func() bool {
+		resp := tryGetPost(t, postId, blockedAuth)
+		return resp.StatusCode == http.StatusForbidden
+	}, 10*time.Second, 200*time.Millisecond)
+
+	resp := tryCreatePost(t, ownerId, map[string]any{"text": "post after block"}, blockedAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryNewComment(t, postId, map[string]any{"content": "comment after block"}, blockedAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryNewLike(t, postId, blockedAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = tryGetPosts(t, ownerId, map[string]any{}, blockedAuth)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// block is one-directional
+	getPostsOk(t, blockedId, map[string]any{}, ownerAuth)
+
+	resp = tryBlock(t, ownerId, ownerAuth)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	unblockOk(t, blockedId, ownerAuth)
+	assert.Empty(t, listBlockedOk(t, ownerAuth))
+
+	require.Eventually(t, func() bool {
+		resp := tryGetPost(t, postId, blockedAuth)
+		return resp.StatusCode == http.StatusOK
+	}, 10*time.Second, 200*time.Millisecond)
+
+	newLikeOk(t, postId, blockedAuth)
+}
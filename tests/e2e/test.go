@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,10 +16,28 @@ import (
 
 var gateway_api_url = "http://localhost:8080/api/v1"
 
+// Must match ACCOUNTS_NOTIFICATIONS_DIR from test.env
+var accounts_notifications_dir = "/temp/soa-e2e-test/accounts-notifications"
+
 func gatewayApiUrl() string {
 	return gateway_api_url
 }
 
+// Returns code from the last message written by accounts file notifier
+func lastNotificationCode(t *testing.T, address string) string {
+	content, err := os.ReadFile(filepath.Join(accounts_notifications_dir, address))
+	require.NoError(t, err, "cannot read notifications of %s", address)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	var notification struct {
+		Code string `json:"code"`
+	}
+	err = json.Unmarshal([]byte(lines[len(lines)-1]), &notification)
+	require.NoError(t, err, "cannot parse notification of %s", address)
+
+	return notification.Code
+}
+
 func makeReader(request any) io.Reader {
 	if request == nil {
 		return nil